@@ -0,0 +1,97 @@
+package base
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// parallelBatchSize is the number of examples at
+// which PredictBatch starts splitting predictions
+// across a pool of goroutines. Below it the overhead
+// of starting the workers isn't worth it.
+const parallelBatchSize = 1000
+
+// PredictBatch runs the given prediction function on
+// every row of x, returning the predictions in the
+// same order as the rows. Models use this to implement
+// their own PredictBatch methods, passing in the number
+// of features they expect each row to have.
+//
+// Every row is checked to have the given number of
+// features before anything is predicted, so an error
+// is returned for the first malformed row (along with
+// its index) without doing any work. If a prediction
+// itself fails, the error of the earliest failing row
+// is returned.
+//
+// Batches of at least 1000 rows are predicted in
+// parallel across runtime.NumCPU() goroutines, so
+// predict must be safe to call concurrently.
+func PredictBatch(x [][]float64, features int, predict func([]float64) ([]float64, error)) ([][]float64, error) {
+	for i := range x {
+		if len(x[i]) != features {
+			return nil, fmt.Errorf("ERROR: %w: Row %v of the batch has the wrong dimension!\n\tLength of row: %v\n\tExpected length: %v\n", ErrDimensionMismatch, i, len(x[i]), features)
+		}
+	}
+
+	guesses := make([][]float64, len(x))
+
+	workers := runtime.NumCPU()
+	if len(x) < parallelBatchSize || workers < 2 {
+		for i := range x {
+			guess, err := predict(x[i])
+			if err != nil {
+				return nil, fmt.Errorf("ERROR: Couldn't predict row %v of the batch!\n\t%w", i, err)
+			}
+			guesses[i] = guess
+		}
+
+		return guesses, nil
+	}
+
+	// split the batch into contiguous chunks,
+	// one for each worker, and keep track of the
+	// first error found within each chunk
+	errs := make([]error, workers)
+	failed := make([]int, workers)
+	chunk := (len(x) + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * chunk
+		end := start + chunk
+		if end > len(x) {
+			end = len(x)
+		}
+		if start >= end {
+			break
+		}
+
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+
+			for i := start; i < end; i++ {
+				guess, err := predict(x[i])
+				if err != nil {
+					errs[w] = err
+					failed[w] = i
+					return
+				}
+				guesses[i] = guess
+			}
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	// chunks are in order, so the first chunk
+	// with an error holds the earliest failure
+	for w := range errs {
+		if errs[w] != nil {
+			return nil, fmt.Errorf("ERROR: Couldn't predict row %v of the batch!\n\t%w", failed[w], errs[w])
+		}
+	}
+
+	return guesses, nil
+}
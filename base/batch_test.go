@@ -0,0 +1,79 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sumPredict(x []float64) ([]float64, error) {
+	var sum float64
+	for i := range x {
+		sum += x[i]
+	}
+
+	return []float64{sum}, nil
+}
+
+func TestPredictBatchShouldPass1(t *testing.T) {
+	x := [][]float64{[]float64{1, 2}, []float64{3, 4}, []float64{-1, 1}}
+
+	guesses, err := PredictBatch(x, 2, sumPredict)
+	assert.Nil(t, err, "Batch prediction error should be nil")
+	assert.Equal(t, [][]float64{[]float64{3}, []float64{7}, []float64{0}}, guesses, "Batch predictions should match")
+
+	guesses, err = PredictBatch([][]float64{}, 2, sumPredict)
+	assert.Nil(t, err, "Batch prediction error should be nil")
+	assert.Len(t, guesses, 0, "An empty batch should have no predictions")
+}
+
+// large batches are predicted in parallel
+// but should keep their order
+func TestPredictBatchShouldPass2(t *testing.T) {
+	x := make([][]float64, 5*parallelBatchSize+3)
+	for i := range x {
+		x[i] = []float64{float64(i), 1}
+	}
+
+	guesses, err := PredictBatch(x, 2, sumPredict)
+	assert.Nil(t, err, "Batch prediction error should be nil")
+	assert.Len(t, guesses, len(x), "There should be a prediction for every row")
+
+	for i := range guesses {
+		assert.Equal(t, []float64{float64(i + 1)}, guesses[i], "Prediction %v should be in order", i)
+	}
+}
+
+func TestPredictBatchShouldFail1(t *testing.T) {
+	var calls int
+	x := [][]float64{[]float64{1, 2}, []float64{3}, []float64{4, 5, 6}}
+
+	_, err := PredictBatch(x, 2, func(x []float64) ([]float64, error) {
+		calls++
+		return sumPredict(x)
+	})
+	assert.NotNil(t, err, "Batch prediction error should not be nil")
+	assert.Contains(t, err.Error(), "Row 1", "Error should give the index of the first malformed row")
+	assert.True(t, errors.Is(err, ErrDimensionMismatch), "Batch prediction error should wrap ErrDimensionMismatch - Given %v", err)
+	assert.Equal(t, 0, calls, "Nothing should be predicted when a row is malformed")
+}
+
+func TestPredictBatchShouldFail2(t *testing.T) {
+	x := make([][]float64, 2*parallelBatchSize)
+	for i := range x {
+		x[i] = []float64{float64(i)}
+	}
+
+	tooBig := fmt.Errorf("too big")
+	_, err := PredictBatch(x, 1, func(x []float64) ([]float64, error) {
+		if x[0] >= 1500 {
+			return nil, tooBig
+		}
+		return x, nil
+	})
+	assert.NotNil(t, err, "Batch prediction error should not be nil")
+	assert.Contains(t, err.Error(), "row 1500", "Error should give the index of the earliest failing row")
+	assert.True(t, errors.Is(err, tooBig), "Batch prediction error should wrap the model's error")
+}
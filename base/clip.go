@@ -0,0 +1,77 @@
+package base
+
+import (
+	"math"
+)
+
+// GradientClipping limits how large a gradient can be
+// before a model takes a step with it, so one huge
+// gradient (from a learning rate that's a bit too
+// aggressive, or an outlier in the training set)
+// gets scaled down rather than sending the parameter
+// vector off to ±Inf.
+//
+// Either (or both) of the limits can be used. A limit
+// that isn't positive is turned off.
+type GradientClipping struct {
+	// MaxNorm clips the gradient by its L2 norm: a
+	// gradient with a norm larger than MaxNorm is
+	// scaled down to have a norm of exactly MaxNorm,
+	// keeping its direction
+	MaxNorm float64
+
+	// MaxValue clips the gradient component-wise:
+	// each value is clamped to [-MaxValue, MaxValue]
+	MaxValue float64
+}
+
+// Clipped is implemented by models which can clip
+// their gradient during training. GradientAscent and
+// the other optimization methods in this package clip
+// the gradient before every step when the model
+// implements it.
+type Clipped interface {
+	// GradientClipping returns the limits the
+	// gradient is clipped to (nil to turn clipping
+	// off)
+	GradientClipping() *GradientClipping
+}
+
+// Clip clips the gradient grad in place, first by
+// value (if MaxValue is positive) and then by norm
+// (if MaxNorm is positive.) A nil GradientClipping
+// leaves the gradient untouched.
+func (c *GradientClipping) Clip(grad []float64) {
+	if c == nil {
+		return
+	}
+
+	if c.MaxValue > 0 {
+		for j := range grad {
+			grad[j] = math.Max(-c.MaxValue, math.Min(c.MaxValue, grad[j]))
+		}
+	}
+
+	if c.MaxNorm > 0 {
+		var norm float64
+		for j := range grad {
+			norm += grad[j] * grad[j]
+		}
+		norm = math.Sqrt(norm)
+
+		if norm > c.MaxNorm {
+			scale := c.MaxNorm / norm
+			for j := range grad {
+				grad[j] *= scale
+			}
+		}
+	}
+}
+
+// clipGradient clips the gradient grad in place if
+// the model d is Clipped
+func clipGradient(d interface{}, grad []float64) {
+	if c, ok := d.(Clipped); ok {
+		c.GradientClipping().Clip(grad)
+	}
+}
@@ -0,0 +1,27 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGradientClippingShouldPass1(t *testing.T) {
+	grad := []float64{3, -4}
+	(&GradientClipping{MaxNorm: 1}).Clip(grad)
+	assert.InDelta(t, 0.6, grad[0], 1e-12, "Gradient should be scaled to a norm of 1")
+	assert.InDelta(t, -0.8, grad[1], 1e-12, "Gradient should be scaled to a norm of 1")
+
+	grad = []float64{3, -4}
+	(&GradientClipping{MaxNorm: 10}).Clip(grad)
+	assert.Equal(t, []float64{3, -4}, grad, "Gradient within the max norm shouldn't change")
+
+	grad = []float64{3, -4, 0.5}
+	(&GradientClipping{MaxValue: 1}).Clip(grad)
+	assert.Equal(t, []float64{1, -1, 0.5}, grad, "Gradient should be clamped by value")
+
+	grad = []float64{3, -4}
+	var clipping *GradientClipping
+	clipping.Clip(grad)
+	assert.Equal(t, []float64{3, -4}, grad, "A nil GradientClipping shouldn't change the gradient")
+}
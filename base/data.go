@@ -6,22 +6,29 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // LoadDataFromCSV takes in a path to a CSV file and
 // loads that data into a Golang 2D array of 'X' values
 // and a Golang 1D array of 'Y', or expected result,
-// values.
+// values. This is the same format SaveDataToCSV writes.
 //
-// Errors are returned if there are any problems
+// Errors are returned if there are any problems. If
+// a value can't be parsed as a float, the error will
+// include the row and column (both starting at 1) of
+// the value within the file.
 //
 // Expected Data Format:
-// - There should be no header/text lines.
+// - There can be at most one header/text line, which
+//     must be the first line. It's detected by none
+//     of its values being numbers, and is skipped.
 // - The 'Y' (expected value) line should be the last
 //     column of the CSV.
 //
 // Example CSV file with 2 input parameters:
 //     >>>>>>> BEGIN FILE
+//     size,bedrooms,price
 //     1.06,2.30,17
 //     17.62,12.06,18.92
 //     11.623,1.1,15.093
@@ -44,31 +51,27 @@ func LoadDataFromCSV(filepath string) ([][]float64, []float64, error) {
 	x := [][]float64{}
 	y := []float64{}
 
-	record, err := reader.Read()
-	if err != nil {
-		return nil, nil, err
-	}
-
 	// parse until the end of the file
-	for err != io.EOF {
-		var row []float64
-
-		for i, val := range record {
-			float, err := strconv.ParseFloat(val, 64)
-			if err != nil {
-				return nil, nil, err
-			}
-
-			if i < len(record)-1 {
-				row = append(row, float)
-			} else {
-				y = append(y, float)
-			}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
 		}
 
-		x = append(x, row)
+		if line == 1 && isCSVHeader(record) {
+			continue
+		}
+
+		row, err := parseCSVRecord(record, line)
+		if err != nil {
+			return nil, nil, err
+		}
 
-		record, err = reader.Read()
+		x = append(x, row[:len(row)-1])
+		y = append(y, row[len(row)-1])
 	}
 
 	if len(x) == 0 || len(x[0]) == 0 || len(y) == 0 {
@@ -93,62 +96,85 @@ func LoadDataFromCSV(filepath string) ([][]float64, []float64, error) {
 // data stream channel and the errors channel will
 // be closed.
 func LoadDataFromCSVToStream(filepath string, data chan Datapoint, errors chan error) {
+	defer close(data)
+	defer close(errors)
+
 	_, err := os.Stat(filepath)
 	if err != nil {
 		errors <- err
-		close(errors)
-		close(data)
 		return
 	}
 
 	file, err := os.Open(filepath)
 	if err != nil {
 		errors <- err
-		close(errors)
-		close(data)
 		return
 	}
 	defer file.Close()
 
 	reader := csv.NewReader(file)
 
-	record, err := reader.Read()
-	if err != nil {
-		errors <- err
-		close(errors)
-		close(data)
-		return
+	// parse until the end of the file
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			errors <- err
+			return
+		}
+
+		if line == 1 && isCSVHeader(record) {
+			continue
+		}
+
+		row, err := parseCSVRecord(record, line)
+		if err != nil {
+			errors <- err
+			return
+		}
+
+		data <- Datapoint{
+			X: row[:len(row)-1],
+			Y: []float64{row[len(row)-1]},
+		}
 	}
+}
 
-	// parse until the end of the file
-	for err != io.EOF {
-		var row []float64
-
-		for i, val := range record {
-			float, err := strconv.ParseFloat(val, 64)
-			if err != nil {
-				errors <- err
-				close(errors)
-				close(data)
-				return
-			}
-
-			if i < len(record)-1 {
-				row = append(row, float)
-			} else {
-				data <- Datapoint{
-					X: row,
-					Y: []float64{float},
-				}
-			}
+// isCSVHeader returns whether a CSV record looks
+// like a header line, which is when none of its
+// values can be parsed as numbers
+func isCSVHeader(record []string) bool {
+	for _, val := range record {
+		_, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseCSVRecord parses every value of a CSV record
+// as a float64, returning an error with the line and
+// column of the first value that can't be parsed
+func parseCSVRecord(record []string, line int) ([]float64, error) {
+	if len(record) < 2 {
+		return nil, fmt.Errorf("ERROR: Row %v of the CSV has %v column(s) but needs at least 2 (the input features and the expected result)", line, len(record))
+	}
+
+	row := make([]float64, len(record))
+	for i, val := range record {
+		float, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil {
+			return nil, fmt.Errorf("ERROR: Couldn't parse value %q at row %v, column %v of the CSV as a number", val, line, i+1)
 		}
 
-		record, err = reader.Read()
+		row[i] = float
 	}
 
-	close(errors)
-	close(data)
-	return
+	return row, nil
 }
 
 // SaveDataToCSV takes in a absolute filepath, as well
@@ -161,21 +187,16 @@ func LoadDataFromCSVToStream(filepath string, data chan Datapoint, errors chan e
 // will be stored with a 64 bit precision when converting
 // the floats to strings. Otherwise (if it's false) it
 // uses 32 bits.
+//
+// It's a wrapper around WriteDataToCSV which creates
+// the file to write to.
 func SaveDataToCSV(filepath string, x [][]float64, y []float64, highPrecision bool) error {
-	lenX := len(x)
-	lenY := len(y)
-	var numFeatures int
-
-	if lenX != 0 {
-		numFeatures = len(x[0])
-	}
-
-	if lenX == 0 || lenY == 0 || numFeatures == 0 || lenX != lenY {
-
-		return fmt.Errorf("ERROR: Training set (either x or y or both) has no examples or the lengths of the dataset don't match\n\tlength of x: %v\n\tlength of y: %v\n\tnumber of features in x: %v\n", lenX, lenY, numFeatures)
+	err := checkCSVData(x, y)
+	if err != nil {
+		return err
 	}
 
-	_, err := os.Stat(filepath)
+	_, err = os.Stat(filepath)
 	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
@@ -186,6 +207,26 @@ func SaveDataToCSV(filepath string, x [][]float64, y []float64, highPrecision bo
 	}
 	defer file.Close()
 
+	return WriteDataToCSV(file, x, y, highPrecision)
+}
+
+// WriteDataToCSV writes a 2D array of 'X' values and
+// a 1D array of 'Y', or expected values, to w in the
+// same format as LoadDataFromCSV (and SaveDataToCSV),
+// returning any errors. This lets you write the data
+// to anything, like a network connection, an HTTP
+// response, or an in-memory buffer, not just a file.
+//
+// highPrecision is a boolean where if true the values
+// will be stored with a 64 bit precision when converting
+// the floats to strings. Otherwise (if it's false) it
+// uses 32 bits.
+func WriteDataToCSV(w io.Writer, x [][]float64, y []float64, highPrecision bool) error {
+	err := checkCSVData(x, y)
+	if err != nil {
+		return err
+	}
+
 	var precision int
 	if highPrecision {
 		precision = 64
@@ -193,7 +234,7 @@ func SaveDataToCSV(filepath string, x [][]float64, y []float64, highPrecision bo
 		precision = 32
 	}
 
-	writer := csv.NewWriter(file)
+	writer := csv.NewWriter(w)
 	records := [][]string{}
 
 	// parse until the end of the file
@@ -209,7 +250,7 @@ func SaveDataToCSV(filepath string, x [][]float64, y []float64, highPrecision bo
 		records = append(records, record)
 	}
 
-	// now save the record to file
+	// now write the records out
 	err = writer.WriteAll(records)
 	if err != nil {
 		return err
@@ -217,3 +258,22 @@ func SaveDataToCSV(filepath string, x [][]float64, y []float64, highPrecision bo
 
 	return nil
 }
+
+// checkCSVData returns an error if the dataset
+// can't be saved as a CSV (it's empty or the
+// lengths of x and y don't match)
+func checkCSVData(x [][]float64, y []float64) error {
+	lenX := len(x)
+	lenY := len(y)
+	var numFeatures int
+
+	if lenX != 0 {
+		numFeatures = len(x[0])
+	}
+
+	if lenX == 0 || lenY == 0 || numFeatures == 0 || lenX != lenY {
+		return fmt.Errorf("ERROR: Training set (either x or y or both) has no examples or the lengths of the dataset don't match\n\tlength of x: %v\n\tlength of y: %v\n\tnumber of features in x: %v\n", lenX, lenY, numFeatures)
+	}
+
+	return nil
+}
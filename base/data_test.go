@@ -1,7 +1,9 @@
 package base
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"testing"
 
@@ -64,6 +66,27 @@ func TestSaveDataToCSVShouldPass2(t *testing.T) {
 	}
 }
 
+func TestWriteDataToCSVShouldPass1(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := WriteDataToCSV(buf, x, y, true)
+	assert.Nil(t, err, "Error writing data should be nil")
+
+	err = SaveDataToCSV("/tmp/.goml/CSVWrite.csv", x, y, true)
+	assert.Nil(t, err, "Error saving data should be nil")
+
+	saved, err := ioutil.ReadFile("/tmp/.goml/CSVWrite.csv")
+	assert.Nil(t, err, "Error reading saved CSV should be nil")
+
+	assert.Equal(t, string(saved), buf.String(), "Written CSV should match the one saved to file")
+}
+
+func TestWriteDataToCSVShouldFail1(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := WriteDataToCSV(buf, x, []float64{}, false)
+	assert.NotNil(t, err, "Error writing data should not be nil")
+	assert.Equal(t, 0, buf.Len(), "Nothing should be written on error")
+}
+
 func TestSaveDataToCSVShouldFail1(t *testing.T) {
 	err := SaveDataToCSV("/tmp/.goml/CSVFail1.csv", x, y, true)
 	assert.Nil(t, err, "Error saving data should be nil")
@@ -92,6 +115,53 @@ func TestSaveDataToCSVShouldFail5(t *testing.T) {
 	assert.NotNil(t, err, "Error saving data should not be nil")
 }
 
+// a header line should be skipped
+func TestLoadDataFromCSVShouldPass1(t *testing.T) {
+	err := ioutil.WriteFile("/tmp/.goml/CSVHeader.csv", []byte("size,bedrooms,price\n1.5,2,17\n-3,4e2,18.25\n"), os.ModePerm)
+	assert.Nil(t, err, "Error saving data should be nil")
+
+	newX, newY, err := LoadDataFromCSV("/tmp/.goml/CSVHeader.csv")
+	assert.Nil(t, err, "Error loading CSV data should be nil")
+
+	assert.Equal(t, [][]float64{[]float64{1.5, 2}, []float64{-3, 400}}, newX, "X should match the CSV without the header")
+	assert.Equal(t, []float64{17, 18.25}, newY, "Y should match the CSV without the header")
+}
+
+// values which aren't numbers (past the header)
+// should return an error with their row and column
+func TestLoadDataFromCSVShouldFail1(t *testing.T) {
+	err := ioutil.WriteFile("/tmp/.goml/CSVFailParse.csv", []byte("x,y\n1,2\n3,four\n"), os.ModePerm)
+	assert.Nil(t, err, "Error saving data should be nil")
+
+	_, _, err = LoadDataFromCSV("/tmp/.goml/CSVFailParse.csv")
+	assert.NotNil(t, err, "Error loading CSV data should not be nil")
+	assert.Contains(t, err.Error(), "row 3, column 2", "Error should include the row and column of the bad value")
+
+	// only the first line can be a header
+	err = ioutil.WriteFile("/tmp/.goml/CSVFailHeader.csv", []byte("x,y\n1,2\nx,y\n"), os.ModePerm)
+	assert.Nil(t, err, "Error saving data should be nil")
+
+	_, _, err = LoadDataFromCSV("/tmp/.goml/CSVFailHeader.csv")
+	assert.NotNil(t, err, "Error loading CSV data should not be nil")
+	assert.Contains(t, err.Error(), "row 3, column 1", "Error should include the row and column of the bad value")
+}
+
+// files with only a header or a single column
+// have no valid examples
+func TestLoadDataFromCSVShouldFail2(t *testing.T) {
+	err := ioutil.WriteFile("/tmp/.goml/CSVFailEmpty.csv", []byte("x,y\n"), os.ModePerm)
+	assert.Nil(t, err, "Error saving data should be nil")
+
+	_, _, err = LoadDataFromCSV("/tmp/.goml/CSVFailEmpty.csv")
+	assert.NotNil(t, err, "Error loading CSV data should not be nil")
+
+	err = ioutil.WriteFile("/tmp/.goml/CSVFailColumns.csv", []byte("1\n2\n"), os.ModePerm)
+	assert.Nil(t, err, "Error saving data should be nil")
+
+	_, _, err = LoadDataFromCSV("/tmp/.goml/CSVFailColumns.csv")
+	assert.NotNil(t, err, "Error loading CSV data should not be nil")
+}
+
 func TestLoadDataFromCSVToStreamShouldPass1(t *testing.T) {
 	err := SaveDataToCSV("/tmp/.goml/CSV_stream.csv", x, y, true)
 	assert.Nil(t, err, "Error saving data should be nil")
@@ -72,3 +72,34 @@ func LNorm(p int) DistanceMeasure {
 		return math.Pow(sum, 1/float64(p))
 	}
 }
+
+// CosineDistance returns 1 minus the cosine
+// similarity between two float64 vectors,
+// which only depends on the angle between
+// them (not their magnitudes.) The distance
+// is 0 for vectors pointing the same way,
+// 1 for orthogonal vectors, and 2 for
+// opposite vectors. This is common for
+// comparing text embeddings.
+//
+// If either vector is the zero vector the
+// distance is 1 (as if they're orthogonal.)
+//
+// NOTE that this function does not check that
+// the vectors are different lengths (to improve
+// computation speed in, say, KNN.) Make
+// sure you pass in same-length vectors.
+func CosineDistance(u []float64, v []float64) float64 {
+	var dot, normU, normV float64
+	for i := range u {
+		dot += u[i] * v[i]
+		normU += u[i] * u[i]
+		normV += v[i] * v[i]
+	}
+
+	if normU == 0 || normV == 0 {
+		return 1
+	}
+
+	return 1 - dot/math.Sqrt(normU*normV)
+}
@@ -33,3 +33,20 @@ func TestDistanceManhattanShouldPass2(t *testing.T) {
 
 	assert.InDelta(t, 213.0522, ManhattanDistance(u, v), 1e-3, "Distance should match")
 }
+
+func TestDistanceCosineShouldPass1(t *testing.T) {
+	u := []float64{1, 0}
+	v := []float64{0, 3}
+
+	assert.InDelta(t, 0, CosineDistance(u, []float64{5, 0}), 1e-9, "Distance should be 0 for vectors in the same direction")
+	assert.InDelta(t, 1, CosineDistance(u, v), 1e-9, "Distance should be 1 for orthogonal vectors")
+	assert.InDelta(t, 2, CosineDistance(u, []float64{-2, 0}), 1e-9, "Distance should be 2 for opposite vectors")
+	assert.InDelta(t, 1, CosineDistance(u, []float64{0, 0}), 1e-9, "Distance should be 1 with the zero vector")
+}
+
+func TestDistanceCosineShouldPass2(t *testing.T) {
+	u := []float64{1, 2, 3}
+	v := []float64{4, 5, 6}
+
+	assert.InDelta(t, 0.025368, CosineDistance(u, v), 1e-6, "Distance should match")
+}
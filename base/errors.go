@@ -0,0 +1,28 @@
+package base
+
+import "errors"
+
+// These are the errors models wrap (with %w) so you
+// can tell why a call failed without matching on the
+// error's message:
+//
+//	guess, err := model.Predict(x)
+//	if errors.Is(err, base.ErrDimensionMismatch) {
+//	    // x has the wrong number of features
+//	}
+var (
+	// ErrDimensionMismatch is wrapped when an input
+	// doesn't have the number of features the model
+	// (or dataset) expects
+	ErrDimensionMismatch = errors.New("dimension mismatch")
+
+	// ErrNotTrained is wrapped when a model is used
+	// before it's been trained (or fit)
+	ErrNotTrained = errors.New("model not trained")
+
+	// ErrDiverged is wrapped when learning stops
+	// because some value of the parameter vector
+	// became ±Inf or NaN. Try a smaller learning
+	// rate.
+	ErrDiverged = errors.New("learning diverged")
+)
@@ -0,0 +1,166 @@
+package base
+
+import (
+	"sync"
+)
+
+// Evaluation keeps track of how well an online model
+// is doing while it learns, using prequential (or
+// test-then-train) evaluation: every datapoint the
+// model gets off of its data stream is first predicted
+// by the model, before it trains on the datapoint, so
+// every prediction is on a point the model hasn't seen
+// yet. The accuracy and cost of those predictions are
+// averaged over a window of the most recent datapoints,
+// so a sudden drop in accuracy shows up quickly (ie.
+// when the data the model sees starts to drift away
+// from what it learned from.)
+//
+// Set a model's Evaluation field to one made with
+// NewEvaluation before calling OnlineLearn, then either
+// read Accuracy and Cost whenever you want (they're
+// safe to call while the model is learning) or set
+// OnEvaluate to be handed them every so often.
+//
+// The zero value averages over a window of the last
+// DefaultEvaluationWindow datapoints.
+type Evaluation struct {
+	// Every is how often (in datapoints) OnEvaluate
+	// is called. If it's 0 it's called once every
+	// window of datapoints.
+	Every int
+
+	// OnEvaluate, if not nil, is called with the
+	// rolling accuracy and cost every Every
+	// datapoints. Like the OnlineLearn onUpdate
+	// callback, it's spawned into a new goroutine.
+	OnEvaluate func(accuracy, cost float64)
+
+	mu sync.Mutex
+
+	// correct and costs are ring buffers holding
+	// the result of the last len(costs) predictions,
+	// with next the index the next result goes in
+	correct []bool
+	costs   []float64
+	next    int
+
+	// seen is the total number of datapoints
+	// evaluated, and filled how many of the ring
+	// buffers are filled so far
+	seen   int
+	filled int
+}
+
+// DefaultEvaluationWindow is the number of datapoints
+// an Evaluation averages over if it isn't given a
+// window (or is given one less than 1.)
+const DefaultEvaluationWindow = 100
+
+// NewEvaluation returns an Evaluation averaging the
+// accuracy and cost over the last window datapoints
+// seen. A window less than 1 is set to
+// DefaultEvaluationWindow.
+func NewEvaluation(window int) *Evaluation {
+	if window < 1 {
+		window = DefaultEvaluationWindow
+	}
+
+	return &Evaluation{
+		correct: make([]bool, window),
+		costs:   make([]float64, window),
+	}
+}
+
+// Add records the result of predicting a datapoint
+// the model hadn't trained on yet: whether the
+// prediction was correct and the cost (or loss) of
+// the prediction. Models call this from OnlineLearn,
+// so you'll only need it if you're writing your own.
+func (e *Evaluation) Add(correct bool, cost float64) {
+	e.mu.Lock()
+
+	if len(e.costs) == 0 {
+		e.correct = make([]bool, DefaultEvaluationWindow)
+		e.costs = make([]float64, DefaultEvaluationWindow)
+	}
+
+	e.correct[e.next] = correct
+	e.costs[e.next] = cost
+	e.next = (e.next + 1) % len(e.costs)
+	e.seen++
+	if e.filled < len(e.costs) {
+		e.filled++
+	}
+
+	every := e.Every
+	if every < 1 {
+		every = len(e.costs)
+	}
+	if e.OnEvaluate != nil && e.seen%every == 0 {
+		go e.OnEvaluate(e.accuracy(), e.cost())
+	}
+
+	e.mu.Unlock()
+}
+
+// Accuracy returns the fraction of the predictions
+// within the window that were correct (0 if no
+// datapoints have been seen.)
+func (e *Evaluation) Accuracy() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.accuracy()
+}
+
+// accuracy returns the accuracy within the
+// window. The caller must hold e.mu.
+func (e *Evaluation) accuracy() float64 {
+	if e.filled == 0 {
+		return 0
+	}
+
+	var count int
+	for i := 0; i < e.filled; i++ {
+		if e.correct[i] {
+			count++
+		}
+	}
+
+	return float64(count) / float64(e.filled)
+}
+
+// Cost returns the average cost of the predictions
+// within the window (0 if no datapoints have been
+// seen.)
+func (e *Evaluation) Cost() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.cost()
+}
+
+// cost returns the average cost within the
+// window. The caller must hold e.mu.
+func (e *Evaluation) cost() float64 {
+	if e.filled == 0 {
+		return 0
+	}
+
+	var sum float64
+	for i := 0; i < e.filled; i++ {
+		sum += e.costs[i]
+	}
+
+	return sum / float64(e.filled)
+}
+
+// Seen returns the total number of datapoints that
+// have been evaluated
+func (e *Evaluation) Seen() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.seen
+}
@@ -0,0 +1,76 @@
+package base
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluationShouldPass1(t *testing.T) {
+	e := NewEvaluation(4)
+	assert.Equal(t, 0.0, e.Accuracy(), "Accuracy should be 0 before any datapoints are seen")
+	assert.Equal(t, 0.0, e.Cost(), "Cost should be 0 before any datapoints are seen")
+
+	e.Add(true, 1)
+	e.Add(false, 3)
+	assert.InDelta(t, 0.5, e.Accuracy(), 1e-12, "Accuracy should only count the datapoints seen")
+	assert.InDelta(t, 2, e.Cost(), 1e-12, "Cost should only count the datapoints seen")
+
+	// the oldest results fall out of
+	// the window
+	e.Add(true, 0)
+	e.Add(true, 0)
+	e.Add(true, 0)
+	assert.InDelta(t, 0.75, e.Accuracy(), 1e-12, "Accuracy should be over the last 4 datapoints")
+	assert.InDelta(t, 0.75, e.Cost(), 1e-12, "Cost should be over the last 4 datapoints")
+	assert.Equal(t, 5, e.Seen(), "Every datapoint should be counted as seen")
+}
+
+func TestEvaluationCallbackShouldPass1(t *testing.T) {
+	results := make(chan float64, 10)
+
+	e := NewEvaluation(10)
+	e.Every = 3
+	e.OnEvaluate = func(accuracy, cost float64) {
+		results <- accuracy
+	}
+
+	for i := 0; i < 7; i++ {
+		e.Add(i%2 == 0, 1)
+	}
+
+	// the callbacks run in their own goroutines,
+	// so they can come back in any order
+	var accuracies []float64
+	for len(accuracies) < 2 {
+		select {
+		case accuracy := <-results:
+			accuracies = append(accuracies, accuracy)
+		case <-time.After(time.Second):
+			t.Fatalf("OnEvaluate should be called every 3 datapoints")
+		}
+	}
+	assert.ElementsMatch(t, []float64{2.0 / 3, 3.0 / 6}, accuracies, "OnEvaluate should be passed the rolling accuracy")
+
+	select {
+	case <-results:
+		t.Errorf("OnEvaluate should only be called every 3 datapoints")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	assert.Equal(t, 100, len(NewEvaluation(0).costs), "Window should default to 100")
+}
+
+// the zero value should use the default window
+// rather than panic on its first datapoint
+func TestEvaluationZeroValueShouldPass1(t *testing.T) {
+	e := &Evaluation{Every: 10}
+	for i := 0; i < DefaultEvaluationWindow+50; i++ {
+		e.Add(i < 50, 1)
+	}
+
+	assert.Equal(t, DefaultEvaluationWindow+50, e.Seen(), "Every datapoint should be counted as seen")
+	assert.InDelta(t, 0, e.Accuracy(), 1e-12, "Accuracy should be over the last DefaultEvaluationWindow datapoints")
+	assert.InDelta(t, 1, e.Cost(), 1e-12, "Cost should be over the last DefaultEvaluationWindow datapoints")
+}
@@ -0,0 +1,83 @@
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// FeatureMismatch describes which feature an input
+// with the given number of values is missing (or
+// that it has a value past the last feature,) for
+// models with named features to add to their
+// dimension errors, so they say "expected feature
+// 'age' at index 2" rather than just the lengths.
+// It returns an empty string if there are no names
+// or the input has one value per name.
+func FeatureMismatch(names []string, given int) string {
+	switch {
+	case len(names) == 0 || given == len(names):
+		return ""
+	case given < len(names):
+		return fmt.Sprintf("\tExpected feature %q at index %v\n\tFeatures: %v\n", names[given], given, names)
+	default:
+		return fmt.Sprintf("\tUnexpected value at index %v, after the last feature %q\n\tFeatures: %v\n", len(names), names[len(names)-1], names)
+	}
+}
+
+// CheckFeatureNames returns an error if a model
+// taking the given number of features has feature
+// names, but not exactly one for each feature. Not
+// naming the features at all is fine.
+func CheckFeatureNames(names []string, features int) error {
+	if len(names) != 0 && len(names) != features {
+		return fmt.Errorf("ERROR: There should be one feature name for each feature!\n\tFeature names: %v\n\tFeatures: %v\n", len(names), features)
+	}
+
+	return nil
+}
+
+// persistedParameters is the format a parameter
+// vector is saved with when the model's features
+// are named
+type persistedParameters struct {
+	Parameters   []float64 `json:"theta"`
+	FeatureNames []string  `json:"feature_names"`
+}
+
+// MarshalParameters returns the JSON models with a
+// single parameter vector θ save to file. Models
+// without feature names save θ on its own (the same
+// format older versions saved,) while models with
+// feature names save an object holding both:
+//
+//	{"theta": [...], "feature_names": [...]}
+func MarshalParameters(theta []float64, featureNames []string) ([]byte, error) {
+	if len(featureNames) == 0 {
+		return json.Marshal(theta)
+	}
+
+	return json.Marshal(persistedParameters{
+		Parameters:   theta,
+		FeatureNames: featureNames,
+	})
+}
+
+// UnmarshalParameters parses JSON saved with
+// MarshalParameters, returning the parameter vector
+// θ and the feature names (nil if there weren't any.)
+func UnmarshalParameters(data []byte) ([]float64, []string, error) {
+	var theta []float64
+	err := json.Unmarshal(data, &theta)
+	if err == nil {
+		return theta, nil, nil
+	}
+
+	var model persistedParameters
+	if json.Unmarshal(data, &model) != nil {
+		// report the error for the format
+		// older versions saved
+		return nil, nil, err
+	}
+
+	return model.Parameters, model.FeatureNames, nil
+}
@@ -0,0 +1,57 @@
+package base
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureMismatchShouldPass1(t *testing.T) {
+	names := []string{"height", "weight", "age"}
+
+	assert.Equal(t, "", FeatureMismatch(nil, 2), "There's nothing to say without names")
+	assert.Equal(t, "", FeatureMismatch(names, 3), "There's nothing to say when the input has every feature")
+
+	missing := FeatureMismatch(names, 2)
+	assert.True(t, strings.Contains(missing, `Expected feature "age" at index 2`), "The first missing feature should be named (got %q)", missing)
+
+	extra := FeatureMismatch(names, 5)
+	assert.True(t, strings.Contains(extra, `Unexpected value at index 3, after the last feature "age"`), "The extra value should be pointed out (got %q)", extra)
+}
+
+func TestCheckFeatureNamesShouldPass1(t *testing.T) {
+	assert.Nil(t, CheckFeatureNames(nil, 3), "Not naming the features should be fine")
+	assert.Nil(t, CheckFeatureNames([]string{"a", "b", "c"}, 3), "Naming every feature should be fine")
+	assert.NotNil(t, CheckFeatureNames([]string{"a", "b"}, 3), "Naming only some features should return an error")
+}
+
+func TestMarshalParametersShouldPass1(t *testing.T) {
+	theta := []float64{1, -2, 3.5}
+
+	// without names θ is saved on its own
+	data, err := MarshalParameters(theta, nil)
+	assert.Nil(t, err, "Marshalling error should be nil")
+	assert.Equal(t, "[1,-2,3.5]", string(data), "Parameters without names should be saved the way older versions saved them")
+
+	restored, names, err := UnmarshalParameters(data)
+	assert.Nil(t, err, "Unmarshalling error should be nil")
+	assert.Equal(t, theta, restored, "Parameters should match")
+	assert.Nil(t, names, "There should be no feature names")
+
+	data, err = MarshalParameters(theta, []string{"a", "b"})
+	assert.Nil(t, err, "Marshalling error should be nil")
+
+	restored, names, err = UnmarshalParameters(data)
+	assert.Nil(t, err, "Unmarshalling error should be nil")
+	assert.Equal(t, theta, restored, "Parameters should match")
+	assert.Equal(t, []string{"a", "b"}, names, "Feature names should match")
+}
+
+func TestMarshalParametersShouldFail1(t *testing.T) {
+	_, _, err := UnmarshalParameters([]byte("not json"))
+	assert.NotNil(t, err, "Unmarshalling invalid JSON should return an error")
+
+	_, _, err = UnmarshalParameters([]byte(`{"theta": "nope"}`))
+	assert.NotNil(t, err, "Unmarshalling a malformed object should return an error")
+}
@@ -2,6 +2,17 @@ package base
 
 import "math"
 
+// Kernel is any function that maps two
+// vectors of float64s to a float64 measuring
+// how similar they are, which models can use
+// for the Kernel Trick. The kernels below are
+// ready-made, but you can pass in your own
+// function too (a string/edit-distance kernel,
+// for example) as long as it's a valid kernel.
+//
+// https://en.wikipedia.org/wiki/Kernel_method
+type Kernel func([]float64, []float64) float64
+
 // GaussianKernel takes in a parameter for sigma (σ)
 // and returns a valid (Gaussian) Radial Basis Function
 // Kernel. If the input dimensions aren't valid, the
@@ -14,7 +25,7 @@ import "math"
 // This can be used within any models that can use Kernels.
 //
 // Sigma (σ) will default to 1 if given 0.0
-func GaussianKernel(sigma float64) func([]float64, []float64) float64 {
+func GaussianKernel(sigma float64) Kernel {
 	if sigma == 0 {
 		sigma = 1.0
 	}
@@ -40,6 +51,50 @@ func GaussianKernel(sigma float64) func([]float64, []float64) float64 {
 	}
 }
 
+// GaussianKernelDiag is the same as GaussianKernel,
+// but with its own length scale sigma (σ[i]) for
+// each dimension rather than one for every dimension,
+// so features on very different scales can be used
+// without normalizing them first:
+//
+//     K(x, x`) = exp( -1 * Σ (x[i] - x`[i])^2 / 2σ[i]^2)
+//
+// If either input vector doesn't have one value
+// per sigma, the kernel will return 0.0 (as if the
+// vectors are orthogonal.)
+//
+// Each sigma (σ[i]) will default to 1 if given 0.0.
+// The sigmas are copied, so changing the given slice
+// afterwards doesn't change the kernel.
+func GaussianKernelDiag(sigmas []float64) Kernel {
+	denoms := make([]float64, len(sigmas))
+	for i, sigma := range sigmas {
+		if sigma == 0 {
+			sigma = 1.0
+		}
+
+		denoms[i] = 2 * sigma * sigma
+	}
+
+	return func(X []float64, x []float64) float64 {
+
+		// don't throw error but fail peacefully
+		//
+		// returning "not at all similar", basically
+		if len(X) != len(denoms) || len(x) != len(denoms) {
+			return 0.0
+		}
+
+		var diff float64
+
+		for i := range X {
+			diff += (X[i] - x[i]) * (X[i] - x[i]) / denoms[i]
+		}
+
+		return math.Exp(-1 * diff)
+	}
+}
+
 // LinearKernel is the base kernel function. It
 // will return a valid kernel for use within models
 // that can use the Kernel Trick. The resultant
@@ -55,7 +110,7 @@ func GaussianKernel(sigma float64) func([]float64, []float64) float64 {
 // Using this kernel is effectively the same as
 // not using a kernel at all (for SVM and Kernel
 // perceptron, at least.)
-func LinearKernel() func([]float64, []float64) float64 {
+func LinearKernel() Kernel {
 	return func(X []float64, x []float64) float64 {
 		// don't throw error but fail peacefully
 		//
@@ -94,7 +149,7 @@ func LinearKernel() func([]float64, []float64) float64 {
 // https://en.wikipedia.org/wiki/Homogeneous_polynomial
 //
 // `d` will default to 1 if 0 is given.
-func PolynomialKernel(d int, constants ...float64) func([]float64, []float64) float64 {
+func PolynomialKernel(d int, constants ...float64) Kernel {
 	if d == 0 {
 		d = 1
 	}
@@ -139,7 +194,7 @@ func PolynomialKernel(d int, constants ...float64) func([]float64, []float64) fl
 // Note that c must be less than 0 (if >= 0 default
 // to -1.0) and κ (for most cases, but not all -
 // hence no default) must be greater than 0
-func TanhKernel(k float64, constants ...float64) func([]float64, []float64) float64 {
+func TanhKernel(k float64, constants ...float64) Kernel {
 	if k == 0.0 {
 		k = 1.0
 	}
@@ -172,3 +227,80 @@ func TanhKernel(k float64, constants ...float64) func([]float64, []float64) floa
 		return math.Tanh(dot + c)
 	}
 }
+
+// LaplacianKernel takes in a parameter for gamma (γ)
+// and returns a valid Laplacian Radial Basis Function
+// Kernel. It's like the GaussianKernel, but it decays
+// with the distance between the vectors rather than
+// the squared distance, so it's less smooth and falls
+// off more slowly for far away points. If the input
+// dimensions aren't valid, the kernel will return 0.0
+// (as if the vectors are orthogonal)
+//
+//     K(x, x`) = exp( -γ|x - x`| )
+//
+// https://en.wikipedia.org/wiki/Laplacian_kernel
+//
+// Gamma (γ) will default to 1 if given 0.0
+func LaplacianKernel(gamma float64) Kernel {
+	if gamma == 0 {
+		gamma = 1.0
+	}
+
+	return func(X []float64, x []float64) float64 {
+		// don't throw error but fail peacefully
+		//
+		// returning "not at all similar", basically
+		if len(X) != len(x) {
+			return 0.0
+		}
+
+		var diff float64
+
+		for i := range X {
+			diff += (X[i] - x[i]) * (X[i] - x[i])
+		}
+
+		return math.Exp(-1 * gamma * math.Sqrt(diff))
+	}
+}
+
+// SigmoidKernel takes in a scale parameter gamma (γ)
+// and a constant c and returns a Sigmoid (hyperbolic
+// tangent) kernel. Unlike TanhKernel, which forces the
+// constant to be negative, both parameters are used
+// exactly as given.
+//
+//     K(x, x`) = tanh(γx*x` + c)
+//
+// https://en.wikipedia.org/wiki/Support_vector_machine#Nonlinear_classification
+//
+// Note that the Sigmoid kernel isn't positive
+// semi-definite for every γ and c, though it still
+// works well in practice for small γ > 0 and c < 0.
+// If the input dimensions aren't valid, the kernel
+// will return 0.0 (as if the vectors are orthogonal)
+//
+// Gamma (γ) will default to 1 if given 0.0
+func SigmoidKernel(gamma, c float64) Kernel {
+	if gamma == 0 {
+		gamma = 1.0
+	}
+
+	return func(X []float64, x []float64) float64 {
+		// don't throw error but fail peacefully
+		//
+		// returning "not at all similar", basically
+		if len(X) != len(x) {
+			return 0.0
+		}
+
+		var dot float64
+
+		for i := range X {
+			dot += X[i] * x[i]
+		}
+
+		return math.Tanh(gamma*dot + c)
+	}
+}
@@ -0,0 +1,190 @@
+package base
+
+import (
+	"math"
+)
+
+// KernelCache caches the Gram matrix of a kernel,
+// which is the value of the kernel between every
+// pair of points, so models which compare the same
+// points over and over (like a kernel perceptron
+// making several passes over a fixed training set,
+// or being trained again and again while sweeping
+// its other hyperparameters) only compute the kernel
+// once per pair. Values are computed the first time
+// they're needed, or all at once with Precompute.
+//
+// Points are keyed by identity, not by value: two
+// points are the same if their slices share the
+// same backing array. That means the cache only
+// helps if you pass the model the same slices each
+// time (ie. ranging over your training set every
+// pass,) and it means you must not change the
+// values of a point after it's been cached, or
+// reuse a buffer for different points, because the
+// cache would keep giving the old kernel value.
+//
+// A cache is only valid for one kernel, so only
+// share one between models using the same kernel.
+// The cache holds onto every point it's seen until
+// it's released with Release (models release the
+// support vectors they drop,) and grows with the
+// square of the number of points, so let go of it
+// once you're done with a training set (and use a
+// new one for the next.) Like the models, it isn't
+// safe to use from multiple goroutines at once.
+type KernelCache struct {
+	// ids maps the identity of each point to
+	// its index in points (and the Gram matrix)
+	ids    map[*float64]int
+	points [][]float64
+
+	// gram[i][j] is the kernel between points i
+	// and j, or NaN if it hasn't been computed.
+	// Rows are only as long as they need to be.
+	gram [][]float64
+
+	// free holds the indices of released points,
+	// which are given to the next points added,
+	// and generation counts the points released
+	free       []int
+	generation int
+}
+
+// NewKernelCache returns a new, empty KernelCache
+func NewKernelCache() *KernelCache {
+	return &KernelCache{
+		ids: make(map[*float64]int),
+	}
+}
+
+// Index returns the index of the point x within the
+// cache, adding x to the cache if it hasn't been
+// seen before. Models can hold onto the index to
+// look up kernel values with Value without having
+// to find the point again. Empty points can't be
+// cached, and get the index -1.
+func (c *KernelCache) Index(x []float64) int {
+	if len(x) == 0 {
+		return -1
+	}
+
+	if i, ok := c.ids[&x[0]]; ok {
+		return i
+	}
+
+	if len(c.free) != 0 {
+		i := c.free[len(c.free)-1]
+		c.free = c.free[:len(c.free)-1]
+		c.ids[&x[0]] = i
+		c.points[i] = x
+
+		return i
+	}
+
+	i := len(c.points)
+	c.ids[&x[0]] = i
+	c.points = append(c.points, x)
+	c.gram = append(c.gram, nil)
+
+	return i
+}
+
+// Release removes the point x from the cache along
+// with its kernel values, for when a model is done
+// with it (ie. a support vector dropped to stay
+// within a budget.) Its index is given to the next
+// point added, so anything holding indices into the
+// cache should look them up again once Generation
+// changes. Releasing a point that isn't cached does
+// nothing.
+func (c *KernelCache) Release(x []float64) {
+	if c == nil || len(x) == 0 {
+		return
+	}
+
+	i, ok := c.ids[&x[0]]
+	if !ok {
+		return
+	}
+
+	delete(c.ids, &x[0])
+	c.points[i] = nil
+	c.gram[i] = nil
+	for j := range c.gram {
+		if i < len(c.gram[j]) {
+			c.gram[j][i] = math.NaN()
+		}
+	}
+
+	c.free = append(c.free, i)
+	c.generation++
+}
+
+// Generation returns the number of points that have
+// been released from the cache. Indices from Index
+// are only valid while it stays the same.
+func (c *KernelCache) Generation() int {
+	return c.generation
+}
+
+// Value returns the kernel between the points with
+// indices i and j (see Index,) computing and caching
+// it the first time the pair is seen.
+func (c *KernelCache) Value(kernel Kernel, i, j int) float64 {
+	if i < 0 || j < 0 {
+		return kernel(c.point(i), c.point(j))
+	}
+
+	row := c.gram[i]
+	if j < len(row) && !math.IsNaN(row[j]) {
+		return row[j]
+	}
+
+	for len(row) <= j {
+		row = append(row, math.NaN())
+	}
+
+	value := kernel(c.points[i], c.points[j])
+	row[j] = value
+	c.gram[i] = row
+
+	return value
+}
+
+// point returns the point with index i, or
+// nil if i is -1 (an empty point)
+func (c *KernelCache) point(i int) []float64 {
+	if i < 0 {
+		return nil
+	}
+
+	return c.points[i]
+}
+
+// Get returns kernel(u, v), computing and caching
+// it the first time the pair is seen. A nil cache
+// just computes the kernel.
+func (c *KernelCache) Get(kernel Kernel, u, v []float64) float64 {
+	if c == nil || len(u) == 0 || len(v) == 0 {
+		return kernel(u, v)
+	}
+
+	return c.Value(kernel, c.Index(u), c.Index(v))
+}
+
+// Precompute fills the cache with the Gram matrix
+// of the given points, so a model trained off of
+// the points never has to compute the kernel itself.
+func (c *KernelCache) Precompute(kernel Kernel, x [][]float64) {
+	for i := range x {
+		for j := range x {
+			c.Get(kernel, x[i], x[j])
+		}
+	}
+}
+
+// Len returns the number of points in the cache
+func (c *KernelCache) Len() int {
+	return len(c.points) - len(c.free)
+}
@@ -0,0 +1,92 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKernelCacheShouldPass1(t *testing.T) {
+	var calls int
+	kernel := func(u, v []float64) float64 {
+		calls++
+		return LinearKernel()(u, v)
+	}
+
+	x := [][]float64{{1, 2}, {3, 4}, {5, 6}}
+
+	cache := NewKernelCache()
+	cache.Precompute(kernel, x)
+	assert.Equal(t, 9, calls, "Every pair should be computed once")
+	assert.Equal(t, 3, cache.Len(), "Every point should be cached")
+
+	for i := range x {
+		for j := range x {
+			assert.Equal(t, LinearKernel()(x[i], x[j]), cache.Get(kernel, x[i], x[j]), "Cached kernel should match the kernel")
+		}
+	}
+	assert.Equal(t, 9, calls, "Cached pairs shouldn't be computed again")
+
+	// points are cached by identity, so an equal
+	// point in a new slice is a new point
+	y := []float64{1, 2}
+	assert.Equal(t, 5.0, cache.Get(kernel, y, x[0]), "Kernel of a new point should be computed")
+	assert.Equal(t, 10, calls, "New pairs should be computed")
+	assert.Equal(t, 4, cache.Len(), "The new point should be cached")
+}
+
+func TestKernelCacheShouldPass2(t *testing.T) {
+	var calls int
+	kernel := func(u, v []float64) float64 {
+		calls++
+		return 1
+	}
+
+	// a nil cache and empty points fall back
+	// to computing the kernel
+	var cache *KernelCache
+	assert.Equal(t, 1.0, cache.Get(kernel, []float64{1}, []float64{1}), "Nil cache should compute the kernel")
+
+	cache = NewKernelCache()
+	assert.Equal(t, 1.0, cache.Get(kernel, []float64{}, []float64{1}), "Empty points should compute the kernel")
+	assert.Equal(t, 1.0, cache.Get(kernel, []float64{}, []float64{1}), "Empty points should compute the kernel")
+	assert.Equal(t, 3, calls, "Nothing should have been cached")
+	assert.Equal(t, 0, cache.Len(), "Nothing should have been cached")
+}
+
+// released points should be forgotten, and their
+// index given to the next point without it picking
+// up any of the released point's kernel values
+func TestKernelCacheReleaseShouldPass1(t *testing.T) {
+	var calls int
+	kernel := func(u, v []float64) float64 {
+		calls++
+		return LinearKernel()(u, v)
+	}
+
+	x := [][]float64{{1, 2}, {3, 4}, {5, 6}}
+
+	cache := NewKernelCache()
+	cache.Precompute(kernel, x)
+	index := cache.Index(x[1])
+
+	cache.Release(x[1])
+	assert.Equal(t, 2, cache.Len(), "The released point should be removed")
+	assert.Equal(t, 1, cache.Generation(), "Releasing a point should change the generation")
+
+	cache.Release([]float64{7, 8})
+	cache.Release(x[1])
+	assert.Equal(t, 1, cache.Generation(), "Releasing a point that isn't cached should do nothing")
+
+	y := []float64{-1, 0}
+	assert.Equal(t, index, cache.Index(y), "The released index should be reused")
+	assert.Equal(t, 3, cache.Len(), "The new point should be cached")
+
+	calls = 0
+	assert.Equal(t, -1.0, cache.Get(kernel, x[0], y), "Kernel of the new point shouldn't be the released point's")
+	assert.Equal(t, -5.0, cache.Get(kernel, y, x[2]), "Kernel of the new point shouldn't be the released point's")
+	assert.Equal(t, 2, calls, "Kernel values of the new point should be computed")
+
+	assert.Equal(t, 11.0, cache.Get(kernel, x[0], x[1]), "A released point should be cached again when it's used")
+	assert.Equal(t, 4, cache.Len(), "The released point should be cached again")
+}
@@ -57,6 +57,43 @@ func TestGaussianKernelShouldPass2(t *testing.T) {
 	}), 5e-4, "Dot product should be valid")
 }
 
+func TestGaussianKernelDiagShouldPass1(t *testing.T) {
+	k := GaussianKernelDiag([]float64{1.0, 2.0, 0.0, 10.0})
+
+	// test different distances which
+	// should be valid (σ[2] defaults to 1)
+
+	assert.InDelta(t, math.Exp(-1*(1.0/8+1.0/2)), k([]float64{
+		0.0, 1.0, 1.0, 0.0,
+	}, []float64{
+		0.0, 2.0, 0.0, 0.0,
+	}), 5e-4, "Distance should be valid")
+
+	assert.InDelta(t, math.Exp(-1*(196.0/2+121.0/2+100.0/200)), k([]float64{
+		15.0, 1.0, -1.0, 10.0,
+	}, []float64{
+		1.0, 1.0, 10.0, 0.0,
+	}), 5e-4, "Distance should be valid")
+
+	// with equal sigmas it should match GaussianKernel
+	diag := GaussianKernelDiag([]float64{4.0, 4.0, 4.0, 4.0})
+	gaussian := GaussianKernel(4.0)
+	assert.InDelta(t, gaussian([]float64{15.0, 1.0, -1.0, 0.0}, []float64{1.0, 1.0, 10.0, 0.0}), diag([]float64{15.0, 1.0, -1.0, 0.0}, []float64{1.0, 1.0, 10.0, 0.0}), 1e-12, "Equal sigmas should be the same as GaussianKernel")
+}
+
+func TestGaussianKernelDiagShouldFail1(t *testing.T) {
+	sigmas := []float64{1.0, 2.0}
+	k := GaussianKernelDiag(sigmas)
+
+	assert.Equal(t, 0.0, k([]float64{1.0, 2.0, 3.0}, []float64{1.0, 2.0, 3.0}), "Kernel should be 0 when the inputs don't match the sigmas")
+	assert.Equal(t, 0.0, k([]float64{1.0, 2.0}, []float64{1.0}), "Kernel should be 0 for mismatched dimensions")
+
+	// changing the sigmas afterwards shouldn't
+	// change the kernel
+	sigmas[0] = 100
+	assert.InDelta(t, math.Exp(-0.5), k([]float64{1.0, 0.0}, []float64{0.0, 0.0}), 1e-12, "Kernel should keep its own copy of the sigmas")
+}
+
 func TestLinearKernelShouldPass1(t *testing.T) {
 	k := LinearKernel()
 
@@ -233,3 +270,58 @@ func TestTanhKernelShouldPass3(t *testing.T) {
 		1.0, 1.0, 100.0, 0.0,
 	}), 5e-4, "Dot product should be valid")
 }
+
+func TestLaplacianKernelShouldPass1(t *testing.T) {
+	k := LaplacianKernel(0.5)
+
+	// test different distances which
+	// should be valid
+
+	assert.InDelta(t, math.Exp(-0.5*1.0), k([]float64{
+		0.0, 1.0, 1.0, 0.0,
+	}, []float64{
+		0.0, 1.0, 0.0, 0.0,
+	}), 5e-4, "Distance should be valid")
+
+	assert.InDelta(t, math.Exp(-0.5*math.Sqrt(277.0)), k([]float64{
+		15.0, 1.0, -1.0, 0.0,
+	}, []float64{
+		1.0, 1.0, 10.0, 0.0,
+	}), 5e-4, "Distance should be valid")
+
+	assert.InDelta(t, 1.0, k([]float64{
+		15.0, 1.0, -1.0, 0.0,
+	}, []float64{
+		15.0, 1.0, -1.0, 0.0,
+	}), 5e-4, "Kernel of a vector with itself should be 1")
+
+	assert.Equal(t, 0.0, k([]float64{1.0}, []float64{1.0, 2.0}), "Kernel should be 0 for mismatched dimensions")
+}
+
+func TestSigmoidKernelShouldPass1(t *testing.T) {
+	k := SigmoidKernel(0.1, 0.5)
+
+	// test different dot products which
+	// should be valid, and that the positive
+	// constant is kept (unlike TanhKernel)
+
+	assert.InDelta(t, math.Tanh(0.1+0.5), k([]float64{
+		0.0, 1.0, 1.0, 0.0,
+	}, []float64{
+		0.0, 1.0, 0.0, 0.0,
+	}), 5e-4, "Dot product should be valid")
+
+	assert.InDelta(t, math.Tanh(0.6+0.5), k([]float64{
+		15.0, 1.0, -1.0, 0.0,
+	}, []float64{
+		1.0, 1.0, 10.0, 0.0,
+	}), 5e-4, "Dot product should be valid")
+
+	assert.InDelta(t, math.Tanh(-8.4+0.5), k([]float64{
+		15.0, 1.0, -1.0, 0.0,
+	}, []float64{
+		1.0, 1.0, 100.0, 0.0,
+	}), 5e-4, "Dot product should be valid")
+
+	assert.Equal(t, 0.0, k([]float64{1.0}, []float64{1.0, 2.0}), "Kernel should be 0 for mismatched dimensions")
+}
@@ -0,0 +1,133 @@
+package base
+
+import "fmt"
+
+// ConfusionMatrix counts the results of a classifier
+// where ConfusionMatrix[a][p] is the number of examples
+// whose actual class was a that were predicted as class
+// p. Correct predictions fall on the diagonal.
+type ConfusionMatrix [][]int
+
+// NewConfusionMatrix takes in the actual classes of a
+// set of examples and the classes predicted for them
+// by a classifier, as well as the number of classes
+// the classifier can predict, and returns the confusion
+// matrix of the results.
+//
+// An error is returned if actual and predicted aren't
+// the same length, or if any class isn't within the
+// range [0,classes)
+func NewConfusionMatrix(actual, predicted []int, classes int) (ConfusionMatrix, error) {
+	if len(actual) != len(predicted) {
+		return nil, fmt.Errorf("ERROR: Actual and predicted classes should be the same length!\n\tLength of actual: %v\n\tLength of predicted: %v\n", len(actual), len(predicted))
+	}
+	if classes < 1 {
+		return nil, fmt.Errorf("ERROR: There should be at least one class! Given %v\n", classes)
+	}
+
+	matrix := make(ConfusionMatrix, classes)
+	for i := range matrix {
+		matrix[i] = make([]int, classes)
+	}
+
+	for i := range actual {
+		if actual[i] < 0 || actual[i] >= classes {
+			return nil, fmt.Errorf("ERROR: Actual class %v of example %v is not within [0,%v)\n", actual[i], i, classes)
+		}
+		if predicted[i] < 0 || predicted[i] >= classes {
+			return nil, fmt.Errorf("ERROR: Predicted class %v of example %v is not within [0,%v)\n", predicted[i], i, classes)
+		}
+
+		matrix[actual[i]][predicted[i]]++
+	}
+
+	return matrix, nil
+}
+
+// Accuracy returns the fraction of all examples which
+// were predicted correctly. Returns 0 if there are no
+// examples.
+func (c ConfusionMatrix) Accuracy() float64 {
+	var correct, total int
+	for a := range c {
+		for p := range c[a] {
+			total += c[a][p]
+			if a == p {
+				correct += c[a][p]
+			}
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(correct) / float64(total)
+}
+
+// Precision returns the fraction of the examples
+// predicted as the given class that actually were
+// that class
+//
+//     TP / (TP + FP)
+//
+// Returns 0 if nothing was predicted as the class
+// (or the class is out of range.)
+func (c ConfusionMatrix) Precision(class int) float64 {
+	if class < 0 || class >= len(c) {
+		return 0
+	}
+
+	var predicted int
+	for a := range c {
+		predicted += c[a][class]
+	}
+
+	if predicted == 0 {
+		return 0
+	}
+
+	return float64(c[class][class]) / float64(predicted)
+}
+
+// Recall returns the fraction of the examples which
+// actually were the given class that were predicted
+// as that class
+//
+//     TP / (TP + FN)
+//
+// Returns 0 if no examples actually were the class
+// (or the class is out of range.)
+func (c ConfusionMatrix) Recall(class int) float64 {
+	if class < 0 || class >= len(c) {
+		return 0
+	}
+
+	var actual int
+	for p := range c[class] {
+		actual += c[class][p]
+	}
+
+	if actual == 0 {
+		return 0
+	}
+
+	return float64(c[class][class]) / float64(actual)
+}
+
+// F1 returns the F1 score of the given class, which
+// is the harmonic mean of its precision and recall
+//
+//     2 * precision * recall / (precision + recall)
+//
+// Returns 0 if both the precision and recall are 0.
+func (c ConfusionMatrix) F1(class int) float64 {
+	precision := c.Precision(class)
+	recall := c.Recall(class)
+
+	if precision+recall == 0 {
+		return 0
+	}
+
+	return 2 * precision * recall / (precision + recall)
+}
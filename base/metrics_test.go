@@ -0,0 +1,66 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfusionMatrixShouldPass1(t *testing.T) {
+	actual := []int{0, 0, 0, 0, 1, 1, 1, 2, 2, 2}
+	predicted := []int{0, 0, 1, 2, 1, 1, 0, 2, 2, 2}
+
+	matrix, err := NewConfusionMatrix(actual, predicted, 3)
+	assert.Nil(t, err, "Confusion matrix error should be nil")
+
+	assert.Equal(t, ConfusionMatrix{
+		[]int{2, 1, 1},
+		[]int{1, 2, 0},
+		[]int{0, 0, 3},
+	}, matrix, "Confusion matrix should count actual classes by row and predicted classes by column")
+
+	assert.InDelta(t, 0.7, matrix.Accuracy(), 1e-9, "Accuracy should match")
+
+	assert.InDelta(t, 2.0/3, matrix.Precision(0), 1e-9, "Precision should match")
+	assert.InDelta(t, 2.0/3, matrix.Precision(1), 1e-9, "Precision should match")
+	assert.InDelta(t, 3.0/4, matrix.Precision(2), 1e-9, "Precision should match")
+
+	assert.InDelta(t, 2.0/4, matrix.Recall(0), 1e-9, "Recall should match")
+	assert.InDelta(t, 2.0/3, matrix.Recall(1), 1e-9, "Recall should match")
+	assert.InDelta(t, 1, matrix.Recall(2), 1e-9, "Recall should match")
+
+	assert.InDelta(t, 4.0/7, matrix.F1(0), 1e-9, "F1 should match")
+	assert.InDelta(t, 2.0/3, matrix.F1(1), 1e-9, "F1 should match")
+	assert.InDelta(t, 6.0/7, matrix.F1(2), 1e-9, "F1 should match")
+}
+
+// classes which are never predicted (or never
+// occur) should have metrics of 0
+func TestConfusionMatrixShouldPass2(t *testing.T) {
+	matrix, err := NewConfusionMatrix([]int{0, 0, 1}, []int{0, 0, 0}, 3)
+	assert.Nil(t, err, "Confusion matrix error should be nil")
+
+	assert.InDelta(t, 2.0/3, matrix.Accuracy(), 1e-9, "Accuracy should match")
+	assert.Equal(t, 0.0, matrix.Precision(1), "Precision should be 0 when the class is never predicted")
+	assert.Equal(t, 0.0, matrix.Recall(2), "Recall should be 0 when the class never occurs")
+	assert.Equal(t, 0.0, matrix.F1(2), "F1 should be 0 when precision and recall are 0")
+	assert.Equal(t, 0.0, matrix.Precision(5), "Precision should be 0 for an out of range class")
+
+	matrix, err = NewConfusionMatrix([]int{}, []int{}, 2)
+	assert.Nil(t, err, "Confusion matrix error should be nil")
+	assert.Equal(t, 0.0, matrix.Accuracy(), "Accuracy should be 0 with no examples")
+}
+
+func TestConfusionMatrixShouldFail1(t *testing.T) {
+	_, err := NewConfusionMatrix([]int{0, 1}, []int{0}, 2)
+	assert.NotNil(t, err, "Confusion matrix error should not be nil")
+
+	_, err = NewConfusionMatrix([]int{0, 2}, []int{0, 1}, 2)
+	assert.NotNil(t, err, "Confusion matrix error should not be nil")
+
+	_, err = NewConfusionMatrix([]int{0, 1}, []int{0, -1}, 2)
+	assert.NotNil(t, err, "Confusion matrix error should not be nil")
+
+	_, err = NewConfusionMatrix([]int{0}, []int{0}, 0)
+	assert.NotNil(t, err, "Confusion matrix error should not be nil")
+}
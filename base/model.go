@@ -13,25 +13,103 @@ package base
 type OptimizationMethod string
 
 // Constants declare the types of optimization
-// methods you can use.
+// methods you can use. NormalEquation solves
+// for the parameters in closed form instead of
+// iterating, and is only supported by models
+// that have a closed form solution.
 const (
-	BatchGA      OptimizationMethod = "Batch Gradient Ascent"
-	StochasticGA                    = "Stochastic Gradient Descent"
+	BatchGA        OptimizationMethod = "Batch Gradient Ascent"
+	StochasticGA                      = "Stochastic Gradient Descent"
+	NewtonMethod   OptimizationMethod = "Newton's Method"
+	MiniBatchGA    OptimizationMethod = "Mini-Batch Gradient Ascent"
+	Adam           OptimizationMethod = "Adam"
+	NormalEquation OptimizationMethod = "Normal Equation"
 )
 
-// Model is an interface that can Train based on
-// a 2D array of data (called x) and an array (y)
-// of solution data. Model trains in a supervised
-// manor. Predict takes in a vector of floats and
-// returns a real number response (float, again)
-// and an error if any
+// RegularizationType defines a type enum which
+// (using constants declared below) lets a user
+// choose the penalty used to regularize a model's
+// parameter vector
+type RegularizationType string
+
+// Constants declare the types of regularization
+// you can use. L2 (ridge) regularization penalizes
+// the square of each parameter, L1 (lasso)
+// regularization penalizes the absolute value of
+// each parameter (driving many parameters to
+// exactly 0,) and ElasticNet mixes the two.
+//
+// An empty RegularizationType is treated as L2
+const (
+	L2         RegularizationType = "L2"
+	L1         RegularizationType = "L1"
+	ElasticNet RegularizationType = "Elastic Net"
+)
+
+// LossType defines a type enum which (using
+// constants declared below) lets a user choose
+// the loss function a regression model minimizes
+type LossType string
+
+// Constants declare the types of loss functions
+// you can use. SquaredLoss is the usual squared
+// error of least squares, while HuberLoss is
+// quadratic for residuals within a threshold δ
+// and linear beyond it, so outliers pull on the
+// fit much less.
+//
+// An empty LossType is treated as SquaredLoss
+const (
+	SquaredLoss LossType = "Squared Loss"
+	HuberLoss   LossType = "Huber Loss"
+)
+
+// Model is the common surface of the models which
+// learn from a training set held in memory (batch
+// learning,) so you can write training, evaluation,
+// and model selection code that works with any of
+// them. Learn trains the model off of its training
+// set, and Predict takes in a vector of floats and
+// returns the model's response (a vector of floats,
+// again) and an error if any.
+//
+// Models satisfying Model (and SupervisedModel or
+// UnsupervisedModel, which add UpdateTrainingSet):
+//
+//	linear.LeastSquares       (SupervisedModel)
+//	linear.Logistic           (SupervisedModel)
+//	linear.PoissonRegression  (SupervisedModel)
+//	linear.Softmax            (SupervisedModel)
+//	linear.MultiLeastSquares  (Model; y has a row per example)
+//	cluster.KMeans            (UnsupervisedModel)
+//	cluster.TriangleKMeans    (UnsupervisedModel)
+//	cluster.GMM               (UnsupervisedModel)
+//
+// Models which don't satisfy it are linear.LocalLinear
+// (which fits a new hypothesis inside each Predict,
+// so there's nothing to Learn or persist,) the
+// perceptrons (which learn online with OnlineLearn,)
+// cluster.KNN (which just remembers its training
+// set,) cluster.DBSCAN and cluster.KMedoids (which
+// can't be persisted,) and the text models (which
+// predict off of strings.)
 type Model interface {
+	// Learn trains the model off of the training
+	// set it was given, returning an error if it
+	// couldn't (ie. there's no training set.)
+	Learn() error
 
 	// The variadic argument in Predict is an
 	// optional arg which (if true) tells the
 	// function to first normalize the input to
 	// vector unit length. Use (and only use) this
 	// if you trained on normalized inputs.
+	//
+	// Predict only reads from the model and
+	// never changes its input, so once a model is
+	// trained it's safe to predict with it from
+	// multiple goroutines at once (just not while
+	// it's still learning.)
 	Predict([]float64, ...bool) ([]float64, error)
 
 	// PersistToFile and RestoreFromFile both take
@@ -45,6 +123,29 @@ type Model interface {
 	RestoreFromFile(string) error
 }
 
+// SupervisedModel is a Model which learns from a
+// training set x with an expected result y[i] for
+// each example x[i]
+type SupervisedModel interface {
+	Model
+
+	// UpdateTrainingSet replaces the training set
+	// (and expected results) the model learns from,
+	// returning an error if either is empty
+	UpdateTrainingSet([][]float64, []float64) error
+}
+
+// UnsupervisedModel is a Model which learns from a
+// training set x alone (ie. clustering)
+type UnsupervisedModel interface {
+	Model
+
+	// UpdateTrainingSet replaces the training set
+	// the model learns from, returning an error if
+	// it's empty
+	UpdateTrainingSet([][]float64) error
+}
+
 // OnlineModel differs from Model because the learning
 // can take place in a goroutine because the data
 // is passed through a channel, ending when the
@@ -175,6 +276,31 @@ type StochasticAscendable interface {
 	MaxIterations() int
 }
 
+// ParallelAscendable is an Ascendable model whose
+// gradient is a sum over the training examples, so
+// the work of computing it can be split up across
+// goroutines with ParallelGradientAscent.
+type ParallelAscendable interface {
+	Ascendable
+
+	// Examples returns the number of examples in the
+	// training set the model is using
+	Examples() int
+
+	// PartialDj returns the derivative of the cost
+	// function J(θ) with respect to every parameter
+	// of the hypothesis, summed only over the training
+	// examples x[start] through x[end-1], and without
+	// any regularization. Called as PartialDj(start, end)
+	PartialDj(int, int) ([]float64, error)
+
+	// RegularizeDj applies the model's regularization
+	// term to the derivative of J(θ) with respect to
+	// θ[j] over the whole training set, returning the
+	// regularized derivative. Called as RegularizeDj(j, dj)
+	RegularizeDj(int, float64) float64
+}
+
 // Datapoint is used in some models where it is cleaner
 // to pass data as a struct rather than just as 1D and
 // 2D arrays like Generalized Linear Models are doing,
@@ -1,6 +1,7 @@
 package base
 
 import (
+	"fmt"
 	"math"
 )
 
@@ -11,6 +12,10 @@ import (
 //
 // That is:
 // x[i][j] := x[i][j] / |x[i]|
+//
+// Rows with a magnitude of 0 (the zero vector)
+// can't be scaled to unit length, so they're
+// left unchanged (see NormalizePoint.)
 func Normalize(x [][]float64) {
 	for i := range x {
 		NormalizePoint(x[i])
@@ -20,7 +25,33 @@ func Normalize(x [][]float64) {
 // NormalizePoint is the same as Normalize,
 // but it only operates on one singular datapoint,
 // normalizing it's value to unit length.
+//
+// The zero vector has no direction to keep, so
+// it's left unchanged rather than dividing by 0
+// (which would fill it with NaNs.) Any value
+// that would still come out as ±Inf or NaN (ie.
+// when x holds ±Inf) falls back to 0.
 func NormalizePoint(x []float64) {
+	normalizePoint(x)
+}
+
+// NormalizedPoint returns a copy of x normalized to
+// unit length like NormalizePoint, leaving x itself
+// unchanged. The models use it when asked to normalize
+// the input to Predict so they don't rescale the
+// caller's slice out from under them.
+func NormalizedPoint(x []float64) []float64 {
+	normalized := make([]float64, len(x))
+	copy(normalized, x)
+	normalizePoint(normalized)
+
+	return normalized
+}
+
+// normalizePoint normalizes x to unit length like
+// NormalizePoint, returning the magnitude |x| it
+// was divided by (0 for the zero vector)
+func normalizePoint(x []float64) float64 {
 
 	var sum float64
 	for i := range x {
@@ -28,6 +59,9 @@ func NormalizePoint(x []float64) {
 	}
 
 	mag := math.Sqrt(sum)
+	if mag == 0 {
+		return 0
+	}
 
 	for i := range x {
 		if math.IsInf(x[i]/mag, 0) || math.IsNaN(x[i]/mag) {
@@ -38,4 +72,174 @@ func NormalizePoint(x []float64) {
 
 		x[i] /= mag
 	}
+
+	return mag
+}
+
+// NormalizeWithFactors is the same as Normalize,
+// but it also returns the magnitude |x[i]| each
+// row was divided by, so the data can be scaled
+// back to its original units with Denormalize.
+// Rows which were the zero vector (and were left
+// unchanged) get a magnitude of 0.
+func NormalizeWithFactors(x [][]float64) []float64 {
+	magnitudes := make([]float64, len(x))
+	for i := range x {
+		magnitudes[i] = normalizePoint(x[i])
+	}
+
+	return magnitudes
+}
+
+// Denormalize undoes NormalizeWithFactors, scaling
+// each row of x back to its original length using
+// the magnitudes NormalizeWithFactors returned.
+//
+// That is:
+// x[i][j] := x[i][j] * |x[i]|
+//
+// An error is returned (and x is left untouched)
+// if there isn't one magnitude per row.
+func Denormalize(x [][]float64, magnitudes []float64) error {
+	if len(x) != len(magnitudes) {
+		return fmt.Errorf("ERROR: Number of magnitudes should match the number of rows to denormalize!\n\tRows: %v\n\tMagnitudes: %v\n", len(x), len(magnitudes))
+	}
+
+	for i := range x {
+		DenormalizePoint(x[i], magnitudes[i])
+	}
+
+	return nil
+}
+
+// DenormalizePoint is the same as Denormalize,
+// but it only operates on one singular datapoint,
+// scaling it back up by the given magnitude. A
+// magnitude of 0 means the point was the zero
+// vector, which NormalizePoint leaves unchanged,
+// so the point is left unchanged here too.
+func DenormalizePoint(x []float64, magnitude float64) {
+	if magnitude == 0 {
+		return
+	}
+
+	for i := range x {
+		x[i] *= magnitude
+	}
+}
+
+// Standardize takes in an array of arrays of
+// inputs and standardizes each feature ('column')
+// of the data to have a mean of 0 and a standard
+// deviation of 1 (the z-score of the value.)
+// Unlike Normalize, each feature is scaled on
+// its own, which keeps features with large
+// ranges from dominating gradient descent.
+//
+// That is:
+// x[i][j] := (x[i][j] - μ[j]) / σ[j]
+//
+// The mean μ and standard deviation σ of each
+// feature are returned so you can standardize
+// new points (ie. test data) the same way with
+// StandardizePoint. Features which never change
+// (σ[j] == 0) are set to 0.
+func Standardize(x [][]float64) (means, stds []float64) {
+	if len(x) == 0 {
+		return nil, nil
+	}
+
+	features := len(x[0])
+	examples := float64(len(x))
+
+	means = make([]float64, features)
+	for i := range x {
+		for j := range means {
+			means[j] += x[i][j]
+		}
+	}
+	for j := range means {
+		means[j] /= examples
+	}
+
+	stds = make([]float64, features)
+	for i := range x {
+		for j := range stds {
+			stds[j] += (x[i][j] - means[j]) * (x[i][j] - means[j])
+		}
+	}
+	for j := range stds {
+		stds[j] = math.Sqrt(stds[j] / examples)
+	}
+
+	for i := range x {
+		StandardizePoint(x[i], means, stds)
+	}
+
+	return means, stds
+}
+
+// StandardizePoint is the same as Standardize,
+// but it only operates on one singular datapoint,
+// using the given means and standard deviations
+// of each feature (as returned by Standardize.)
+func StandardizePoint(x, means, stds []float64) {
+	for j := range x {
+		if stds[j] == 0 {
+			// fallback to zero when dividing by 0
+			x[j] = 0
+			continue
+		}
+
+		x[j] = (x[j] - means[j]) / stds[j]
+	}
+}
+
+// OneHot takes in class labels between 0 and
+// numClasses-1 and returns their one-hot
+// encoding, where row i is all 0s except for
+// a 1 in column labels[i].
+//
+//    OneHot([]int{0, 2, 1}, 3)
+//    // [[1 0 0] [0 0 1] [0 1 0]]
+//
+// An error is returned if any label is out of
+// that range.
+func OneHot(labels []int, numClasses int) ([][]float64, error) {
+	if numClasses < 1 {
+		return nil, fmt.Errorf("ERROR: Number of classes should be positive! Given %v", numClasses)
+	}
+
+	encoded := make([][]float64, len(labels))
+	for i, label := range labels {
+		if label < 0 || label >= numClasses {
+			return nil, fmt.Errorf("ERROR: Label %v (%v) should be a class in [0,%v)!", i, label, numClasses)
+		}
+
+		encoded[i] = make([]float64, numClasses)
+		encoded[i][label] = 1
+	}
+
+	return encoded, nil
+}
+
+// ArgMax returns the index of the largest value
+// in the given row (the first one if there's a
+// tie), which is the inverse of OneHot. Use this
+// to get the most likely class out of a vector of
+// class probabilities, like Softmax predictions.
+// -1 is returned for an empty row.
+func ArgMax(row []float64) int {
+	if len(row) == 0 {
+		return -1
+	}
+
+	var max int
+	for i := range row {
+		if row[i] > row[max] {
+			max = i
+		}
+	}
+
+	return max
 }
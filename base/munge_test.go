@@ -53,6 +53,59 @@ func TestNormalizeShouldPass2(t *testing.T) {
 	}
 }
 
+// the zero vector can't be normalized, so
+// it should come out unchanged without NaNs
+func TestNormalizeZeroShouldPass1(t *testing.T) {
+	x := [][]float64{
+		[]float64{0, 0, 0},
+		[]float64{3, 0, 4},
+	}
+
+	Normalize(x)
+
+	assert.Equal(t, []float64{0, 0, 0}, x[0], "The zero vector should be left unchanged")
+	assert.InDelta(t, 0.6, x[1][0], 1e-12, "Other rows should still be normalized")
+	assert.InDelta(t, 0.8, x[1][2], 1e-12, "Other rows should still be normalized")
+}
+
+func TestDenormalizeShouldPass1(t *testing.T) {
+	x := [][]float64{
+		[]float64{3, 0, 4},
+		[]float64{0, 0, 0},
+		[]float64{-1, 2, 2},
+	}
+
+	magnitudes := NormalizeWithFactors(x)
+	assert.Equal(t, []float64{5, 0, 3}, magnitudes, "Magnitudes should be the length of each row")
+	assert.InDelta(t, 0.6, x[0][0], 1e-12, "Rows should be normalized")
+
+	err := Denormalize(x, magnitudes)
+	assert.Nil(t, err, "Denormalizing error should be nil")
+
+	expected := [][]float64{
+		[]float64{3, 0, 4},
+		[]float64{0, 0, 0},
+		[]float64{-1, 2, 2},
+	}
+	for i := range x {
+		for j := range x[i] {
+			assert.InDelta(t, expected[i][j], x[i][j], 1e-12, "Denormalizing should recover the original data")
+		}
+	}
+
+	point := []float64{0.6, 0.8}
+	DenormalizePoint(point, 10)
+	assert.InDelta(t, 6, point[0], 1e-12, "Point should be scaled by the magnitude")
+	assert.InDelta(t, 8, point[1], 1e-12, "Point should be scaled by the magnitude")
+}
+
+func TestDenormalizeShouldFail1(t *testing.T) {
+	x := [][]float64{[]float64{0.6, 0.8}}
+	err := Denormalize(x, []float64{5, 2})
+	assert.NotNil(t, err, "Denormalizing error should not be nil with the wrong number of magnitudes")
+	assert.Equal(t, []float64{0.6, 0.8}, x[0], "Data should be left untouched on error")
+}
+
 func TestNormalizePointShouldPass1(t *testing.T) {
 	x := []float64{}
 
@@ -101,6 +154,15 @@ func TestNormalizePointShouldPass3(t *testing.T) {
 
 /* Benchmarks */
 
+func TestNormalizedPointShouldPass1(t *testing.T) {
+	x := []float64{3, 4}
+
+	normalized := NormalizedPoint(x)
+
+	assert.Equal(t, []float64{0.6, 0.8}, normalized, "Point should be normalized to unit length")
+	assert.Equal(t, []float64{3, 4}, x, "Original point should be left unchanged")
+}
+
 func BenchmarkNormalizePoint1Input(b *testing.B) {
 	x := []float64{100.0}
 
@@ -181,3 +243,88 @@ func BenchmarkNormalizePoint400Inputs(b *testing.B) {
 		NormalizePoint(x)
 	}
 }
+
+func TestStandardizeShouldPass1(t *testing.T) {
+	x := [][]float64{}
+
+	// features with very different ranges,
+	// and one which never changes
+	for i := -200; i < 200; i++ {
+		x = append(x, []float64{float64(i), 1000 * float64(i%7), 42})
+	}
+
+	means, stds := Standardize(x)
+	assert.Len(t, means, 3, "There should be a mean for every feature")
+	assert.Len(t, stds, 3, "There should be a standard deviation for every feature")
+
+	assert.InDelta(t, -0.5, means[0], 1e-9, "Mean of the first feature should be -0.5")
+	assert.InDelta(t, 42, means[2], 1e-9, "Mean of the constant feature should be 42")
+	assert.InDelta(t, 0, stds[2], 1e-9, "Standard deviation of the constant feature should be 0")
+
+	for j := 0; j < 2; j++ {
+		var mean, variance float64
+		for i := range x {
+			mean += x[i][j]
+		}
+		mean /= float64(len(x))
+
+		for i := range x {
+			variance += (x[i][j] - mean) * (x[i][j] - mean)
+		}
+		variance /= float64(len(x))
+
+		assert.InDelta(t, 0, mean, 1e-9, "Standardized feature %v should have a mean of 0", j)
+		assert.InDelta(t, 1, variance, 1e-9, "Standardized feature %v should have a variance of 1", j)
+	}
+
+	for i := range x {
+		assert.Equal(t, 0.0, x[i][2], "Constant feature should be standardized to 0")
+	}
+
+	// new points should be transformed the same way
+	point := []float64{-0.5 + stds[0], means[1], 100}
+	StandardizePoint(point, means, stds)
+	assert.InDelta(t, 1, point[0], 1e-9, "A point 1 standard deviation above the mean should be standardized to 1")
+	assert.InDelta(t, 0, point[1], 1e-9, "A point at the mean should be standardized to 0")
+	assert.Equal(t, 0.0, point[2], "Constant feature should be standardized to 0")
+}
+
+func TestStandardizeShouldPass2(t *testing.T) {
+	means, stds := Standardize([][]float64{})
+	assert.Nil(t, means, "Means of an empty dataset should be nil")
+	assert.Nil(t, stds, "Standard deviations of an empty dataset should be nil")
+}
+
+func TestOneHotShouldPass1(t *testing.T) {
+	encoded, err := OneHot([]int{0, 2, 1, 2}, 3)
+	assert.Nil(t, err, "One-hot encoding error should be nil")
+	assert.Equal(t, [][]float64{
+		{1, 0, 0},
+		{0, 0, 1},
+		{0, 1, 0},
+		{0, 0, 1},
+	}, encoded, "Labels should be one-hot encoded")
+
+	// ArgMax should undo the encoding
+	for i, label := range []int{0, 2, 1, 2} {
+		assert.Equal(t, label, ArgMax(encoded[i]), "ArgMax should return the encoded label")
+	}
+}
+
+func TestOneHotShouldFail1(t *testing.T) {
+	_, err := OneHot([]int{0, 3, 1}, 3)
+	assert.NotNil(t, err, "Labels out of range should return an error")
+
+	_, err = OneHot([]int{0, -1}, 3)
+	assert.NotNil(t, err, "Negative labels should return an error")
+
+	_, err = OneHot([]int{0}, 0)
+	assert.NotNil(t, err, "No classes should return an error")
+}
+
+func TestArgMaxShouldPass1(t *testing.T) {
+	assert.Equal(t, 2, ArgMax([]float64{0.1, 0.2, 0.6, 0.1}), "ArgMax should return the index of the largest value")
+	assert.Equal(t, 0, ArgMax([]float64{-1, -3, -2}), "ArgMax should work with negative values")
+	assert.Equal(t, 1, ArgMax([]float64{0, 5, 5}), "ArgMax should return the first index of a tie")
+	assert.Equal(t, -1, ArgMax([]float64{}), "ArgMax of an empty row should be -1")
+}
@@ -3,8 +3,16 @@ package base
 import (
 	"fmt"
 	"math"
+	"runtime"
+	"sync"
 )
 
+// DefaultTolerance is the tolerance used to detect
+// convergence in GradientAscent. Once the parameter
+// vector θ moves less than this (by the L2 norm) over
+// an iteration, learning stops.
+const DefaultTolerance = 1e-6
+
 // GradientAscent operates on a Ascendable model and
 // further optimizes the parameter vector Theta of the
 // model, which is then used within the Predict function.
@@ -15,7 +23,37 @@ import (
 // where J(θ) is the cost function, α is the learning
 // rate, and θ[j] is the j-th value in the parameter
 // vector
+//
+// Learning stops early if the algorithm converges
+// (see GradientAscentWithTolerance) using the
+// DefaultTolerance.
+//
+// If the model is Scheduled then α decays each
+// iteration following its LearningRateSchedule,
+// if the model is Clipped then the gradient is
+// clipped before each step, and if the model is
+// Proximal then its proximal operator is applied
+// after each step (this goes for every
+// optimization method here.)
 func GradientAscent(d Ascendable) error {
+	_, err := GradientAscentWithTolerance(d, DefaultTolerance)
+	return err
+}
+
+// GradientAscentWithTolerance runs GradientAscent on
+// the given model, but stops as soon as the algorithm
+// converges, which is when the L2 norm of the change
+// in the parameter vector over an iteration
+//
+//     ||θ(new) - θ(old)||
+//
+// is less than the given tolerance, rather than always
+// running for the model's maximum number of iterations.
+// If the tolerance isn't positive then learning never
+// stops early.
+//
+// The number of iterations actually run is returned.
+func GradientAscentWithTolerance(d Ascendable, tolerance float64) (int, error) {
 	Theta := d.Theta()
 	Alpha := d.LearningRate()
 	MaxIterations := d.MaxIterations()
@@ -28,31 +66,182 @@ func GradientAscent(d Ascendable) error {
 
 	var iter int
 	features := len(Theta)
+	prox := proximal(d)
 
 	// Stop iterating if the number of iterations exceeds
 	// the limit
-	for ; iter < MaxIterations; iter++ {
-		newTheta := make([]float64, features)
+	for iter < MaxIterations {
+		alpha := scheduledRate(d, Alpha, iter)
+
+		grad := make([]float64, features)
 		for j := range Theta {
 			dj, err := d.Dj(j)
 			if err != nil {
-				return err
+				return iter, err
 			}
 
-			newTheta[j] = Theta[j] + Alpha*dj
+			grad[j] = dj
 		}
+		clipGradient(d, grad)
 
-		// now simultaneously update Theta
+		// now simultaneously update Theta,
+		// keeping track of how far it moves
+		var change float64
 		for j := range Theta {
-			newθ := newTheta[j]
+			newθ := Theta[j] + alpha*grad[j]
 			if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
-				return fmt.Errorf("Sorry! Learning diverged. Some value of the parameter vector theta is ±Inf or NaN")
+				return iter, fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", ErrDiverged)
+			}
+			if prox != nil {
+				newθ = prox.Prox(j, newθ, alpha)
 			}
+			change += (newθ - Theta[j]) * (newθ - Theta[j])
 			Theta[j] = newθ
 		}
+		iter++
+
+		if math.Sqrt(change) < tolerance {
+			break
+		}
 	}
 
-	return nil
+	return iter, nil
+}
+
+// ParallelGradientAscent is the same as GradientAscent,
+// but the gradient of each iteration is computed over
+// chunks of the training set in parallel across
+// runtime.NumCPU() goroutines (see ParallelGradient,)
+// which is much faster for large training sets on
+// machines with multiple cores. The result is the same
+// as GradientAscent up to floating point rounding.
+func ParallelGradientAscent(d ParallelAscendable) error {
+	_, err := ParallelGradientAscentWithTolerance(d, DefaultTolerance)
+	return err
+}
+
+// ParallelGradientAscentWithTolerance is the same as
+// GradientAscentWithTolerance, but computes the gradient
+// of each iteration in parallel like ParallelGradientAscent.
+//
+// The number of iterations actually run is returned.
+func ParallelGradientAscentWithTolerance(d ParallelAscendable, tolerance float64) (int, error) {
+	Theta := d.Theta()
+	Alpha := d.LearningRate()
+	MaxIterations := d.MaxIterations()
+	Examples := d.Examples()
+
+	// if the iterations given is 0, set it to be
+	// 250 (seems reasonable base value)
+	if MaxIterations == 0 {
+		MaxIterations = 250
+	}
+
+	var iter int
+	features := len(Theta)
+	prox := proximal(d)
+
+	// Stop iterating if the number of iterations exceeds
+	// the limit
+	for iter < MaxIterations {
+		grad, err := ParallelGradient(Examples, features, d.PartialDj)
+		if err != nil {
+			return iter, err
+		}
+
+		alpha := scheduledRate(d, Alpha, iter)
+
+		for j := range grad {
+			grad[j] = d.RegularizeDj(j, grad[j])
+		}
+		clipGradient(d, grad)
+
+		// now simultaneously update Theta,
+		// keeping track of how far it moves
+		var change float64
+		for j := range Theta {
+			newθ := Theta[j] + alpha*grad[j]
+			if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
+				return iter, fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", ErrDiverged)
+			}
+			if prox != nil {
+				newθ = prox.Prox(j, newθ, alpha)
+			}
+			change += (newθ - Theta[j]) * (newθ - Theta[j])
+			Theta[j] = newθ
+		}
+		iter++
+
+		if math.Sqrt(change) < tolerance {
+			break
+		}
+	}
+
+	return iter, nil
+}
+
+// ParallelGradient splits the training examples 0 through
+// examples-1 into contiguous chunks, one for each of
+// runtime.NumCPU() goroutines, and sums the partial
+// gradients (of length features) returned by calling
+// partial(start, end) on each chunk. The chunks are
+// always added up in order, so the result doesn't
+// depend on which goroutine finishes first.
+//
+// partial must be safe to call concurrently. If it
+// returns an error for any chunk, the error of the
+// earliest chunk is returned.
+func ParallelGradient(examples, features int, partial func(start, end int) ([]float64, error)) ([]float64, error) {
+	grad := make([]float64, features)
+	if examples < 1 {
+		return grad, nil
+	}
+
+	workers := runtime.NumCPU()
+	if workers > examples {
+		workers = examples
+	}
+	chunk := (examples + workers - 1) / workers
+
+	partials := make([][]float64, workers)
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * chunk
+		end := start + chunk
+		if end > examples {
+			end = examples
+		}
+		if start >= end {
+			break
+		}
+
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			partials[w], errs[w] = partial(start, end)
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	for w := range partials {
+		if errs[w] != nil {
+			return nil, errs[w]
+		}
+		if partials[w] == nil {
+			continue
+		}
+		if len(partials[w]) != features {
+			return nil, fmt.Errorf("ERROR: Partial gradient should have one value per parameter!\n\tLength of partial gradient: %v\n\tNumber of parameters: %v\n", len(partials[w]), features)
+		}
+
+		for j := range grad {
+			grad[j] += partials[w][j]
+		}
+	}
+
+	return grad, nil
 }
 
 // StochasticGradientAscent operates on a StochasticAscendable
@@ -72,6 +261,11 @@ func GradientAscent(d Ascendable) error {
 // where J(θ) is the cost function, α is the learning
 // rate, and θ[j] is the j-th value in the parameter
 // vector
+//
+// If the model is Shuffled, the order the training
+// examples are visited in is shuffled at the start of
+// every iteration with the model's source of randomness.
+// Otherwise they're visited in order.
 func StochasticGradientAscent(d StochasticAscendable) error {
 	Theta := d.Theta()
 	Alpha := d.LearningRate()
@@ -86,26 +280,45 @@ func StochasticGradientAscent(d StochasticAscendable) error {
 
 	var iter int
 	features := len(Theta)
+	prox := proximal(d)
+
+	rng := shuffleSource(d)
+	order := make([]int, Examples)
+	for i := range order {
+		order[i] = i
+	}
 
 	// Stop iterating if the number of iterations exceeds
 	// the limit
 	for ; iter < MaxIterations; iter++ {
-		newTheta := make([]float64, features)
-		for i := 0; i < Examples; i++ {
+		alpha := scheduledRate(d, Alpha, iter)
+
+		if rng != nil {
+			rng.Shuffle(Examples, func(a, b int) {
+				order[a], order[b] = order[b], order[a]
+			})
+		}
+
+		grad := make([]float64, features)
+		for _, i := range order {
 			for j := range Theta {
 				dj, err := d.Dij(i, j)
 				if err != nil {
 					return err
 				}
 
-				newTheta[j] = Theta[j] + Alpha*dj
+				grad[j] = dj
 			}
+			clipGradient(d, grad)
 
 			// now simultaneously update Theta
 			for j := range Theta {
-				newθ := newTheta[j]
+				newθ := Theta[j] + alpha*grad[j]
 				if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
-					return fmt.Errorf("Sorry! Learning diverged. Some value of the parameter vector theta is ±Inf or NaN")
+					return fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", ErrDiverged)
+				}
+				if prox != nil {
+					newθ = prox.Prox(j, newθ, alpha)
 				}
 				Theta[j] = newθ
 			}
@@ -114,3 +327,293 @@ func StochasticGradientAscent(d StochasticAscendable) error {
 
 	return nil
 }
+
+// MiniBatchGradientAscent operates on a StochasticAscendable
+// model and further optimizes the parameter vector Theta of
+// the model, which is then used within the Predict function.
+// Mini-batch gradient ascent is the middle ground between
+// batch and stochastic gradient ascent: each iteration (or
+// epoch) shuffles the training set, then updates the
+// parameter vector once for every batchSize examples using
+// the average of Dij over the examples in the batch. The
+// last batch of an epoch might be smaller if batchSize
+// doesn't divide the number of examples evenly.
+//
+// Gradient Ascent follows the following algorithm:
+// θ[j] := θ[j] + α·∇J(θ)
+//
+// where J(θ) is the cost function, α is the learning
+// rate, and θ[j] is the j-th value in the parameter
+// vector
+//
+// The training set is shuffled with the model's source
+// of randomness if it's Shuffled, or otherwise with one
+// seeded with DefaultShuffleSeed, so training is
+// reproducible.
+func MiniBatchGradientAscent(d StochasticAscendable, batchSize int) error {
+	Theta := d.Theta()
+	Alpha := d.LearningRate()
+	MaxIterations := d.MaxIterations()
+	Examples := d.Examples()
+
+	// if the iterations given is 0, set it to be
+	// 250 (seems reasonable base value)
+	if MaxIterations == 0 {
+		MaxIterations = 250
+	}
+
+	// if the batch size given is less than 1, set
+	// it to be 32 (seems reasonable base value)
+	if batchSize < 1 {
+		batchSize = 32
+	}
+
+	var iter int
+	features := len(Theta)
+	prox := proximal(d)
+	rng := shuffleRand(d)
+
+	// Stop iterating if the number of iterations exceeds
+	// the limit
+	for ; iter < MaxIterations; iter++ {
+		alpha := scheduledRate(d, Alpha, iter)
+		order := rng.Perm(Examples)
+
+		for start := 0; start < Examples; start += batchSize {
+			end := start + batchSize
+			if end > Examples {
+				end = Examples
+			}
+
+			grad := make([]float64, features)
+			for _, i := range order[start:end] {
+				for j := range Theta {
+					dj, err := d.Dij(i, j)
+					if err != nil {
+						return err
+					}
+
+					grad[j] += dj
+				}
+			}
+
+			size := float64(end - start)
+			for j := range grad {
+				grad[j] /= size
+			}
+			clipGradient(d, grad)
+
+			// now simultaneously update Theta
+			for j := range Theta {
+				newθ := Theta[j] + alpha*grad[j]
+				if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
+					return fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", ErrDiverged)
+				}
+				if prox != nil {
+					newθ = prox.Prox(j, newθ, alpha)
+				}
+				Theta[j] = newθ
+			}
+		}
+	}
+
+	return nil
+}
+
+// AdamOptimizer holds the hyperparameters and the
+// per-parameter state of the Adam optimization method
+// (adaptive moment estimation.) Adam keeps exponentially
+// decaying averages of past gradients (the first moment
+// m) and past squared gradients (the second moment v)
+// for each parameter, and scales each step by them,
+// which makes training much less sensitive to the
+// choice of learning rate α.
+//
+// https://arxiv.org/abs/1412.6980
+//
+// Beta1, Beta2, and Epsilon are tunable. Use
+// NewAdamOptimizer to get the usual defaults.
+type AdamOptimizer struct {
+	// Beta1 and Beta2 are the decay rates of the
+	// first and second moment estimates,
+	// respectively. Epsilon is added to the
+	// denominator of each step to avoid
+	// dividing by 0
+	Beta1   float64
+	Beta2   float64
+	Epsilon float64
+
+	m []float64
+	v []float64
+	t int
+}
+
+// NewAdamOptimizer returns an AdamOptimizer with the
+// defaults recommended by the Adam paper: β1 = 0.9,
+// β2 = 0.999, and ε = 1e-8
+func NewAdamOptimizer() *AdamOptimizer {
+	return &AdamOptimizer{
+		Beta1:   0.9,
+		Beta2:   0.999,
+		Epsilon: 1e-8,
+	}
+}
+
+// Reset clears the moment estimates and timestep
+// of the optimizer so it can be used to train
+// from scratch again
+func (a *AdamOptimizer) Reset() {
+	a.m = nil
+	a.v = nil
+	a.t = 0
+}
+
+// Step takes the gradient of the function being
+// maximized with respect to each parameter in
+// theta, updates the moment estimates, then updates
+// theta in place following
+//
+//     m := β1·m + (1-β1)·g
+//     v := β2·v + (1-β2)·g²
+//     θ := θ + α·m̂/(√v̂ + ε)
+//
+// where m̂ and v̂ are the bias corrected moment
+// estimates. An error is returned if theta and the
+// gradient have different lengths or if learning
+// diverges.
+func (a *AdamOptimizer) Step(theta, grad []float64, alpha float64) error {
+	if len(theta) != len(grad) {
+		return fmt.Errorf("ERROR: Parameter vector and gradient should be the same length!\n\tLength of theta: %v\n\tLength of gradient: %v\n", len(theta), len(grad))
+	}
+
+	if len(a.m) != len(theta) {
+		a.m = make([]float64, len(theta))
+		a.v = make([]float64, len(theta))
+		a.t = 0
+	}
+
+	a.t++
+	correction1 := 1 - math.Pow(a.Beta1, float64(a.t))
+	correction2 := 1 - math.Pow(a.Beta2, float64(a.t))
+
+	for j := range theta {
+		a.m[j] = a.Beta1*a.m[j] + (1-a.Beta1)*grad[j]
+		a.v[j] = a.Beta2*a.v[j] + (1-a.Beta2)*grad[j]*grad[j]
+
+		mHat := a.m[j] / correction1
+		vHat := a.v[j] / correction2
+
+		newθ := theta[j] + alpha*mHat/(math.Sqrt(vHat)+a.Epsilon)
+		if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
+			return fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", ErrDiverged)
+		}
+		theta[j] = newθ
+	}
+
+	return nil
+}
+
+// StepSize returns the learning rate the last Step
+// effectively took the j-th parameter's step with,
+// given the α it was called with:
+//
+//     α/(√v̂[j] + ε)
+//
+// This is the learning rate to apply a proximal
+// operator with after the step (see Proximal.) It
+// returns alpha if the optimizer hasn't taken a step.
+func (a *AdamOptimizer) StepSize(j int, alpha float64) float64 {
+	if a.t == 0 || j < 0 || j >= len(a.v) {
+		return alpha
+	}
+
+	vHat := a.v[j] / (1 - math.Pow(a.Beta2, float64(a.t)))
+
+	return alpha / (math.Sqrt(vHat) + a.Epsilon)
+}
+
+// AdamAscent operates on a StochasticAscendable model
+// and further optimizes the parameter vector Theta of
+// the model, which is then used within the Predict
+// function. Like MiniBatchGradientAscent, each iteration
+// shuffles the training set and averages Dij over
+// batches of batchSize examples, but each update is
+// taken with the given AdamOptimizer rather than with
+// a fixed step of α times the gradient.
+//
+// If the optimizer is nil then the defaults from
+// NewAdamOptimizer are used. The optimizer is Reset
+// before training. The training set is shuffled like
+// it is for MiniBatchGradientAscent.
+func AdamAscent(d StochasticAscendable, batchSize int, optimizer *AdamOptimizer) error {
+	Theta := d.Theta()
+	Alpha := d.LearningRate()
+	MaxIterations := d.MaxIterations()
+	Examples := d.Examples()
+
+	// if the iterations given is 0, set it to be
+	// 250 (seems reasonable base value)
+	if MaxIterations == 0 {
+		MaxIterations = 250
+	}
+
+	// if the batch size given is less than 1, set
+	// it to be 32 (seems reasonable base value)
+	if batchSize < 1 {
+		batchSize = 32
+	}
+
+	if optimizer == nil {
+		optimizer = NewAdamOptimizer()
+	}
+	optimizer.Reset()
+
+	var iter int
+	features := len(Theta)
+	prox := proximal(d)
+	rng := shuffleRand(d)
+
+	// Stop iterating if the number of iterations exceeds
+	// the limit
+	for ; iter < MaxIterations; iter++ {
+		alpha := scheduledRate(d, Alpha, iter)
+		order := rng.Perm(Examples)
+
+		for start := 0; start < Examples; start += batchSize {
+			end := start + batchSize
+			if end > Examples {
+				end = Examples
+			}
+
+			grad := make([]float64, features)
+			for _, i := range order[start:end] {
+				for j := range Theta {
+					dj, err := d.Dij(i, j)
+					if err != nil {
+						return err
+					}
+
+					grad[j] += dj
+				}
+			}
+
+			size := float64(end - start)
+			for j := range grad {
+				grad[j] /= size
+			}
+			clipGradient(d, grad)
+
+			err := optimizer.Step(Theta, grad, alpha)
+			if err != nil {
+				return err
+			}
+			if prox != nil {
+				for j := range Theta {
+					Theta[j] = prox.Prox(j, Theta[j], optimizer.StepSize(j, alpha))
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,290 @@
+package base
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PCA implements Principal Component Analysis, which
+// reduces the number of features of a dataset by
+// projecting it onto the directions it varies along
+// the most (its principal components.) This is useful
+// before clustering or regressing on high-dimensional
+// data: most of the information in the data is kept
+// with far fewer features.
+//
+// The principal components are the eigenvectors of
+// the covariance matrix of the data with the largest
+// eigenvalues, and each eigenvalue is the variance
+// of the data along its component.
+//
+// https://en.wikipedia.org/wiki/Principal_component_analysis
+//
+// Example PCA Usage:
+//
+//	pca := base.NewPCA()
+//
+//	// keep the top 2 principal components
+//	err := pca.Fit(x, 2)
+//	if err != nil {
+//	    panic("fitting error")
+//	}
+//
+//	// how much of the variance of the data
+//	// each component explains
+//	fmt.Println(pca.ExplainedVarianceRatio)
+//
+//	// the data projected onto the components
+//	reduced, err := pca.Transform(x)
+//	if err != nil {
+//	    panic("transform error")
+//	}
+type PCA struct {
+	// Mean is the mean of each feature of the
+	// data the model was fit to, which is
+	// subtracted before projecting
+	Mean []float64 `json:"mean"`
+
+	// Components holds the principal components
+	// (unit vectors) as rows, from the one the
+	// data varies along the most to the least
+	Components [][]float64 `json:"components"`
+
+	// ExplainedVariance is the variance of the data
+	// along each component, and ExplainedVarianceRatio
+	// is that variance as a fraction of the total
+	// variance of the data, so you can pick how
+	// many components you need to keep
+	ExplainedVariance      []float64 `json:"explained_variance"`
+	ExplainedVarianceRatio []float64 `json:"explained_variance_ratio"`
+}
+
+// NewPCA returns a pointer to a PCA model, which
+// needs to be fit to data before transforming any.
+func NewPCA() *PCA {
+	return &PCA{}
+}
+
+// Fit finds the given number of principal components of
+// the dataset x (where each row is a datapoint) from
+// the eigendecomposition of its covariance matrix. Pass
+// the number of features as components to keep all of
+// them, which is useful to see the ExplainedVarianceRatio
+// of each before picking how many you need.
+//
+// An error is returned if there are fewer than 2
+// datapoints, the rows have different lengths, or
+// components isn't between 1 and the number of
+// features.
+func (p *PCA) Fit(x [][]float64, components int) error {
+	if len(x) < 2 || len(x[0]) == 0 {
+		return fmt.Errorf("ERROR: PCA needs at least 2 datapoints with at least 1 feature!\n\tNumber of datapoints: %v\n", len(x))
+	}
+
+	features := len(x[0])
+	for i := range x {
+		if len(x[i]) != features {
+			return fmt.Errorf("ERROR: %w: Row %v of the dataset has the wrong dimension!\n\tLength of row: %v\n\tExpected length: %v\n", ErrDimensionMismatch, i, len(x[i]), features)
+		}
+	}
+
+	if components < 1 || components > features {
+		return fmt.Errorf("ERROR: the number of components (%v) should be between 1 and the number of features (%v)!", components, features)
+	}
+
+	mean := make([]float64, features)
+	for i := range x {
+		for j := range x[i] {
+			mean[j] += x[i][j]
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(len(x))
+	}
+
+	// the (sample) covariance matrix
+	cov := make([][]float64, features)
+	for j := range cov {
+		cov[j] = make([]float64, features)
+	}
+	for i := range x {
+		for j := 0; j < features; j++ {
+			dj := x[i][j] - mean[j]
+			for l := j; l < features; l++ {
+				cov[j][l] += dj * (x[i][l] - mean[l])
+			}
+		}
+	}
+	for j := 0; j < features; j++ {
+		for l := j; l < features; l++ {
+			cov[j][l] /= float64(len(x) - 1)
+			cov[l][j] = cov[j][l]
+		}
+	}
+
+	values, vectors := symmetricEigen(cov)
+
+	var total float64
+	for _, value := range values {
+		total += value
+	}
+
+	// order the eigenvectors by eigenvalue,
+	// largest first
+	order := make([]int, features)
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return values[order[a]] > values[order[b]]
+	})
+
+	p.Mean = mean
+	p.Components = make([][]float64, components)
+	p.ExplainedVariance = make([]float64, components)
+	p.ExplainedVarianceRatio = make([]float64, components)
+	for c := 0; c < components; c++ {
+		k := order[c]
+
+		// eigenvectors are the columns of vectors,
+		// and they're flipped so their largest
+		// value is positive, which makes the
+		// components deterministic
+		component := make([]float64, features)
+		var largest float64
+		for j := range component {
+			component[j] = vectors[j][k]
+			if math.Abs(component[j]) > math.Abs(largest) {
+				largest = component[j]
+			}
+		}
+		if largest < 0 {
+			for j := range component {
+				component[j] = -component[j]
+			}
+		}
+
+		// rounding can leave tiny negative
+		// eigenvalues when there's no variance
+		variance := math.Max(values[k], 0)
+
+		p.Components[c] = component
+		p.ExplainedVariance[c] = variance
+		if total > 0 {
+			p.ExplainedVarianceRatio[c] = variance / total
+		}
+	}
+
+	return nil
+}
+
+// Transform projects each row of x onto the principal
+// components the model was fit to, returning a new
+// dataset with one feature per component. x isn't
+// changed.
+//
+// An error is returned if the model hasn't been fit
+// or a row doesn't have the same number of features
+// as the data the model was fit to.
+func (p *PCA) Transform(x [][]float64) ([][]float64, error) {
+	if len(p.Components) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: Attempting to transform with a PCA model that hasn't been fit!\n", ErrNotTrained)
+	}
+
+	reduced := make([][]float64, len(x))
+	for i := range x {
+		if len(x[i]) != len(p.Mean) {
+			return nil, fmt.Errorf("ERROR: %w: Row %v of the dataset has the wrong dimension!\n\tLength of row: %v\n\tExpected length: %v\n", ErrDimensionMismatch, i, len(x[i]), len(p.Mean))
+		}
+
+		reduced[i] = make([]float64, len(p.Components))
+		for c, component := range p.Components {
+			var sum float64
+			for j := range component {
+				sum += (x[i][j] - p.Mean[j]) * component[j]
+			}
+			reduced[i][c] = sum
+		}
+	}
+
+	return reduced, nil
+}
+
+// symmetricEigen finds the eigenvalues and eigenvectors
+// of the symmetric matrix a with the cyclic Jacobi
+// eigenvalue algorithm, which repeatedly rotates a to
+// zero out its off-diagonal values. The eigenvectors
+// are returned as the columns of vectors, so
+// vectors[j][k] is value j of eigenvector k. a is
+// changed in the process.
+//
+// https://en.wikipedia.org/wiki/Jacobi_eigenvalue_algorithm
+func symmetricEigen(a [][]float64) ([]float64, [][]float64) {
+	n := len(a)
+
+	vectors := make([][]float64, n)
+	for j := range vectors {
+		vectors[j] = make([]float64, n)
+		vectors[j][j] = 1
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		var off, diag float64
+		for p := 0; p < n; p++ {
+			diag += a[p][p] * a[p][p]
+			for q := p + 1; q < n; q++ {
+				off += a[p][q] * a[p][q]
+			}
+		}
+
+		// stop once the off-diagonal values are
+		// negligible next to the diagonal
+		if off <= 1e-30*diag || off == 0 {
+			break
+		}
+
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+
+				// find the rotation which zeroes a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				// a := Rᵀ a R
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+
+				// vectors := vectors R
+				for k := 0; k < n; k++ {
+					vkp, vkq := vectors[k][p], vectors[k][q]
+					vectors[k][p] = c*vkp - s*vkq
+					vectors[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	values := make([]float64, n)
+	for j := range values {
+		values[j] = a[j][j]
+	}
+
+	return values, vectors
+}
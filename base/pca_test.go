@@ -0,0 +1,147 @@
+package base
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// points spread out along (1, 2, 0) with a little
+// noise in every direction
+func linePCAData() [][]float64 {
+	r := rand.New(rand.NewSource(1))
+
+	x := [][]float64{}
+	for i := 0; i < 500; i++ {
+		t := 10 * r.NormFloat64()
+		x = append(x, []float64{
+			3 + t + 0.1*r.NormFloat64(),
+			-1 + 2*t + 0.1*r.NormFloat64(),
+			5 + 0.1*r.NormFloat64(),
+		})
+	}
+
+	return x
+}
+
+func TestPCAShouldPass1(t *testing.T) {
+	x := linePCAData()
+
+	pca := NewPCA()
+	assert.Nil(t, pca.Fit(x, 3), "Fitting error should be nil")
+
+	// the first component should be along the line
+	assert.InDelta(t, 1/math.Sqrt(5), pca.Components[0][0], 1e-3, "First component should be along the line")
+	assert.InDelta(t, 2/math.Sqrt(5), pca.Components[0][1], 1e-3, "First component should be along the line")
+	assert.InDelta(t, 0, pca.Components[0][2], 1e-3, "First component should be along the line")
+	assert.True(t, pca.ExplainedVarianceRatio[0] > 0.99, "First component should explain almost all of the variance (%v)", pca.ExplainedVarianceRatio[0])
+
+	var sum float64
+	for c := range pca.Components {
+		sum += pca.ExplainedVarianceRatio[c]
+		if c > 0 {
+			assert.True(t, pca.ExplainedVariance[c-1] >= pca.ExplainedVariance[c], "Components should be ordered by variance")
+		}
+
+		// components should be orthonormal
+		for d := range pca.Components {
+			var dot float64
+			for j := range pca.Components[c] {
+				dot += pca.Components[c][j] * pca.Components[d][j]
+			}
+
+			expected := 0.0
+			if c == d {
+				expected = 1
+			}
+			assert.InDelta(t, expected, dot, 1e-9, "Components should be orthonormal")
+		}
+	}
+	assert.InDelta(t, 1, sum, 1e-9, "Explained variance ratios of every component should add up to 1")
+
+	// the transformed data should be centered with
+	// the explained variance along each feature
+	reduced, err := pca.Transform(x)
+	assert.Nil(t, err, "Transform error should be nil")
+	assert.Len(t, reduced, len(x), "Transform should keep every row")
+
+	for c := range pca.Components {
+		var mean, variance float64
+		for i := range reduced {
+			mean += reduced[i][c]
+		}
+		mean /= float64(len(reduced))
+
+		for i := range reduced {
+			variance += (reduced[i][c] - mean) * (reduced[i][c] - mean)
+		}
+		variance /= float64(len(reduced) - 1)
+
+		assert.InDelta(t, 0, mean, 1e-9, "Transformed data should be centered")
+		assert.InDelta(t, pca.ExplainedVariance[c], variance, 1e-6, "Transformed variance should be the explained variance")
+	}
+}
+
+func TestPCAShouldPass2(t *testing.T) {
+	x := linePCAData()
+
+	pca := NewPCA()
+	assert.Nil(t, pca.Fit(x, 1), "Fitting error should be nil")
+
+	reduced, err := pca.Transform(x[:2])
+	assert.Nil(t, err, "Transform error should be nil")
+	assert.Len(t, reduced, 2, "Transform should keep every row")
+	assert.Len(t, reduced[0], 1, "Transform should keep one feature per component")
+	assert.Len(t, pca.ExplainedVarianceRatio, 1, "There should be one ratio per component")
+}
+
+func TestSymmetricEigenShouldPass1(t *testing.T) {
+	a := [][]float64{
+		{4, 1, 2},
+		{1, 3, 0},
+		{2, 0, 5},
+	}
+
+	values, vectors := symmetricEigen([][]float64{
+		{4, 1, 2},
+		{1, 3, 0},
+		{2, 0, 5},
+	})
+
+	// a v = λ v for each eigenvector v
+	for k := range values {
+		for j := range a {
+			var av float64
+			for l := range a {
+				av += a[j][l] * vectors[l][k]
+			}
+
+			assert.InDelta(t, values[k]*vectors[j][k], av, 1e-9, "Eigenvector %v should satisfy a v = λ v", k)
+		}
+	}
+
+	// the trace is the sum of the eigenvalues
+	assert.InDelta(t, 12, values[0]+values[1]+values[2], 1e-9, "Eigenvalues should add up to the trace")
+}
+
+func TestPCAShouldFail1(t *testing.T) {
+	x := linePCAData()
+
+	pca := NewPCA()
+	assert.NotNil(t, pca.Fit(x, 0), "Fitting error should not be nil with no components")
+	assert.NotNil(t, pca.Fit(x, 4), "Fitting error should not be nil with more components than features")
+	assert.NotNil(t, pca.Fit(x[:1], 1), "Fitting error should not be nil with one datapoint")
+	assert.NotNil(t, pca.Fit([][]float64{{1, 2}, {3}}, 1), "Fitting error should not be nil with mismatched rows")
+
+	_, err := pca.Transform(x)
+	assert.NotNil(t, err, "Transform error should not be nil before fitting")
+	assert.True(t, errors.Is(err, ErrNotTrained), "Transform error should wrap ErrNotTrained - Given %v", err)
+
+	assert.Nil(t, pca.Fit(x, 2), "Fitting error should be nil")
+	_, err = pca.Transform([][]float64{{1, 2}})
+	assert.NotNil(t, err, "Transform error should not be nil with the wrong dimension")
+	assert.True(t, errors.Is(err, ErrDimensionMismatch), "Transform error should wrap ErrDimensionMismatch - Given %v", err)
+}
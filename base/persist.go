@@ -0,0 +1,92 @@
+package base
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// PersistToGob takes in an absolute filepath and saves
+// the given value to the file using the binary
+// encoding/gob format. Models use this to implement
+// their own PersistToGob methods, passing in the same
+// data they persist as JSON with PersistToFile.
+//
+// Gob files are much more compact and faster to parse
+// than JSON, which matters for models with a lot of
+// parameters (like the text models' vocabularies,) but
+// they aren't human readable and can only be read from
+// Go. Only exported fields of structs are saved.
+func PersistToGob(path string, v interface{}) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = gob.NewEncoder(file).Encode(v)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// RestoreFromGob takes in a path to a file saved with
+// PersistToGob and decodes it into the value v points
+// to, which should be the same type that was persisted.
+//
+// The path must ba an absolute path or a path from the current
+// directory
+func RestoreFromGob(path string, v interface{}) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to restore your model from a file with no path! That's a no-no. Try it with a valid filepath")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return gob.NewDecoder(file).Decode(v)
+}
+
+// PersistParametersToGob saves a parameter vector θ
+// along with the model's feature names (nil if the
+// features aren't named) to the given file with
+// PersistToGob. Models with a single parameter vector
+// use this so their gob files hold the same data as
+// the JSON saved with MarshalParameters.
+func PersistParametersToGob(path string, theta []float64, featureNames []string) error {
+	return PersistToGob(path, persistedParameters{
+		Parameters:   theta,
+		FeatureNames: featureNames,
+	})
+}
+
+// RestoreParametersFromGob reads a file saved with
+// PersistParametersToGob, returning the parameter
+// vector θ and the feature names (nil if there
+// weren't any.) Files holding only θ, as older
+// versions saved, are read as well.
+func RestoreParametersFromGob(path string) ([]float64, []string, error) {
+	var model persistedParameters
+	err := RestoreFromGob(path, &model)
+	if err == nil {
+		return model.Parameters, model.FeatureNames, nil
+	}
+
+	var theta []float64
+	if RestoreFromGob(path, &theta) != nil {
+		// report the error for the
+		// current format
+		return nil, nil, err
+	}
+
+	return theta, nil, nil
+}
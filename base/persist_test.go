@@ -0,0 +1,72 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistToGobShouldPass1(t *testing.T) {
+	theta := [][]float64{{1, -2.5, 3e-12}, {0, 4, 1e300}}
+
+	err := PersistToGob("/tmp/.goml/theta.gob", theta)
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	var restored [][]float64
+	err = RestoreFromGob("/tmp/.goml/theta.gob", &restored)
+	assert.Nil(t, err, "Restoring error should be nil")
+	assert.Equal(t, theta, restored, "Restored value should match the persisted value")
+}
+
+func TestPersistToGobShouldFail1(t *testing.T) {
+	err := PersistToGob("", []float64{1})
+	assert.NotNil(t, err, "Persisting with no path should return an error")
+
+	var restored []float64
+	err = RestoreFromGob("", &restored)
+	assert.NotNil(t, err, "Restoring with no path should return an error")
+
+	err = RestoreFromGob("/tmp/.goml/does_not_exist.gob", &restored)
+	assert.NotNil(t, err, "Restoring from a missing file should return an error")
+
+	// the file holds a [][]float64
+	err = PersistToGob("/tmp/.goml/theta.gob", [][]float64{{1, 2}})
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	var wrong map[string]int
+	err = RestoreFromGob("/tmp/.goml/theta.gob", &wrong)
+	assert.NotNil(t, err, "Restoring into the wrong type should return an error")
+}
+
+func TestPersistParametersToGobShouldPass1(t *testing.T) {
+	theta := []float64{1, -2.5, 3}
+	names := []string{"age", "height"}
+
+	err := PersistParametersToGob("/tmp/.goml/params.gob", theta, names)
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	restored, restoredNames, err := RestoreParametersFromGob("/tmp/.goml/params.gob")
+	assert.Nil(t, err, "Restoring error should be nil")
+	assert.Equal(t, theta, restored, "Restored parameters should match the persisted parameters")
+	assert.Equal(t, names, restoredNames, "Restored feature names should match the persisted feature names")
+
+	// older versions saved θ on its own
+	err = PersistToGob("/tmp/.goml/params.gob", theta)
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	restored, restoredNames, err = RestoreParametersFromGob("/tmp/.goml/params.gob")
+	assert.Nil(t, err, "Restoring error should be nil")
+	assert.Equal(t, theta, restored, "Restored parameters should match the persisted parameters")
+	assert.Nil(t, restoredNames, "A file holding only θ shouldn't have feature names")
+}
+
+func TestPersistParametersToGobShouldFail1(t *testing.T) {
+	_, _, err := RestoreParametersFromGob("")
+	assert.NotNil(t, err, "Restoring with no path should return an error")
+
+	err = PersistToGob("/tmp/.goml/params.gob", map[string]int{"a": 1})
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	_, _, err = RestoreParametersFromGob("/tmp/.goml/params.gob")
+	assert.NotNil(t, err, "Restoring a file that doesn't hold parameters should return an error")
+}
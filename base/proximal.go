@@ -0,0 +1,30 @@
+package base
+
+// Proximal is implemented by models with a penalty
+// that isn't differentiable everywhere (like the L1
+// regularization penalty λ·|θ[j]|, which has no
+// derivative at 0.) The penalty isn't part of the
+// gradient for these models; instead every
+// optimization method here applies the model's
+// proximal operator to each parameter right after
+// each step, using the learning rate the step was
+// actually taken with (after the model's schedule,
+// and for Adam the per-parameter step size.) This is
+// what lets an L1 penalty drive parameters to exactly
+// 0 no matter how the step was taken.
+type Proximal interface {
+	// Prox returns the j-th parameter after applying
+	// the penalty to theta, the value it was just
+	// stepped to with learning rate alpha
+	Prox(j int, theta, alpha float64) float64
+}
+
+// proximal returns the model d's proximal operator,
+// or nil if it isn't Proximal
+func proximal(d interface{}) Proximal {
+	if p, ok := d.(Proximal); ok {
+		return p
+	}
+
+	return nil
+}
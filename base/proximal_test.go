@@ -0,0 +1,35 @@
+package base
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type proximalModel struct{}
+
+func (p proximalModel) Prox(j int, theta, alpha float64) float64 {
+	return math.Max(math.Abs(theta)-alpha, 0) * math.Copysign(1, theta)
+}
+
+func TestProximalShouldPass1(t *testing.T) {
+	assert.Nil(t, proximal(struct{}{}), "Models without a proximal operator shouldn't have one")
+
+	prox := proximal(proximalModel{})
+	assert.NotNil(t, prox, "Proximal models should have a proximal operator")
+	assert.Equal(t, 0.5, prox.Prox(0, 1, 0.5), "Proximal operator should be the model's")
+}
+
+func TestAdamStepSizeShouldPass1(t *testing.T) {
+	adam := NewAdamOptimizer()
+	assert.Equal(t, 0.1, adam.StepSize(0, 0.1), "Step size should be α before the first step")
+
+	theta := []float64{0, 0}
+	assert.Nil(t, adam.Step(theta, []float64{2, -0.5}, 0.1), "Step error should be nil")
+
+	// after one step v̂ is the squared gradient
+	assert.InDelta(t, 0.1/(2+1e-8), adam.StepSize(0, 0.1), 1e-12, "Step size should be α/(√v̂ + ε)")
+	assert.InDelta(t, 0.1/(0.5+1e-8), adam.StepSize(1, 0.1), 1e-12, "Step size should be α/(√v̂ + ε)")
+	assert.InDelta(t, theta[0], 2*adam.StepSize(0, 0.1), 1e-12, "Step should be the step size times m̂")
+}
@@ -0,0 +1,107 @@
+package base
+
+import (
+	"math"
+)
+
+// LearningRateSchedule decays the learning rate α
+// over the course of training. It takes in the
+// model's initial learning rate α₀ and the current
+// iteration t (starting at 0, and counting passes
+// over the training set for the stochastic methods)
+// and returns the learning rate to use for that
+// iteration.
+//
+// A nil schedule keeps the learning rate constant,
+// so models behave exactly like they did without
+// one unless a schedule is set. Use ConstantRate,
+// StepDecay, ExponentialDecay, or InverseTimeDecay
+// for the common schedules, or any function of
+// your own.
+type LearningRateSchedule func(alpha float64, iter int) float64
+
+// Scheduled is implemented by models whose learning
+// rate can decay during training. GradientAscent and
+// the other optimization methods in this package use
+// the model's schedule in place of the constant
+// LearningRate when the model implements it.
+type Scheduled interface {
+	// LearningRateSchedule returns the schedule the
+	// learning rate decays with (nil for a constant
+	// learning rate)
+	LearningRateSchedule() LearningRateSchedule
+}
+
+// Rate returns the learning rate to use for the
+// given iteration, starting from α₀ = alpha. A nil
+// schedule always returns alpha.
+func (s LearningRateSchedule) Rate(alpha float64, iter int) float64 {
+	if s == nil {
+		return alpha
+	}
+
+	return s(alpha, iter)
+}
+
+// ConstantRate returns a schedule that never decays
+// the learning rate. It's the same as a nil schedule.
+func ConstantRate() LearningRateSchedule {
+	return func(alpha float64, iter int) float64 {
+		return alpha
+	}
+}
+
+// StepDecay returns a schedule that multiplies the
+// learning rate by factor (ie. 0.5 to halve it)
+// once every given number of iterations:
+//
+//     α = α₀ * factor^⌊t/every⌋
+//
+// If every isn't positive the learning rate is
+// never dropped.
+func StepDecay(factor float64, every int) LearningRateSchedule {
+	return func(alpha float64, iter int) float64 {
+		if every < 1 {
+			return alpha
+		}
+
+		return alpha * math.Pow(factor, float64(iter/every))
+	}
+}
+
+// ExponentialDecay returns a schedule that decays
+// the learning rate exponentially with rate k:
+//
+//     α = α₀ * exp(-kt)
+func ExponentialDecay(k float64) LearningRateSchedule {
+	return func(alpha float64, iter int) float64 {
+		return alpha * math.Exp(-k*float64(iter))
+	}
+}
+
+// InverseTimeDecay returns a schedule that decays
+// the learning rate in proportion to the inverse of
+// the iteration with rate k:
+//
+//     α = α₀ / (1 + kt)
+//
+// This lets you start training with a large
+// learning rate to make quick progress, while
+// still taking small enough steps later on to
+// converge cleanly.
+func InverseTimeDecay(k float64) LearningRateSchedule {
+	return func(alpha float64, iter int) float64 {
+		return alpha / (1 + k*float64(iter))
+	}
+}
+
+// scheduledRate returns the learning rate the model d
+// should use for the given iteration: the rate from its
+// schedule if it's Scheduled, and alpha otherwise
+func scheduledRate(d interface{}, alpha float64, iter int) float64 {
+	if s, ok := d.(Scheduled); ok {
+		return s.LearningRateSchedule().Rate(alpha, iter)
+	}
+
+	return alpha
+}
@@ -0,0 +1,38 @@
+package base
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLearningRateScheduleShouldPass1(t *testing.T) {
+	var constant LearningRateSchedule
+	assert.Equal(t, 0.5, constant.Rate(0.5, 100), "A nil schedule should keep the learning rate constant")
+	assert.Equal(t, 0.5, ConstantRate().Rate(0.5, 100), "ConstantRate should keep the learning rate constant")
+
+	step := StepDecay(0.5, 10)
+	assert.Equal(t, 1.0, step.Rate(1, 0), "StepDecay shouldn't drop the learning rate at first")
+	assert.Equal(t, 1.0, step.Rate(1, 9), "StepDecay shouldn't drop the learning rate before the first step")
+	assert.Equal(t, 0.5, step.Rate(1, 10), "StepDecay should halve the learning rate after the first step")
+	assert.Equal(t, 0.25, step.Rate(1, 25), "StepDecay should halve the learning rate twice after two steps")
+	assert.Equal(t, 1.0, StepDecay(0.5, 0).Rate(1, 25), "StepDecay should never drop the learning rate without a positive step")
+
+	assert.InDelta(t, 2*math.Exp(-1), ExponentialDecay(0.1).Rate(2, 10), 1e-12, "ExponentialDecay should be α₀exp(-kt)")
+	assert.InDelta(t, 2.0/3, InverseTimeDecay(0.1).Rate(2, 20), 1e-12, "InverseTimeDecay should be α₀/(1+kt)")
+}
+
+type scheduledModel struct {
+	schedule LearningRateSchedule
+}
+
+func (s scheduledModel) LearningRateSchedule() LearningRateSchedule {
+	return s.schedule
+}
+
+func TestScheduledRateShouldPass1(t *testing.T) {
+	assert.Equal(t, 0.1, scheduledRate(struct{}{}, 0.1, 10), "Models without a schedule should use a constant learning rate")
+	assert.Equal(t, 0.1, scheduledRate(scheduledModel{}, 0.1, 10), "Models with a nil schedule should use a constant learning rate")
+	assert.InDelta(t, 0.05, scheduledRate(scheduledModel{InverseTimeDecay(0.1)}, 0.1, 10), 1e-12, "Models with a schedule should use it")
+}
@@ -0,0 +1,51 @@
+package base
+
+import (
+	"math/rand"
+)
+
+// DefaultShuffleSeed is the seed models use for the
+// source of randomness they shuffle their training set
+// with, so training with StochasticGradientAscent is
+// reproducible unless you give them another source.
+const DefaultShuffleSeed int64 = 42
+
+// Shuffled is implemented by models whose training
+// examples are visited in a random order each epoch.
+// StochasticGradientAscent shuffles the order of the
+// training set at the start of every iteration with
+// the model's source of randomness when the model
+// implements it. Visiting examples in a fixed order
+// can bias stochastic gradient ascent (ie. when the
+// training set is sorted by its results) and tends to
+// converge slower than a random order.
+type Shuffled interface {
+	// ShuffleSource returns the source of randomness
+	// used to shuffle the training set (nil to visit
+	// the examples in their fixed order)
+	ShuffleSource() *rand.Rand
+}
+
+// shuffleSource returns the source of randomness the
+// model d shuffles its training set with, or nil if
+// it isn't Shuffled
+func shuffleSource(d interface{}) *rand.Rand {
+	if s, ok := d.(Shuffled); ok {
+		return s.ShuffleSource()
+	}
+
+	return nil
+}
+
+// shuffleRand returns the model d's source of
+// randomness like shuffleSource, but falls back to a
+// new source seeded with DefaultShuffleSeed, for the
+// optimization methods that always shuffle the
+// training set. Either way training is reproducible.
+func shuffleRand(d interface{}) *rand.Rand {
+	if rng := shuffleSource(d); rng != nil {
+		return rng
+	}
+
+	return rand.New(rand.NewSource(DefaultShuffleSeed))
+}
@@ -0,0 +1,272 @@
+package base
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Scorer scores the predictions a model made for a
+// set of examples against their expected results,
+// where predicted[i] is the output of Predict for
+// the example with the expected result expected[i].
+// Higher scores are better, so CrossValidate and
+// GridSearch can pick the best model with any
+// Scorer.
+type Scorer func(predicted [][]float64, expected []float64) (float64, error)
+
+// AccuracyScore is a Scorer for classifiers which
+// returns the fraction of examples that were
+// predicted correctly (see ConfusionMatrix.) Models
+// predicting a single value (ie. the probability
+// from logistic regression) are taken to predict
+// that value rounded to the nearest class, while
+// models predicting a vector (ie. the probability
+// of each class from softmax regression) are taken
+// to predict the class with the largest value.
+func AccuracyScore(predicted [][]float64, expected []float64) (float64, error) {
+	if len(predicted) != len(expected) {
+		return 0, fmt.Errorf("ERROR: Predicted and expected results should be the same length!\n\tLength of predicted: %v\n\tLength of expected: %v\n", len(predicted), len(expected))
+	}
+
+	actual := make([]int, len(expected))
+	classes := make([]int, len(predicted))
+	max := 0
+	for i := range predicted {
+		if len(predicted[i]) == 0 {
+			return 0, fmt.Errorf("ERROR: Prediction %v is empty!\n", i)
+		}
+
+		if len(predicted[i]) == 1 {
+			classes[i] = int(math.Round(predicted[i][0]))
+		} else {
+			for j := range predicted[i] {
+				if predicted[i][j] > predicted[i][classes[i]] {
+					classes[i] = j
+				}
+			}
+		}
+		actual[i] = int(expected[i])
+
+		if classes[i] > max {
+			max = classes[i]
+		}
+		if actual[i] > max {
+			max = actual[i]
+		}
+	}
+
+	matrix, err := NewConfusionMatrix(actual, classes, max+1)
+	if err != nil {
+		return 0, err
+	}
+
+	return matrix.Accuracy(), nil
+}
+
+// NegativeMeanSquaredError is a Scorer for regression
+// which returns the negative of the mean squared error
+// between the (first value of each) prediction and
+// the expected results, so a better fit (with a
+// smaller error) gets a higher score
+func NegativeMeanSquaredError(predicted [][]float64, expected []float64) (float64, error) {
+	if len(predicted) != len(expected) {
+		return 0, fmt.Errorf("ERROR: Predicted and expected results should be the same length!\n\tLength of predicted: %v\n\tLength of expected: %v\n", len(predicted), len(expected))
+	}
+	if len(expected) == 0 {
+		return 0, fmt.Errorf("ERROR: Attempting to score no predictions!\n")
+	}
+
+	var sum float64
+	for i := range predicted {
+		if len(predicted[i]) == 0 {
+			return 0, fmt.Errorf("ERROR: Prediction %v is empty!\n", i)
+		}
+
+		sum += (predicted[i][0] - expected[i]) * (predicted[i][0] - expected[i])
+	}
+
+	return -sum / float64(len(expected)), nil
+}
+
+// CrossValidate estimates how well the models newModel
+// returns do on data they haven't seen with k-fold
+// cross validation. The examples are shuffled (with
+// DefaultShuffleSeed, so the folds are the same every
+// time) and split into the given number of folds, then
+// for each fold a fresh model from newModel is trained
+// on the other folds and scored on that one. The score
+// of each fold is returned.
+//
+// newModel is called once per fold so no fold's model
+// carries over anything learned from another fold.
+//
+// An error is returned if x and y aren't the same
+// length, if folds isn't between 2 and the number
+// of examples, or if any model fails to train or
+// predict.
+//
+// Example CrossValidate Usage:
+//
+//	scores, err := base.CrossValidate(func() base.SupervisedModel {
+//	    return linear.NewLogistic(base.BatchGA, 1e-4, 0, 800, nil, nil, len(x[0]))
+//	}, x, y, 5, base.AccuracyScore)
+func CrossValidate(newModel func() SupervisedModel, x [][]float64, y []float64, folds int, score Scorer) ([]float64, error) {
+	if len(x) != len(y) {
+		return nil, fmt.Errorf("ERROR: Length of training set (%v) doesn't match the number of results (%v)!\n", len(x), len(y))
+	}
+	if folds < 2 || folds > len(x) {
+		return nil, fmt.Errorf("ERROR: The number of folds (%v) should be between 2 and the number of examples (%v)!\n", folds, len(x))
+	}
+
+	order := rand.New(rand.NewSource(DefaultShuffleSeed)).Perm(len(x))
+
+	scores := make([]float64, folds)
+	for f := range scores {
+		start, end := f*len(x)/folds, (f+1)*len(x)/folds
+
+		trainX := make([][]float64, 0, len(x)-(end-start))
+		trainY := make([]float64, 0, len(x)-(end-start))
+		for j, i := range order {
+			if j >= start && j < end {
+				continue
+			}
+
+			trainX = append(trainX, x[i])
+			trainY = append(trainY, y[i])
+		}
+
+		model := newModel()
+		err := model.UpdateTrainingSet(trainX, trainY)
+		if err != nil {
+			return nil, err
+		}
+
+		err = model.Learn()
+		if err != nil {
+			return nil, err
+		}
+
+		predicted := make([][]float64, 0, end-start)
+		expected := make([]float64, 0, end-start)
+		for _, i := range order[start:end] {
+			guess, err := model.Predict(x[i])
+			if err != nil {
+				return nil, err
+			}
+
+			predicted = append(predicted, guess)
+			expected = append(expected, y[i])
+		}
+
+		scores[f], err = score(predicted, expected)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return scores, nil
+}
+
+// ParamGrid maps the name of each hyperparameter to
+// search over to the values to try for it. Integer
+// hyperparameters (ie. the maximum number of
+// iterations) are given as floats too, and converted
+// back by the function creating the models.
+type ParamGrid map[string][]float64
+
+// GridResult is the result of cross validating the
+// models created with one combination of hyperparameters
+type GridResult struct {
+	// Params holds the value of each hyperparameter
+	Params map[string]float64
+
+	// Scores holds the score of each fold, and
+	// Mean their average
+	Scores []float64
+	Mean   float64
+}
+
+// GridSearch tries every combination of the
+// hyperparameters in grid, cross validating the models
+// newModel creates for each (see CrossValidate,) and
+// returns the combination with the best mean score as
+// well as the results of every combination. Results
+// are in a fixed order: the hyperparameters are
+// iterated in order of their names, with the last
+// name changing fastest. Ties go to the combination
+// tried first.
+//
+// An error is returned if the grid is empty, any
+// hyperparameter has no values to try, or cross
+// validation fails for any combination.
+//
+// Example GridSearch Usage:
+//
+//	grid := base.ParamGrid{
+//	    "alpha":          {1e-5, 1e-4, 1e-3},
+//	    "regularization": {0, 1, 10},
+//	}
+//
+//	best, results, err := base.GridSearch(func(params map[string]float64) base.SupervisedModel {
+//	    return linear.NewLogistic(base.BatchGA, params["alpha"], params["regularization"], 800, nil, nil, len(x[0]))
+//	}, grid, x, y, 5, base.AccuracyScore)
+//
+//	fmt.Println(best.Params, best.Mean)
+func GridSearch(newModel func(params map[string]float64) SupervisedModel, grid ParamGrid, x [][]float64, y []float64, folds int, score Scorer) (GridResult, []GridResult, error) {
+	if len(grid) == 0 {
+		return GridResult{}, nil, fmt.Errorf("ERROR: Attempting to search an empty grid of hyperparameters!\n")
+	}
+
+	names := make([]string, 0, len(grid))
+	combinations := 1
+	for name, values := range grid {
+		if len(values) == 0 {
+			return GridResult{}, nil, fmt.Errorf("ERROR: Hyperparameter %q has no values to try!\n", name)
+		}
+
+		names = append(names, name)
+		combinations *= len(values)
+	}
+	sort.Strings(names)
+
+	results := make([]GridResult, combinations)
+	best := 0
+	for c := range results {
+		// decode the combination's index into an
+		// index for each hyperparameter, with the
+		// last name changing fastest
+		params := make(map[string]float64, len(names))
+		index := c
+		for n := len(names) - 1; n >= 0; n-- {
+			values := grid[names[n]]
+			params[names[n]] = values[index%len(values)]
+			index /= len(values)
+		}
+
+		scores, err := CrossValidate(func() SupervisedModel {
+			return newModel(params)
+		}, x, y, folds, score)
+		if err != nil {
+			return GridResult{}, nil, err
+		}
+
+		var sum float64
+		for _, s := range scores {
+			sum += s
+		}
+
+		results[c] = GridResult{
+			Params: params,
+			Scores: scores,
+			Mean:   sum / float64(len(scores)),
+		}
+
+		if results[c].Mean > results[best].Mean {
+			best = c
+		}
+	}
+
+	return results[best], results, nil
+}
@@ -0,0 +1,156 @@
+package base
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// meanModel is a toy SupervisedModel which predicts
+// the mean of its training results times scale, plus
+// offset, so tests can check CrossValidate and
+// GridSearch without the other packages
+type meanModel struct {
+	scale, offset float64
+
+	y    []float64
+	mean float64
+}
+
+func (m *meanModel) Learn() error {
+	if len(m.y) == 0 {
+		return fmt.Errorf("ERROR: no training data")
+	}
+
+	var sum float64
+	for i := range m.y {
+		sum += m.y[i]
+	}
+	m.mean = m.scale*sum/float64(len(m.y)) + m.offset
+
+	return nil
+}
+
+func (m *meanModel) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	return []float64{m.mean}, nil
+}
+
+func (m *meanModel) UpdateTrainingSet(x [][]float64, y []float64) error {
+	m.y = y
+	return nil
+}
+
+func (m *meanModel) PersistToFile(path string) error   { return nil }
+func (m *meanModel) RestoreFromFile(path string) error { return nil }
+
+func meanData() ([][]float64, []float64) {
+	x := make([][]float64, 20)
+	y := make([]float64, 20)
+	for i := range x {
+		x[i] = []float64{float64(i)}
+		y[i] = 10 + float64(i%2)
+	}
+
+	return x, y
+}
+
+func TestAccuracyScoreShouldPass1(t *testing.T) {
+	// a single value is rounded to the nearest
+	// class, and a vector is its largest class
+	predicted := [][]float64{{0.9}, {0.2}, {0.6}, {0.1, 0.3, 0.6}}
+	expected := []float64{1, 0, 0, 2}
+
+	score, err := AccuracyScore(predicted, expected)
+	assert.Nil(t, err, "Scoring error should be nil")
+	assert.InDelta(t, 0.75, score, 1e-9, "Accuracy should match")
+
+	_, err = AccuracyScore(predicted, expected[1:])
+	assert.NotNil(t, err, "Scoring mismatched lengths should return an error")
+}
+
+func TestNegativeMeanSquaredErrorShouldPass1(t *testing.T) {
+	score, err := NegativeMeanSquaredError([][]float64{{1}, {3}}, []float64{2, 2})
+	assert.Nil(t, err, "Scoring error should be nil")
+	assert.InDelta(t, -1.0, score, 1e-9, "Score should be the negative mean squared error")
+
+	_, err = NegativeMeanSquaredError(nil, nil)
+	assert.NotNil(t, err, "Scoring no predictions should return an error")
+}
+
+func TestCrossValidateShouldPass1(t *testing.T) {
+	x, y := meanData()
+
+	var created int
+	scores, err := CrossValidate(func() SupervisedModel {
+		created++
+		return &meanModel{scale: 1}
+	}, x, y, 4, NegativeMeanSquaredError)
+	assert.Nil(t, err, "Cross validation error should be nil")
+	assert.Len(t, scores, 4, "There should be a score for each fold")
+	assert.Equal(t, 4, created, "A fresh model should be created for each fold")
+
+	for _, score := range scores {
+		assert.True(t, score <= 0 && score > -1, "Score (%v) should be close to 0 when predicting the mean", score)
+	}
+}
+
+func TestCrossValidateShouldFail1(t *testing.T) {
+	x, y := meanData()
+	newModel := func() SupervisedModel {
+		return &meanModel{scale: 1}
+	}
+
+	_, err := CrossValidate(newModel, x, y[1:], 4, NegativeMeanSquaredError)
+	assert.NotNil(t, err, "Cross validating mismatched data should return an error")
+
+	_, err = CrossValidate(newModel, x, y, 1, NegativeMeanSquaredError)
+	assert.NotNil(t, err, "Cross validating with 1 fold should return an error")
+
+	_, err = CrossValidate(newModel, x, y, 21, NegativeMeanSquaredError)
+	assert.NotNil(t, err, "Cross validating with more folds than examples should return an error")
+}
+
+func TestGridSearchShouldPass1(t *testing.T) {
+	x, y := meanData()
+
+	grid := ParamGrid{
+		"scale":  {0, 0.5, 1, 2},
+		"offset": {1, 0},
+	}
+
+	best, results, err := GridSearch(func(params map[string]float64) SupervisedModel {
+		return &meanModel{scale: params["scale"], offset: params["offset"]}
+	}, grid, x, y, 5, NegativeMeanSquaredError)
+	assert.Nil(t, err, "Grid search error should be nil")
+
+	assert.Equal(t, map[string]float64{"scale": 1, "offset": 0}, best.Params, "The best parameters should predict the mean")
+	assert.Len(t, best.Scores, 5, "There should be a score for each fold")
+
+	// names are iterated in order, with the
+	// last one changing fastest
+	assert.Len(t, results, 8, "There should be a result for each combination")
+	assert.Equal(t, map[string]float64{"offset": 1, "scale": 0}, results[0].Params, "Results should be in order")
+	assert.Equal(t, map[string]float64{"offset": 1, "scale": 0.5}, results[1].Params, "Results should be in order")
+	assert.Equal(t, map[string]float64{"offset": 0, "scale": 0}, results[4].Params, "Results should be in order")
+
+	for _, result := range results {
+		assert.True(t, result.Mean <= best.Mean, "No result should beat the best")
+	}
+}
+
+func TestGridSearchShouldFail1(t *testing.T) {
+	x, y := meanData()
+	newModel := func(params map[string]float64) SupervisedModel {
+		return &meanModel{scale: params["scale"]}
+	}
+
+	_, _, err := GridSearch(newModel, ParamGrid{}, x, y, 5, NegativeMeanSquaredError)
+	assert.NotNil(t, err, "Searching an empty grid should return an error")
+
+	_, _, err = GridSearch(newModel, ParamGrid{"scale": {}}, x, y, 5, NegativeMeanSquaredError)
+	assert.NotNil(t, err, "Searching a hyperparameter with no values should return an error")
+
+	_, _, err = GridSearch(newModel, ParamGrid{"scale": {1}}, x, y, 1, NegativeMeanSquaredError)
+	assert.NotNil(t, err, "Searching with 1 fold should return an error")
+}
@@ -0,0 +1,368 @@
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+// Noise is the class DBSCAN assigns to points
+// which don't belong to any cluster
+const Noise = -1
+
+/*
+DBSCAN implements the DBSCAN (Density-Based
+Spatial Clustering of Applications with Noise)
+unsupervised clustering algorithm. Unlike
+k-means you don't choose the number of clusters
+up front. Instead clusters are grown out of
+dense regions of the data, so they can take
+any shape (like two concentric rings, which
+k-means can't separate,) and points in sparse
+regions are marked as noise.
+
+A point is a core point if at least minPoints
+points (including itself) are within epsilon
+of it. Clusters are made of core points within
+epsilon of each other, along with the border
+points within epsilon of those core points.
+Every other point is noise, and is given the
+class -1 (Noise.)
+
+https://en.wikipedia.org/wiki/DBSCAN
+
+Example DBSCAN Model Usage:
+
+	// initialize data with 2 concentric rings
+	rings := [][]float64{}
+	for theta := 0.0; theta < 2*math.Pi; theta += 0.05 {
+		rings = append(rings, []float64{2 * math.Cos(theta), 2 * math.Sin(theta)})
+		rings = append(rings, []float64{10 * math.Cos(theta), 10 * math.Sin(theta)})
+	}
+
+	// points within 1 of each other are
+	// neighbors, and a point needs 3
+	// neighbors to be a core point
+	model := NewDBSCAN(1, 3, rings)
+
+	if model.Learn() != nil {
+		panic("Oh NO!!! There was an error learning!!")
+	}
+
+	// the cluster of each point in the
+	// training set, with -1 for noise
+	results := model.Guesses()
+
+	// new points are given the cluster of
+	// the nearest core point within epsilon
+	// (or -1 if there isn't one)
+	guess, err := model.Predict([]float64{0, 2.1})
+	if err != nil {
+		panic("prediction error")
+	}
+*/
+type DBSCAN struct {
+	// Epsilon is the radius (using the
+	// Euclidean distance) of the neighborhood
+	// around each point. MinPoints is the number
+	// of points (including the point itself)
+	// needed within that neighborhood for the
+	// point to be a core point.
+	Epsilon   float64
+	MinPoints int
+
+	// trainingSet and guesses are the
+	// 'x', and 'y' of the data, expressed as
+	// vectors. guesses is set while learning,
+	// with -1 for noise, and core keeps track
+	// of which points are core points.
+	//
+	// [][]float64{guesses[i]} == Predict(trainingSet[i])
+	// for core points
+	trainingSet [][]float64
+	guesses     []int
+	core        []bool
+
+	// clusters is the number of clusters
+	// found while learning
+	clusters int
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
+	// Output is the io.Writer to write logs
+	// and output from training to
+	Output io.Writer
+}
+
+// NewDBSCAN returns a pointer to a DBSCAN model,
+// which clusters given inputs in an unsupervised
+// manner based on the density of the data.
+//
+// epsilon is the radius of the neighborhood
+// around each point, and minPoints is the number
+// of points that need to be within that radius
+// for a point to be a core point.
+func NewDBSCAN(epsilon float64, minPoints int, trainingSet [][]float64) *DBSCAN {
+	// start all guesses as noise.
+	// they will be changed during learning
+	guesses := make([]int, len(trainingSet))
+	for i := range guesses {
+		guesses[i] = Noise
+	}
+
+	return &DBSCAN{
+		Epsilon:   epsilon,
+		MinPoints: minPoints,
+
+		trainingSet: trainingSet,
+		guesses:     guesses,
+		core:        make([]bool, len(trainingSet)),
+
+		Output: os.Stdout,
+	}
+}
+
+// UpdateTrainingSet takes in a new training set (variable x.)
+//
+// Will reset the hidden 'guesses' param of the DBSCAN model.
+func (d *DBSCAN) UpdateTrainingSet(trainingSet [][]float64) error {
+	if len(trainingSet) == 0 {
+		return fmt.Errorf("Error: length of given training set is 0! Need data!")
+	}
+
+	d.trainingSet = trainingSet
+	d.guesses = make([]int, len(trainingSet))
+	for i := range d.guesses {
+		d.guesses[i] = Noise
+	}
+	d.core = make([]bool, len(trainingSet))
+	d.clusters = 0
+
+	return nil
+}
+
+// Examples returns the number of training examples (m)
+// that the model currently is training from.
+func (d *DBSCAN) Examples() int {
+	return len(d.trainingSet)
+}
+
+// Clusters returns the number of clusters found
+// while learning (not counting noise.)
+func (d *DBSCAN) Clusters() int {
+	return d.clusters
+}
+
+// neighbors returns the indices of every point
+// in the training set within epsilon of x
+// (including x itself if it's in the training
+// set.) diff is squared so it's compared to
+// the square of epsilon.
+func (d *DBSCAN) neighbors(x []float64) []int {
+	radius := d.Epsilon * d.Epsilon
+
+	n := []int{}
+	for i := range d.trainingSet {
+		if diff(x, d.trainingSet[i]) <= radius {
+			n = append(n, i)
+		}
+	}
+
+	return n
+}
+
+// Predict takes in a variable x (an array of floats,) and
+// finds the cluster of the nearest core point within
+// epsilon of x. If there isn't one x is noise and the
+// prediction is -1.
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (d *DBSCAN) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	if len(d.trainingSet) == 0 {
+		return nil, fmt.Errorf("ERROR: Attempting to predict with no training examples!\n")
+	}
+	if len(x) != len(d.trainingSet[0]) {
+		return nil, fmt.Errorf("Error: %w: Training examples should be the same length as input vector!\n\tLength of x given: %v\n\tLength of training examples: %v\n", base.ErrDimensionMismatch, len(x), len(d.trainingSet[0]))
+	}
+
+	if len(normalize) != 0 && normalize[0] {
+		x = base.NormalizedPoint(x)
+	}
+
+	guess := Noise
+	minDiff := d.Epsilon * d.Epsilon
+	for i := range d.trainingSet {
+		if !d.core[i] {
+			continue
+		}
+
+		difference := diff(x, d.trainingSet[i])
+		if difference <= minDiff {
+			minDiff = difference
+			guess = d.guesses[i]
+		}
+	}
+
+	return []float64{float64(guess)}, nil
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (d *DBSCAN) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	if len(d.trainingSet) == 0 {
+		return nil, fmt.Errorf("ERROR: Attempting to predict with no training examples!\n")
+	}
+
+	return base.PredictBatch(x, len(d.trainingSet[0]), func(row []float64) ([]float64, error) {
+		return d.Predict(row, normalize...)
+	})
+}
+
+// Learn clusters the training set, labeling each
+// point with the cluster it belongs to (or -1 if
+// it's noise.) Clusters are numbered from 0 in the
+// order they're found.
+func (d *DBSCAN) Learn() error {
+	if len(d.trainingSet) == 0 || len(d.trainingSet[0]) == 0 {
+		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
+		d.logf(err.Error())
+		return err
+	}
+
+	if d.Epsilon <= 0 || d.MinPoints < 1 {
+		err := fmt.Errorf("ERROR: Epsilon should be positive and MinPoints should be at least 1!\n\tEpsilon: %v\n\tMinPoints: %v\n", d.Epsilon, d.MinPoints)
+		d.logf(err.Error())
+		return err
+	}
+
+	examples := len(d.trainingSet)
+	features := len(d.trainingSet[0])
+
+	d.logf("Training:\n\tModel: DBSCAN Classification\n\tTraining Examples: %v\n\tFeatures: %v\n\tEpsilon: %v\n\tMinimum Points: %v\n...\n\n", examples, features, d.Epsilon, d.MinPoints)
+
+	visited := make([]bool, examples)
+	d.guesses = make([]int, examples)
+	d.core = make([]bool, examples)
+	for i := range d.guesses {
+		d.guesses[i] = Noise
+	}
+
+	cluster := 0
+	for i := range d.trainingSet {
+		if visited[i] {
+			continue
+		}
+		visited[i] = true
+
+		neighbors := d.neighbors(d.trainingSet[i])
+		if len(neighbors) < d.MinPoints {
+			// noise for now, though it might be
+			// claimed as a border point later
+			continue
+		}
+
+		// i is a core point, so grow a new
+		// cluster out from it
+		d.core[i] = true
+		d.guesses[i] = cluster
+
+		queue := neighbors
+		for len(queue) != 0 {
+			j := queue[0]
+			queue = queue[1:]
+
+			if d.guesses[j] == Noise {
+				d.guesses[j] = cluster
+			}
+
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+
+			n := d.neighbors(d.trainingSet[j])
+			if len(n) >= d.MinPoints {
+				d.core[j] = true
+				queue = append(queue, n...)
+			}
+		}
+
+		cluster++
+	}
+
+	d.clusters = cluster
+
+	d.logf("Training Completed.\n%v\n", d)
+
+	return nil
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (d *DBSCAN) logf(format string, a ...interface{}) {
+	if d.Verbose {
+		fmt.Fprintf(d.Output, format, a...)
+	}
+}
+
+// String implements the fmt interface for clean printing. Here
+// we're using it to print the model's parameters and the
+// number of clusters it found
+func (d *DBSCAN) String() string {
+	return fmt.Sprintf("DBSCAN:\n\tε = %v\n\tMinimum Points = %v\n\tClusters Found = %v", d.Epsilon, d.MinPoints, d.clusters)
+}
+
+// Guesses returns the hidden parameter for the
+// unsupervised classification assigned during
+// learning, with -1 for noise.
+//
+//    model.Guesses[i] = E[d.trainingSet[i]]
+func (d *DBSCAN) Guesses() []int {
+	return d.guesses
+}
+
+// SaveClusteredData takes in an absolute filepath, and
+// saves the currently clustered data to that path. Noise
+// is saved with the class -1.
+//
+// Basically just a wrapper for the base.SaveDataToCSV
+// with the DBSCAN data.
+func (d *DBSCAN) SaveClusteredData(filepath string) error {
+	return base.SaveDataToCSV(filepath, d.trainingSet, d.floatGuesses(), true)
+}
+
+// SaveClusteredDataTo is the same as SaveClusteredData
+// but writes the clustered dataset to w instead of a
+// file, so you can send it anywhere (an HTTP response,
+// an in-memory buffer, etc.)
+func (d *DBSCAN) SaveClusteredDataTo(w io.Writer) error {
+	return base.WriteDataToCSV(w, d.trainingSet, d.floatGuesses(), true)
+}
+
+// floatGuesses returns the cluster assignments of
+// the training set as floats so they can be saved
+// along with it
+func (d *DBSCAN) floatGuesses() []float64 {
+	floatGuesses := []float64{}
+	for _, val := range d.guesses {
+		floatGuesses = append(floatGuesses, float64(val))
+	}
+
+	return floatGuesses
+}
@@ -0,0 +1,157 @@
+package cluster
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var rings [][]float64
+
+func init() {
+	rings = [][]float64{}
+	for theta := 0.0; theta < 2*math.Pi; theta += 0.05 {
+		rings = append(rings, []float64{2 * math.Cos(theta), 2 * math.Sin(theta)})
+		rings = append(rings, []float64{10 * math.Cos(theta), 10 * math.Sin(theta)})
+	}
+}
+
+func TestDBSCANShouldPass1(t *testing.T) {
+	model := NewDBSCAN(0.5, 4, circles)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+	assert.Equal(t, 4, model.Clusters(), "There should be one cluster for each block")
+
+	c1, err := model.Predict([]float64{-10, -10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c2, err := model.Predict([]float64{-10, 10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c3, err := model.Predict([]float64{10, -10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c4, err := model.Predict([]float64{10, 10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	classes := map[float64]bool{c1[0]: true, c2[0]: true, c3[0]: true, c4[0]: true}
+	assert.Len(t, classes, 4, "Each block should have its own cluster")
+	assert.False(t, classes[Noise], "No block should be noise")
+
+	for i, guess := range model.Guesses() {
+		assert.NotEqual(t, Noise, guess, "Point %v in a dense block should not be noise", i)
+	}
+
+	// far from any point
+	guess, err := model.Predict([]float64{0, 0})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, float64(Noise), guess[0], "Points far from every cluster should be noise")
+}
+
+// concentric rings can't be separated by
+// k-means, but should be by DBSCAN, and
+// outliers should be marked as noise
+func TestDBSCANShouldPass2(t *testing.T) {
+	x := append([][]float64{[]float64{6, 0}, []float64{0, -6}}, rings...)
+
+	model := NewDBSCAN(1, 3, x)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+	assert.Equal(t, 2, model.Clusters(), "There should be one cluster for each ring")
+
+	guesses := model.Guesses()
+	assert.Equal(t, Noise, guesses[0], "Outlier should be noise")
+	assert.Equal(t, Noise, guesses[1], "Outlier should be noise")
+
+	// every point on the same ring should share
+	// a cluster, which differs between rings
+	inner, outer := guesses[2], guesses[3]
+	assert.NotEqual(t, inner, outer, "Rings should be in different clusters")
+	for i := 2; i < len(x); i += 2 {
+		assert.Equal(t, inner, guesses[i], "Point %v should be in the inner ring's cluster", i)
+		assert.Equal(t, outer, guesses[i+1], "Point %v should be in the outer ring's cluster", i+1)
+	}
+}
+
+// border points should join a cluster even
+// though they aren't core points
+func TestDBSCANShouldPass3(t *testing.T) {
+	x := [][]float64{[]float64{0}, []float64{0.5}, []float64{1}, []float64{1.9}, []float64{5}}
+
+	model := NewDBSCAN(1, 3, x)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	assert.Equal(t, []int{0, 0, 0, 0, Noise}, model.Guesses(), "Border point should join the cluster and the far point should be noise")
+
+	// only the core point at 1 is within
+	// epsilon of 1.9 so it should be clustered,
+	// but nothing is within epsilon of 4
+	guess, err := model.Predict([]float64{1.9})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, 0.0, guess[0], "Prediction near a core point should match its cluster")
+
+	guess, err = model.Predict([]float64{4})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, float64(Noise), guess[0], "Prediction far from core points should be noise")
+}
+
+// batch predictions should match predicting
+// each point on its own
+func TestDBSCANPredictBatchShouldPass1(t *testing.T) {
+	model := NewDBSCAN(0.5, 4, circles)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	x := [][]float64{[]float64{-10, -10}, []float64{-10, 10}, []float64{10, -10}, []float64{10, 10}, []float64{0, 0}}
+	guesses, err := model.PredictBatch(x)
+	assert.Nil(t, err, "Batch prediction error should be nil")
+
+	for i := range x {
+		guess, err := model.Predict(x[i])
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, guess, guesses[i], "Batch predictions should match Predict")
+	}
+
+	_, err = model.PredictBatch([][]float64{[]float64{0, 0}, []float64{1}})
+	assert.NotNil(t, err, "Batch prediction error should not be nil")
+}
+
+func TestDBSCANSaveClusteredDataShouldPass1(t *testing.T) {
+	model := NewDBSCAN(0.5, 4, circles)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	// save results to disk
+	assert.Nil(t, model.SaveClusteredData("/tmp/.goml/DBSCANResults.csv"), "Save results error should be nil")
+
+	// and write them to a buffer, which should
+	// match the file
+	buf := &bytes.Buffer{}
+	assert.Nil(t, model.SaveClusteredDataTo(buf), "Write results error should be nil")
+
+	saved, err := ioutil.ReadFile("/tmp/.goml/DBSCANResults.csv")
+	assert.Nil(t, err, "Read results error should be nil")
+	assert.Equal(t, string(saved), buf.String(), "Written results should match the saved file")
+}
+
+func TestDBSCANShouldFail1(t *testing.T) {
+	// no data
+	model := NewDBSCAN(1, 3, nil)
+	assert.NotNil(t, model.Learn(), "Learning error should not be nil")
+
+	_, err := model.Predict([]float64{1})
+	assert.NotNil(t, err, "Prediction error should not be nil")
+
+	// invalid parameters
+	model = NewDBSCAN(0, 3, circles)
+	assert.NotNil(t, model.Learn(), "Learning error should not be nil")
+
+	model = NewDBSCAN(1, 0, circles)
+	assert.NotNil(t, model.Learn(), "Learning error should not be nil")
+
+	// wrong dimension
+	model = NewDBSCAN(1, 3, circles)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	_, err = model.Predict([]float64{1, 2, 3})
+	assert.NotNil(t, err, "Prediction error should not be nil")
+}
@@ -0,0 +1,577 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+// minVariance is the smallest variance a GMM
+// component is allowed along any feature, which
+// keeps components from collapsing onto a single
+// point (and the likelihood blowing up.)
+const minVariance = 1e-6
+
+/*
+GMM implements a Gaussian Mixture Model, which
+clusters data by modeling it as being drawn from
+k Gaussian distributions (components.) Unlike
+k-means, which assumes every cluster is a sphere
+of the same size, each component has its own
+variance along each feature (a diagonal
+covariance matrix) and its own mixing weight,
+which is the probability a point comes from it.
+
+The model is fit with the Expectation-Maximization
+algorithm, which alternates between
+
+	E: finding the responsibility of each component
+	   for each point, which is the probability the
+	   point came from the component
+	       r[i][j] = w[j]N(x[i]|μ[j],σ[j]) / Σ_l w[l]N(x[i]|μ[l],σ[l])
+
+	M: updating the mixing weight, mean, and variance
+	   of each component to those of the points it's
+	   responsible for, weighted by responsibility
+
+until the log-likelihood of the training set
+improves by less than Tolerance, or maxIterations
+is reached. The means are instantiated with
+k-means++, just like KMeans.
+
+https://en.wikipedia.org/wiki/Mixture_model#Gaussian_mixture_model
+
+Example GMM Model Usage:
+
+	// initialize data with 2 clusters of
+	// different shapes
+	gaussian := [][]float64{}
+	for i := 0; i < 200; i++ {
+		gaussian = append(gaussian, []float64{rand.NormFloat64()*5 - 10, rand.NormFloat64() * 0.5})
+		gaussian = append(gaussian, []float64{rand.NormFloat64()*0.5 + 10, rand.NormFloat64() * 5})
+	}
+
+	model := NewGMM(2, 100, gaussian)
+
+	if model.Learn() != nil {
+		panic("Oh NO!!! There was an error learning!!")
+	}
+
+	// predict the most likely component
+	guess, err := model.Predict([]float64{-3, 0})
+	if err != nil {
+		panic("prediction error")
+	}
+
+	// or the probability of each component
+	probabilities, err := model.PredictSoft([]float64{-3, 0})
+	if err != nil {
+		panic("prediction error")
+	}
+
+	// or if you just want to get the clustering
+	// results from the data
+	results := model.Guesses()
+*/
+type GMM struct {
+	// maxIterations is the number of iterations
+	// the learning will be cut off at if the
+	// log-likelihood hasn't converged.
+	maxIterations int
+
+	// Tolerance is the change in log-likelihood
+	// between iterations under which learning
+	// is considered converged and stops
+	Tolerance float64 `json:"tolerance"`
+
+	// trainingSet and guesses are the
+	// 'x', and 'y' of the data, expressed as
+	// vectors. guesses is set while learning
+	// to the most likely component of each
+	// point.
+	//
+	// [][]float64{guesses[i]} == Predict(trainingSet[i])
+	trainingSet [][]float64
+	guesses     []int
+
+	// Means and Variances hold the mean and the
+	// variance along each feature of each
+	// component, and Weights holds the mixing
+	// weight of each component (which sum to 1.)
+	Means     [][]float64 `json:"means"`
+	Variances [][]float64 `json:"variances"`
+	Weights   []float64   `json:"weights"`
+
+	// logLikelihood is the log-likelihood of
+	// the training set found in the last
+	// iteration of learning
+	logLikelihood float64
+
+	// rng is the model's own source of
+	// randomness used to instantiate the
+	// means, so clustering can be reproduced
+	// with a fixed seed
+	rng *rand.Rand
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool `json:"-"`
+
+	// Output is the io.Writer to write logs
+	// and output from training to. It isn't
+	// persisted with the model.
+	Output io.Writer `json:"-"`
+}
+
+// NewGMM returns a pointer to a Gaussian Mixture
+// Model with k components, which clusters given
+// inputs in an unsupervised manner.
+//
+// seed is an optional parameter which (if given and
+// not 0) seeds the model's randomness so clustering
+// is reproducible. Otherwise the model is seeded from
+// the current time.
+func NewGMM(k, maxIterations int, trainingSet [][]float64, seed ...int64) *GMM {
+	var features int
+	if len(trainingSet) != 0 {
+		features = len(trainingSet[0])
+	}
+
+	source := time.Now().UTC().UnixNano()
+	if len(seed) != 0 && seed[0] != 0 {
+		source = seed[0]
+	}
+
+	means := make([][]float64, k)
+	variances := make([][]float64, k)
+	weights := make([]float64, k)
+	for i := range means {
+		means[i] = make([]float64, features)
+		variances[i] = make([]float64, features)
+		for j := range variances[i] {
+			variances[i][j] = 1
+		}
+		weights[i] = 1 / float64(k)
+	}
+
+	return &GMM{
+		maxIterations: maxIterations,
+		Tolerance:     1e-6,
+
+		trainingSet: trainingSet,
+		guesses:     make([]int, len(trainingSet)),
+
+		Means:     means,
+		Variances: variances,
+		Weights:   weights,
+
+		rng: rand.New(rand.NewSource(source)),
+
+		Output: os.Stdout,
+	}
+}
+
+// UpdateTrainingSet takes in a new training set (variable x.)
+//
+// Will reset the hidden 'guesses' param of the GMM model.
+func (g *GMM) UpdateTrainingSet(trainingSet [][]float64) error {
+	if len(trainingSet) == 0 {
+		return fmt.Errorf("Error: length of given training set is 0! Need data!")
+	}
+
+	g.trainingSet = trainingSet
+	g.guesses = make([]int, len(trainingSet))
+
+	return nil
+}
+
+// Examples returns the number of training examples (m)
+// that the model currently is training from.
+func (g *GMM) Examples() int {
+	return len(g.trainingSet)
+}
+
+// MaxIterations returns the number of maximum iterations
+// the model will go through in EM
+func (g *GMM) MaxIterations() int {
+	return g.maxIterations
+}
+
+// LogLikelihood returns the log-likelihood of the
+// training set under the model, as of the last
+// iteration of learning
+func (g *GMM) LogLikelihood() float64 {
+	return g.logLikelihood
+}
+
+// logProbabilities returns log(w[j]N(x|μ[j],σ[j]))
+// for each component j, as well as the log of their
+// sum (the log-likelihood of x.) Working in logs
+// keeps far away points from underflowing to 0.
+func (g *GMM) logProbabilities(x []float64) ([]float64, float64) {
+	logP := make([]float64, len(g.Means))
+	max := math.Inf(-1)
+	for j := range g.Means {
+		sum := math.Log(g.Weights[j])
+		for l := range x {
+			d := x[l] - g.Means[j][l]
+			sum -= 0.5 * (math.Log(2*math.Pi*g.Variances[j][l]) + d*d/g.Variances[j][l])
+		}
+
+		logP[j] = sum
+		if sum > max {
+			max = sum
+		}
+	}
+
+	// log-sum-exp
+	var total float64
+	for j := range logP {
+		total += math.Exp(logP[j] - max)
+	}
+
+	return logP, max + math.Log(total)
+}
+
+// Predict takes in a variable x (an array of floats,) and
+// finds the most likely component for x to have come from
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (g *GMM) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	r, err := g.PredictSoft(x, normalize...)
+	if err != nil {
+		return nil, err
+	}
+
+	var guess int
+	for j := 1; j < len(r); j++ {
+		if r[j] > r[guess] {
+			guess = j
+		}
+	}
+
+	return []float64{float64(guess)}, nil
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (g *GMM) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	if len(g.Means) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: Attempting to predict with no components!\n", base.ErrNotTrained)
+	}
+
+	return base.PredictBatch(x, len(g.Means[0]), func(row []float64) ([]float64, error) {
+		return g.Predict(row, normalize...)
+	})
+}
+
+// PredictSoft takes in a variable x (an array of floats,) and
+// returns the responsibility of each component for x, which
+// is the probability that x came from that component. The
+// result has length k and sums to 1.
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (g *GMM) PredictSoft(x []float64, normalize ...bool) ([]float64, error) {
+	if len(g.Means) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: Attempting to predict with no components!\n", base.ErrNotTrained)
+	}
+	if len(x) != len(g.Means[0]) {
+		return nil, fmt.Errorf("Error: %w: Mean vector should be the same length as input vector!\n\tLength of x given: %v\n\tLength of mean: %v\n", base.ErrDimensionMismatch, len(x), len(g.Means[0]))
+	}
+
+	if len(normalize) != 0 && normalize[0] {
+		x = base.NormalizedPoint(x)
+	}
+
+	logP, logLikelihood := g.logProbabilities(x)
+	for j := range logP {
+		logP[j] = math.Exp(logP[j] - logLikelihood)
+	}
+
+	return logP, nil
+}
+
+// Learn fits the model to the struct's dataset using
+// Expectation-Maximization, stopping when the change in
+// log-likelihood falls under Tolerance or after
+// maxIterations iterations (100 if the model was given 0.)
+func (g *GMM) Learn() error {
+	if len(g.trainingSet) == 0 || len(g.trainingSet[0]) == 0 {
+		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
+		g.logf(err.Error())
+		return err
+	}
+
+	components := len(g.Means)
+	if components == 0 || components > len(g.trainingSet) {
+		err := fmt.Errorf("ERROR: Need between 1 and %v components to learn! Given %v\n", len(g.trainingSet), components)
+		g.logf(err.Error())
+		return err
+	}
+
+	// if the iterations given is 0, set it to be
+	// 100 (seems reasonable base value)
+	if g.maxIterations == 0 {
+		g.maxIterations = 100
+	}
+
+	examples := len(g.trainingSet)
+	features := len(g.trainingSet[0])
+
+	g.logf("Training:\n\tModel: Gaussian Mixture Model\n\tTraining Examples: %v\n\tFeatures: %v\n\tComponents: %v\n...\n\n", examples, features, components)
+
+	// start every component with the variance
+	// of the whole dataset and equal weights,
+	// with means instantiated by k-means++
+	mean := make([]float64, features)
+	variance := make([]float64, features)
+	for _, x := range g.trainingSet {
+		for l := range x {
+			mean[l] += x[l] / float64(examples)
+		}
+	}
+	for _, x := range g.trainingSet {
+		for l := range x {
+			d := x[l] - mean[l]
+			variance[l] += d * d / float64(examples)
+		}
+	}
+	for l := range variance {
+		variance[l] = math.Max(variance[l], minVariance)
+	}
+
+	g.Means = kMeansPlusPlus(g.trainingSet, components, g.rng, diff)
+	for j := range g.Means {
+		g.Variances[j] = append([]float64{}, variance...)
+		g.Weights[j] = 1 / float64(components)
+	}
+
+	r := make([][]float64, examples)
+	for i := range r {
+		r[i] = make([]float64, components)
+	}
+
+	g.logLikelihood = math.Inf(-1)
+
+	iter := 0
+	for ; iter < g.maxIterations; iter++ {
+
+		// E step: find the responsibilities
+		var logLikelihood float64
+		for i, x := range g.trainingSet {
+			logP, total := g.logProbabilities(x)
+			logLikelihood += total
+
+			g.guesses[i] = 0
+			for j := range logP {
+				r[i][j] = math.Exp(logP[j] - total)
+				if r[i][j] > r[i][g.guesses[i]] {
+					g.guesses[i] = j
+				}
+			}
+		}
+
+		converged := math.Abs(logLikelihood-g.logLikelihood) < g.Tolerance
+		g.logLikelihood = logLikelihood
+		if converged {
+			break
+		}
+
+		// M step: update the components
+		reinitialized := false
+		for j := range g.Means {
+			var n float64
+			newMean := make([]float64, features)
+			for i, x := range g.trainingSet {
+				n += r[i][j]
+				for l := range x {
+					newMean[l] += r[i][j] * x[l]
+				}
+			}
+
+			// if no points are the component's
+			// responsibility, reinitialize it to
+			// a random point with the weight it
+			// started with
+			if n == 0 {
+				g.Means[j] = append([]float64{}, g.trainingSet[g.rng.Intn(examples)]...)
+				g.Variances[j] = append([]float64{}, variance...)
+				g.Weights[j] = 1 / float64(components)
+				reinitialized = true
+				continue
+			}
+
+			for l := range newMean {
+				newMean[l] /= n
+			}
+
+			newVariance := make([]float64, features)
+			for i, x := range g.trainingSet {
+				for l := range x {
+					d := x[l] - newMean[l]
+					newVariance[l] += r[i][j] * d * d
+				}
+			}
+			for l := range newVariance {
+				newVariance[l] = math.Max(newVariance[l]/n, minVariance)
+			}
+
+			g.Means[j] = newMean
+			g.Variances[j] = newVariance
+			g.Weights[j] = n / float64(examples)
+		}
+
+		// the other components' weights already
+		// sum to 1, so make room for the ones
+		// that were reinitialized
+		if reinitialized {
+			var sum float64
+			for j := range g.Weights {
+				sum += g.Weights[j]
+			}
+			for j := range g.Weights {
+				g.Weights[j] /= sum
+			}
+		}
+	}
+
+	g.logf("Training Completed in %v iterations.\n%v\n", iter, g)
+
+	return nil
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (g *GMM) logf(format string, a ...interface{}) {
+	if g.Verbose {
+		fmt.Fprintf(g.Output, format, a...)
+	}
+}
+
+// String implements the fmt interface for clean printing. Here
+// we're using it to print the model's components
+func (g *GMM) String() string {
+	return fmt.Sprintf("h(θ,x) = argmax_j w[j]N(x|μ[j],σ[j])\n\tμ = %v\n\tσ = %v\n\tw = %v", g.Means, g.Variances, g.Weights)
+}
+
+// Guesses returns the hidden parameter for the
+// unsupervised classification assigned during
+// learning.
+//
+//    model.Guesses[i] = E[g.trainingSet[i]]
+func (g *GMM) Guesses() []int {
+	return g.guesses
+}
+
+// PersistToFile takes in an absolute filepath and saves the
+// means, variances, and weights of the model to the file,
+// which can be restored later. The function will take paths
+// from the current directory, but functions
+//
+// The data is stored as JSON because it's one of the most
+// efficient storage method (you only need one comma extra
+// per feature + two brackets, total!) And it's extendable.
+func (g *GMM) PersistToFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, bytes, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreFromFile takes in a path to a persisted model
+// and assigns the model it's operating on's means,
+// variances, and weights to those.
+//
+// The path must ba an absolute path or a path from the current
+// directory
+func (g *GMM) RestoreFromFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to restore your model from a file with no path! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(bytes, g)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// persistedGMM holds the fields of a GMM that are
+// persisted. The model can't be gob encoded itself
+// because of its Output writer.
+type persistedGMM struct {
+	Tolerance float64
+	Means     [][]float64
+	Variances [][]float64
+	Weights   []float64
+}
+
+// PersistToGob saves the means, variances, and weights
+// of the model (along with its Tolerance) to the given
+// file like PersistToFile, but encoded with encoding/gob
+// (see base.PersistToGob.)
+func (g *GMM) PersistToGob(path string) error {
+	return base.PersistToGob(path, persistedGMM{
+		Tolerance: g.Tolerance,
+		Means:     g.Means,
+		Variances: g.Variances,
+		Weights:   g.Weights,
+	})
+}
+
+// RestoreFromGob takes in a path to a model saved with
+// PersistToGob and assigns the model it's operating on's
+// means, variances, and weights to those.
+func (g *GMM) RestoreFromGob(path string) error {
+	var model persistedGMM
+	err := base.RestoreFromGob(path, &model)
+	if err != nil {
+		return err
+	}
+
+	g.Tolerance = model.Tolerance
+	g.Means = model.Means
+	g.Variances = model.Variances
+	g.Weights = model.Weights
+
+	return nil
+}
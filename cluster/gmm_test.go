@@ -0,0 +1,213 @@
+package cluster
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/cdipaolo/goml/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var stretched [][]float64
+
+func init() {
+	// two clusters which aren't spheres of
+	// the same size: one long along x and
+	// one long along y
+	r := rand.New(rand.NewSource(1))
+	stretched = [][]float64{}
+	for i := 0; i < 200; i++ {
+		stretched = append(stretched, []float64{r.NormFloat64()*5 - 10, r.NormFloat64() * 0.5})
+		stretched = append(stretched, []float64{r.NormFloat64()*0.5 + 10, r.NormFloat64() * 5})
+	}
+}
+
+func TestGMMShouldPass1(t *testing.T) {
+	model := NewGMM(2, 200, stretched, 42)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	c1, err := model.Predict([]float64{-10, 0})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c2, err := model.Predict([]float64{10, 0})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	assert.NotEqual(t, c1[0], c2[0], "Clusters should be in different components")
+
+	var wrong int
+	for i, guess := range model.Guesses() {
+		expected := c1[0]
+		if i%2 == 1 {
+			expected = c2[0]
+		}
+		if float64(guess) != expected {
+			wrong++
+		}
+	}
+
+	accuracy := 100 * (1 - float64(wrong)/float64(len(stretched)))
+	assert.True(t, accuracy > 98, "Accuracy (%v) should be greater than 98 percent", accuracy)
+
+	// the components should have learned the
+	// shape of each cluster
+	a, b := int(c1[0]), int(c2[0])
+	assert.InDelta(t, -10, model.Means[a][0], 1, "Mean should be close to the cluster's")
+	assert.InDelta(t, 10, model.Means[b][0], 1, "Mean should be close to the cluster's")
+	assert.True(t, model.Variances[a][0] > 10*model.Variances[a][1], "First component should be long along x (variances %v)", model.Variances[a])
+	assert.True(t, model.Variances[b][1] > 10*model.Variances[b][0], "Second component should be long along y (variances %v)", model.Variances[b])
+	assert.InDelta(t, 0.5, model.Weights[a], 0.05, "Mixing weights should be about equal")
+	assert.InDelta(t, 1, model.Weights[a]+model.Weights[b], 1e-9, "Mixing weights should sum to 1")
+}
+
+// a point on the long axis of one cluster
+// should belong to it even though it's
+// closer to the other cluster's mean (which
+// k-means would get wrong)
+func TestGMMShouldPass2(t *testing.T) {
+	model := NewGMM(2, 200, stretched, 42)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	c2, err := model.Predict([]float64{10, 0})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	guess, err := model.Predict([]float64{9, 12})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, c2[0], guess[0], "Point along the second cluster's long axis should belong to it")
+
+	probabilities, err := model.PredictSoft([]float64{9, 12})
+	assert.Nil(t, err, "Soft prediction error should be nil")
+	assert.Len(t, probabilities, 2, "There should be a probability for each component")
+	assert.InDelta(t, 1, probabilities[0]+probabilities[1], 1e-9, "Probabilities should sum to 1")
+	assert.True(t, probabilities[int(c2[0])] > 0.99, "Point should almost surely be from the second cluster")
+
+	// far away points shouldn't underflow
+	probabilities, err = model.PredictSoft([]float64{1e4, 1e4})
+	assert.Nil(t, err, "Soft prediction error should be nil")
+	assert.False(t, math.IsNaN(probabilities[0]) || math.IsNaN(probabilities[1]), "Probabilities should not be NaN")
+}
+
+// learning should stop once the log-likelihood
+// converges, and the log-likelihood should
+// only ever improve with more iterations
+func TestGMMShouldPass3(t *testing.T) {
+	previous := math.Inf(-1)
+	for _, iterations := range []int{1, 2, 5, 20} {
+		model := NewGMM(2, iterations, stretched, 42)
+		assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+		assert.True(t, model.LogLikelihood() >= previous-1e-9, "Log-likelihood should not decrease with more iterations")
+		previous = model.LogLikelihood()
+	}
+}
+
+// a model given 0 iterations should still learn,
+// using the default number of iterations, and
+// the mixing weights should always sum to 1
+func TestGMMShouldPass4(t *testing.T) {
+	model := NewGMM(4, 0, stretched, 42)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+	assert.Equal(t, 100, model.MaxIterations(), "Model should default to 100 iterations")
+	assert.False(t, math.IsInf(model.LogLikelihood(), -1), "Model should have gone through at least one iteration")
+
+	expected := NewGMM(4, 100, stretched, 42)
+	assert.Nil(t, expected.Learn(), "Learning error should be nil")
+	assert.Equal(t, expected.LogLikelihood(), model.LogLikelihood(), "Model should learn the same as one given the default iterations")
+
+	var sum float64
+	for _, w := range model.Weights {
+		sum += w
+	}
+	assert.InDelta(t, 1, sum, 1e-9, "Mixing weights should sum to 1")
+}
+
+// batch predictions should match predicting
+// each point on its own
+func TestGMMPredictBatchShouldPass1(t *testing.T) {
+	model := NewGMM(2, 100, stretched, 42)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	guesses, err := model.PredictBatch(stretched)
+	assert.Nil(t, err, "Batch prediction error should be nil")
+	assert.Len(t, guesses, len(stretched), "There should be a prediction for every row")
+
+	for i := range stretched {
+		guess, err := model.Predict(stretched[i])
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, guess, guesses[i], "Batch predictions should match Predict")
+	}
+
+	_, err = (&GMM{}).PredictBatch(stretched)
+	assert.True(t, errors.Is(err, base.ErrNotTrained), "Batch prediction error should wrap base.ErrNotTrained - Given %v", err)
+}
+
+func TestGMMShouldFail1(t *testing.T) {
+	// no data
+	model := NewGMM(2, 10, nil)
+	assert.NotNil(t, model.Learn(), "Learning error should not be nil")
+
+	// more components than points
+	model = NewGMM(3, 10, [][]float64{[]float64{1}, []float64{2}})
+	assert.NotNil(t, model.Learn(), "Learning error should not be nil")
+
+	// no components (ie. restored from an empty file)
+	_, err := (&GMM{}).Predict([]float64{1, 2})
+	assert.True(t, errors.Is(err, base.ErrNotTrained), "Prediction error should wrap base.ErrNotTrained - Given %v", err)
+
+	// wrong dimension
+	model = NewGMM(2, 10, stretched)
+	_, err = model.Predict([]float64{1, 2, 3})
+	assert.NotNil(t, err, "Prediction error should not be nil")
+	assert.True(t, errors.Is(err, base.ErrDimensionMismatch), "Prediction error should wrap base.ErrDimensionMismatch - Given %v", err)
+
+	_, err = model.PredictSoft([]float64{1})
+	assert.NotNil(t, err, "Soft prediction error should not be nil")
+}
+
+func TestGMMPersistToFileShouldPass1(t *testing.T) {
+	model := NewGMM(2, 200, stretched, 42)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	guess, err := model.PredictSoft([]float64{0, 0})
+	assert.Nil(t, err, "Soft prediction error should be nil")
+
+	assert.Nil(t, model.PersistToFile("/tmp/.goml/GMM.json"), "Persist error should be nil")
+
+	restored := NewGMM(2, 0, nil)
+	assert.Nil(t, restored.RestoreFromFile("/tmp/.goml/GMM.json"), "Restore error should be nil")
+
+	assert.Equal(t, model.Means, restored.Means, "Restored means should match")
+	assert.Equal(t, model.Variances, restored.Variances, "Restored variances should match")
+	assert.Equal(t, model.Weights, restored.Weights, "Restored weights should match")
+
+	restoredGuess, err := restored.PredictSoft([]float64{0, 0})
+	assert.Nil(t, err, "Soft prediction error should be nil")
+	assert.Equal(t, guess, restoredGuess, "Restored predictions should match")
+}
+
+func TestGMMPersistToGobShouldPass1(t *testing.T) {
+	model := NewGMM(2, 200, stretched, 42)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+	model.Tolerance = 1e-4
+
+	assert.Nil(t, model.PersistToGob("/tmp/.goml/GMM.gob"), "Persist error should be nil")
+
+	restored := NewGMM(2, 0, nil)
+	assert.Nil(t, restored.RestoreFromGob("/tmp/.goml/GMM.gob"), "Restore error should be nil")
+
+	assert.Equal(t, model.Means, restored.Means, "Restored means should match")
+	assert.Equal(t, model.Variances, restored.Variances, "Restored variances should match")
+	assert.Equal(t, model.Weights, restored.Weights, "Restored weights should match")
+	assert.Equal(t, 1e-4, restored.Tolerance, "Restored tolerance should match")
+
+	assert.NotNil(t, restored.RestoreFromGob("/tmp/.goml/NotAGMM.gob"), "Restore error should not be nil")
+}
+
+func TestGMMPersistToFileShouldFail1(t *testing.T) {
+	model := NewGMM(2, 10, stretched)
+	assert.NotNil(t, model.PersistToFile(""), "Persist error should not be nil")
+	assert.NotNil(t, model.RestoreFromFile(""), "Restore error should not be nil")
+	assert.NotNil(t, model.RestoreFromFile("/tmp/.goml/NotAGMM.json"), "Restore error should not be nil")
+}
@@ -1,12 +1,16 @@
 package cluster
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/rand"
 	"os"
+	"runtime"
+	"sync"
 	"time"
 
 	"github.com/cdipaolo/goml/base"
@@ -31,6 +35,161 @@ func diff(u, v []float64) float64 {
 	return sum
 }
 
+// kMeansPlusPlus picks k initial centers from the
+// rows of x using k-means++ instantiation. The first
+// center is a random point, and each one after is
+// picked at random with probability proportional to
+// the (squared) distance from the point to the
+// closest center already picked, which spreads the
+// centers out across the data.
+//
+// The centers are copies, so updating them won't
+// change the training set.
+//
+// http://ilpubs.stanford.edu:8090/778/1/2006-13.pdf
+func kMeansPlusPlus(x [][]float64, k int, rng *rand.Rand, distance base.DistanceMeasure) [][]float64 {
+	indices := kMeansPlusPlusIndices(len(x), k, rng, func(i, j int) float64 {
+		return distance(x[i], x[j])
+	})
+
+	centers := make([][]float64, k)
+	for i := range indices {
+		centers[i] = append([]float64{}, x[indices[i]]...)
+	}
+
+	return centers
+}
+
+// kMeansPlusPlusIndices picks k of the n points of
+// a dataset using k-means++ instantiation (see
+// kMeansPlusPlus,) returning the index of each
+// point picked. distance(i, j) is the distance
+// between points i and j, so the dataset only
+// needs to be known through its distances.
+func kMeansPlusPlusIndices(n, k int, rng *rand.Rand, distance func(i, j int) float64) []int {
+	centers := make([]int, k)
+	centers[0] = rng.Intn(n)
+
+	distances := make([]float64, n)
+	for i := 1; i < k; i++ {
+		var sum float64
+		for j := 0; j < n; j++ {
+			minDiff := distance(j, centers[0])
+			for l := 1; l < i; l++ {
+				difference := distance(j, centers[l])
+				if difference < minDiff {
+					minDiff = difference
+				}
+			}
+
+			distances[j] = minDiff * minDiff
+			sum += distances[j]
+		}
+
+		target := rng.Float64() * sum
+		j := 0
+		for sum = distances[0]; sum < target; sum += distances[j] {
+			j++
+		}
+		centers[i] = j
+	}
+
+	return centers
+}
+
+// silhouette returns the mean silhouette coefficient
+// of the clustering given by guesses over the dataset
+// x, where there are k clusters. For each point i,
+//
+// s(i) = (b(i) - a(i)) / max(a(i), b(i))
+//
+// where a(i) is the mean distance from x[i] to the
+// other points in its cluster and b(i) is the minimum
+// mean distance from x[i] to the points of any other
+// cluster. Points alone in their cluster have s(i) = 0.
+// Distances are measured with the given distance measure.
+//
+// An error is returned if there are no points or there
+// are fewer than 2 non-empty clusters.
+func silhouette(x [][]float64, guesses []int, k int, distance base.DistanceMeasure) (float64, error) {
+	if len(x) == 0 || len(guesses) != len(x) {
+		return 0, fmt.Errorf("ERROR: %w: Attempting to score a clustering with no (or mismatched) examples! Train the model first\n", base.ErrNotTrained)
+	}
+
+	counts := make([]int, k)
+	for _, c := range guesses {
+		counts[c]++
+	}
+
+	var clusters int
+	for _, count := range counts {
+		if count != 0 {
+			clusters++
+		}
+	}
+	if clusters < 2 {
+		return 0, fmt.Errorf("ERROR: Silhouette score needs at least 2 non-empty clusters (found %v)\n", clusters)
+	}
+
+	var total float64
+	sums := make([]float64, k)
+	for i := range x {
+		for j := range sums {
+			sums[j] = 0
+		}
+		for j := range x {
+			if i != j {
+				sums[guesses[j]] += distance(x[i], x[j])
+			}
+		}
+
+		own := guesses[i]
+		if counts[own] == 1 {
+			continue
+		}
+
+		a := sums[own] / float64(counts[own]-1)
+		b := math.Inf(1)
+		for c := range sums {
+			if c == own || counts[c] == 0 {
+				continue
+			}
+
+			b = math.Min(b, sums[c]/float64(counts[c]))
+		}
+
+		if m := math.Max(a, b); m != 0 {
+			total += (b - a) / m
+		}
+	}
+
+	return total / float64(len(x)), nil
+}
+
+// clusterSizes returns the number of examples
+// assigned to each of the k clusters
+func clusterSizes(guesses []int, k int) []int {
+	sizes := make([]int, k)
+	for _, c := range guesses {
+		sizes[c]++
+	}
+
+	return sizes
+}
+
+// clusterDistortions returns the summed distance
+// between each example and its assigned centroid
+// within each cluster. They add up to the total
+// distortion of the clustering.
+func clusterDistortions(x [][]float64, guesses []int, centroids [][]float64, distance base.DistanceMeasure) []float64 {
+	sums := make([]float64, len(centroids))
+	for i := range guesses {
+		sums[guesses[i]] += distance(x[i], centroids[guesses[i]])
+	}
+
+	return sums
+}
+
 /*
 KMeans implements the k-means unsupervised
 clustering algorithm. The batch version
@@ -110,9 +269,23 @@ Example KMeans Model Usage:
 type KMeans struct {
 	// maxIterations is the number of iterations
 	// the learning will be cut off at in a
-	// non-online setting.
+	// non-online setting. Learning stops sooner
+	// if the centroids converge (see Tolerance.)
 	maxIterations int
 
+	// Tolerance is used to detect convergence
+	// in batch learning: learning stops once no
+	// centroid moves more than Tolerance (by the
+	// Euclidean distance) over an iteration. If
+	// left 0, base.DefaultTolerance is used, and
+	// a negative tolerance turns early stopping
+	// off.
+	Tolerance float64
+
+	// iterations is the number of iterations the
+	// last call to Learn actually went through
+	iterations int
+
 	// alpha is only used in the
 	// online setting of the algorithm
 	alpha float64
@@ -135,6 +308,31 @@ type KMeans struct {
 
 	Centroids [][]float64 `json:"centroids"`
 
+	// Distance is the distance measure used
+	// to find the closest centroid to each
+	// example (and in Distortion and
+	// SilhouetteScore.) If left nil it
+	// defaults to the squared Euclidean
+	// distance. Note that centroids are
+	// always updated to the mean of their
+	// examples, which is only optimal for
+	// the squared Euclidean distance. Learn
+	// calls it from several goroutines at
+	// once, so it must be safe to call
+	// concurrently.
+	Distance base.DistanceMeasure
+
+	// rng is the model's own source of
+	// randomness used to instantiate and
+	// reinitialize centroids, so clustering
+	// can be reproduced with a fixed seed
+	rng *rand.Rand
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
 	// Output is the io.Writer to write
 	// logging to. Defaults to os.Stdout
 	// but can be changed to any io.Writer
@@ -144,10 +342,19 @@ type KMeans struct {
 // OnlineParams is used to pass optional
 // parameters in to creating a new K-Means
 // model if you want to learn using the
-// online version of the model
+// online version of the model, or if you
+// want to seed the model's randomness.
+//
+// Alpha and Features are ignored if they're
+// 0 (Features then comes from the training
+// set.) If Seed is 0 the model is seeded
+// from the current time. Distance sets the
+// model's distance measure (see KMeans.)
 type OnlineParams struct {
 	Alpha    float64
 	Features int
+	Seed     int64
+	Distance base.DistanceMeasure
 }
 
 // NewKMeans returns a pointer to the k-means
@@ -158,32 +365,44 @@ type OnlineParams struct {
 // than the same algorithm) so you aren't allowed
 // to pass one in as an option.
 //
-// n is an optional parameter which (if given) assigns
-// the length of the input vector.
+// params is an optional parameter which (if given)
+// assigns the learning rate and length of the input
+// vector for online learning, and/or the seed of
+// the model's randomness (see OnlineParams.)
 func NewKMeans(k, maxIterations int, trainingSet [][]float64, params ...OnlineParams) *KMeans {
 	var features int
-	if len(params) != 0 {
+	if len(params) != 0 && params[0].Features != 0 {
 		features = params[0].Features
 	} else if len(trainingSet) != 0 {
 		features = len(trainingSet[0])
 	}
 
 	alpha := 0.5
-	if len(params) != 0 {
+	if len(params) != 0 && params[0].Alpha != 0 {
 		alpha = params[0].Alpha
 	}
 
+	seed := time.Now().UTC().UnixNano()
+	if len(params) != 0 && params[0].Seed != 0 {
+		seed = params[0].Seed
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	var distance base.DistanceMeasure
+	if len(params) != 0 {
+		distance = params[0].Distance
+	}
+
 	// start all guesses with the zero vector.
 	// they will be changed during learning
 	var guesses []int
 	guesses = make([]int, len(trainingSet))
 
-	rand.Seed(time.Now().UTC().Unix())
 	centroids := make([][]float64, k)
 	for i := range centroids {
 		centroids[i] = make([]float64, features)
 		for j := range centroids[i] {
-			centroids[i][j] = 10 * (rand.Float64() - 0.5)
+			centroids[i][j] = 10 * (rng.Float64() - 0.5)
 		}
 	}
 
@@ -196,13 +415,30 @@ func NewKMeans(k, maxIterations int, trainingSet [][]float64, params ...OnlinePa
 		guesses:     guesses,
 
 		Centroids: centroids,
+		Distance:  distance,
+		rng:       rng,
 		Output:    os.Stdout,
 	}
 }
 
+// distance returns the distance between u and v
+// using the model's distance measure, defaulting
+// to the squared Euclidean distance
+func (k *KMeans) distance(u, v []float64) float64 {
+	if k.Distance == nil {
+		return diff(u, v)
+	}
+
+	return k.Distance(u, v)
+}
+
 // UpdateTrainingSet takes in a new training set (variable x.)
 //
-// Will reset the hidden 'guesses' param of the KMeans model.
+// Will reset the hidden 'guesses' param of the KMeans model,
+// assigning each example to its nearest centroid (if the
+// centroids have the same number of features as the new
+// training set,) so a restored model can report its
+// Distortion on the new data without learning again.
 func (k *KMeans) UpdateTrainingSet(trainingSet [][]float64) error {
 	if len(trainingSet) == 0 {
 		return fmt.Errorf("Error: length of given training set is 0! Need data!")
@@ -210,6 +446,58 @@ func (k *KMeans) UpdateTrainingSet(trainingSet [][]float64) error {
 
 	k.trainingSet = trainingSet
 	k.guesses = make([]int, len(trainingSet))
+	k.reassign()
+
+	return nil
+}
+
+// reassign sets the guesses of the training set to
+// the nearest of the current centroids, returning
+// false (and leaving the guesses alone) if there is
+// no training set or centroids, or if they have a
+// different number of features
+func (k *KMeans) reassign() bool {
+	if len(k.trainingSet) == 0 || len(k.Centroids) == 0 {
+		return false
+	}
+
+	features := len(k.trainingSet[0])
+	for j := range k.Centroids {
+		if len(k.Centroids[j]) != features {
+			return false
+		}
+	}
+
+	k.guesses = make([]int, len(k.trainingSet))
+	k.assign(assignWorkers)
+
+	return true
+}
+
+// restore assigns restored centroids to the model,
+// recomputing the guesses of the training set (if
+// the model has one) so they match the new
+// centroids. If the centroids don't have the same
+// number of features as the training set an error
+// is returned and the model is left untouched.
+func (k *KMeans) restore(centroids [][]float64) error {
+	if len(k.trainingSet) != 0 {
+		features := len(k.trainingSet[0])
+
+		valid := len(centroids) != 0
+		for j := range centroids {
+			if len(centroids[j]) != features {
+				valid = false
+			}
+		}
+
+		if !valid {
+			return fmt.Errorf("ERROR: restored centroids don't match the model's training set!\n\tNumber of centroids: %v\n\tFeatures in training set: %v\n", len(centroids), features)
+		}
+	}
+
+	k.Centroids = centroids
+	k.reassign()
 
 	return nil
 }
@@ -240,6 +528,24 @@ func (k *KMeans) MaxIterations() int {
 	return k.maxIterations
 }
 
+// Iterations returns the number of iterations the
+// last call to Learn went through before converging
+// (or reaching the maximum number of iterations.)
+func (k *KMeans) Iterations() int {
+	return k.iterations
+}
+
+// tolerance returns the tolerance used to detect
+// convergence, using base.DefaultTolerance if the
+// model's Tolerance is left as 0
+func (k *KMeans) tolerance() float64 {
+	if k.Tolerance == 0 {
+		return base.DefaultTolerance
+	}
+
+	return k.Tolerance
+}
+
 // Predict takes in a variable x (an array of floats,) and
 // finds the value of the hypothesis function given the
 // current parameter vector θ
@@ -249,20 +555,16 @@ func (k *KMeans) MaxIterations() int {
 // you trained off of normalized inputs and are feeding
 // an un-normalized input
 func (k *KMeans) Predict(x []float64, normalize ...bool) ([]float64, error) {
-	if len(x) != len(k.Centroids[0]) {
-		return nil, fmt.Errorf("Error: Centroid vector should be the same length as input vector!\n\tLength of x given: %v\n\tLength of centroid: %v\n", len(x), len(k.Centroids[0]))
-	}
-
-	if len(normalize) != 0 && normalize[0] {
-		base.NormalizePoint(x)
+	distances, err := k.centroidDistances(x, normalize...)
+	if err != nil {
+		return nil, err
 	}
 
 	var guess int
-	minDiff := diff(x, k.Centroids[0])
-	for j := 1; j < len(k.Centroids); j++ {
-		difference := diff(x, k.Centroids[j])
-		if difference < minDiff {
-			minDiff = difference
+	minDiff := distances[0]
+	for j := 1; j < len(distances); j++ {
+		if distances[j] < minDiff {
+			minDiff = distances[j]
 			guess = j
 		}
 	}
@@ -270,6 +572,95 @@ func (k *KMeans) Predict(x []float64, normalize ...bool) ([]float64, error) {
 	return []float64{float64(guess)}, nil
 }
 
+// PredictSoft takes in a variable x (an array of floats,)
+// and returns a soft assignment of x to each cluster. The
+// result has length k, sums to 1, and is the softmax of
+// the negative distances from x to each centroid, so
+// closer centroids get higher weight
+//
+//     p[j] = exp(-d(x, μ[j])) / Σ exp(-d(x, μ[l]))
+//
+// The index of the highest weight is the same as the
+// class given by Predict.
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (k *KMeans) PredictSoft(x []float64, normalize ...bool) ([]float64, error) {
+	distances, err := k.centroidDistances(x, normalize...)
+	if err != nil {
+		return nil, err
+	}
+
+	// shift by the smallest distance so
+	// the largest exponent is 0 and far
+	// away points don't underflow to 0/0
+	minDiff := distances[0]
+	for j := range distances {
+		if distances[j] < minDiff {
+			minDiff = distances[j]
+		}
+	}
+
+	var sum float64
+	weights := make([]float64, len(distances))
+	for j := range distances {
+		weights[j] = math.Exp(minDiff - distances[j])
+		sum += weights[j]
+	}
+
+	for j := range weights {
+		weights[j] /= sum
+	}
+
+	return weights, nil
+}
+
+// centroidDistances returns the distance from x to
+// each centroid, after checking that the model has
+// centroids and that x has the same dimension as
+// them (and normalizing x if asked to.) It's shared by Predict and PredictSoft.
+func (k *KMeans) centroidDistances(x []float64, normalize ...bool) ([]float64, error) {
+	if len(k.Centroids) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: Attempting to predict with no centroids!\n", base.ErrNotTrained)
+	}
+	if len(x) != len(k.Centroids[0]) {
+		return nil, fmt.Errorf("Error: %w: Centroid vector should be the same length as input vector!\n\tLength of x given: %v\n\tLength of centroid: %v\n", base.ErrDimensionMismatch, len(x), len(k.Centroids[0]))
+	}
+
+	if len(normalize) != 0 && normalize[0] {
+		x = base.NormalizedPoint(x)
+	}
+
+	distances := make([]float64, len(k.Centroids))
+	for j := range k.Centroids {
+		distances[j] = k.distance(x, k.Centroids[j])
+	}
+
+	return distances, nil
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (k *KMeans) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	if len(k.Centroids) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: Attempting to predict with no centroids!\n", base.ErrNotTrained)
+	}
+
+	return base.PredictBatch(x, len(k.Centroids[0]), func(row []float64) ([]float64, error) {
+		return k.Predict(row, normalize...)
+	})
+}
+
 // Learn takes the struct's dataset and expected results and runs
 // batch gradient descent on them, optimizing theta so you can
 // predict based on those results
@@ -279,55 +670,39 @@ func (k *KMeans) Predict(x []float64, normalize ...bool) ([]float64, error) {
 // model than regular, randomized instantiation of
 // centroids.
 // Paper: http://ilpubs.stanford.edu:8090/778/1/2006-13.pdf
+//
+// Learning stops early once the centroids converge (see
+// Tolerance.)
+//
+// The nearest centroid of each example is found in
+// parallel across runtime.NumCPU() goroutines. The
+// results are identical to doing it serially.
 func (k *KMeans) Learn() error {
 	if k.trainingSet == nil {
 		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		fmt.Fprintf(k.Output, err.Error())
+		k.logf(err.Error())
 		return err
 	}
 
 	examples := len(k.trainingSet)
 	if examples == 0 || len(k.trainingSet[0]) == 0 {
 		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		fmt.Fprintf(k.Output, err.Error())
+		k.logf(err.Error())
 		return err
 	}
 
 	centroids := len(k.Centroids)
 	features := len(k.trainingSet[0])
 
-	fmt.Fprintf(k.Output, "Training:\n\tModel: K-Means++ Classification\n\tTraining Examples: %v\n\tFeatures: %v\n\tClasses: %v\n...\n\n", examples, features, centroids)
+	k.logf("Training:\n\tModel: K-Means++ Classification\n\tTraining Examples: %v\n\tFeatures: %v\n\tClasses: %v\n...\n\n", examples, features, centroids)
 
 	// instantiate the centroids using k-means++
-	k.Centroids[0] = k.trainingSet[rand.Intn(len(k.trainingSet))]
-
-	distances := make([]float64, len(k.trainingSet))
-	for i := 1; i < len(k.Centroids); i++ {
-		var sum float64
-		for j, x := range k.trainingSet {
-			minDiff := diff(x, k.Centroids[0])
-			for l := 1; l < i; l++ {
-				difference := diff(x, k.Centroids[l])
-				if difference < minDiff {
-					minDiff = difference
-				}
-			}
-
-			distances[j] = minDiff * minDiff
-			sum += distances[j]
-		}
+	k.Centroids = kMeansPlusPlus(k.trainingSet, centroids, k.rng, k.distance)
 
-		target := rand.Float64() * sum
-		j := 0
-		for sum = distances[0]; sum < target; sum += distances[j] {
-			j++
-		}
-		k.Centroids[i] = k.trainingSet[j]
+	tolerance := k.tolerance()
 
-	}
-
-	iter := 0
-	for ; iter < k.maxIterations; iter++ {
+	k.iterations = 0
+	for k.iterations < k.maxIterations {
 
 		// set new guesses
 		//
@@ -340,50 +715,109 @@ func (k *KMeans) Learn() error {
 			classTotal[j] = make([]float64, features)
 		}
 
-		for i, x := range k.trainingSet {
-			k.guesses[i] = 0
-			minDiff := diff(x, k.Centroids[0])
-			for j := 1; j < len(k.Centroids); j++ {
-				difference := diff(x, k.Centroids[j])
-				if difference < minDiff {
-					minDiff = difference
-					k.guesses[i] = j
-				}
-			}
+		k.assign(assignWorkers)
 
+		// the sums are taken in order of the
+		// training set (not per worker) so the
+		// centroids don't depend on the number
+		// of workers
+		for i, x := range k.trainingSet {
 			classCount[k.guesses[i]]++
 			for j := range x {
 				classTotal[k.guesses[i]][j] += x[j]
 			}
 		}
 
-		newCentroids := append([][]float64{}, k.Centroids...)
+		// move each centroid to the mean of its
+		// examples, keeping track of the furthest
+		// any centroid moves
+		var maxShift float64
+		newCentroids := make([][]float64, centroids)
 		for j := range k.Centroids {
+			newCentroids[j] = make([]float64, features)
+
 			// if no objects are in the same class,
 			// reinitialize it to a random vector
 			if classCount[j] == 0 {
-				for l := range k.Centroids[j] {
-					k.Centroids[j][l] = 10 * (rand.Float64() - 0.5)
+				for l := range newCentroids[j] {
+					newCentroids[j][l] = 10 * (k.rng.Float64() - 0.5)
+				}
+			} else {
+				for l := range newCentroids[j] {
+					newCentroids[j][l] = classTotal[j][l] / float64(classCount[j])
 				}
-				continue
 			}
 
-			for l := range k.Centroids[j] {
-				k.Centroids[j][l] = classTotal[j][l] / float64(classCount[j])
-			}
+			maxShift = math.Max(maxShift, math.Sqrt(diff(k.Centroids[j], newCentroids[j])))
 		}
 
-		// only update if something was deleted
-		if len(newCentroids) != len(k.Centroids) {
-			k.Centroids = newCentroids
+		k.Centroids = newCentroids
+		k.iterations++
+
+		// stop once the centroids have converged
+		if maxShift < tolerance {
+			break
 		}
 	}
 
-	fmt.Fprintf(k.Output, "Training Completed in %v iterations.\n%v\n", iter, k)
+	k.logf("Training Completed in %v iterations.\n%v\n", k.iterations, k)
 
 	return nil
 }
 
+// assignWorkers is the number of goroutines
+// Learn splits the assignment step across
+var assignWorkers = runtime.NumCPU()
+
+// assign sets the guess for each example in the
+// training set to its nearest centroid, splitting
+// the training set into contiguous chunks across
+// the given number of goroutines. Each goroutine
+// only writes the guesses within its own chunk, so
+// no locking is needed.
+func (k *KMeans) assign(workers int) {
+	examples := len(k.trainingSet)
+	if workers > examples {
+		workers = examples
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	chunk := (examples + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * chunk
+		end := start + chunk
+		if end > examples {
+			end = examples
+		}
+		if start >= end {
+			break
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+
+			for i := start; i < end; i++ {
+				x := k.trainingSet[i]
+
+				k.guesses[i] = 0
+				minDiff := k.distance(x, k.Centroids[0])
+				for j := 1; j < len(k.Centroids); j++ {
+					difference := k.distance(x, k.Centroids[j])
+					if difference < minDiff {
+						minDiff = difference
+						k.guesses[i] = j
+					}
+				}
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
+
 /*
 OnlineLearn implements a variant of the K-Means
 learning algorithm to work with streams of data.
@@ -494,6 +928,16 @@ Example Online K-Means Model:
     }
 */
 func (k *KMeans) OnlineLearn(errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	k.OnlineLearnContext(context.Background(), errors, dataset, onUpdate, normalize...)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// dataset channel is still open. This lets you stop
+// training, ie. when shutting down a server, without
+// having to close the dataset yourself.
+func (k *KMeans) OnlineLearnContext(ctx context.Context, errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
 	if errors == nil {
 		errors = make(chan error)
 	}
@@ -506,7 +950,7 @@ func (k *KMeans) OnlineLearn(errors chan error, dataset chan base.Datapoint, onU
 	centroids := len(k.Centroids)
 	features := len(k.Centroids[0])
 
-	fmt.Fprintf(k.Output, "Training:\n\tModel: Online K-Means Classification\n\tFeatures: %v\n\tClasses: %v\n...\n\n", features, centroids)
+	k.logf("Training:\n\tModel: Online K-Means Classification\n\tFeatures: %v\n\tClasses: %v\n...\n\n", features, centroids)
 
 	var point base.Datapoint
 	var more bool
@@ -514,17 +958,23 @@ func (k *KMeans) OnlineLearn(errors chan error, dataset chan base.Datapoint, onU
 	oneMinusAlpha := 1.0 - k.alpha
 
 	for {
-		point, more = <-dataset
+		select {
+		case <-ctx.Done():
+			k.logf("Training Cancelled.\n%v\n\n", k)
+			close(errors)
+			return
+		case point, more = <-dataset:
+		}
 
 		if more {
 			if len(point.X) != features {
-				errors <- fmt.Errorf("ERROR: point.X must have the same dimensions as clusters (len %v). Point: %v", centroids, point)
+				errors <- fmt.Errorf("ERROR: %w: point.X must have the same dimensions as clusters (len %v). Point: %v", base.ErrDimensionMismatch, centroids, point)
 			}
 
-			minDiff := diff(point.X, k.Centroids[0])
+			minDiff := k.distance(point.X, k.Centroids[0])
 			c := 0
 			for j := 1; j < len(k.Centroids); j++ {
-				difference := diff(point.X, k.Centroids[j])
+				difference := k.distance(point.X, k.Centroids[j])
 				if difference < minDiff {
 					minDiff = difference
 					c = j
@@ -538,13 +988,21 @@ func (k *KMeans) OnlineLearn(errors chan error, dataset chan base.Datapoint, onU
 			go onUpdate([][]float64{[]float64{float64(c)}, k.Centroids[c]})
 
 		} else {
-			fmt.Fprintf(k.Output, "Training Completed.\n%v\n\n", k)
+			k.logf("Training Completed.\n%v\n\n", k)
 			close(errors)
 			return
 		}
 	}
 }
 
+// logf prints training progress to Output
+// when the model is Verbose
+func (k *KMeans) logf(format string, a ...interface{}) {
+	if k.Verbose {
+		fmt.Fprintf(k.Output, format, a...)
+	}
+}
+
 // String implements the fmt interface for clean printing. Here
 // we're using it to print the model as the equation h(θ)=...
 // where h is the k-means hypothesis model
@@ -557,6 +1015,10 @@ func (k *KMeans) String() string {
 // learning.
 //
 //    model.Guesses[i] = E[k.trainingSet[i]]
+//
+// A model restored from a file without a training
+// set has no guesses (the returned slice is empty)
+// until it's given one with UpdateTrainingSet.
 func (k *KMeans) Guesses() []int {
 	return k.guesses
 }
@@ -567,15 +1029,145 @@ func (k *KMeans) Guesses() []int {
 //
 // Distorition() = Σ |x[i] - μ[c[i]]|^2
 // over all training examples
+//
+// A model restored from a file without a training
+// set can Predict, but its distortion is 0 until
+// it's given data with UpdateTrainingSet.
 func (k *KMeans) Distortion() float64 {
 	var sum float64
 	for i := range k.trainingSet {
-		sum += diff(k.trainingSet[i], k.Centroids[int(k.guesses[i])])
+		sum += k.distance(k.trainingSet[i], k.Centroids[int(k.guesses[i])])
 	}
 
 	return sum
 }
 
+// SilhouetteScore returns the mean silhouette coefficient
+// of the clustering of the training set given by the
+// trained model. This measures how well the model's
+// clusters are separated without needing any ground-truth
+// labels. Scores range from -1 to 1, where values close
+// to 1 mean points are much closer to their own cluster
+// than to any other.
+//
+// For each training example i,
+//
+// s(i) = (b(i) - a(i)) / max(a(i), b(i))
+//
+// where a(i) is the mean distance (|x[i] - x[j]|^2) to
+// the other examples in its cluster and b(i) is the
+// minimum mean distance to the examples of any other
+// cluster.
+//
+// An error is returned if the model hasn't been trained
+// or has fewer than 2 non-empty clusters. Note that this
+// takes time quadratic in the number of examples.
+func (k *KMeans) SilhouetteScore() (float64, error) {
+	return silhouette(k.trainingSet, k.guesses, len(k.Centroids), k.distance)
+}
+
+// ClusterSizes returns the number of training
+// examples assigned to each centroid, so
+// ClusterSizes()[j] is the size of cluster j.
+func (k *KMeans) ClusterSizes() []int {
+	return clusterSizes(k.guesses, len(k.Centroids))
+}
+
+// ClusterDistortions returns the distortion within
+// each cluster (see Distortion,) which is
+//
+// ClusterDistortions()[j] = Σ |x[i] - μ[j]|^2
+// over all training examples with c[i] == j
+//
+// so they add up to Distortion(). Clusters with a
+// large distortion compared to their size are loose,
+// which might mean k is too small.
+func (k *KMeans) ClusterDistortions() []float64 {
+	return clusterDistortions(k.trainingSet, k.guesses, k.Centroids, k.distance)
+}
+
+// KMeansElbow fits a separate k-means model (with
+// NewKMeans) to the training set for every k from 1
+// up to maxK, and returns the Distortion() of each
+// fit, so distortions[i] is the distortion using
+// i+1 clusters. The distortion always drops as k
+// grows, but usually stops dropping quickly after
+// the 'right' number of clusters. Plot it and pick
+// the k at the 'elbow' of the curve, or use ElbowK.
+//
+// The models' logging output is discarded. An error
+// is returned if maxK is less than 1 or greater than
+// the number of training examples, or if any of the
+// fits fail.
+func KMeansElbow(trainingSet [][]float64, maxK, maxIterations int) ([]float64, error) {
+	if maxK < 1 || maxK > len(trainingSet) {
+		return nil, fmt.Errorf("ERROR: maximum k (%v) should be between 1 and the number of training examples (%v)!", maxK, len(trainingSet))
+	}
+
+	distortions := make([]float64, maxK)
+	for k := 1; k <= maxK; k++ {
+		model := NewKMeans(k, maxIterations, trainingSet)
+		model.Output = ioutil.Discard
+
+		err := model.Learn()
+		if err != nil {
+			return nil, fmt.Errorf("ERROR: Couldn't fit k-means with k = %v!\n\t%w", k, err)
+		}
+
+		distortions[k-1] = model.Distortion()
+	}
+
+	return distortions, nil
+}
+
+// ElbowK takes the distortions returned by KMeansElbow
+// and returns the k at the 'elbow' of the curve. The
+// distortions and k are both scaled to [0,1], and the
+// elbow is the point of the curve furthest below the
+// straight line joining its first and last points,
+// which estimates the point of maximum curvature (this
+// is the 'Kneedle' method.)
+//
+// https://raghavan.usc.edu/papers/kneedle-simplex11.pdf
+//
+// 0 is returned if there are less than 3 distortions
+// because there's no elbow to look for.
+func ElbowK(distortions []float64) int {
+	if len(distortions) < 3 {
+		return 0
+	}
+
+	min, max := distortions[0], distortions[0]
+	for _, d := range distortions {
+		min = math.Min(min, d)
+		max = math.Max(max, d)
+	}
+	if max == min {
+		return 1
+	}
+
+	n := len(distortions) - 1
+	first := (distortions[0] - min) / (max - min)
+	last := (distortions[n] - min) / (max - min)
+
+	var best int
+	var bestGap float64
+	for i := 1; i < n; i++ {
+		x := float64(i) / float64(n)
+		y := (distortions[i] - min) / (max - min)
+
+		// how far the curve is below the line
+		gap := first + (last-first)*x - y
+		if gap > bestGap {
+			best = i
+			bestGap = gap
+		}
+	}
+
+	// distortions[i] is the distortion of k = i+1
+	return best + 1
+}
+
 // SaveClusteredData takes operates on a k-means
 // model, concatenating the given dataset with the
 // assigned class from clustering and saving it to
@@ -584,12 +1176,27 @@ func (k *KMeans) Distortion() float64 {
 // Basically just a wrapper for the base.SaveDataToCSV
 // with the K-Means data.
 func (k *KMeans) SaveClusteredData(filepath string) error {
+	return base.SaveDataToCSV(filepath, k.trainingSet, k.floatGuesses(), true)
+}
+
+// SaveClusteredDataTo is the same as SaveClusteredData
+// but writes the clustered dataset to w instead of a
+// file, so you can send it anywhere (an HTTP response,
+// an in-memory buffer, etc.)
+func (k *KMeans) SaveClusteredDataTo(w io.Writer) error {
+	return base.WriteDataToCSV(w, k.trainingSet, k.floatGuesses(), true)
+}
+
+// floatGuesses returns the cluster assignments of
+// the training set as floats so they can be saved
+// along with it
+func (k *KMeans) floatGuesses() []float64 {
 	floatGuesses := []float64{}
 	for _, val := range k.guesses {
 		floatGuesses = append(floatGuesses, float64(val))
 	}
 
-	return base.SaveDataToCSV(filepath, k.trainingSet, floatGuesses, true)
+	return floatGuesses
 }
 
 // PersistToFile takes in an absolute filepath and saves the
@@ -628,6 +1235,15 @@ func (k *KMeans) PersistToFile(path string) error {
 // This would be useful in persisting data between running
 // a model on data, or for graphing a dataset with a fit in
 // another framework like Julia/Gadfly.
+//
+// Only the centroids are saved, so a restored model can
+// Predict right away but has no training set. If the model
+// already has a training set its guesses are recomputed
+// from the restored centroids, and an error is returned if
+// they don't have the same number of features (leaving
+// the model's centroids as they were.) Otherwise
+// give it data with UpdateTrainingSet to get its
+// Guesses and Distortion.
 func (k *KMeans) RestoreFromFile(path string) error {
 	if path == "" {
 		return fmt.Errorf("ERROR: you just tried to restore your model from a file with no path! That's a no-no. Try it with a valid filepath")
@@ -638,10 +1254,31 @@ func (k *KMeans) RestoreFromFile(path string) error {
 		return err
 	}
 
-	err = json.Unmarshal(bytes, &k.Centroids)
+	var centroids [][]float64
+	err = json.Unmarshal(bytes, &centroids)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return k.restore(centroids)
+}
+
+// PersistToGob saves the model's centroids to the given
+// file like PersistToFile, but encoded with encoding/gob
+// (see base.PersistToGob.)
+func (k *KMeans) PersistToGob(path string) error {
+	return base.PersistToGob(path, k.Centroids)
+}
+
+// RestoreFromGob takes in a path to centroids saved with
+// PersistToGob and assigns the model's centroids to them,
+// like RestoreFromFile.
+func (k *KMeans) RestoreFromGob(path string) error {
+	var centroids [][]float64
+	err := base.RestoreFromGob(path, &centroids)
+	if err != nil {
+		return err
+	}
+
+	return k.restore(centroids)
 }
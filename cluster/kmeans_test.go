@@ -1,7 +1,12 @@
 package cluster
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"math"
 	"math/rand"
 	"os"
 	"testing"
@@ -64,9 +69,11 @@ func init() {
 // 100% accuracy, but I'm thresholding faliure
 // at 87% because maybe <10% of the time the
 // randomization of the clusters leaves two
-// areas with the same classification
+// areas with the same classification. The
+// models are seeded so the tests themselves
+// are deterministic.
 func TestKMeansShouldPass1(t *testing.T) {
-	model := NewKMeans(4, 2, circles)
+	model := NewKMeans(4, 2, circles, OnlineParams{Seed: 42})
 
 	assert.Nil(t, model.Learn(), "Learning error should be nil")
 
@@ -138,10 +145,23 @@ func TestKMeansShouldPass1(t *testing.T) {
 }
 
 // use normalized data
+// predicting with normalize should leave the
+// caller's input untouched
+func TestKMeansPredictNormalizeShouldPass1(t *testing.T) {
+	model := NewKMeans(4, 2, circles, OnlineParams{Seed: 42})
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	x := []float64{-10, 10}
+
+	_, err := model.Predict(x, true)
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, []float64{-10, 10}, x, "Predict should not change the input when normalizing")
+}
+
 func TestKMeansShouldPass2(t *testing.T) {
 	norm := append([][]float64{}, circles...)
 	base.Normalize(norm)
-	model := NewKMeans(4, 2, norm)
+	model := NewKMeans(4, 2, norm, OnlineParams{Seed: 42})
 
 	assert.Nil(t, model.Learn(), "Learning error should be nil")
 
@@ -267,6 +287,25 @@ func TestKMeansShouldPass3(t *testing.T) {
 
 //* Test Online KMeans *//
 
+func TestKMeansConvergenceShouldPass1(t *testing.T) {
+	model := NewKMeans(4, 1000, circles, OnlineParams{Seed: 42})
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	assert.True(t, model.Iterations() > 0, "Model should have gone through at least one iteration")
+	assert.True(t, model.Iterations() < 20, "Model should have converged well before the maximum number of iterations - went through %v", model.Iterations())
+
+	sizes := model.ClusterSizes()
+	for j := range sizes {
+		assert.Equal(t, len(circles)/4, sizes[j], "Cluster %v should hold one circle", j)
+	}
+
+	// a negative tolerance turns early stopping off
+	model = NewKMeans(4, 50, circles, OnlineParams{Seed: 42})
+	model.Tolerance = -1
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+	assert.Equal(t, 50, model.Iterations(), "Model should go through every iteration without early stopping")
+}
+
 func TestOnlineKMeansShouldPass1(t *testing.T) {
 	// create the channel of data and errors
 	stream := make(chan base.Datapoint, 100)
@@ -422,13 +461,328 @@ func TestOnlineKMeansShouldPass1(t *testing.T) {
 
 //* Test Persistance *//
 
+func TestKMeansSilhouetteScoreShouldPass1(t *testing.T) {
+	x := make([][]float64, len(circles))
+	for i := range circles {
+		x[i] = append([]float64{}, circles[i]...)
+	}
+
+	model := NewKMeans(4, 2, x, OnlineParams{Seed: 42})
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	score, err := model.SilhouetteScore()
+	assert.Nil(t, err, "Silhouette score error should be nil")
+	assert.True(t, score > 0.9, "Silhouette score should be close to 1 for well separated clusters (currently %v)", score)
+	assert.True(t, score <= 1, "Silhouette score should never exceed 1")
+}
+
+// test the silhouette against a hand-computed
+// example in 1 dimension
+func TestKMeansSilhouetteScoreShouldPass2(t *testing.T) {
+	x := [][]float64{[]float64{0}, []float64{1}, []float64{10}, []float64{11}, []float64{30}}
+	guesses := []int{0, 0, 1, 1, 2}
+
+	// squared distances, so
+	//   s(0) = (mean(100, 121) - 1) / mean(100, 121)
+	//   s(1) = (mean(81, 100) - 1) / mean(81, 100)
+	// and by symmetry the same for points 2 and 3,
+	// while the single point in cluster 2 scores 0
+	s0 := (110.5 - 1) / 110.5
+	s1 := (90.5 - 1) / 90.5
+
+	score, err := silhouette(x, guesses, 3, diff)
+	assert.Nil(t, err, "Silhouette score error should be nil")
+	assert.InDelta(t, (2*s0+2*s1)/5, score, 1e-9, "Silhouette score should match the hand-computed value")
+}
+
+func TestKMeansClusterSizesShouldPass1(t *testing.T) {
+	x := make([][]float64, len(circles))
+	for i := range circles {
+		x[i] = append([]float64{}, circles[i]...)
+	}
+
+	model := NewKMeans(4, 2, x, OnlineParams{Seed: 42})
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	// each of the 4 circles has the same
+	// number of points
+	sizes := model.ClusterSizes()
+	assert.Len(t, sizes, 4, "There should be a size for every cluster")
+	for j := range sizes {
+		assert.Equal(t, len(x)/4, sizes[j], "Cluster %v should hold one circle", j)
+	}
+
+	distortions := model.ClusterDistortions()
+	assert.Len(t, distortions, 4, "There should be a distortion for every cluster")
+
+	var sum float64
+	for j := range distortions {
+		assert.True(t, distortions[j] > 0, "Cluster distortion should be positive")
+		sum += distortions[j]
+	}
+	assert.InDelta(t, model.Distortion(), sum, 1e-6, "Cluster distortions should add up to the total distortion")
+}
+
+func TestKMeansSilhouetteScoreShouldFail1(t *testing.T) {
+	// not trained
+	model := NewKMeans(4, 2, circles)
+	_, err := model.SilhouetteScore()
+	assert.NotNil(t, err, "Silhouette score error should not be nil")
+
+	// no training set
+	model = NewKMeans(4, 2, nil, OnlineParams{Features: 2})
+	_, err = model.SilhouetteScore()
+	assert.NotNil(t, err, "Silhouette score error should not be nil")
+}
+
+// models with the same seed should cluster
+// identically
+func TestKMeansElbowShouldPass1(t *testing.T) {
+	distortions, err := KMeansElbow(circles, 7, 30)
+	assert.Nil(t, err, "Elbow error should be nil")
+	assert.Len(t, distortions, 7, "There should be a distortion for every k")
+
+	for i := 1; i < len(distortions); i++ {
+		assert.True(t, distortions[i] <= distortions[i-1]*1.001, "Distortion should not grow with k (k = %v: %v, k = %v: %v)", i, distortions[i-1], i+1, distortions[i])
+	}
+
+	// there are 4 clusters in the data
+	assert.Equal(t, 4, ElbowK(distortions), "Elbow should be at the true number of clusters")
+}
+
+func TestKMeansElbowShouldFail1(t *testing.T) {
+	_, err := KMeansElbow(circles, 0, 30)
+	assert.NotNil(t, err, "Elbow with no k to try should return an error")
+
+	_, err = KMeansElbow(circles[:3], 4, 30)
+	assert.NotNil(t, err, "Elbow with more clusters than examples should return an error")
+
+	assert.Equal(t, 0, ElbowK([]float64{10, 5}), "Elbow with less than 3 distortions should be 0")
+	assert.Equal(t, 1, ElbowK([]float64{10, 10, 10}), "Elbow of a flat curve should be at k = 1")
+}
+
+func TestKMeansSeedShouldPass1(t *testing.T) {
+	// give each model its own copy of the data
+	// so training one can't affect the other
+	x1 := make([][]float64, len(circles))
+	x2 := make([][]float64, len(circles))
+	for i := range circles {
+		x1[i] = append([]float64{}, circles[i]...)
+		x2[i] = append([]float64{}, circles[i]...)
+	}
+
+	model1 := NewKMeans(4, 2, x1, OnlineParams{Seed: 7})
+	model2 := NewKMeans(4, 2, x2, OnlineParams{Seed: 7})
+
+	assert.Nil(t, model1.Learn(), "Learning error should be nil")
+	assert.Nil(t, model2.Learn(), "Learning error should be nil")
+
+	assert.Equal(t, model1.Centroids, model2.Centroids, "Centroids should match with the same seed")
+	assert.Equal(t, model1.Guesses(), model2.Guesses(), "Guesses should match with the same seed")
+}
+
+// learning in parallel should give exactly the
+// same results as learning serially
+func TestKMeansParallelShouldPass1(t *testing.T) {
+	x1 := make([][]float64, len(circles))
+	x2 := make([][]float64, len(circles))
+	for i := range circles {
+		x1[i] = append([]float64{}, circles[i]...)
+		x2[i] = append([]float64{}, circles[i]...)
+	}
+
+	workers := assignWorkers
+	defer func() { assignWorkers = workers }()
+
+	assignWorkers = 1
+	serial := NewKMeans(4, 50, x1, OnlineParams{Seed: 7})
+	assert.Nil(t, serial.Learn(), "Learning error should be nil")
+
+	assignWorkers = 8
+	parallel := NewKMeans(4, 50, x2, OnlineParams{Seed: 7})
+	assert.Nil(t, parallel.Learn(), "Learning error should be nil")
+
+	assert.Equal(t, serial.Centroids, parallel.Centroids, "Centroids should match the serial model")
+	assert.Equal(t, serial.Guesses(), parallel.Guesses(), "Guesses should match the serial model")
+	assert.Equal(t, serial.Iterations(), parallel.Iterations(), "Iterations should match the serial model")
+}
+
+// batch predictions should match predicting
+// each point on its own
+func TestKMeansPredictBatchShouldPass1(t *testing.T) {
+	model := NewKMeans(2, 2, nil, OnlineParams{Features: 1})
+	model.Centroids = [][]float64{[]float64{-5}, []float64{5}}
+
+	guesses, err := model.PredictBatch([][]float64{[]float64{-4}, []float64{6}, []float64{-1}, []float64{1}})
+	assert.Nil(t, err, "Batch prediction error should be nil")
+	assert.Equal(t, [][]float64{[]float64{0}, []float64{1}, []float64{0}, []float64{1}}, guesses, "Batch predictions should match the closest centroid")
+
+	_, err = model.PredictBatch([][]float64{[]float64{-4}, []float64{6, 1}})
+	assert.NotNil(t, err, "Batch prediction error should not be nil")
+}
+
+func TestKMeansPredictSoftShouldPass1(t *testing.T) {
+	model := NewKMeans(3, 2, nil, OnlineParams{Features: 1})
+	model.Centroids = [][]float64{[]float64{-5}, []float64{0}, []float64{5}}
+
+	weights, err := model.PredictSoft([]float64{1})
+	assert.Nil(t, err, "Soft prediction error should be nil")
+	assert.Len(t, weights, 3, "There should be a weight for each centroid")
+
+	// squared distances of 36, 1, and 16
+	denom := math.Exp(-36) + math.Exp(-1) + math.Exp(-16)
+	assert.InDelta(t, math.Exp(-36)/denom, weights[0], 1e-12, "Weight should be the softmax of the negative distance")
+	assert.InDelta(t, math.Exp(-1)/denom, weights[1], 1e-12, "Weight should be the softmax of the negative distance")
+	assert.InDelta(t, math.Exp(-16)/denom, weights[2], 1e-12, "Weight should be the softmax of the negative distance")
+
+	// far away points shouldn't underflow
+	weights, err = model.PredictSoft([]float64{1000})
+	assert.Nil(t, err, "Soft prediction error should be nil")
+
+	var sum float64
+	for j := range weights {
+		assert.False(t, math.IsNaN(weights[j]), "Weights should not be NaN")
+		sum += weights[j]
+	}
+	assert.InDelta(t, 1, sum, 1e-12, "Weights should sum to 1")
+	assert.InDelta(t, 1, weights[2], 1e-12, "The closest centroid should get all the weight")
+
+	guess, err := model.Predict([]float64{1000})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, 2.0, guess[0], "Predict should pick the highest weight")
+}
+
+func TestKMeansPredictSoftShouldFail1(t *testing.T) {
+	model := NewKMeans(3, 2, nil, OnlineParams{Features: 1})
+
+	_, err := model.PredictSoft([]float64{1, 2})
+	assert.NotNil(t, err, "Soft prediction error should not be nil")
+}
+
+// predicting with a model that has no centroids
+// should fail rather than panic
+func TestKMeansPredictShouldFail1(t *testing.T) {
+	// no centroids (ie. restored from an empty file)
+	model := &KMeans{}
+
+	_, err := model.Predict([]float64{1, 2})
+	assert.True(t, errors.Is(err, base.ErrNotTrained), "Prediction error should wrap base.ErrNotTrained - Given %v", err)
+
+	_, err = model.PredictSoft([]float64{1, 2})
+	assert.True(t, errors.Is(err, base.ErrNotTrained), "Soft prediction error should wrap base.ErrNotTrained - Given %v", err)
+
+	_, err = model.PredictBatch([][]float64{[]float64{1, 2}})
+	assert.True(t, errors.Is(err, base.ErrNotTrained), "Batch prediction error should wrap base.ErrNotTrained - Given %v", err)
+}
+
+// the model should cluster with (and measure
+// distortion using) the given distance measure
+func TestKMeansDistanceShouldPass1(t *testing.T) {
+	x := make([][]float64, len(circles))
+	for i := range circles {
+		x[i] = append([]float64{}, circles[i]...)
+	}
+
+	model := NewKMeans(4, 2, x, OnlineParams{Seed: 42, Distance: base.ManhattanDistance})
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	c1, err := model.Predict([]float64{-10, -10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c2, err := model.Predict([]float64{-10, 10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c3, err := model.Predict([]float64{10, -10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c4, err := model.Predict([]float64{10, 10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	classes := map[float64]bool{c1[0]: true, c2[0]: true, c3[0]: true, c4[0]: true}
+	assert.Len(t, classes, 4, "Each block should have its own cluster")
+
+	var expected float64
+	for i, guess := range model.Guesses() {
+		expected += base.ManhattanDistance(x[i], model.Centroids[guess])
+	}
+	assert.InDelta(t, expected, model.Distortion(), 1e-6, "Distortion should use the given distance measure")
+}
+
+func TestKMeansPersistToGobShouldPass1(t *testing.T) {
+	model := NewKMeans(4, 30, circles, OnlineParams{Seed: 42})
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	assert.Nil(t, model.PersistToGob("/tmp/.goml/KMeans.gob"), "Persistance error should be nil")
+
+	restored := NewKMeans(4, 30, circles)
+	assert.Nil(t, restored.RestoreFromGob("/tmp/.goml/KMeans.gob"), "Restoration error should be nil")
+	assert.Equal(t, model.Centroids, restored.Centroids, "Restored centroids should match")
+}
+
+// a restored model should recompute its guesses
+// from the restored centroids, and a model restored
+// without data should report them once it's given
+// a training set
+func TestKMeansRestoreGuessesShouldPass1(t *testing.T) {
+	model := NewKMeans(4, 30, circles, OnlineParams{Seed: 42})
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+	assert.Nil(t, model.PersistToFile("/tmp/.goml/KMeansGuesses.json"), "Persistance error should be nil")
+
+	expected := make([]int, len(circles))
+	for i := range circles {
+		guess, err := model.Predict(circles[i])
+		assert.Nil(t, err, "Prediction error should be nil")
+		expected[i] = int(guess[0])
+	}
+
+	restored := NewKMeans(4, 30, circles)
+	assert.Nil(t, restored.RestoreFromFile("/tmp/.goml/KMeansGuesses.json"), "Restoration error should be nil")
+	assert.Equal(t, expected, restored.Guesses(), "Restored guesses should be the nearest restored centroid")
+	assert.True(t, restored.Distortion() > 0, "Restored distortion should be positive")
+
+	// no training set, so no guesses until it's
+	// given one
+	empty := NewKMeans(4, 30, nil)
+	assert.Nil(t, empty.RestoreFromFile("/tmp/.goml/KMeansGuesses.json"), "Restoration error should be nil")
+	assert.Empty(t, empty.Guesses(), "Guesses should be empty without a training set")
+	assert.Equal(t, 0.0, empty.Distortion(), "Distortion should be 0 without a training set")
+
+	assert.Nil(t, empty.UpdateTrainingSet(circles), "Update training set error should be nil")
+	assert.Equal(t, expected, empty.Guesses(), "Guesses should be the nearest restored centroid")
+	assert.Equal(t, restored.Distortion(), empty.Distortion(), "Distortion should match the restored model")
+}
+
+func TestKMeansRestoreGuessesShouldFail1(t *testing.T) {
+	model := NewKMeans(4, 30, circles, OnlineParams{Seed: 42})
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+	assert.Nil(t, model.PersistToGob("/tmp/.goml/KMeansGuessesFail.gob"), "Persistance error should be nil")
+
+	// the training set has 3 features but the
+	// centroids only have 2
+	x := [][]float64{{1, 2, 3}, {4, 5, 6}}
+	restored := NewKMeans(2, 30, x, OnlineParams{Seed: 42})
+	assert.Nil(t, restored.Learn(), "Learning error should be nil")
+	centroids := restored.Centroids
+	distortion := restored.Distortion()
+
+	assert.NotNil(t, restored.RestoreFromGob("/tmp/.goml/KMeansGuessesFail.gob"), "Restoration error should not be nil")
+	assert.Nil(t, model.PersistToFile("/tmp/.goml/KMeansGuessesFail.json"), "Persistance error should be nil")
+	assert.NotNil(t, restored.RestoreFromFile("/tmp/.goml/KMeansGuessesFail.json"), "Restoration error should not be nil")
+
+	// a failed restore should leave the
+	// model as it was
+	assert.Equal(t, centroids, restored.Centroids, "Centroids should be left untouched")
+	assert.Equal(t, distortion, restored.Distortion(), "Distortion should be left untouched")
+	assert.Len(t, restored.ClusterDistortions(), 2, "There should be a distortion for each centroid")
+}
+
 func TestKMeansPersistToFileShouldPass1(t *testing.T) {
 	var wrong int
 	var count int
 	var c1, c2 []float64
 	var err error
 
-	model := NewKMeans(2, 2, double)
+	model := NewKMeans(2, 2, double, OnlineParams{Seed: 42})
 
 	assert.Nil(t, model.Learn(), "Learning error should be nil")
 
@@ -539,4 +893,74 @@ func TestKMeansPersistToFileShouldPass1(t *testing.T) {
 
 	// save results to disk
 	assert.Nil(t, model.SaveClusteredData("/tmp/.goml/KMeansResults.csv"), "Save results error should be nil")
+
+	// and write them to a buffer, which should
+	// match the file
+	buf := &bytes.Buffer{}
+	assert.Nil(t, model.SaveClusteredDataTo(buf), "Write results error should be nil")
+
+	saved, err := ioutil.ReadFile("/tmp/.goml/KMeansResults.csv")
+	assert.Nil(t, err, "Read results error should be nil")
+	assert.Equal(t, string(saved), buf.String(), "Written results should match the saved file")
+}
+
+func TestOnlineKMeansContextShouldPass1(t *testing.T) {
+	// the stream is never closed, so learning
+	// should only stop when the context is
+	// cancelled
+	stream := make(chan base.Datapoint)
+	errors := make(chan error)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	model := NewKMeans(2, 0, nil, OnlineParams{
+		Alpha:    0.5,
+		Features: 2,
+	})
+
+	go model.OnlineLearnContext(ctx, errors, stream, func(theta [][]float64) {})
+
+	for i := 0; i < 100; i++ {
+		stream <- base.Datapoint{
+			X: []float64{float64(i % 2 * 10), float64(i % 2 * 10)},
+		}
+	}
+
+	cancel()
+
+	select {
+	case _, more := <-errors:
+		assert.False(t, more, "The errors channel should be closed once the context is cancelled")
+	case <-time.After(time.Second):
+		t.Errorf("Learning should stop when the context is cancelled")
+	}
+}
+
+// the batch clustering models should all be
+// usable through the base.UnsupervisedModel
+// interface, so you can train and evaluate
+// them the same way
+func TestUnsupervisedModelShouldPass1(t *testing.T) {
+	models := []base.UnsupervisedModel{
+		NewKMeans(4, 30, nil, OnlineParams{Seed: 42}),
+		NewTriangleKMeans(4, 30, nil, 42),
+		NewGMM(4, 30, nil, 42),
+	}
+
+	for _, model := range models {
+		assert.NotNil(t, model.UpdateTrainingSet(nil), "Updating the training set with no data should return an error")
+		assert.Nil(t, model.UpdateTrainingSet(circles), "Updating the training set should not return an error")
+		assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+		c1, err := model.Predict([]float64{-10, -10})
+		assert.Nil(t, err, "Prediction error should be nil")
+
+		c2, err := model.Predict([]float64{10, 10})
+		assert.Nil(t, err, "Prediction error should be nil")
+
+		assert.NotEqual(t, c1, c2, "Points in different blocks should be in different clusters")
+
+		_, err = model.Predict([]float64{10, 10, 10})
+		assert.NotNil(t, err, "Predicting with the wrong number of features should return an error")
+	}
 }
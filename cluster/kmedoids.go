@@ -0,0 +1,499 @@
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+/*
+KMedoids implements the k-medoids clustering
+algorithm, fit with PAM (Partitioning Around
+Medoids.) It's a lot like k-means, but the center
+of each cluster (its medoid) is always one of the
+points in the training set rather than the mean of
+the cluster. That makes it much less sensitive to
+outliers (one far away point can drag a mean a long
+way, but not a medoid,) and lets it use any distance
+measure, since it never has to average points.
+
+Because the medoids are real points, the model only
+ever needs the distances between points in the
+training set. If that's all you have (say the points
+aren't vectors at all) make the model with
+NewKMedoidsFromDistances and pass in the matrix of
+distances instead.
+
+The medoids are instantiated with k-means++, just
+like KMeans. Learning then repeatedly makes the swap
+of a medoid with a non-medoid point which lowers
+the distortion (the sum of the distance from each
+point to its medoid) the most, until no swap lowers
+it or maxIterations swaps have been made.
+
+https://en.wikipedia.org/wiki/K-medoids
+
+Example K-Medoids Model Usage:
+
+	// initialize data with 2 clusters and
+	// an outlier far away from both
+	double := [][]float64{[]float64{1000, 0}}
+	for i := -10.0; i < -3; i += 0.1 {
+		for j := -10.0; j < 10; j += 0.1 {
+			double = append(double, []float64{i, j})
+		}
+	}
+
+	for i := 3.0; i < 10; i += 0.1 {
+		for j := -10.0; j < 10; j += 0.1 {
+			double = append(double, []float64{i, j})
+		}
+	}
+
+	model := NewKMedoids(2, 100, double)
+
+	// (optionally) use a different distance
+	// measure than the squared Euclidean
+	// distance
+	model.Distance = base.ManhattanDistance
+
+	if model.Learn() != nil {
+		panic("Oh NO!!! There was an error learning!!")
+	}
+
+	// the medoids are points from the
+	// training set
+	fmt.Println(model.Medoids, model.MedoidIndices())
+
+	// now you can predict like normal!
+	guess, err := model.Predict([]float64{-3, 6})
+	if err != nil {
+		panic("prediction error")
+	}
+
+	// or if you want to get the clustering
+	// results from the data
+	results := model.Guesses()
+*/
+type KMedoids struct {
+	// maxIterations is the number of swaps
+	// the learning will be cut off at if the
+	// medoids haven't converged.
+	maxIterations int
+
+	// iterations is the number of swaps the
+	// last call to Learn made
+	iterations int
+
+	// Distance is the distance measure used
+	// between points. If left nil it defaults
+	// to the squared Euclidean distance. It
+	// isn't used if the model was made with
+	// NewKMedoidsFromDistances.
+	Distance base.DistanceMeasure
+
+	// trainingSet and guesses are the
+	// 'x', and 'y' of the data, expressed as
+	// vectors. guesses is set while learning
+	// to the closest medoid of each point.
+	//
+	// [][]float64{guesses[i]} == Predict(trainingSet[i])
+	trainingSet [][]float64
+	guesses     []int
+
+	// distances, if not nil, holds the
+	// precomputed distance between every pair
+	// of points, and is used instead of the
+	// training set
+	distances [][]float64
+
+	// Medoids holds the medoid of each cluster,
+	// and medoids the index of each medoid
+	// within the training set. Medoids is nil
+	// if the model was made with
+	// NewKMedoidsFromDistances.
+	Medoids [][]float64 `json:"medoids"`
+	medoids []int
+
+	// rng is the model's own source of
+	// randomness used to instantiate the
+	// medoids, so clustering can be reproduced
+	// with a fixed seed
+	rng *rand.Rand
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool `json:"-"`
+
+	// Output is the io.Writer to write logs
+	// and output from training to
+	Output io.Writer `json:"-"`
+}
+
+// NewKMedoids returns a pointer to a k-medoids
+// model with k clusters, which clusters given
+// inputs in an unsupervised manner.
+//
+// seed is an optional parameter which (if given and
+// not 0) seeds the model's randomness so clustering
+// is reproducible. Otherwise the model is seeded from
+// the current time.
+func NewKMedoids(k, maxIterations int, trainingSet [][]float64, seed ...int64) *KMedoids {
+	return &KMedoids{
+		maxIterations: maxIterations,
+
+		trainingSet: trainingSet,
+		guesses:     make([]int, len(trainingSet)),
+
+		medoids: make([]int, k),
+
+		rng: newRand(seed),
+
+		Output: os.Stdout,
+	}
+}
+
+// NewKMedoidsFromDistances returns a pointer to a
+// k-medoids model with k clusters like NewKMedoids,
+// but which learns from a precomputed distance matrix
+// rather than a training set, so distances[i][j] is
+// the distance between points i and j. The matrix
+// must be square, though it doesn't have to be
+// symmetric.
+//
+// A model made this way can be trained and give its
+// Guesses and Distortion, but it can't Predict new
+// points because it doesn't have any vectors to
+// compare them to.
+func NewKMedoidsFromDistances(k, maxIterations int, distances [][]float64, seed ...int64) *KMedoids {
+	return &KMedoids{
+		maxIterations: maxIterations,
+
+		guesses:   make([]int, len(distances)),
+		distances: distances,
+
+		medoids: make([]int, k),
+
+		rng: newRand(seed),
+
+		Output: os.Stdout,
+	}
+}
+
+// newRand returns a new source of randomness seeded
+// with the optional seed (if given and not 0,) or
+// with the current time otherwise
+func newRand(seed []int64) *rand.Rand {
+	source := time.Now().UTC().UnixNano()
+	if len(seed) != 0 && seed[0] != 0 {
+		source = seed[0]
+	}
+
+	return rand.New(rand.NewSource(source))
+}
+
+// UpdateTrainingSet takes in a new training set (variable x.)
+//
+// Will reset the hidden 'guesses' param of the KMedoids
+// model, and stop it from using a precomputed distance
+// matrix.
+func (k *KMedoids) UpdateTrainingSet(trainingSet [][]float64) error {
+	if len(trainingSet) == 0 {
+		return fmt.Errorf("Error: length of given training set is 0! Need data!")
+	}
+
+	k.trainingSet = trainingSet
+	k.guesses = make([]int, len(trainingSet))
+	k.distances = nil
+	k.Medoids = nil
+
+	return nil
+}
+
+// Examples returns the number of training examples (m)
+// that the model currently is training from.
+func (k *KMedoids) Examples() int {
+	if k.distances != nil {
+		return len(k.distances)
+	}
+
+	return len(k.trainingSet)
+}
+
+// MaxIterations returns the maximum number of swaps
+// the model will make while learning
+func (k *KMedoids) MaxIterations() int {
+	return k.maxIterations
+}
+
+// Iterations returns the number of swaps the last
+// call to Learn made before converging (or reaching
+// the maximum number of iterations.)
+func (k *KMedoids) Iterations() int {
+	return k.iterations
+}
+
+// MedoidIndices returns the index of the medoid of
+// each cluster within the training set (or the rows
+// of the distance matrix.)
+func (k *KMedoids) MedoidIndices() []int {
+	return k.medoids
+}
+
+// distance returns the distance between u and v
+// using the model's distance measure, defaulting
+// to the squared Euclidean distance
+func (k *KMedoids) distance(u, v []float64) float64 {
+	if k.Distance == nil {
+		return diff(u, v)
+	}
+
+	return k.Distance(u, v)
+}
+
+// between returns the distance between points
+// i and j of the training set
+func (k *KMedoids) between(i, j int) float64 {
+	if k.distances != nil {
+		return k.distances[i][j]
+	}
+
+	return k.distance(k.trainingSet[i], k.trainingSet[j])
+}
+
+// Predict takes in a variable x (an array of floats,) and
+// finds the medoid closest to x, returning its cluster
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (k *KMedoids) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	if len(k.Medoids) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: Attempting to predict with no medoids! Train the model on a training set (not a distance matrix) first\n", base.ErrNotTrained)
+	}
+	if len(x) != len(k.Medoids[0]) {
+		return nil, fmt.Errorf("Error: %w: Medoids should be the same length as input vector!\n\tLength of x given: %v\n\tLength of medoids: %v\n", base.ErrDimensionMismatch, len(x), len(k.Medoids[0]))
+	}
+
+	if len(normalize) != 0 && normalize[0] {
+		x = base.NormalizedPoint(x)
+	}
+
+	var guess int
+	minDiff := k.distance(x, k.Medoids[0])
+	for j := 1; j < len(k.Medoids); j++ {
+		difference := k.distance(x, k.Medoids[j])
+		if difference < minDiff {
+			minDiff = difference
+			guess = j
+		}
+	}
+
+	return []float64{float64(guess)}, nil
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (k *KMedoids) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	if len(k.Medoids) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: Attempting to predict with no medoids! Train the model on a training set (not a distance matrix) first\n", base.ErrNotTrained)
+	}
+
+	return base.PredictBatch(x, len(k.Medoids[0]), func(row []float64) ([]float64, error) {
+		return k.Predict(row, normalize...)
+	})
+}
+
+// nearest finds the distance from each point to
+// its closest and second closest medoids, setting
+// the guesses to the closest
+func (k *KMedoids) nearest(first, second []float64) {
+	for i := range k.guesses {
+		first[i] = math.Inf(1)
+		second[i] = math.Inf(1)
+
+		for j, m := range k.medoids {
+			d := k.between(i, m)
+			if d < first[i] {
+				second[i] = first[i]
+				first[i] = d
+				k.guesses[i] = j
+			} else if d < second[i] {
+				second[i] = d
+			}
+		}
+	}
+}
+
+// Learn takes the struct's dataset (or distance
+// matrix) and clusters it with PAM, finding the
+// medoid of each cluster. See KMedoids for more
+// on the algorithm.
+//
+// Each swap considered takes time linear in the
+// number of examples, so an iteration takes time
+// quadratic in the number of examples.
+func (k *KMedoids) Learn() error {
+	examples := k.Examples()
+	if examples == 0 || (k.distances == nil && len(k.trainingSet[0]) == 0) {
+		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
+		k.logf(err.Error())
+		return err
+	}
+
+	clusters := len(k.medoids)
+	if clusters < 1 || clusters > examples {
+		err := fmt.Errorf("ERROR: the number of clusters (%v) should be between 1 and the number of training examples (%v)!\n", clusters, examples)
+		k.logf(err.Error())
+		return err
+	}
+
+	if k.distances != nil {
+		for i := range k.distances {
+			if len(k.distances[i]) != examples {
+				err := fmt.Errorf("ERROR: the distance matrix should be square!\n\tLength of row %v: %v\n\tNumber of rows: %v\n", i, len(k.distances[i]), examples)
+				k.logf(err.Error())
+				return err
+			}
+		}
+	}
+
+	k.logf("Training:\n\tModel: K-Medoids (PAM) Classification\n\tTraining Examples: %v\n\tClasses: %v\n...\n\n", examples, clusters)
+
+	// instantiate the medoids using k-means++
+	k.medoids = kMeansPlusPlusIndices(examples, clusters, k.rng, k.between)
+	k.guesses = make([]int, examples)
+
+	first := make([]float64, examples)
+	second := make([]float64, examples)
+	isMedoid := make([]bool, examples)
+
+	k.iterations = 0
+	for k.iterations < k.maxIterations {
+		k.nearest(first, second)
+
+		for i := range isMedoid {
+			isMedoid[i] = false
+		}
+		for _, m := range k.medoids {
+			isMedoid[m] = true
+		}
+
+		// find the swap of medoid j with point o
+		// which lowers the distortion the most
+		bestChange := 0.0
+		bestMedoid, bestPoint := -1, -1
+		for o := 0; o < examples; o++ {
+			if isMedoid[o] {
+				continue
+			}
+
+			for j := range k.medoids {
+				var change float64
+				for i := 0; i < examples; i++ {
+					d := k.between(i, o)
+
+					// points in cluster j move to o or
+					// their second closest medoid, and
+					// the rest only move if o is closer
+					if k.guesses[i] == j {
+						change += math.Min(d, second[i]) - first[i]
+					} else if d < first[i] {
+						change += d - first[i]
+					}
+				}
+
+				if change < bestChange {
+					bestChange = change
+					bestMedoid, bestPoint = j, o
+				}
+			}
+		}
+
+		// stop once no swap lowers the distortion
+		if bestMedoid == -1 {
+			break
+		}
+
+		k.medoids[bestMedoid] = bestPoint
+		k.iterations++
+	}
+
+	k.nearest(first, second)
+
+	k.Medoids = nil
+	if k.distances == nil {
+		k.Medoids = make([][]float64, clusters)
+		for j, m := range k.medoids {
+			k.Medoids[j] = append([]float64{}, k.trainingSet[m]...)
+		}
+	}
+
+	k.logf("Training Completed in %v iterations.\n%v\n", k.iterations, k)
+
+	return nil
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (k *KMedoids) logf(format string, a ...interface{}) {
+	if k.Verbose {
+		fmt.Fprintf(k.Output, format, a...)
+	}
+}
+
+// String implements the fmt interface for clean printing. Here
+// we're using it to print the model's medoids
+func (k *KMedoids) String() string {
+	if k.Medoids == nil {
+		return fmt.Sprintf("K-Medoids Model:\n\tMedoid Indices: %v", k.medoids)
+	}
+
+	return fmt.Sprintf("K-Medoids Model:\n\tMedoids: %v", k.Medoids)
+}
+
+// Guesses returns the hidden parameter for the
+// unsupervised classification assigned during
+// learning.
+//
+//	model.Guesses[i] = E[k.trainingSet[i]]
+func (k *KMedoids) Guesses() []int {
+	return k.guesses
+}
+
+// Distortion returns the distortion of the clustering
+// currently given by the k-medoids model. This is the
+// function the learning algorithm tries to minimize.
+//
+// Distorition() = Σ d(x[i], m[c[i]])
+// over all training examples
+func (k *KMedoids) Distortion() float64 {
+	var sum float64
+	for i := range k.guesses {
+		sum += k.between(i, k.medoids[k.guesses[i]])
+	}
+
+	return sum
+}
+
+// ClusterSizes returns the number of training
+// examples assigned to each medoid, so
+// ClusterSizes()[j] is the size of cluster j.
+func (k *KMedoids) ClusterSizes() []int {
+	return clusterSizes(k.guesses, len(k.medoids))
+}
@@ -0,0 +1,164 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cdipaolo/goml/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// every fourth point of circles, which keeps
+// the (quadratic) tests quick
+func sparseCircles() [][]float64 {
+	x := [][]float64{}
+	for i := 0; i < len(circles); i += 4 {
+		x = append(x, circles[i])
+	}
+
+	return x
+}
+
+func TestKMedoidsShouldPass1(t *testing.T) {
+	x := sparseCircles()
+
+	model := NewKMedoids(4, 100, x, 42)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	c1, err := model.Predict([]float64{-10, -10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c2, err := model.Predict([]float64{-10, 10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c3, err := model.Predict([]float64{10, -10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c4, err := model.Predict([]float64{10, 10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	classes := map[float64]bool{c1[0]: true, c2[0]: true, c3[0]: true, c4[0]: true}
+	assert.Len(t, classes, 4, "Each block should have its own cluster")
+
+	// the medoids should be points from the
+	// training set
+	for j, m := range model.MedoidIndices() {
+		assert.Equal(t, x[m], model.Medoids[j], "Medoid should be a point in the training set")
+	}
+
+	// every guess should be the medoid closest
+	// to the point
+	for i, guess := range model.Guesses() {
+		p, err := model.Predict(x[i])
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, p[0], float64(guess), "Guess should be the closest medoid")
+	}
+
+	// every point should be in its block's cluster
+	for i, guess := range model.Guesses() {
+		c := c1
+		switch {
+		case x[i][0] < 0 && x[i][1] > 0:
+			c = c2
+		case x[i][0] > 0 && x[i][1] < 0:
+			c = c3
+		case x[i][0] > 0 && x[i][1] > 0:
+			c = c4
+		}
+
+		assert.Equal(t, c[0], float64(guess), "Point %v should be in its block's cluster", i)
+	}
+
+	var total int
+	for _, size := range model.ClusterSizes() {
+		total += size
+	}
+	assert.Equal(t, len(x), total, "Cluster sizes should add up to the number of examples")
+}
+
+// an outlier can drag a k-means centroid away
+// from its cluster, but a medoid has to stay
+// on a point in it
+func TestKMedoidsOutlierShouldPass1(t *testing.T) {
+	// the outlier is far enough out to drag a
+	// mean, but closer to a block than it would
+	// be worth giving it a cluster of its own
+	x := append([][]float64{[]float64{40, 40}}, sparseCircles()...)
+
+	model := NewKMedoids(4, 100, x, 42)
+	model.Distance = base.ManhattanDistance
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	for _, m := range model.MedoidIndices() {
+		assert.NotEqual(t, 0, m, "The outlier should not be a medoid")
+	}
+
+	for _, medoid := range model.Medoids {
+		for _, v := range medoid {
+			assert.True(t, v > -12 && v < 12, "Medoids should be within the blocks")
+		}
+	}
+}
+
+// learning from a distance matrix should give
+// the same clustering as learning from the
+// points themselves
+func TestKMedoidsDistancesShouldPass1(t *testing.T) {
+	x := sparseCircles()
+
+	distances := make([][]float64, len(x))
+	for i := range x {
+		distances[i] = make([]float64, len(x))
+		for j := range x {
+			distances[i][j] = diff(x[i], x[j])
+		}
+	}
+
+	model := NewKMedoids(4, 100, x, 7)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	matrix := NewKMedoidsFromDistances(4, 100, distances, 7)
+	assert.Nil(t, matrix.Learn(), "Learning error should be nil")
+
+	assert.Equal(t, model.MedoidIndices(), matrix.MedoidIndices(), "Medoids should match")
+	assert.Equal(t, model.Guesses(), matrix.Guesses(), "Guesses should match")
+	assert.InDelta(t, model.Distortion(), matrix.Distortion(), 1e-9, "Distortion should match")
+
+	// there aren't any vectors to predict with
+	_, err := matrix.Predict([]float64{0, 0})
+	assert.NotNil(t, err, "Prediction error should not be nil")
+}
+
+// batch predictions should match predicting
+// each point on its own
+func TestKMedoidsPredictBatchShouldPass1(t *testing.T) {
+	x := sparseCircles()
+
+	model := NewKMedoids(4, 100, x, 42)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	guesses, err := model.PredictBatch(x)
+	assert.Nil(t, err, "Batch prediction error should be nil")
+	assert.Len(t, guesses, len(x), "There should be a prediction for every row")
+
+	for i := range x {
+		guess, err := model.Predict(x[i])
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, guess, guesses[i], "Batch predictions should match Predict")
+	}
+
+	_, err = NewKMedoids(4, 100, x).PredictBatch(x)
+	assert.True(t, errors.Is(err, base.ErrNotTrained), "Batch prediction error should wrap base.ErrNotTrained - Given %v", err)
+}
+
+func TestKMedoidsShouldFail1(t *testing.T) {
+	model := NewKMedoids(4, 100, [][]float64{[]float64{1, 2}, []float64{3, 4}})
+	assert.NotNil(t, model.Learn(), "Learning error should not be nil with more clusters than examples")
+
+	model = NewKMedoids(2, 100, nil)
+	assert.NotNil(t, model.Learn(), "Learning error should not be nil with no examples")
+
+	model = NewKMedoidsFromDistances(2, 100, [][]float64{[]float64{0, 1}, []float64{1}, []float64{2, 2}})
+	assert.NotNil(t, model.Learn(), "Learning error should not be nil with a non-square matrix")
+}
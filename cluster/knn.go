@@ -178,11 +178,11 @@ func (k *KNN) Predict(x []float64, normalize ...bool) ([]float64, error) {
 		return nil, fmt.Errorf("Given K (%v) is greater than the length of the training set", k.K)
 	}
 	if len(x) != len(k.trainingSet[0]) {
-		return nil, fmt.Errorf("Given x (len %v) does not match dimensions of training set", len(x))
+		return nil, fmt.Errorf("%w: Given x (len %v) does not match dimensions of training set", base.ErrDimensionMismatch, len(x))
 	}
 
 	if len(normalize) != 0 && normalize[0] {
-		base.NormalizePoint(x)
+		x = base.NormalizedPoint(x)
 	}
 
 	// initialize the neighbors as an empty
@@ -210,3 +210,23 @@ func (k *KNN) Predict(x []float64, normalize ...bool) ([]float64, error) {
 
 	return []float64{round(sum / float64(k.K))}, nil
 }
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (k *KNN) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	if len(k.trainingSet) == 0 {
+		return nil, fmt.Errorf("ERROR: Attempting to predict with no training examples!\n")
+	}
+
+	return base.PredictBatch(x, len(k.trainingSet[0]), func(row []float64) ([]float64, error) {
+		return k.Predict(row, normalize...)
+	})
+}
@@ -245,6 +245,25 @@ func TestKNNShouldPass1(t *testing.T) {
 	fmt.Printf("Accuracy: %v percent\n\tPoints Tested: %v\n\tMisclassifications: %v\n\tAverage Prediction Time: %v\n", accuracy, count, wrong, duration/time.Duration(count))
 }
 
+// batch predictions should match predicting
+// each point on its own
+func TestKNNPredictBatchShouldPass1(t *testing.T) {
+	model := NewKNN(3, fourClusters, fourClustersY, base.EuclideanDistance)
+
+	x := [][]float64{[]float64{-10, -10}, []float64{-10, 10}, []float64{10, -10}, []float64{10, 10}}
+	guesses, err := model.PredictBatch(x)
+	assert.Nil(t, err, "Batch prediction error should be nil")
+
+	for i := range x {
+		guess, err := model.Predict(x[i])
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, guess, guesses[i], "Batch predictions should match Predict")
+	}
+
+	_, err = model.PredictBatch([][]float64{[]float64{-10, -10}, []float64{1}})
+	assert.NotNil(t, err, "Batch prediction error should not be nil")
+}
+
 // use normalized data
 func TestKNNShouldPass2(t *testing.T) {
 	norm := append([][]float64{}, fourClusters...)
@@ -148,6 +148,33 @@ type TriangleKMeans struct {
 	centroidDist    [][]float64
 	minCentroidDist []float64
 
+	// Distance is the distance measure used
+	// to find the closest centroid to each
+	// example (and in Distortion and
+	// SilhouetteScore.) If left nil it
+	// defaults to the squared Euclidean
+	// distance, like KMeans.
+	//
+	// The bounds this algorithm uses to skip
+	// distance calculations are only valid
+	// if the measure satisfies the Triangle
+	// Inequality, so only set this to a true
+	// metric (like base.EuclideanDistance or
+	// base.ManhattanDistance.) Otherwise use
+	// the standard KMeans model.
+	Distance base.DistanceMeasure
+
+	// rng is the model's own source of
+	// randomness used to instantiate and
+	// reinitialize centroids, so clustering
+	// can be reproduced with a fixed seed
+	rng *rand.Rand
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
 	// Output is the io.Writer to write logs
 	// and output from training to
 	Output io.Writer
@@ -180,7 +207,12 @@ type pointInfo struct {
 // algorithm implemented in NewKMeans are discribed
 // in the struct comments and in the paper URL
 // found within those.
-func NewTriangleKMeans(k, maxIterations int, trainingSet [][]float64) *TriangleKMeans {
+//
+// seed is an optional parameter which (if given and
+// not 0) seeds the model's randomness so clustering
+// is reproducible. Otherwise the model is seeded from
+// the current time.
+func NewTriangleKMeans(k, maxIterations int, trainingSet [][]float64, seed ...int64) *TriangleKMeans {
 	var features int
 	if len(trainingSet) != 0 {
 		features = len(trainingSet[0])
@@ -200,7 +232,11 @@ func NewTriangleKMeans(k, maxIterations int, trainingSet [][]float64) *TriangleK
 		}
 	}
 
-	rand.Seed(time.Now().UTC().Unix())
+	source := time.Now().UTC().UnixNano()
+	if len(seed) != 0 && seed[0] != 0 {
+		source = seed[0]
+	}
+
 	centroids := make([][]float64, k)
 	centroidDist := make([][]float64, k)
 	minCentroidDist := make([]float64, k)
@@ -220,13 +256,27 @@ func NewTriangleKMeans(k, maxIterations int, trainingSet [][]float64) *TriangleK
 		centroidDist:    centroidDist,
 		minCentroidDist: minCentroidDist,
 
+		rng: rand.New(rand.NewSource(source)),
+
 		Output: os.Stdout,
 	}
 }
 
+// distance returns the distance between u and v
+// using the model's distance measure, defaulting
+// to the squared Euclidean distance
+func (k *TriangleKMeans) distance(u, v []float64) float64 {
+	if k.Distance == nil {
+		return diff(u, v)
+	}
+
+	return k.Distance(u, v)
+}
+
 // UpdateTrainingSet takes in a new training set (variable x.)
 //
-// Will reset the hidden 'guesses' param of the KMeans model.
+// Will reset the hidden 'guesses' param of the KMeans model,
+// along with the distance bounds kept for each point.
 func (k *TriangleKMeans) UpdateTrainingSet(trainingSet [][]float64) error {
 	if len(trainingSet) == 0 {
 		return fmt.Errorf("Error: length of given training set is 0! Need data!")
@@ -234,6 +284,14 @@ func (k *TriangleKMeans) UpdateTrainingSet(trainingSet [][]float64) error {
 
 	k.trainingSet = trainingSet
 	k.guesses = make([]int, len(trainingSet))
+	k.info = make([]pointInfo, len(trainingSet))
+	for i := range k.info {
+		k.info[i] = pointInfo{
+			lower:     make([]float64, len(k.Centroids)),
+			upper:     0,
+			recompute: true,
+		}
+	}
 
 	return nil
 }
@@ -259,18 +317,21 @@ func (k *TriangleKMeans) MaxIterations() int {
 // you trained off of normalized inputs and are feeding
 // an un-normalized input
 func (k *TriangleKMeans) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	if len(k.Centroids) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: Attempting to predict with no centroids!\n", base.ErrNotTrained)
+	}
 	if len(x) != len(k.Centroids[0]) {
-		return nil, fmt.Errorf("Error: Centroid vector should be the same length as input vector!\n\tLength of x given: %v\n\tLength of centroid: %v\n", len(x), len(k.Centroids[0]))
+		return nil, fmt.Errorf("Error: %w: Centroid vector should be the same length as input vector!\n\tLength of x given: %v\n\tLength of centroid: %v\n", base.ErrDimensionMismatch, len(x), len(k.Centroids[0]))
 	}
 
 	if len(normalize) != 0 && normalize[0] {
-		base.NormalizePoint(x)
+		x = base.NormalizedPoint(x)
 	}
 
 	var guess int
-	minDiff := diff(x, k.Centroids[0])
+	minDiff := k.distance(x, k.Centroids[0])
 	for j := 1; j < len(k.Centroids); j++ {
-		difference := diff(x, k.Centroids[j])
+		difference := k.distance(x, k.Centroids[j])
 		if difference < minDiff {
 			minDiff = difference
 			guess = j
@@ -280,6 +341,26 @@ func (k *TriangleKMeans) Predict(x []float64, normalize ...bool) ([]float64, err
 	return []float64{float64(guess)}, nil
 }
 
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (k *TriangleKMeans) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	if len(k.Centroids) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: Attempting to predict with no centroids!\n", base.ErrNotTrained)
+	}
+
+	return base.PredictBatch(x, len(k.Centroids[0]), func(row []float64) ([]float64, error) {
+		return k.Predict(row, normalize...)
+	})
+}
+
 // computeCentroidDistanceMatrix, as said in the
 // function name, computes the centroid distance
 // matrix, saving it to the model.
@@ -292,7 +373,7 @@ func (k *TriangleKMeans) computeCentroidDistanceMatrix() {
 	// and then copy values over to maintain functionality
 	for i := range k.Centroids {
 		for j := 0; j < i; j++ {
-			k.centroidDist[i][j] = 0.5 * diff(k.Centroids[i], k.Centroids[j])
+			k.centroidDist[i][j] = 0.5 * k.distance(k.Centroids[i], k.Centroids[j])
 		}
 	}
 
@@ -353,13 +434,15 @@ func (k *TriangleKMeans) recalculateCentroids() [][]float64 {
 		}
 	}
 
-	centroids := append([][]float64{}, k.Centroids...)
+	centroids := make([][]float64, len(k.Centroids))
 	for j := range centroids {
+		centroids[j] = make([]float64, len(k.Centroids[j]))
+
 		// if no objects are in the same class,
 		// reinitialize it to a random vector
 		if classCount[j] == 0 {
 			for l := range centroids[j] {
-				centroids[j][l] = 10 * (rand.Float64() - 0.5)
+				centroids[j][l] = 10 * (k.rng.Float64() - 0.5)
 			}
 			continue
 		}
@@ -390,34 +473,34 @@ func (k *TriangleKMeans) recalculateCentroids() [][]float64 {
 func (k *TriangleKMeans) Learn() error {
 	if k.trainingSet == nil {
 		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		fmt.Fprintf(k.Output, err.Error())
+		k.logf(err.Error())
 		return err
 	}
 
 	examples := len(k.trainingSet)
 	if examples == 0 || len(k.trainingSet[0]) == 0 {
 		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		fmt.Fprintf(k.Output, err.Error())
+		k.logf(err.Error())
 		return err
 	}
 
 	centroids := len(k.Centroids)
 	features := len(k.trainingSet[0])
 
-	fmt.Fprintf(k.Output, "Training:\n\tModel: Triangle Inequality Accelerated K-Means++ Classification\n\tTraining Examples: %v\n\tFeatures: %v\n\tClasses: %v\n...\n\n", examples, features, centroids)
+	k.logf("Training:\n\tModel: Triangle Inequality Accelerated K-Means++ Classification\n\tTraining Examples: %v\n\tFeatures: %v\n\tClasses: %v\n...\n\n", examples, features, centroids)
 
 	/* Step 0 */
 
 	// instantiate the centroids using k-means++
-	k.Centroids[0] = k.trainingSet[rand.Intn(len(k.trainingSet))]
+	k.Centroids[0] = k.trainingSet[k.rng.Intn(len(k.trainingSet))]
 
 	distances := make([]float64, len(k.trainingSet))
 	for i := 1; i < len(k.Centroids); i++ {
 		var sum float64
 		for j, x := range k.trainingSet {
-			minDiff := diff(x, k.Centroids[0])
+			minDiff := k.distance(x, k.Centroids[0])
 			for l := 1; l < i; l++ {
-				difference := diff(x, k.Centroids[l])
+				difference := k.distance(x, k.Centroids[l])
 				if difference < minDiff {
 					minDiff = difference
 				}
@@ -427,7 +510,7 @@ func (k *TriangleKMeans) Learn() error {
 			sum += distances[j]
 		}
 
-		target := rand.Float64() * sum
+		target := k.rng.Float64() * sum
 		j := 0
 		for sum = distances[0]; sum < target; sum += distances[j] {
 			j++
@@ -441,7 +524,7 @@ func (k *TriangleKMeans) Learn() error {
 	// the closest cluster
 	for i, x := range k.trainingSet {
 		k.guesses[i] = 0
-		minDiff := diff(x, k.Centroids[0])
+		minDiff := k.distance(x, k.Centroids[0])
 		k.info[i].lower[0] = minDiff
 		for j := 1; j < len(k.Centroids); j++ {
 			// avoid redundant distance computations
@@ -449,7 +532,7 @@ func (k *TriangleKMeans) Learn() error {
 				continue
 			}
 
-			difference := diff(x, k.Centroids[j])
+			difference := k.distance(x, k.Centroids[j])
 			k.info[i].lower[j] = difference
 			if difference < minDiff {
 				minDiff = difference
@@ -494,7 +577,7 @@ func (k *TriangleKMeans) Learn() error {
 				if k.info[i].recompute {
 					// then recompute the distance to the assigned
 					// centroid
-					distToCentroid = diff(x, k.Centroids[guess])
+					distToCentroid = k.distance(x, k.Centroids[guess])
 					k.info[i].lower[guess] = distToCentroid
 					k.info[i].upper = distToCentroid
 					k.info[i].recompute = false
@@ -505,7 +588,7 @@ func (k *TriangleKMeans) Learn() error {
 					distToCentroid > k.centroidDist[guess][j] {
 					// only now compute the distance to the
 					// centroid
-					dist := diff(x, k.Centroids[j])
+					dist := k.distance(x, k.Centroids[j])
 					k.info[i].lower[j] = dist
 					if dist < distToCentroid {
 						k.guesses[i] = j
@@ -521,7 +604,7 @@ func (k *TriangleKMeans) Learn() error {
 			/* Step 5 */
 			for j := range k.Centroids {
 				// calculate the shift to the new centroid
-				shift := k.info[i].lower[j] - diff(k.Centroids[j], newCentroids[j])
+				shift := k.info[i].lower[j] - k.distance(k.Centroids[j], newCentroids[j])
 
 				// bound the shift at 0 and assign it
 				// as the new lower bound
@@ -534,7 +617,7 @@ func (k *TriangleKMeans) Learn() error {
 			/* Step 6 */
 			// reassign the upper bound to account
 			// for the centroid shift
-			k.info[i].upper += diff(newCentroids[k.guesses[i]], k.Centroids[k.guesses[i]])
+			k.info[i].upper += k.distance(newCentroids[k.guesses[i]], k.Centroids[k.guesses[i]])
 			k.info[i].recompute = true
 		}
 
@@ -542,11 +625,19 @@ func (k *TriangleKMeans) Learn() error {
 		k.Centroids = newCentroids
 	}
 
-	fmt.Fprintf(k.Output, "Training Completed in %v iterations.\n%v\n", iter, k)
+	k.logf("Training Completed in %v iterations.\n%v\n", iter, k)
 
 	return nil
 }
 
+// logf prints training progress to Output
+// when the model is Verbose
+func (k *TriangleKMeans) logf(format string, a ...interface{}) {
+	if k.Verbose {
+		fmt.Fprintf(k.Output, format, a...)
+	}
+}
+
 // String implements the fmt interface for clean printing. Here
 // we're using it to print the model as the equation h(θ)=...
 // where h is the k-means hypothesis model
@@ -572,12 +663,56 @@ func (k *TriangleKMeans) Guesses() []int {
 func (k *TriangleKMeans) Distortion() float64 {
 	var sum float64
 	for i := range k.trainingSet {
-		sum += diff(k.trainingSet[i], k.Centroids[int(k.guesses[i])])
+		sum += k.distance(k.trainingSet[i], k.Centroids[int(k.guesses[i])])
 	}
 
 	return sum
 }
 
+// SilhouetteScore returns the mean silhouette coefficient
+// of the clustering of the training set given by the
+// trained model. This measures how well the model's
+// clusters are separated without needing any ground-truth
+// labels. Scores range from -1 to 1, where values close
+// to 1 mean points are much closer to their own cluster
+// than to any other.
+//
+// For each training example i,
+//
+// s(i) = (b(i) - a(i)) / max(a(i), b(i))
+//
+// where a(i) is the mean distance (|x[i] - x[j]|^2) to
+// the other examples in its cluster and b(i) is the
+// minimum mean distance to the examples of any other
+// cluster.
+//
+// An error is returned if the model hasn't been trained
+// or has fewer than 2 non-empty clusters. Note that this
+// takes time quadratic in the number of examples.
+func (k *TriangleKMeans) SilhouetteScore() (float64, error) {
+	return silhouette(k.trainingSet, k.guesses, len(k.Centroids), k.distance)
+}
+
+// ClusterSizes returns the number of training
+// examples assigned to each centroid, so
+// ClusterSizes()[j] is the size of cluster j.
+func (k *TriangleKMeans) ClusterSizes() []int {
+	return clusterSizes(k.guesses, len(k.Centroids))
+}
+
+// ClusterDistortions returns the distortion within
+// each cluster (see Distortion,) which is
+//
+// ClusterDistortions()[j] = Σ |x[i] - μ[j]|^2
+// over all training examples with c[i] == j
+//
+// so they add up to Distortion(). Clusters with a
+// large distortion compared to their size are loose,
+// which might mean k is too small.
+func (k *TriangleKMeans) ClusterDistortions() []float64 {
+	return clusterDistortions(k.trainingSet, k.guesses, k.Centroids, k.distance)
+}
+
 // SaveClusteredData takes operates on a k-means
 // model, concatenating the given dataset with the
 // assigned class from clustering and saving it to
@@ -586,12 +721,27 @@ func (k *TriangleKMeans) Distortion() float64 {
 // Basically just a wrapper for the base.SaveDataToCSV
 // with the K-Means data.
 func (k *TriangleKMeans) SaveClusteredData(filepath string) error {
+	return base.SaveDataToCSV(filepath, k.trainingSet, k.floatGuesses(), true)
+}
+
+// SaveClusteredDataTo is the same as SaveClusteredData
+// but writes the clustered dataset to w instead of a
+// file, so you can send it anywhere (an HTTP response,
+// an in-memory buffer, etc.)
+func (k *TriangleKMeans) SaveClusteredDataTo(w io.Writer) error {
+	return base.WriteDataToCSV(w, k.trainingSet, k.floatGuesses(), true)
+}
+
+// floatGuesses returns the cluster assignments of
+// the training set as floats so they can be saved
+// along with it
+func (k *TriangleKMeans) floatGuesses() []float64 {
 	floatGuesses := []float64{}
 	for _, val := range k.guesses {
 		floatGuesses = append(floatGuesses, float64(val))
 	}
 
-	return base.SaveDataToCSV(filepath, k.trainingSet, floatGuesses, true)
+	return floatGuesses
 }
 
 // PersistToFile takes in an absolute filepath and saves the
@@ -647,3 +797,17 @@ func (k *TriangleKMeans) RestoreFromFile(path string) error {
 
 	return nil
 }
+
+// PersistToGob saves the model's centroids to the given
+// file like PersistToFile, but encoded with encoding/gob
+// (see base.PersistToGob.)
+func (k *TriangleKMeans) PersistToGob(path string) error {
+	return base.PersistToGob(path, k.Centroids)
+}
+
+// RestoreFromGob takes in a path to centroids saved with
+// PersistToGob and assigns the model's centroids to them,
+// like RestoreFromFile.
+func (k *TriangleKMeans) RestoreFromGob(path string) error {
+	return base.RestoreFromGob(path, &k.Centroids)
+}
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"testing"
@@ -90,8 +91,21 @@ func TestComputeCentroidDistanceMatrix1(t *testing.T) {
 	assert.InDeltaSlice(t, mins, model.minCentroidDist, 1e-6, "Differences in min centroid dist from expected should be small")
 }
 
+// recalculating the centroids shouldn't touch the
+// model's current centroids, otherwise the centroid
+// shifts used to update the bounds are always 0
+func TestTriangleKMeansRecalculateCentroidsShouldPass1(t *testing.T) {
+	model := NewTriangleKMeans(2, 2, [][]float64{{0, 0}, {0, 2}, {10, 0}, {10, 2}}, 42)
+	model.Centroids = [][]float64{{1, 1}, {9, 1}}
+	model.guesses = []int{0, 0, 1, 1}
+
+	centroids := model.recalculateCentroids()
+	assert.Equal(t, [][]float64{{0, 1}, {10, 1}}, centroids, "New centroids should be the mean of their examples")
+	assert.Equal(t, [][]float64{{1, 1}, {9, 1}}, model.Centroids, "Model's centroids shouldn't change")
+}
+
 func TestTriangleKMeansShouldPass1(t *testing.T) {
-	model := NewTriangleKMeans(4, 2, circles)
+	model := NewTriangleKMeans(4, 2, circles, 42)
 
 	assert.Nil(t, model.Learn(), "Learning error should be nil")
 
@@ -166,7 +180,7 @@ func TestTriangleKMeansShouldPass1(t *testing.T) {
 func TestTriangleKMeansShouldPass2(t *testing.T) {
 	norm := append([][]float64{}, circles...)
 	base.Normalize(norm)
-	model := NewTriangleKMeans(4, 2, norm)
+	model := NewTriangleKMeans(4, 2, norm, 42)
 
 	assert.Nil(t, model.Learn(), "Learning error should be nil")
 
@@ -292,13 +306,129 @@ func TestTriangleKMeansShouldPass3(t *testing.T) {
 
 //* Test Persistance *//
 
+func TestTriangleKMeansSilhouetteScoreShouldPass1(t *testing.T) {
+	x := make([][]float64, len(circles))
+	for i := range circles {
+		x[i] = append([]float64{}, circles[i]...)
+	}
+
+	model := NewTriangleKMeans(4, 2, x, 42)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	score, err := model.SilhouetteScore()
+	assert.Nil(t, err, "Silhouette score error should be nil")
+	assert.True(t, score > 0.9, "Silhouette score should be close to 1 for well separated clusters (currently %v)", score)
+	assert.True(t, score <= 1, "Silhouette score should never exceed 1")
+}
+
+func TestTriangleKMeansClusterSizesShouldPass1(t *testing.T) {
+	x := make([][]float64, len(circles))
+	for i := range circles {
+		x[i] = append([]float64{}, circles[i]...)
+	}
+
+	model := NewTriangleKMeans(4, 2, x, 42)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	// each of the 4 circles has the same
+	// number of points
+	sizes := model.ClusterSizes()
+	assert.Len(t, sizes, 4, "There should be a size for every cluster")
+	for j := range sizes {
+		assert.Equal(t, len(x)/4, sizes[j], "Cluster %v should hold one circle", j)
+	}
+
+	distortions := model.ClusterDistortions()
+	assert.Len(t, distortions, 4, "There should be a distortion for every cluster")
+
+	var sum float64
+	for j := range distortions {
+		assert.True(t, distortions[j] > 0, "Cluster distortion should be positive")
+		sum += distortions[j]
+	}
+	assert.InDelta(t, model.Distortion(), sum, 1e-6, "Cluster distortions should add up to the total distortion")
+}
+
+func TestTriangleKMeansSilhouetteScoreShouldFail1(t *testing.T) {
+	model := NewTriangleKMeans(4, 2, circles)
+	_, err := model.SilhouetteScore()
+	assert.NotNil(t, err, "Silhouette score error should not be nil")
+}
+
+// predicting with a model that has no centroids
+// should fail rather than panic
+func TestTriangleKMeansPredictShouldFail1(t *testing.T) {
+	// no centroids (ie. restored from an empty file)
+	model := &TriangleKMeans{}
+
+	_, err := model.Predict([]float64{1, 2})
+	assert.True(t, errors.Is(err, base.ErrNotTrained), "Prediction error should wrap base.ErrNotTrained - Given %v", err)
+
+	_, err = model.PredictBatch([][]float64{[]float64{1, 2}})
+	assert.True(t, errors.Is(err, base.ErrNotTrained), "Batch prediction error should wrap base.ErrNotTrained - Given %v", err)
+}
+
+// models with the same seed should cluster
+// identically
+func TestTriangleKMeansSeedShouldPass1(t *testing.T) {
+	// give each model its own copy of the data
+	// so training one can't affect the other
+	x1 := make([][]float64, len(circles))
+	x2 := make([][]float64, len(circles))
+	for i := range circles {
+		x1[i] = append([]float64{}, circles[i]...)
+		x2[i] = append([]float64{}, circles[i]...)
+	}
+
+	model1 := NewTriangleKMeans(4, 2, x1, 7)
+	model2 := NewTriangleKMeans(4, 2, x2, 7)
+
+	assert.Nil(t, model1.Learn(), "Learning error should be nil")
+	assert.Nil(t, model2.Learn(), "Learning error should be nil")
+
+	assert.Equal(t, model1.Centroids, model2.Centroids, "Centroids should match with the same seed")
+	assert.Equal(t, model1.Guesses(), model2.Guesses(), "Guesses should match with the same seed")
+}
+
+func TestTriangleKMeansDistanceShouldPass1(t *testing.T) {
+	x := make([][]float64, len(circles))
+	for i := range circles {
+		x[i] = append([]float64{}, circles[i]...)
+	}
+
+	model := NewTriangleKMeans(4, 2, x, 42)
+	model.Distance = base.EuclideanDistance
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	c1, err := model.Predict([]float64{-10, -10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c2, err := model.Predict([]float64{-10, 10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c3, err := model.Predict([]float64{10, -10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	c4, err := model.Predict([]float64{10, 10})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	classes := map[float64]bool{c1[0]: true, c2[0]: true, c3[0]: true, c4[0]: true}
+	assert.Len(t, classes, 4, "Each block should have its own cluster")
+
+	var expected float64
+	for i, guess := range model.Guesses() {
+		expected += base.EuclideanDistance(x[i], model.Centroids[guess])
+	}
+	assert.InDelta(t, expected, model.Distortion(), 1e-6, "Distortion should use the given distance measure")
+}
+
 func TestTriangleKMeansPersistToFileShouldPass1(t *testing.T) {
 	var wrong int
 	var count int
 	var c1, c2, c3, c4 []float64
 	var err error
 
-	model := NewTriangleKMeans(4, 10, gaussian)
+	model := NewTriangleKMeans(4, 10, gaussian, 42)
 
 	assert.Nil(t, model.Learn(), "Learning error should be nil")
 
@@ -4,7 +4,8 @@ go 1.17
 
 require (
 	github.com/stretchr/testify v1.7.0
-	golang.org/x/text v0.3.6
+	golang.org/x/text v0.3.7
+	gonum.org/v1/gonum v0.11.0
 )
 
 require (
@@ -0,0 +1,94 @@
+//go:build gonum
+// +build gonum
+
+package linear
+
+import (
+	"fmt"
+
+	"github.com/cdipaolo/goml/base"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// This file bridges the linear models to gonum
+// (https://www.gonum.org) matrices, so you can train
+// off of data you already have as a mat.Matrix and get
+// the parameter vector back as a mat.VecDense without
+// converting them by hand. It's only built with the
+// gonum build tag (go build -tags gonum) so the rest
+// of the package doesn't depend on gonum.
+
+// matToRows copies the rows of the matrix x and the
+// values of the vector y into the [][]float64 and
+// []float64 the models train off of, returning an
+// error if they don't have the same number of rows
+func matToRows(x mat.Matrix, y mat.Vector) ([][]float64, []float64, error) {
+	rows, cols := x.Dims()
+	if rows != y.Len() {
+		return nil, nil, fmt.Errorf("ERROR: the training set and expected results should have the same number of rows!\n\tRows of x: %v\n\tLength of y: %v\n", rows, y.Len())
+	}
+
+	trainingSet := make([][]float64, rows)
+	for i := range trainingSet {
+		trainingSet[i] = make([]float64, cols)
+		mat.Row(trainingSet[i], i, x)
+	}
+
+	expectedResults := make([]float64, rows)
+	for i := range expectedResults {
+		expectedResults[i] = y.AtVec(i)
+	}
+
+	return trainingSet, expectedResults, nil
+}
+
+// NewLeastSquaresFromMat is the same as NewLeastSquares,
+// but takes the training set as a gonum matrix (with a
+// row for each example) and the expected results as a
+// gonum vector. The data is copied, so changing x or y
+// later won't change the model.
+func NewLeastSquaresFromMat(method base.OptimizationMethod, alpha, regularization float64, maxIterations int, x mat.Matrix, y mat.Vector, features ...int) (*LeastSquares, error) {
+	trainingSet, expectedResults, err := matToRows(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLeastSquares(method, alpha, regularization, maxIterations, trainingSet, expectedResults, features...), nil
+}
+
+// ThetaVec returns a copy of the parameter vector θ
+// as a gonum vector, with the intercept first (an
+// empty vector if the model has no parameters yet.)
+func (l *LeastSquares) ThetaVec() *mat.VecDense {
+	if len(l.Parameters) == 0 {
+		return &mat.VecDense{}
+	}
+
+	return mat.NewVecDense(len(l.Parameters), append([]float64{}, l.Parameters...))
+}
+
+// NewLogisticFromMat is the same as NewLogistic, but
+// takes the training set as a gonum matrix (with a row
+// for each example) and the expected results as a gonum
+// vector. The data is copied, so changing x or y later
+// won't change the model.
+func NewLogisticFromMat(method base.OptimizationMethod, alpha, regularization float64, maxIterations int, x mat.Matrix, y mat.Vector, features ...int) (*Logistic, error) {
+	trainingSet, expectedResults, err := matToRows(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLogistic(method, alpha, regularization, maxIterations, trainingSet, expectedResults, features...), nil
+}
+
+// ThetaVec returns a copy of the parameter vector θ
+// as a gonum vector, with the intercept first (an
+// empty vector if the model has no parameters yet.)
+func (l *Logistic) ThetaVec() *mat.VecDense {
+	if len(l.Parameters) == 0 {
+		return &mat.VecDense{}
+	}
+
+	return mat.NewVecDense(len(l.Parameters), append([]float64{}, l.Parameters...))
+}
@@ -0,0 +1,68 @@
+//go:build gonum
+// +build gonum
+
+package linear
+
+import (
+	"testing"
+
+	"github.com/cdipaolo/goml/base"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/mat"
+)
+
+// learning off of gonum matrices should be the
+// same as learning off of the slices
+func TestLeastSquaresFromMatShouldPass1(t *testing.T) {
+	x := mat.NewDense(len(threeDLineX), 2, nil)
+	for i := range threeDLineX {
+		x.SetRow(i, threeDLineX[i])
+	}
+	y := mat.NewVecDense(len(threeDLineY), append([]float64{}, threeDLineY...))
+
+	model, err := NewLeastSquaresFromMat(base.BatchGA, 1e-4, 0, 800, x, y)
+	assert.Nil(t, err, "Constructor error should be nil")
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	expected := NewLeastSquares(base.BatchGA, 1e-4, 0, 800, threeDLineX, threeDLineY)
+	assert.Nil(t, expected.Learn(), "Learning error should be nil")
+
+	assert.Equal(t, expected.Parameters, model.Parameters, "Parameters should match learning off of slices")
+
+	theta := model.ThetaVec()
+	assert.Equal(t, len(model.Parameters), theta.Len(), "ThetaVec should have every parameter")
+	for j := range model.Parameters {
+		assert.Equal(t, model.Parameters[j], theta.AtVec(j), "ThetaVec should match the parameters")
+	}
+
+	// it's a copy
+	theta.SetVec(0, 1e6)
+	assert.NotEqual(t, 1e6, model.Parameters[0], "Changing ThetaVec shouldn't change the model")
+}
+
+func TestLogisticFromMatShouldPass1(t *testing.T) {
+	x := mat.NewDense(4, 1, []float64{-2, -1, 1, 2})
+	y := mat.NewVecDense(4, []float64{0, 0, 1, 1})
+
+	model, err := NewLogisticFromMat(base.BatchGA, 1e-1, 0, 500, x, y)
+	assert.Nil(t, err, "Constructor error should be nil")
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	guess, err := model.Predict([]float64{3})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.True(t, guess[0] > 0.5, "Prediction should be class 1")
+
+	assert.Equal(t, 2, model.ThetaVec().Len(), "ThetaVec should have every parameter")
+}
+
+func TestLeastSquaresFromMatShouldFail1(t *testing.T) {
+	x := mat.NewDense(3, 2, nil)
+	y := mat.NewVecDense(2, nil)
+
+	_, err := NewLeastSquaresFromMat(base.BatchGA, 1e-4, 0, 800, x, y)
+	assert.NotNil(t, err, "Constructor error should not be nil with mismatched rows")
+
+	_, err = NewLogisticFromMat(base.BatchGA, 1e-4, 0, 800, x, y)
+	assert.NotNil(t, err, "Constructor error should not be nil with mismatched rows")
+}
@@ -0,0 +1,71 @@
+package linear
+
+// intercept returns the number of constant terms
+// at the start of a parameter vector θ: 1 if the
+// model fits an intercept θ[0], and 0 if it doesn't
+func intercept(fitIntercept bool) int {
+	if fitIntercept {
+		return 1
+	}
+	return 0
+}
+
+// feature returns x[j] via Andrew Ng's terminology,
+// where the j-th feature lines up with θ[j]. If the
+// model fits an intercept then x[0] is the constant
+// term 1, otherwise it's just the first feature.
+func feature(x []float64, j int, fitIntercept bool) float64 {
+	if !fitIntercept {
+		return x[j]
+	}
+	if j == 0 {
+		return 1
+	}
+	return x[j-1]
+}
+
+// hypothesis evaluates the linear hypothesis θx
+// with parameter vector theta at x, including the
+// constant term θ[0] only if fitIntercept is true
+func hypothesis(theta []float64, x []float64, fitIntercept bool) float64 {
+	if !fitIntercept {
+		var sum float64
+		for i := range x {
+			sum += x[i] * theta[i]
+		}
+		return sum
+	}
+
+	// include constant term in sum
+	sum := theta[0]
+	for i := range x {
+		sum += x[i] * theta[i+1]
+	}
+
+	return sum
+}
+
+// sizeParameters returns the parameter vector θ
+// for a model with the given number of features.
+// The constructors always size θ with room for the
+// intercept, so if fitIntercept is false and θ still
+// has that extra term it's replaced by the zero
+// vector without it. Otherwise θ is returned as is.
+func sizeParameters(theta []float64, features int, fitIntercept bool) []float64 {
+	if !fitIntercept && len(theta) == features+1 {
+		return make([]float64, features)
+	}
+	return theta
+}
+
+// isZero returns whether every value of the
+// parameter vector θ is 0 (ie. it hasn't been
+// trained yet)
+func isZero(theta []float64) bool {
+	for i := range theta {
+		if theta[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -5,7 +5,9 @@
 //
 // Models implemented as of yet include:
 //     - Ordinary Least Squares
+//     - Multi-Output Least Squares
 //     - Logistic Regression
+//     - Poisson Regression
 //
 // General Usage:
 // Find the model you want to use. Then
@@ -51,11 +53,12 @@ package linear
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math"
+	"math/rand"
 	"os"
 
 	"github.com/cdipaolo/goml/base"
@@ -66,12 +69,15 @@ import (
 //
 // https://en.wikipedia.org/wiki/Least_squares
 //
-// The model uses gradient descent, NOT regular equations.
+// The model uses gradient descent by default, but can
+// also be fit exactly with the normal equations by calling
+// LearnNormalEquation instead of Learn.
 type LeastSquares struct {
 	// alpha and maxIterations are used only for
 	// GradientAscent during learning. If maxIterations
-	// is 0, then GradientAscent will run until the
-	// algorithm detects convergence.
+	// is 0, then a default maximum (250 iterations) is
+	// used. GradientAscent stops early once the
+	// algorithm detects convergence (see Tolerance.)
 	//
 	// regularization is used as the regularization
 	// term to avoid overfitting within regression.
@@ -86,14 +92,108 @@ type LeastSquares struct {
 	// the model
 	method base.OptimizationMethod
 
+	// batchSize is the number of examples used for each
+	// update when training with base.MiniBatchGA or
+	// base.Adam
+	batchSize int
+
+	// Adam holds the hyperparameters (β1, β2, and ε)
+	// used when training with base.Adam. If left nil
+	// the defaults from base.NewAdamOptimizer are used.
+	Adam *base.AdamOptimizer
+
+	// Schedule decays the learning rate α over the
+	// course of training (see base.LearningRateSchedule.)
+	// If left nil the learning rate stays constant, as it
+	// always does when learning online.
+	Schedule base.LearningRateSchedule
+
+	// Clipping limits the size of the gradient before
+	// every step of gradient ascent, scaling down huge
+	// gradients rather than letting learning diverge
+	// (see base.GradientClipping.) If left nil the
+	// gradient is never clipped.
+	Clipping *base.GradientClipping
+
+	// Shuffle is the source of randomness used to
+	// shuffle the order of the training set at the
+	// start of every iteration of base.StochasticGA
+	// (see base.Shuffled.) The constructor seeds it
+	// with base.DefaultShuffleSeed so training is
+	// reproducible. Set it to your own source to
+	// change the seed, or to nil to visit the
+	// examples in order.
+	Shuffle *rand.Rand
+
+	// Tolerance is used to detect convergence when
+	// training with base.BatchGA: learning stops once
+	// the parameter vector moves less than Tolerance
+	// (by the L2 norm) over an iteration. If left 0,
+	// base.DefaultTolerance is used, and a negative
+	// tolerance turns early stopping off.
+	Tolerance float64
+
+	// iterations is the number of iterations the
+	// last call to Learn actually went through
+	iterations int
+
+	// fitted is whether the parameter vector has been
+	// trained (or restored,) so Score knows whether
+	// the model has anything to score
+	fitted bool
+
+	// FitIntercept is whether the model fits the
+	// constant term θ[0] (the intercept.) Defaults
+	// to true. When false the model is forced
+	// through the origin and θ has one parameter
+	// per feature (so θ[j] lines up with x[j])
+	// instead of one extra. Set it before training:
+	// learning drops the intercept from the zero
+	// vector θ the constructor created.
+	FitIntercept bool
+
+	// RegularizationType is the penalty used along with
+	// the regularization term (base.L2 if left empty.)
+	// L1Ratio is the fraction of the regularization given
+	// to the L1 penalty when using base.ElasticNet (the
+	// rest goes to the L2 penalty.)
+	RegularizationType base.RegularizationType
+	L1Ratio            float64
+
+	// Loss is the loss function the model minimizes
+	// (base.SquaredLoss if left empty.) Delta is the
+	// threshold δ of base.HuberLoss, beyond which
+	// residuals only count linearly. See
+	// NewHuberRegression.
+	Loss  base.LossType
+	Delta float64
+
 	// trainingSet and expectedResults are the
 	// 'x', and 'y' of the data, expressed as
 	// vectors, that the model can optimize from
 	trainingSet     [][]float64
 	expectedResults []float64
 
+	// sampleWeights holds how much each training
+	// example counts towards the cost function
+	// (weighted least squares.) If nil, every
+	// example has a weight of 1
+	sampleWeights []float64
+
 	Parameters []float64 `json:"theta"`
 
+	// FeatureNames optionally names each feature
+	// (in the same order as x,) so errors from
+	// Predict can say which feature an input is
+	// missing. It's saved by PersistToFile and
+	// ExportSpec.
+	FeatureNames []string `json:"feature_names,omitempty"`
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
 	// Output is the io.Writer used for logging
 	// and printing. Defaults to os.Stdout.
 	Output io.Writer
@@ -154,10 +254,85 @@ func NewLeastSquares(method base.OptimizationMethod, alpha, regularization float
 		// the vector of all zeros)
 		Parameters: params,
 
+		FitIntercept: true,
+
+		Shuffle: rand.New(rand.NewSource(base.DefaultShuffleSeed)),
+
 		Output: os.Stdout,
 	}
 }
 
+// NewHuberRegression returns a pointer to a LeastSquares
+// model (see NewLeastSquares) which minimizes the Huber
+// loss rather than the squared error. The Huber loss is
+// quadratic for residuals within delta (δ) and linear
+// beyond it, so a few extreme outliers can't drag the
+// fit away from the rest of the data like they can with
+// ordinary least squares. δ is in the units of the
+// expected results: residuals smaller than δ are
+// treated as usual, and larger ones as outliers.
+//
+// The model can only be trained with Learn or
+// OnlineLearn because there is no closed form solution
+// for the Huber loss.
+//
+// Example Huber Regression (Batch GA):
+//
+//     // optimization method: Batch Gradient Ascent
+//     // Learning rate: 1e-4
+//     // Regularization term: 0
+//     // Huber threshold δ: 1
+//     // Max Iterations: 800
+//     // Dataset to learn from: testX
+//     // Expected results dataset: testY
+//     model := NewHuberRegression(base.BatchGA, 1e-4, 0, 1, 800, testX, testY)
+//
+//     err := model.Learn()
+//     if err != nil {
+//         panic("SOME ERROR!! RUN!")
+//     }
+func NewHuberRegression(method base.OptimizationMethod, alpha, regularization, delta float64, maxIterations int, trainingSet [][]float64, expectedResults []float64, features ...int) *LeastSquares {
+	model := NewLeastSquares(method, alpha, regularization, maxIterations, trainingSet, expectedResults, features...)
+	model.Loss = base.HuberLoss
+	model.Delta = delta
+
+	return model
+}
+
+// NewRidgeRegression returns a pointer to a LeastSquares
+// model with L2 regularization term lambda (λ) which has
+// already been fit to the training set, so you can Predict
+// with it right away. It's fit with the regularized normal
+// equations (see LearnNormalEquation)
+//
+//     θ = (XᵀX + λI)⁻¹Xᵀy
+//
+// where the intercept isn't penalized, so there is no
+// learning rate or iteration count to tune. The model's
+// optimization method is base.NormalEquation, so calling
+// Learn after updating the training set refits it the
+// same way.
+//
+// An error is returned (along with the unfit model) if
+// there is no data or XᵀX + λI is singular.
+//
+// Example Ridge Regression:
+//
+//     model, err := NewRidgeRegression(1.5, testX, testY)
+//     if err != nil {
+//         panic("SOME ERROR!! RUN!")
+//     }
+//
+//     guess, err = model.Predict([]float64{10000,6})
+//     if err != nil {
+//         panic("AAAARGGGH! SHIVER ME TIMBERS! THESE ROTTEN SCOUNDRELS FOUND AN ERROR!!!")
+//     }
+func NewRidgeRegression(lambda float64, trainingSet [][]float64, expectedResults []float64) (*LeastSquares, error) {
+	model := NewLeastSquares(base.NormalEquation, 0, lambda, 0, trainingSet, expectedResults)
+
+	return model, model.Learn()
+}
+
 // UpdateTrainingSet takes in a new training set (variable x)
 // as well as a new result set (y). This could be useful if
 // you want to retrain a model starting with the parameter
@@ -177,12 +352,76 @@ func (l *LeastSquares) UpdateTrainingSet(trainingSet [][]float64, expectedResult
 	return nil
 }
 
+// UpdateSampleWeights sets how much each example in the
+// training set counts towards the cost function J(θ) and
+// its derivatives, turning the model into weighted least
+// squares:
+//
+//     J(θ) = 1/2m Σ w[i](h(x[i]) - y[i])²
+//
+// Use this when some observations are more reliable than
+// others. There must be a weight for every training
+// example, and weights can't be negative. Passing nil
+// weights every example equally again.
+func (l *LeastSquares) UpdateSampleWeights(weights []float64) error {
+	if weights == nil {
+		l.sampleWeights = nil
+		return nil
+	}
+
+	if len(weights) != len(l.trainingSet) {
+		return fmt.Errorf("Error: length of given sample weights (%v) doesn't match the number of training examples (%v)!", len(weights), len(l.trainingSet))
+	}
+
+	for i := range weights {
+		if weights[i] < 0 || math.IsNaN(weights[i]) || math.IsInf(weights[i], 0) {
+			return fmt.Errorf("Error: sample weight %v (%v) should be a non-negative, finite number!", i, weights[i])
+		}
+	}
+
+	l.sampleWeights = weights
+
+	return nil
+}
+
+// SampleWeights returns the weight of each training
+// example set with UpdateSampleWeights (or nil if
+// every example is weighted equally.)
+func (l *LeastSquares) SampleWeights() []float64 {
+	return l.sampleWeights
+}
+
+// weight returns the sample weight of the i-th
+// training example
+func (l *LeastSquares) weight(i int) float64 {
+	if l.sampleWeights == nil {
+		return 1
+	}
+
+	return l.sampleWeights[i]
+}
+
 // UpdateLearningRate set's the learning rate of the model
 // to the given float64.
 func (l *LeastSquares) UpdateLearningRate(a float64) {
 	l.alpha = a
 }
 
+// UpdateBatchSize sets the number of examples used for
+// each update of the parameter vector when training with
+// base.MiniBatchGA or base.Adam. A batch size less than 1
+// uses the default of 32.
+func (l *LeastSquares) UpdateBatchSize(b int) {
+	l.batchSize = b
+}
+
+// BatchSize returns the number of examples used for each
+// update of the parameter vector when training with
+// base.MiniBatchGA or base.Adam.
+func (l *LeastSquares) BatchSize() int {
+	return l.batchSize
+}
+
 // LearningRate returns the learning rate α for gradient
 // descent to optimize the model. Could vary as a function
 // of something else later, potentially.
@@ -190,12 +429,48 @@ func (l *LeastSquares) LearningRate() float64 {
 	return l.alpha
 }
 
+// LearningRateSchedule returns the schedule the learning
+// rate decays with during training (nil for a constant
+// learning rate.) This lets the optimization methods in
+// base use it (see base.Scheduled.)
+func (l *LeastSquares) LearningRateSchedule() base.LearningRateSchedule {
+	return l.Schedule
+}
+
+// GradientClipping returns the limits the gradient is
+// clipped to during training (nil if it isn't clipped.)
+// This lets the optimization methods in base use them
+// (see base.Clipped.)
+func (l *LeastSquares) GradientClipping() *base.GradientClipping {
+	return l.Clipping
+}
+
+// ShuffleSource returns the source of randomness the
+// training set is shuffled with during stochastic
+// gradient ascent (nil if it isn't shuffled.) This lets
+// the optimization methods in base use it (see
+// base.Shuffled.)
+func (l *LeastSquares) ShuffleSource() *rand.Rand {
+	return l.Shuffle
+}
+
 // Examples returns the number of training examples (m)
 // that the model currently is training from.
 func (l *LeastSquares) Examples() int {
 	return len(l.trainingSet)
 }
 
+// Features returns the number of features the model
+// takes as input (the length of x given to Predict,)
+// not counting the intercept
+func (l *LeastSquares) Features() int {
+	if len(l.Parameters) == 0 {
+		return 0
+	}
+
+	return len(l.Parameters) - intercept(l.FitIntercept)
+}
+
 // MaxIterations returns the number of maximum iterations
 // the model will go through in GradientAscent, in the
 // worst case
@@ -203,6 +478,25 @@ func (l *LeastSquares) MaxIterations() int {
 	return l.maxIterations
 }
 
+// Iterations returns the number of iterations the
+// last call to Learn went through before converging
+// (or reaching the maximum number of iterations) when
+// training with base.BatchGA.
+func (l *LeastSquares) Iterations() int {
+	return l.iterations
+}
+
+// tolerance returns the tolerance used to detect
+// convergence, using base.DefaultTolerance if the
+// model's Tolerance is left as 0
+func (l *LeastSquares) tolerance() float64 {
+	if l.Tolerance == 0 {
+		return base.DefaultTolerance
+	}
+
+	return l.Tolerance
+}
+
 // Predict takes in a variable x (an array of floats,) and
 // finds the value of the hypothesis function given the
 // current parameter vector θ
@@ -212,63 +506,176 @@ func (l *LeastSquares) MaxIterations() int {
 // you trained off of normalized inputs and are feeding
 // an un-normalized input
 func (l *LeastSquares) Predict(x []float64, normalize ...bool) ([]float64, error) {
-	if len(x)+1 != len(l.Parameters) {
-		return nil, fmt.Errorf("Error: Parameter vector should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n", len(x), len(l.Parameters))
+	if len(x)+intercept(l.FitIntercept) != len(l.Parameters) {
+		return nil, fmt.Errorf("Error: %w: Parameter vector should be %v longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n%v", base.ErrDimensionMismatch, intercept(l.FitIntercept), len(x), len(l.Parameters), base.FeatureMismatch(l.FeatureNames, len(x)))
 	}
 
 	if len(normalize) != 0 && normalize[0] {
-		base.NormalizePoint(x)
+		x = base.NormalizedPoint(x)
 	}
 
-	// include constant term in sum
-	sum := l.Parameters[0]
-
-	for i := range x {
-		sum += x[i] * l.Parameters[i+1]
-	}
+	sum := hypothesis(l.Parameters, x, l.FitIntercept)
 
 	return []float64{sum}, nil
 }
 
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (l *LeastSquares) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	return base.PredictBatch(x, len(l.Parameters)-intercept(l.FitIntercept), func(row []float64) ([]float64, error) {
+		return l.Predict(row, normalize...)
+	})
+}
+
 // Learn takes the struct's dataset and expected results and runs
 // batch gradient descent on them, optimizing theta so you can
 // predict based on those results
+//
+// If the model's optimization method is base.NormalEquation
+// then this is the same as calling LearnNormalEquation.
 func (l *LeastSquares) Learn() error {
+	if l.method == base.NormalEquation {
+		return l.LearnNormalEquation()
+	}
+
 	if l.trainingSet == nil || l.expectedResults == nil {
 		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		fmt.Fprintf(l.Output, err.Error())
+		l.logf(err.Error())
 		return err
 	}
 
 	examples := len(l.trainingSet)
 	if examples == 0 || len(l.trainingSet[0]) == 0 {
 		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		fmt.Fprintf(l.Output, err.Error())
+		l.logf(err.Error())
 		return err
 	}
 	if len(l.expectedResults) == 0 {
 		err := fmt.Errorf("ERROR: Attempting to learn with no expected results! This isn't an unsupervised model!! You'll need to include data before you learn :)\n")
-		fmt.Fprintf(l.Output, err.Error())
+		l.logf(err.Error())
+		return err
+	}
+	if l.sampleWeights != nil && len(l.sampleWeights) != examples {
+		err := fmt.Errorf("ERROR: Number of sample weights (%v) doesn't match the number of training examples (%v)!\n", len(l.sampleWeights), examples)
+		l.logf(err.Error())
+		return err
+	}
+
+	if l.Loss == base.HuberLoss && l.Delta <= 0 {
+		err := fmt.Errorf("ERROR: The Huber loss threshold δ must be positive! Given %v\n", l.Delta)
+		l.logf(err.Error())
 		return err
 	}
 
-	fmt.Fprintf(l.Output, "Training:\n\tModel: Logistic (Binary) Classification\n\tOptimization Method: %v\n\tTraining Examples: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", l.method, examples, len(l.trainingSet[0]), l.alpha, l.regularization)
+	l.Parameters = sizeParameters(l.Parameters, len(l.trainingSet[0]), l.FitIntercept)
+
+	l.logf("Training:\n\tModel: Logistic (Binary) Classification\n\tOptimization Method: %v\n\tTraining Examples: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", l.method, examples, len(l.trainingSet[0]), l.alpha, l.regularization)
 
 	var err error
 	if l.method == base.BatchGA {
-		err = base.GradientAscent(l)
+		l.iterations, err = base.ParallelGradientAscentWithTolerance(l, l.tolerance())
+		l.logf("Went through %v iterations.\n", l.iterations)
 	} else if l.method == base.StochasticGA {
 		err = base.StochasticGradientAscent(l)
+	} else if l.method == base.MiniBatchGA {
+		err = base.MiniBatchGradientAscent(l, l.batchSize)
+	} else if l.method == base.Adam {
+		err = base.AdamAscent(l, l.batchSize, l.Adam)
 	} else {
 		err = fmt.Errorf("Chose a training method not implemented for LeastSquares regression")
 	}
 
 	if err != nil {
-		fmt.Fprintf(l.Output, "\nERROR: Error while learning –\n\t%v\n\n", err)
+		l.logf("\nERROR: Error while learning –\n\t%v\n\n", err)
 		return err
 	}
 
-	fmt.Fprintf(l.Output, "Training Completed.\n%v\n\n", l)
+	l.fitted = true
+
+	l.logf("Training Completed.\n%v\n\n", l)
+	return nil
+}
+
+// LearnNormalEquation takes the struct's dataset and expected
+// results and solves for the optimal parameter vector θ
+// directly using the normal equations rather than iterating
+// with gradient ascent:
+//
+//     θ = (XᵀX + λI)⁻¹Xᵀy
+//
+// where X is the training set with the constant term 1
+// prepended to each row and λ is the model's regularization
+// term (the constant term is not regularized.) Only L2
+// regularization is supported because the L1 penalty has
+// no closed form solution. This is
+// usually much faster than gradient ascent for small to
+// medium sized datasets, but needs to invert a square matrix
+// with one row per feature, so it won't scale well to very
+// many features.
+//
+// An error is returned if XᵀX + λI is singular (for example
+// when one feature is a multiple of another and there is no
+// regularization.)
+func (l *LeastSquares) LearnNormalEquation() error {
+	if l.trainingSet == nil || l.expectedResults == nil {
+		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
+		l.logf(err.Error())
+		return err
+	}
+
+	examples := len(l.trainingSet)
+	if examples == 0 || len(l.trainingSet[0]) == 0 {
+		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
+		l.logf(err.Error())
+		return err
+	}
+	if len(l.expectedResults) != examples {
+		err := fmt.Errorf("ERROR: Number of expected results (%v) doesn't match the number of training examples (%v)!\n", len(l.expectedResults), examples)
+		l.logf(err.Error())
+		return err
+	}
+	if l.sampleWeights != nil && len(l.sampleWeights) != examples {
+		err := fmt.Errorf("ERROR: Number of sample weights (%v) doesn't match the number of training examples (%v)!\n", len(l.sampleWeights), examples)
+		l.logf(err.Error())
+		return err
+	}
+
+	if l.RegularizationType == base.L1 || l.RegularizationType == base.ElasticNet {
+		err := fmt.Errorf("ERROR: The normal equations only have a closed form solution with L2 regularization! Use Learn for %v regularization\n", l.RegularizationType)
+		l.logf(err.Error())
+		return err
+	}
+	if l.Loss == base.HuberLoss {
+		err := fmt.Errorf("ERROR: The normal equations only have a closed form solution for the squared loss! Use Learn for the %v\n", l.Loss)
+		l.logf(err.Error())
+		return err
+	}
+
+	l.logf("Training:\n\tModel: Ordinary Least Squares Regression\n\tOptimization Method: Normal Equations\n\tTraining Examples: %v\n\tFeatures: %v\n\tRegularization Parameter λ: %v\n...\n\n", examples, len(l.trainingSet[0]), l.regularization)
+
+	l.Parameters = sizeParameters(l.Parameters, len(l.trainingSet[0]), l.FitIntercept)
+
+	xTx, xTy := normalMatrix(l.trainingSet, l.expectedResults, l.sampleWeights, l.regularization, l.FitIntercept)
+
+	inverse, err := invert(xTx)
+	if err != nil {
+		err = fmt.Errorf("ERROR: Can't solve the normal equations because XᵀX + λI is singular. Try adding regularization or removing redundant features.\n\t%v", err)
+		l.logf("\nERROR: Error while learning –\n\t%v\n\n", err)
+		return err
+	}
+
+	l.Parameters = matVec(inverse, xTy)
+	l.fitted = true
+
+	l.logf("Training Completed.\n%v\n\n", l)
 	return nil
 }
 
@@ -366,6 +773,16 @@ func (l *LeastSquares) Learn() error {
 //         panic("AAAARGGGH! SHIVER ME TIMBERS! THESE ROTTEN SCOUNDRELS FOUND AN ERROR!!!")
 //     }
 func (l *LeastSquares) OnlineLearn(errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	l.OnlineLearnContext(context.Background(), errors, dataset, onUpdate, normalize...)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// dataset channel is still open. This lets you stop
+// training, ie. when shutting down a server, without
+// having to close the dataset yourself.
+func (l *LeastSquares) OnlineLearnContext(ctx context.Context, errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
 	if errors == nil {
 		errors = make(chan error)
 	}
@@ -375,17 +792,30 @@ func (l *LeastSquares) OnlineLearn(errors chan error, dataset chan base.Datapoin
 		return
 	}
 
-	fmt.Fprintf(l.Output, "Training:\n\tModel: Ordinary Least Squares Regression\n\tOptimization Method: Online Stochastic Gradient Descent\n\tFeatures: %v\n\tLearning Rate α: %v\n...\n\n", len(l.Parameters), l.alpha)
+	l.logf("Training:\n\tModel: Ordinary Least Squares Regression\n\tOptimization Method: Online Stochastic Gradient Descent\n\tFeatures: %v\n\tLearning Rate α: %v\n...\n\n", len(l.Parameters), l.alpha)
 
 	var point base.Datapoint
 	var more bool
 
 	for {
-		point, more = <-dataset
+		select {
+		case <-ctx.Done():
+			l.logf("Training Cancelled.\n%v\n\n", l)
+			close(errors)
+			return
+		case point, more = <-dataset:
+		}
 
 		if more {
 			if len(point.Y) != 1 {
 				errors <- fmt.Errorf("ERROR: point.Y must have a length of 1. Point: %v", point)
+				continue
+			}
+
+			// drop the intercept from the constructor's
+			// zero vector before the first update
+			if isZero(l.Parameters) {
+				l.Parameters = sizeParameters(l.Parameters, len(point.X), l.FitIntercept)
 			}
 
 			newTheta := make([]float64, len(l.Parameters))
@@ -404,23 +834,18 @@ func (l *LeastSquares) OnlineLearn(errors chan error, dataset chan base.Datapoin
 
 					// account for constant term
 					// x is x[i][j] via Andrew Ng's terminology
-					var x float64
-					if j == 0 {
-						x = 1
-					} else {
-						x = point.X[j-1]
-					}
+					x := feature(point.X, j, l.FitIntercept)
 
 					var gradient float64
-					gradient = (point.Y[0] - prediction[0]) * x
+					gradient = lossGradient(l.Loss, l.Delta, point.Y[0]-prediction[0]) * x
 
-					// add in the regularization term
-					// λ*θ[j]
+					// apply the regularization term
+					// (λ*θ[j] for L2 regularization)
 					//
 					// notice that we don't count the
 					// constant term
-					if j != 0 {
-						gradient += l.regularization * l.Parameters[j]
+					if !l.FitIntercept || j != 0 {
+						gradient = regularize(l.RegularizationType, l.L1Ratio, l.regularization, l.Parameters[j], gradient)
 					}
 
 					return gradient, nil
@@ -430,44 +855,57 @@ func (l *LeastSquares) OnlineLearn(errors chan error, dataset chan base.Datapoin
 					continue
 				}
 
-				newTheta[j] = l.Parameters[j] + l.alpha*dj
+				newTheta[j] = l.Prox(j, l.Parameters[j]+l.alpha*dj, l.alpha)
 			}
 
 			// now simultaneously update Theta
 			for j := range l.Parameters {
 				newθ := newTheta[j]
 				if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
-					errors <- fmt.Errorf("Sorry! Learning diverged. Some value of the parameter vector theta is ±Inf or NaN")
+					errors <- fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
 					continue
 				}
 				l.Parameters[j] = newθ
 			}
+			l.fitted = true
 
 			go onUpdate([][]float64{l.Parameters})
 
 		} else {
-			fmt.Fprintf(l.Output, "Training Completed.\n%v\n\n", l)
+			l.logf("Training Completed.\n%v\n\n", l)
 			close(errors)
 			return
 		}
 	}
 }
 
+// logf prints training progress to Output
+// when the model is Verbose
+func (l *LeastSquares) logf(format string, a ...interface{}) {
+	if l.Verbose {
+		fmt.Fprintf(l.Output, format, a...)
+	}
+}
+
 // String implements the fmt interface for clean printing. Here
 // we're using it to print the model as the equation h(θ)=...
 // where h is the linear hypothesis model
 func (l *LeastSquares) String() string {
-	features := len(l.Parameters) - 1
+	offset := intercept(l.FitIntercept)
+	features := len(l.Parameters) - offset
 	if len(l.Parameters) == 0 {
-		fmt.Fprintf(l.Output, "ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
+		l.logf("ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
 	}
 	var buffer bytes.Buffer
 
-	buffer.WriteString(fmt.Sprintf("h(θ,x) = %.3f + ", l.Parameters[0]))
+	buffer.WriteString("h(θ,x) = ")
+	if l.FitIntercept {
+		buffer.WriteString(fmt.Sprintf("%.3f + ", l.Parameters[0]))
+	}
 
 	length := features + 1
 	for i := 1; i < length; i++ {
-		buffer.WriteString(fmt.Sprintf("%.5f(x[%d])", l.Parameters[i], i))
+		buffer.WriteString(fmt.Sprintf("%.5f(x[%d])", l.Parameters[i-1+offset], i))
 
 		if i != features {
 			buffer.WriteString(fmt.Sprintf(" + "))
@@ -496,26 +934,78 @@ func (l *LeastSquares) Dj(j int) (float64, error) {
 
 		// account for constant term
 		// x is x[i][j] via Andrew Ng's terminology
-		var x float64
-		if j == 0 {
-			x = 1
-		} else {
-			x = l.trainingSet[i][j-1]
+		x := feature(l.trainingSet[i], j, l.FitIntercept)
+
+		sum += l.weight(i) * lossGradient(l.Loss, l.Delta, l.expectedResults[i]-prediction[0]) * x
+	}
+
+	return l.RegularizeDj(j, sum), nil
+}
+
+// PartialDj returns the derivative of the cost function
+// J(θ) with respect to every parameter of the hypothesis,
+// summed only over the training examples x[start] through
+// x[end-1] and without regularization. The training set
+// is split up this way to compute the gradient in parallel
+// (see base.ParallelGradientAscent.)
+func (l *LeastSquares) PartialDj(start, end int) ([]float64, error) {
+	if start < 0 || end > len(l.trainingSet) || start > end {
+		return nil, fmt.Errorf("Range [%v,%v) would index out of the bounds of the training set data (len: %v)", start, end, len(l.trainingSet))
+	}
+
+	sum := make([]float64, len(l.Parameters))
+
+	for i := start; i < end; i++ {
+		prediction, err := l.Predict(l.trainingSet[i])
+		if err != nil {
+			return nil, err
 		}
 
-		sum += (l.expectedResults[i] - prediction[0]) * x
+		diff := l.weight(i) * lossGradient(l.Loss, l.Delta, l.expectedResults[i]-prediction[0])
+
+		// account for constant term
+		if l.FitIntercept {
+			sum[0] += diff
+		}
+		offset := intercept(l.FitIntercept)
+		for j := range l.trainingSet[i] {
+			sum[j+offset] += diff * l.trainingSet[i][j]
+		}
 	}
 
-	// add in the regularization term
-	// λ*θ[j]
+	return sum, nil
+}
+
+// RegularizeDj applies the regularization term to the
+// derivative of the cost function J(θ) with respect to
+// θ[j] (over the whole training set,) returning the
+// regularized derivative.
+func (l *LeastSquares) RegularizeDj(j int, dj float64) float64 {
+	// apply the regularization term
+	// (λ*θ[j] for L2 regularization)
 	//
 	// notice that we don't count the
 	// constant term
-	if j != 0 {
-		sum += l.regularization * l.Parameters[j]
+	if !l.FitIntercept || j != 0 {
+		dj = regularize(l.RegularizationType, l.L1Ratio, l.regularization, l.Parameters[j], dj)
 	}
 
-	return sum, nil
+	return dj
+}
+
+// Prox applies the L1 part of the regularization
+// penalty to θ[j] after a step of gradient ascent was
+// taken to theta with learning rate alpha, returning
+// the new θ[j]. This lets the optimization methods in
+// base apply it (see base.Proximal.)
+func (l *LeastSquares) Prox(j int, theta, alpha float64) float64 {
+	// notice that we don't count the
+	// constant term
+	if l.FitIntercept && j == 0 {
+		return theta
+	}
+
+	return shrink(l.RegularizationType, l.L1Ratio, l.regularization, alpha, theta)
 }
 
 // Dij returns the derivative of the cost function
@@ -535,23 +1025,18 @@ func (l *LeastSquares) Dij(i int, j int) (float64, error) {
 
 	// account for constant term
 	// x is x[i][j] via Andrew Ng's terminology
-	var x float64
-	if j == 0 {
-		x = 1
-	} else {
-		x = l.trainingSet[i][j-1]
-	}
+	x := feature(l.trainingSet[i], j, l.FitIntercept)
 
 	var gradient float64
-	gradient = (l.expectedResults[i] - prediction[0]) * x
+	gradient = l.weight(i) * lossGradient(l.Loss, l.Delta, l.expectedResults[i]-prediction[0]) * x
 
-	// add in the regularization term
-	// λ*θ[j]
+	// apply the regularization term
+	// (λ*θ[j] for L2 regularization)
 	//
 	// notice that we don't count the
 	// constant term
-	if j != 0 {
-		gradient += l.regularization * l.Parameters[j]
+	if !l.FitIntercept || j != 0 {
+		gradient = regularize(l.RegularizationType, l.L1Ratio, l.regularization, l.Parameters[j], gradient)
 	}
 
 	return gradient, nil
@@ -568,19 +1053,178 @@ func (l *LeastSquares) J() (float64, error) {
 			return 0, err
 		}
 
-		sum += (l.expectedResults[i] - prediction[0]) * (l.expectedResults[i] - prediction[0])
+		sum += l.weight(i) * loss(l.Loss, l.Delta, l.expectedResults[i]-prediction[0])
 	}
 
 	// add regularization term!
 	//
 	// notice that the constant term doesn't matter
-	for i := 1; i < len(l.Parameters); i++ {
-		sum += l.regularization * l.Parameters[i] * l.Parameters[i]
+	for i := intercept(l.FitIntercept); i < len(l.Parameters); i++ {
+		sum += penalty(l.RegularizationType, l.L1Ratio, l.regularization, l.Parameters[i])
 	}
 
 	return sum / float64(2*len(l.trainingSet)), nil
 }
 
+// Score returns the coefficient of determination R²
+// of the model over its own training set, which is
+//
+//     R² = 1 - SS_res/SS_tot
+//
+// where SS_res is the sum of squared residuals of the
+// model's predictions and SS_tot is the total sum of
+// squares of the expected results about their mean.
+// A perfect fit has an R² of 1, while a model that
+// always predicts the mean has an R² of 0 (worse
+// models can go negative.)
+//
+// Call this after training the model with Learn or
+// LearnNormalEquation; until then an error wrapping
+// base.ErrNotTrained is returned. See ScoreOn to score
+// the model against a held-out set.
+func (l *LeastSquares) Score() (float64, error) {
+	return l.ScoreOn(l.trainingSet, l.expectedResults)
+}
+
+// ScoreOn returns the coefficient of determination R²
+// (see Score) of the model over the given dataset and
+// expected results rather than the training set. This
+// is useful for evaluating the model on data it wasn't
+// trained on.
+//
+// An error is returned if the model hasn't been
+// trained (wrapping base.ErrNotTrained,) if the dataset
+// is empty, if x and y have different lengths, if the expected results
+// have no variance (R² is undefined,) or if the model
+// can't predict on the data (ie. the parameter vector
+// hasn't been trained for this many features.)
+func (l *LeastSquares) ScoreOn(x [][]float64, y []float64) (float64, error) {
+	if !l.fitted {
+		return 0, fmt.Errorf("ERROR: %w: Attempting to score a model that hasn't been trained! Call Learn or LearnNormalEquation first\n", base.ErrNotTrained)
+	}
+	if len(x) == 0 || len(y) == 0 {
+		return 0, fmt.Errorf("ERROR: Attempting to score with no examples!\n")
+	}
+	if len(x) != len(y) {
+		return 0, fmt.Errorf("ERROR: Dataset and expected results should be the same length!\n\tLength of x given: %v\n\tLength of y given: %v\n", len(x), len(y))
+	}
+
+	var mean float64
+	for i := range y {
+		mean += y[i]
+	}
+	mean /= float64(len(y))
+
+	var ssRes, ssTot float64
+	for i := range x {
+		prediction, err := l.Predict(x[i])
+		if err != nil {
+			return 0, err
+		}
+
+		ssRes += (y[i] - prediction[0]) * (y[i] - prediction[0])
+		ssTot += (y[i] - mean) * (y[i] - mean)
+	}
+
+	if ssTot == 0 {
+		return 0, fmt.Errorf("ERROR: Expected results have no variance, so R² is undefined!\n")
+	}
+
+	return 1 - ssRes/ssTot, nil
+}
+
+// StandardErrors returns the standard error of each
+// parameter θ[j] of the trained model, which is the
+// square root of the j-th diagonal entry of the
+// covariance matrix
+//
+//     σ²(XᵀX)⁻¹
+//
+// where X is the training set (with the constant term
+// 1 prepended to each row if the model fits an
+// intercept) and σ² = Σ(y - h(θ,x))²/(m - n) is the
+// residual variance, with m training examples and n
+// parameters. If the model has sample weights then
+// XᵀWX and the weighted residuals are used instead.
+//
+// This is the classic estimate for ordinary least
+// squares, so it assumes the model was fit without
+// regularization (ie. with LearnNormalEquation or
+// gradient ascent run to convergence.) See TStatistics
+// to find which coefficients are significant.
+//
+// An error is returned if the model is underdetermined
+// (there aren't more training examples than parameters)
+// or if XᵀX is singular.
+func (l *LeastSquares) StandardErrors() ([]float64, error) {
+	examples := len(l.trainingSet)
+	if examples == 0 || len(l.trainingSet[0]) == 0 {
+		return nil, fmt.Errorf("ERROR: Attempting to find standard errors with no training examples!\n")
+	}
+	if len(l.expectedResults) != examples {
+		return nil, fmt.Errorf("ERROR: Number of expected results (%v) doesn't match the number of training examples (%v)!\n", len(l.expectedResults), examples)
+	}
+	if l.sampleWeights != nil && len(l.sampleWeights) != examples {
+		return nil, fmt.Errorf("ERROR: Number of sample weights (%v) doesn't match the number of training examples (%v)!\n", len(l.sampleWeights), examples)
+	}
+
+	params := len(l.Parameters)
+	if examples <= params {
+		return nil, fmt.Errorf("ERROR: Model is underdetermined! Need more training examples (%v) than parameters (%v) to find standard errors\n", examples, params)
+	}
+
+	var rss float64
+	for i := range l.trainingSet {
+		prediction, err := l.Predict(l.trainingSet[i])
+		if err != nil {
+			return nil, err
+		}
+
+		residual := l.expectedResults[i] - prediction[0]
+		rss += l.weight(i) * residual * residual
+	}
+	variance := rss / float64(examples-params)
+
+	xTx, _ := normalMatrix(l.trainingSet, l.expectedResults, l.sampleWeights, 0, l.FitIntercept)
+	inverse, err := invert(xTx)
+	if err != nil {
+		return nil, fmt.Errorf("ERROR: Can't find standard errors because XᵀX is singular. Try removing redundant features.\n\t%v", err)
+	}
+
+	se := make([]float64, params)
+	for j := range se {
+		se[j] = math.Sqrt(variance * inverse[j][j])
+	}
+
+	return se, nil
+}
+
+// TStatistics returns the t-statistic θ[j]/SE(θ[j])
+// of each parameter of the trained model, where SE is
+// the standard error from StandardErrors. The larger
+// a t-statistic is in magnitude, the more significant
+// the coefficient is (compare against the Student's t
+// distribution with m - n degrees of freedom, or
+// roughly ±2 for a 95% confidence level with a decent
+// amount of data.)
+//
+// The same errors as StandardErrors are returned. A
+// parameter with a standard error of 0 (a perfect fit)
+// gets a t-statistic of ±Inf.
+func (l *LeastSquares) TStatistics() ([]float64, error) {
+	se, err := l.StandardErrors()
+	if err != nil {
+		return nil, err
+	}
+
+	t := make([]float64, len(se))
+	for j := range t {
+		t[j] = l.Parameters[j] / se[j]
+	}
+
+	return t, nil
+}
+
 // Theta returns the parameter vector θ for use in persisting
 // the model, and optimizing the model through gradient descent
 // ( or other methods like Newton's Method)
@@ -590,6 +1234,7 @@ func (l *LeastSquares) Theta() []float64 {
 
 // PersistToFile takes in an absolute filepath and saves the
 // parameter vector θ to the file, which can be restored later.
+// If the model has FeatureNames they're saved along with θ.
 // The function will take paths from the current directory, but
 // functions
 //
@@ -601,7 +1246,7 @@ func (l *LeastSquares) PersistToFile(path string) error {
 		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
 	}
 
-	bytes, err := json.Marshal(l.Parameters)
+	bytes, err := base.MarshalParameters(l.Parameters, l.FeatureNames)
 	if err != nil {
 		return err
 	}
@@ -616,7 +1261,7 @@ func (l *LeastSquares) PersistToFile(path string) error {
 
 // RestoreFromFile takes in a path to a parameter vector theta
 // and assigns the model it's operating on's parameter vector
-// to that.
+// to that, along with the FeatureNames saved with it (if any.)
 //
 // The path must ba an absolute path or a path from the current
 // directory
@@ -634,10 +1279,49 @@ func (l *LeastSquares) RestoreFromFile(path string) error {
 		return err
 	}
 
-	err = json.Unmarshal(bytes, &l.Parameters)
+	theta, names, err := base.UnmarshalParameters(bytes)
+	if err != nil {
+		return err
+	}
+
+	err = base.CheckFeatureNames(names, len(theta)-intercept(l.FitIntercept))
 	if err != nil {
 		return err
 	}
 
+	l.Parameters = theta
+	l.FeatureNames = names
+	l.fitted = true
+
+	return nil
+}
+
+// PersistToGob saves the parameter vector θ (and the
+// FeatureNames, if any) to the given file like
+// PersistToFile, but encoded with encoding/gob (see
+// base.PersistToGob.)
+func (l *LeastSquares) PersistToGob(path string) error {
+	return base.PersistParametersToGob(path, l.Parameters, l.FeatureNames)
+}
+
+// RestoreFromGob takes in a path to a parameter vector
+// saved with PersistToGob and assigns the model's
+// parameter vector and FeatureNames to it, like
+// RestoreFromFile.
+func (l *LeastSquares) RestoreFromGob(path string) error {
+	theta, names, err := base.RestoreParametersFromGob(path)
+	if err != nil {
+		return err
+	}
+
+	err = base.CheckFeatureNames(names, len(theta)-intercept(l.FitIntercept))
+	if err != nil {
+		return err
+	}
+
+	l.Parameters = theta
+	l.FeatureNames = names
+	l.fitted = true
+
 	return nil
 }
@@ -1,10 +1,16 @@
 package linear
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"os"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/cdipaolo/goml/base"
 
@@ -26,6 +32,9 @@ var normY []float64
 var noisyX [][]float64
 var noisyY []float64
 
+var sparseX [][]float64
+var sparseY []float64
+
 func init() {
 
 	// create the /tmp/.goml/ dir for persistance testing
@@ -85,6 +94,16 @@ func init() {
 	}
 	// save the random data to make some nice plots!
 	base.SaveDataToCSV("/tmp/.goml/noisy_linear.csv", noisyX, noisyY, true)
+
+	// the line y = 3 + 2x[0] where x[1], x[2], and x[3]
+	// are random features irrelevant to the result
+	sparseX = [][]float64{}
+	sparseY = []float64{}
+	for i := 0; i < 100; i++ {
+		x := []float64{rand.Float64()*2 - 1, rand.Float64()*2 - 1, rand.Float64()*2 - 1, rand.Float64()*2 - 1}
+		sparseX = append(sparseX, x)
+		sparseY = append(sparseY, 3+2*x[0]+(rand.Float64()-0.5)/100)
+	}
 }
 
 // test y=3
@@ -273,6 +292,32 @@ func TestInclinedLineShouldPass1(t *testing.T) {
 }
 
 // same as above but with StochasticGA
+// learning should stop once θ converges rather
+// than going through every iteration
+func TestInclinedLineConvergenceShouldPass1(t *testing.T) {
+	var err error
+
+	model := NewLeastSquares(base.BatchGA, .0001, 0, 100000, increasingX, increasingY)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	assert.True(t, model.Iterations() > 0, "Model should have gone through at least one iteration")
+	assert.True(t, model.Iterations() < 100000, "Model should have converged before the maximum number of iterations - went through %v", model.Iterations())
+
+	for i := -20; i < 20; i++ {
+		guess, err := model.Predict([]float64{float64(i)})
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.InDelta(t, i, guess[0], 1e-2, "Guess should be really close to input (within 1e-2) for y=x")
+	}
+
+	// a negative tolerance turns early stopping off
+	model = NewLeastSquares(base.BatchGA, .0001, 0, 500, increasingX, increasingY)
+	model.Tolerance = -1
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.Equal(t, 500, model.Iterations(), "Model should go through every iteration without early stopping")
+}
+
 func TestInclinedLineShouldPass2(t *testing.T) {
 	var err error
 
@@ -290,6 +335,44 @@ func TestInclinedLineShouldPass2(t *testing.T) {
 	}
 }
 
+// same as above but with MiniBatchGA
+func TestInclinedLineShouldPass3(t *testing.T) {
+	var err error
+
+	model := NewLeastSquares(base.MiniBatchGA, .001, 0, 500, increasingX, increasingY)
+	model.UpdateBatchSize(5)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var guess []float64
+
+	for i := -20; i < 20; i++ {
+		guess, err = model.Predict([]float64{float64(i)})
+		assert.Len(t, guess, 1, "Length of a LeastSquares model output from the hypothesis should always be a 1 dimensional vector. Never multidimensional.")
+		assert.InDelta(t, i, guess[0], 1e-2, "Guess should be really close to input (within 1e-2) for y=x")
+		assert.Nil(t, err, "Prediction error should be nil")
+	}
+}
+
+// same as above but with Adam
+func TestInclinedLineShouldPass4(t *testing.T) {
+	var err error
+
+	model := NewLeastSquares(base.Adam, .01, 0, 500, increasingX, increasingY)
+	model.UpdateBatchSize(5)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var guess []float64
+
+	for i := -20; i < 20; i++ {
+		guess, err = model.Predict([]float64{float64(i)})
+		assert.Len(t, guess, 1, "Length of a LeastSquares model output from the hypothesis should always be a 1 dimensional vector. Never multidimensional.")
+		assert.InDelta(t, i, guess[0], 1e-2, "Guess should be really close to input (within 1e-2) for y=x")
+		assert.Nil(t, err, "Prediction error should be nil")
+	}
+}
+
 // test y=x but regularization term too large
 func TestInclinedLineShouldFail1(t *testing.T) {
 	var err error
@@ -357,6 +440,27 @@ func TestThreeDimensionalLineShouldPass1(t *testing.T) {
 }
 
 // same as above but with StochasticGA
+// computing the gradient in parallel should
+// give the same result as the serial version
+func TestThreeDimensionalLineParallelShouldPass1(t *testing.T) {
+	serial := NewLeastSquares(base.BatchGA, .0001, 0.01, 500, threeDLineX, threeDLineY)
+	serial.Tolerance = -1
+
+	parallel := NewLeastSquares(base.BatchGA, .0001, 0.01, 500, threeDLineX, threeDLineY)
+	parallel.Tolerance = -1
+
+	iterations, err := base.GradientAscentWithTolerance(serial, serial.Tolerance)
+	assert.Nil(t, err, "Serial learning error should be nil")
+
+	parallelIterations, err := base.ParallelGradientAscentWithTolerance(parallel, parallel.Tolerance)
+	assert.Nil(t, err, "Parallel learning error should be nil")
+
+	assert.Equal(t, iterations, parallelIterations, "Both methods should go through the same number of iterations")
+	for j := range serial.Parameters {
+		assert.InDelta(t, serial.Parameters[j], parallel.Parameters[j], 1e-9, "Parameter %v should be the same with serial and parallel gradient ascent", j)
+	}
+}
+
 func TestThreeDimensionalLineShouldPass2(t *testing.T) {
 	var err error
 
@@ -376,6 +480,761 @@ func TestThreeDimensionalLineShouldPass2(t *testing.T) {
 	}
 }
 
+//* Test L1 and Elastic Net Regularization *//
+
+// test that L1 regularization drives the irrelevant
+// features of y = 3 + 2x[0] to exactly 0
+func TestSparseLineL1ShouldPass1(t *testing.T) {
+	var err error
+
+	model := NewLeastSquares(base.BatchGA, 1e-3, 1, 1000, sparseX, sparseY)
+	model.RegularizationType = base.L1
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	assert.InDelta(t, 3, model.Parameters[0], 1e-1, "Constant term should be close to 3")
+	assert.InDelta(t, 2, model.Parameters[1], 1e-1, "Relevant feature parameter should be close to 2")
+	for j := 2; j < len(model.Parameters); j++ {
+		assert.Equal(t, 0.0, model.Parameters[j], "Irrelevant feature parameters should be driven to exactly 0 by L1 regularization")
+	}
+}
+
+// same as above but with Elastic Net regularization
+func TestSparseLineElasticNetShouldPass1(t *testing.T) {
+	var err error
+
+	model := NewLeastSquares(base.BatchGA, 1e-3, 1, 1000, sparseX, sparseY)
+	model.RegularizationType = base.ElasticNet
+	model.L1Ratio = 0.5
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	assert.InDelta(t, 3, model.Parameters[0], 1e-1, "Constant term should be close to 3")
+	assert.InDelta(t, 2, model.Parameters[1], 1e-1, "Relevant feature parameter should be close to 2")
+	for j := 2; j < len(model.Parameters); j++ {
+		assert.Equal(t, 0.0, model.Parameters[j], "Irrelevant feature parameters should be driven to exactly 0 by Elastic Net regularization")
+	}
+}
+
+// the L1 penalty is applied after each step with
+// the learning rate the step was actually taken
+// with, so the irrelevant features still go to
+// exactly 0 with a learning rate schedule, gradient
+// clipping, or Adam
+func TestSparseLineL1ShouldPass2(t *testing.T) {
+	scheduled := NewLeastSquares(base.BatchGA, 1e-3, 1, 1000, sparseX, sparseY)
+	scheduled.Schedule = base.InverseTimeDecay(1e-3)
+
+	clipped := NewLeastSquares(base.BatchGA, 1e-3, 1, 1000, sparseX, sparseY)
+	clipped.Clipping = &base.GradientClipping{MaxNorm: 50}
+
+	adam := NewLeastSquares(base.Adam, 1e-2, .05, 300, sparseX, sparseY)
+
+	for _, model := range []*LeastSquares{scheduled, clipped, adam} {
+		for _, kind := range []base.RegularizationType{base.L1, base.ElasticNet} {
+			model.Parameters = make([]float64, len(model.Parameters))
+			model.RegularizationType = kind
+			model.L1Ratio = 0.5
+
+			err := model.Learn()
+			assert.Nil(t, err, "Learning error should be nil")
+
+			assert.InDelta(t, 3, model.Parameters[0], 1e-1, "Constant term should be close to 3")
+			assert.InDelta(t, 2, model.Parameters[1], 0.25, "Relevant feature parameter should be close to 2")
+			for j := 2; j < len(model.Parameters); j++ {
+				assert.Equal(t, 0.0, model.Parameters[j], "Irrelevant feature parameters should be driven to exactly 0 by %v regularization", kind)
+			}
+		}
+	}
+}
+
+// both halves of the elastic net penalty should
+// shrink θ towards 0, while L2 regularization
+// keeps the term it always has
+func TestElasticNetShrinksShouldPass1(t *testing.T) {
+	alpha := 0.1
+	theta := 10.0
+
+	step := theta + alpha*regularize(base.ElasticNet, 0.5, 1, theta, 0)
+	step = shrink(base.ElasticNet, 0.5, 1, alpha, step)
+	assert.InDelta(t, 9.45, step, 1e-12, "Elastic net should shrink θ by both its L2 and L1 terms")
+	assert.True(t, penalty(base.ElasticNet, 0.5, 1, step) < penalty(base.ElasticNet, 0.5, 1, theta), "Elastic net penalty should fall as θ shrinks")
+
+	step = shrink(base.L1, 0, 1, alpha, 0.05)
+	assert.Equal(t, 0.0, step, "L1 should stop at exactly 0 rather than cross it")
+
+	assert.Equal(t, 10.0, regularize(base.L2, 0, 1, theta, 0), "L2 regularization should add λ·θ like it always has")
+	assert.Equal(t, theta, shrink(base.L2, 0, 1, alpha, theta), "L2 regularization shouldn't shrink θ after the step")
+}
+
+// L2 regularization shrinks, but doesn't zero,
+// the irrelevant features
+func TestSparseLineL2ShouldFail1(t *testing.T) {
+	var err error
+
+	model := NewLeastSquares(base.BatchGA, 1e-3, 1, 1000, sparseX, sparseY)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var zeros int
+	for j := 2; j < len(model.Parameters); j++ {
+		if model.Parameters[j] == 0 {
+			zeros++
+		}
+	}
+
+	assert.Equal(t, 0, zeros, "L2 regularization should not drive parameters to exactly 0")
+}
+
+// the normal equation has no closed form
+// with an L1 penalty
+func TestSparseLineL1ShouldFail2(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, 1e-3, 1, 1000, sparseX, sparseY)
+	model.RegularizationType = base.L1
+	err := model.LearnNormalEquation()
+	assert.NotNil(t, err, "Learning error should not be nil")
+}
+
+//* Test Normal Equation Learning *//
+
+// test y=x
+// y = 3x + 2 with a few extreme outliers
+func TestHuberRegressionOutliersShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10.0; i < 10; i += 0.5 {
+		x = append(x, []float64{i})
+		y = append(y, 3*i+2)
+	}
+
+	// outliers at the end of the line
+	// drag least squares' slope up
+	for i := 8.0; i < 10; i += 0.5 {
+		x = append(x, []float64{i})
+		y = append(y, 3*i+2+300)
+	}
+
+	leastSquares := NewLeastSquares(base.BatchGA, 1e-4, 0, 5000, x, y)
+	err := leastSquares.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	huber := NewHuberRegression(base.BatchGA, 1e-4, 0, 1, 5000, x, y)
+	err = huber.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	leastSquaresErr := math.Abs(leastSquares.Parameters[1] - 3)
+	huberErr := math.Abs(huber.Parameters[1] - 3)
+
+	fmt.Printf("Least Squares: %v\nHuber: %v\n", leastSquares, huber)
+
+	assert.InDelta(t, 3, huber.Parameters[1], 0.1, "Huber regression should recover the true slope")
+	assert.InDelta(t, 2, huber.Parameters[0], 0.5, "Huber regression should recover the true intercept")
+	assert.True(t, huberErr*10 < leastSquaresErr, "Huber regression should be much closer to the true slope than least squares - Given %v vs %v", huberErr, leastSquaresErr)
+
+	// outliers only count linearly
+	// towards the Huber cost
+	huberJ, err := huber.J()
+	assert.Nil(t, err, "Cost error should be nil")
+
+	huber.Loss = base.SquaredLoss
+	squaredJ, err := huber.J()
+	assert.Nil(t, err, "Cost error should be nil")
+	assert.True(t, huberJ < squaredJ, "The Huber cost should be less than the squared cost with outliers - Given %v vs %v", huberJ, squaredJ)
+}
+
+func TestHuberRegressionShouldFail1(t *testing.T) {
+	model := NewHuberRegression(base.BatchGA, 1e-4, 0, 0, 100, increasingX, increasingY)
+	err := model.Learn()
+	assert.NotNil(t, err, "Learning error should not be nil without a positive δ")
+
+	model = NewHuberRegression(base.BatchGA, 1e-4, 0, 1, 100, increasingX, increasingY)
+	err = model.LearnNormalEquation()
+	assert.NotNil(t, err, "The normal equations shouldn't work with the Huber loss")
+}
+
+func TestInclinedLineNormalEquationShouldPass1(t *testing.T) {
+	var err error
+
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, increasingX, increasingY)
+	err = model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	assert.InDelta(t, 0, model.Parameters[0], 1e-8, "Intercept should be exactly 0 for y=x")
+	assert.InDelta(t, 1, model.Parameters[1], 1e-8, "Slope should be exactly 1 for y=x")
+
+	var guess []float64
+
+	for i := -20; i < 20; i++ {
+		guess, err = model.Predict([]float64{float64(i)})
+		assert.Len(t, guess, 1, "Length of a LeastSquares model output from the hypothesis should always be a 1 dimensional vector. Never multidimensional.")
+		assert.InDelta(t, i, guess[0], 1e-8, "Guess should be really close to input (within 1e-8) for y=x")
+		assert.Nil(t, err, "Prediction error should be nil")
+	}
+}
+
+// test z = 2x - y, which goes through the origin
+func TestThreeDimensionalLineNoInterceptShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10; i < 10; i++ {
+		for j := -10; j < 10; j++ {
+			x = append(x, []float64{float64(i), float64(j)})
+			y = append(y, 2*float64(i)-float64(j))
+		}
+	}
+
+	for _, method := range []base.OptimizationMethod{base.BatchGA, base.StochasticGA} {
+		model := NewLeastSquares(method, .0001, 0, 1000, x, y)
+		model.FitIntercept = false
+
+		err := model.Learn()
+		assert.Nil(t, err, "Learning error should be nil")
+		assert.Len(t, model.Parameters, 2, "There should be one parameter per feature without an intercept")
+		assert.InDelta(t, 2, model.Parameters[0], 1e-3, "θ[0] should line up with x[0]")
+		assert.InDelta(t, -1, model.Parameters[1], 1e-3, "θ[1] should line up with x[1]")
+
+		guess, err := model.Predict([]float64{3, -4})
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.InDelta(t, 10, guess[0], 1e-2, "Guess should be really close to 2x - y")
+
+		// the old dimensions shouldn't work anymore
+		_, err = model.Predict([]float64{3, -4, 1})
+		assert.NotNil(t, err, "Prediction error should not be nil with an extra feature")
+	}
+}
+
+// y = x + 5 forced through the origin
+func TestInclinedLineNoInterceptNormalEquationShouldPass1(t *testing.T) {
+	y := []float64{}
+	for i := range increasingX {
+		y = append(y, increasingX[i][0]+5)
+	}
+
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, increasingX, y)
+	model.FitIntercept = false
+
+	err := model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.Len(t, model.Parameters, 1, "There should be one parameter per feature without an intercept")
+
+	// the least squares slope through the
+	// origin is Σxy / Σx²
+	var xy, xx float64
+	for i := range increasingX {
+		xy += increasingX[i][0] * y[i]
+		xx += increasingX[i][0] * increasingX[i][0]
+	}
+	assert.InDelta(t, xy/xx, model.Parameters[0], 1e-8, "Slope should be Σxy / Σx²")
+
+	guess, err := model.Predict([]float64{0})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, 0.0, guess[0], "The fit should go through the origin")
+
+	guesses, err := model.PredictBatch([][]float64{{0}, {1}})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Len(t, guesses, 2, "There should be a guess for every row")
+
+	assert.Equal(t, "h(θ,x) = 0.92537(x[1])", model.String(), "The model shouldn't print an intercept")
+}
+
+// test z = 10 + (x/10) + (y/5)
+func TestThreeDimensionalLineNormalEquationShouldPass1(t *testing.T) {
+	var err error
+
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, threeDLineX, threeDLineY)
+	err = model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var guess []float64
+
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			guess, err = model.Predict([]float64{float64(i), float64(j)})
+			assert.Len(t, guess, 1, "Length of a LeastSquares model output from the hypothesis should always be a 1 dimensional vector. Never multidimensional.")
+			assert.InDelta(t, 10.0+float64(i)/10+float64(j)/5, guess[0], 1e-8, "Guess should be really close to i+x (within 1e-8) for line z=10 + (x+y)/10")
+			assert.Nil(t, err, "Prediction error should be nil")
+		}
+	}
+}
+
+// test y=x but regularization term too large
+func TestInclinedLineNormalEquationShouldFail1(t *testing.T) {
+	var err error
+
+	model := NewLeastSquares(base.BatchGA, 0, 1e3, 0, increasingX, increasingY)
+	err = model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var guess []float64
+	var faliures int
+
+	for i := -20; i < 20; i += 2 {
+		guess, err = model.Predict([]float64{float64(i)})
+		assert.Len(t, guess, 1, "Length of a LeastSquares model output from the hypothesis should always be a 1 dimensional vector. Never multidimensional.")
+		if abs(float64(i)-guess[0]) > 1e-2 {
+			faliures++
+		}
+		assert.Nil(t, err, "Prediction error should be nil")
+	}
+
+	assert.True(t, faliures > 15, "There should be more faliures than half of the training set")
+}
+
+// test with a duplicated feature so XᵀX is singular
+func TestNormalEquationShouldFail2(t *testing.T) {
+	x := [][]float64{}
+	for i := range increasingX {
+		x = append(x, []float64{increasingX[i][0], 2 * increasingX[i][0]})
+	}
+
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, x, increasingY)
+	err := model.LearnNormalEquation()
+	assert.NotNil(t, err, "Learning error should not be nil because XᵀX is singular")
+
+	for i := range model.Parameters {
+		assert.False(t, math.IsNaN(model.Parameters[i]), "Parameters should not be NaN after a failed fit")
+	}
+
+	// regularization makes the system solvable
+	model = NewLeastSquares(base.BatchGA, 0, 1e-3, 0, x, increasingY)
+	err = model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil with regularization")
+}
+
+// points on y=x are weighted fully while
+// outliers on y=x+10 have no weight, so
+// weighted least squares should ignore them
+func TestWeightedLeastSquaresShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	weights := []float64{}
+	for i := -10.0; i < 10; i++ {
+		x = append(x, []float64{i})
+		y = append(y, i)
+		weights = append(weights, 1)
+
+		x = append(x, []float64{i})
+		y = append(y, i+10)
+		weights = append(weights, 0)
+	}
+
+	model := NewLeastSquares(base.BatchGA, .0001, 0, 0, x, y)
+	assert.Nil(t, model.UpdateSampleWeights(weights), "Sample weight error should be nil")
+
+	err := model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.InDelta(t, 0, model.Parameters[0], 1e-8, "Intercept should be 0 for y=x")
+	assert.InDelta(t, 1, model.Parameters[1], 1e-8, "Slope should be 1 for y=x")
+
+	// the weighted cost is 0 at the optimum even
+	// though the outliers are far from the line
+	cost, err := model.J()
+	assert.Nil(t, err, "Cost error should be nil")
+	assert.InDelta(t, 0, cost, 1e-8, "Weighted cost should be 0")
+
+	// gradient ascent should find the same line
+	model = NewLeastSquares(base.BatchGA, .0001, 0, 5000, x, y)
+	assert.Nil(t, model.UpdateSampleWeights(weights), "Sample weight error should be nil")
+
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	for i := -20; i < 20; i++ {
+		guess, err := model.Predict([]float64{float64(i)})
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.InDelta(t, i, guess[0], 1e-2, "Guess should be really close to input (within 1e-2) for y=x")
+	}
+
+	// without weights the line goes between
+	// the two sets of points
+	assert.Nil(t, model.UpdateSampleWeights(nil), "Sample weight error should be nil")
+	err = model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.InDelta(t, 5, model.Parameters[0], 1e-8, "Intercept should be 5 without weights")
+}
+
+func TestWeightedLeastSquaresShouldFail1(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, .0001, 0, 0, increasingX, increasingY)
+
+	err := model.UpdateSampleWeights([]float64{1, 2, 3})
+	assert.NotNil(t, err, "There should be an error when there isn't a weight for every training example")
+	assert.Nil(t, model.SampleWeights(), "Invalid weights shouldn't be set")
+
+	weights := make([]float64, len(increasingX))
+	weights[3] = -1
+	err = model.UpdateSampleWeights(weights)
+	assert.NotNil(t, err, "There should be an error for negative weights")
+
+	// changing the training set after setting
+	// weights should fail when learning
+	weights[3] = 1
+	assert.Nil(t, model.UpdateSampleWeights(weights), "Sample weight error should be nil")
+	assert.Nil(t, model.UpdateTrainingSet(flatX, flatY), "Training set error should be nil")
+
+	assert.NotNil(t, model.Learn(), "Learning should fail when the weights don't match the training set")
+	assert.NotNil(t, model.LearnNormalEquation(), "Learning should fail when the weights don't match the training set")
+}
+
+// test with no training data
+func TestNormalEquationShouldFail3(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, [][]float64{}, flatY)
+	err := model.LearnNormalEquation()
+	assert.NotNil(t, err, "Learning error should not be nil")
+
+	model = NewLeastSquares(base.BatchGA, 0, 0, 0, increasingX, increasingY[1:])
+	err = model.LearnNormalEquation()
+	assert.NotNil(t, err, "Learning error should not be nil")
+}
+
+//* Test R² Score *//
+
+// y=x should be fit perfectly
+func TestInclinedLineScoreShouldPass1(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, increasingX, increasingY)
+	err := model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	score, err := model.Score()
+	assert.Nil(t, err, "Score error should be nil")
+	assert.InDelta(t, 1, score, 1e-6, "R² should be ~1 for a perfect fit")
+
+	// score on a held-out section of the line
+	x := [][]float64{}
+	y := []float64{}
+	for i := 20; i < 40; i++ {
+		x = append(x, []float64{float64(i)})
+		y = append(y, float64(i))
+	}
+
+	score, err = model.ScoreOn(x, y)
+	assert.Nil(t, err, "Score error should be nil")
+	assert.InDelta(t, 1, score, 1e-6, "R² should be ~1 for a perfect fit")
+}
+
+// noisy data should fit well, but not perfectly
+func TestNoisyLineScoreShouldPass1(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, noisyX, noisyY)
+	err := model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	score, err := model.Score()
+	assert.Nil(t, err, "Score error should be nil")
+	assert.True(t, score > 0.9, "R² should be high for y=x/2 with noise")
+	assert.True(t, score < 1, "R² should be less than 1 with noise")
+
+	// a model that always predicts the mean should
+	// have an R² of 0
+	var mean float64
+	for i := range noisyY {
+		mean += noisyY[i]
+	}
+	mean /= float64(len(noisyY))
+	model.Parameters = []float64{mean, 0}
+
+	score, err = model.Score()
+	assert.Nil(t, err, "Score error should be nil")
+	assert.InDelta(t, 0, score, 1e-9, "R² should be 0 when always predicting the mean")
+}
+
+func TestScoreShouldFail1(t *testing.T) {
+	// no data
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, nil, nil, 1)
+	_, err := model.Score()
+	assert.NotNil(t, err, "Score error should not be nil")
+
+	// not trained yet
+	model = NewLeastSquares(base.BatchGA, 0, 0, 0, increasingX, increasingY)
+	_, err = model.Score()
+	assert.True(t, errors.Is(err, base.ErrNotTrained), "Score error should wrap base.ErrNotTrained - Given %v", err)
+
+	_, err = model.ScoreOn(increasingX, increasingY)
+	assert.True(t, errors.Is(err, base.ErrNotTrained), "Score error should wrap base.ErrNotTrained - Given %v", err)
+
+	// mismatched lengths
+	assert.Nil(t, model.LearnNormalEquation(), "Learning error should be nil")
+	_, err = model.ScoreOn(increasingX, increasingY[1:])
+	assert.NotNil(t, err, "Score error should not be nil")
+
+	// no variance in the expected results
+	_, err = model.ScoreOn(increasingX[:5], flatY[:5])
+	assert.NotNil(t, err, "Score error should not be nil")
+
+	// parameters don't match the data
+	_, err = model.ScoreOn(threeDLineX, threeDLineY)
+	assert.NotNil(t, err, "Score error should not be nil")
+}
+
+func TestRidgeRegressionShouldPass1(t *testing.T) {
+	model, err := NewRidgeRegression(1.5, threeDLineX, threeDLineY)
+	assert.Nil(t, err, "Learning error should be nil")
+
+	normal := NewLeastSquares(base.BatchGA, 0, 1.5, 0, threeDLineX, threeDLineY)
+	err = normal.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	assert.Equal(t, normal.Parameters, model.Parameters, "Ridge regression should match the regularized normal equations")
+
+	guess, err := model.Predict([]float64{1, 2})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.InDelta(t, 10.5, guess[0], 5e-2, "Guess should be close to z = 10 + x/10 + y/5")
+
+	// refitting with Learn uses the normal
+	// equations too
+	err = model.UpdateTrainingSet(increasingX, increasingY)
+	assert.Nil(t, err, "Updating the training set should not error")
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.Len(t, model.Parameters, 2, "Parameter vector should be refit for the new training set")
+}
+
+func TestRidgeRegressionShouldFail1(t *testing.T) {
+	_, err := NewRidgeRegression(1, nil, nil)
+	assert.NotNil(t, err, "Learning error should not be nil without data")
+
+	// XᵀX is singular because x[1] = 2x[0]
+	_, err = NewRidgeRegression(0, [][]float64{{1, 2}, {2, 4}, {3, 6}}, []float64{1, 2, 3})
+	assert.NotNil(t, err, "Learning error should not be nil when XᵀX is singular")
+}
+
+// the example far from the rest makes the
+// learning rate too aggressive, so learning
+// diverges unless the gradient is clipped
+func TestGradientClippingShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -1.0; i <= 1; i += 0.1 {
+		x = append(x, []float64{i})
+		y = append(y, 2*i+1)
+	}
+	x = append(x, []float64{50})
+	y = append(y, 101)
+
+	for _, method := range []base.OptimizationMethod{base.BatchGA, base.StochasticGA} {
+		model := NewLeastSquares(method, 1e-2, 0, 500, x, y)
+		err := model.Learn()
+		assert.NotNil(t, err, "Learning should diverge without clipping")
+
+		for _, clipping := range []*base.GradientClipping{{MaxNorm: 1}, {MaxValue: 1}} {
+			model = NewLeastSquares(method, 1e-2, 0, 500, x, y)
+			model.Clipping = clipping
+
+			err = model.Learn()
+			assert.Nil(t, err, "Learning error should be nil with clipping")
+			assert.InDelta(t, 1, model.Parameters[0], 2e-2, "Intercept should be close to 1")
+			assert.InDelta(t, 2, model.Parameters[1], 2e-2, "Slope should be close to 2")
+		}
+	}
+}
+
+// the training set is sorted by its results, so
+// stochastic gradient ascent in a fixed order ends
+// every epoch pulled towards the largest results
+func TestShuffledStochasticShouldPass1(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	points := [][]float64{}
+	for i := 0; i < 200; i++ {
+		x := 2*r.Float64() - 1
+		points = append(points, []float64{x, 2*x + 1 + 0.5*r.NormFloat64()})
+	}
+	sort.Slice(points, func(a, b int) bool {
+		return points[a][1] < points[b][1]
+	})
+
+	x := [][]float64{}
+	y := []float64{}
+	for i := range points {
+		x = append(x, points[i][:1])
+		y = append(y, points[i][1])
+	}
+
+	// epochs returns the number of epochs it takes to
+	// get the cost under 0.14 (the least squares fit
+	// has a cost of about 0.127,) up to 20
+	epochs := func(shuffle bool) int {
+		for e := 1; e < 20; e++ {
+			model := NewLeastSquares(base.StochasticGA, 3e-2, 0, e, x, y)
+			if !shuffle {
+				model.Shuffle = nil
+			}
+
+			err := model.Learn()
+			assert.Nil(t, err, "Learning error should be nil")
+
+			cost, err := model.J()
+			assert.Nil(t, err, "Cost error should be nil")
+			if cost < 0.14 {
+				return e
+			}
+		}
+
+		return 20
+	}
+
+	fixed := epochs(false)
+	shuffled := epochs(true)
+	assert.True(t, shuffled < fixed, "Shuffling the training set (%v epochs) should converge faster than a fixed order (%v epochs)", shuffled, fixed)
+
+	// the same seed should give the same model
+	a := NewLeastSquares(base.StochasticGA, 3e-2, 0, 5, x, y)
+	err := a.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	b := NewLeastSquares(base.StochasticGA, 3e-2, 0, 5, x, y)
+	err = b.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.Equal(t, a.Parameters, b.Parameters, "Training with the same seed should be reproducible")
+}
+
+// mini-batch gradient ascent (and Adam) should shuffle
+// with the model's source of randomness rather than the
+// global one, so training is reproducible
+func TestShuffledMiniBatchShouldPass1(t *testing.T) {
+	for _, method := range []base.OptimizationMethod{base.MiniBatchGA, base.Adam} {
+		learn := func(seed int64) []float64 {
+			// moving the global source shouldn't matter
+			rand.Int()
+
+			model := NewLeastSquares(method, .001, 0, 50, increasingX, increasingY)
+			model.UpdateBatchSize(5)
+			model.Shuffle = rand.New(rand.NewSource(seed))
+
+			err := model.Learn()
+			assert.Nil(t, err, "Learning error should be nil")
+
+			return model.Parameters
+		}
+
+		assert.Equal(t, learn(7), learn(7), "Training with the same seed should be reproducible with %v", method)
+		assert.NotEqual(t, learn(7), learn(8), "Training with a different seed should visit the examples in a different order with %v", method)
+	}
+}
+
+func TestVerboseShouldPass1(t *testing.T) {
+	var output bytes.Buffer
+
+	model := NewLeastSquares(base.BatchGA, 1e-4, 0, 100, threeDLineX, threeDLineY)
+	model.Output = &output
+
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.Equal(t, 0, output.Len(), "Nothing should be logged unless the model is verbose")
+
+	err = NewLeastSquares(base.BatchGA, 1e-4, 0, 100, nil, nil).Learn()
+	assert.NotNil(t, err, "Learning error should not be nil without data")
+	assert.Equal(t, 0, output.Len(), "Errors should be returned, not logged, unless the model is verbose")
+
+	model.Verbose = true
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.Contains(t, output.String(), "Training Completed.", "Training progress should be logged to Output when the model is verbose")
+}
+
+// normalizing a dataset with a zero row shouldn't
+// let NaNs into learning or prediction
+func TestNormalizedZeroRowShouldPass1(t *testing.T) {
+	x := [][]float64{{0, 0}}
+	y := []float64{1}
+	for i := -5.0; i < 5; i++ {
+		x = append(x, []float64{i, 2})
+		y = append(y, 1+i)
+	}
+	base.Normalize(x)
+
+	for i := range x {
+		for j := range x[i] {
+			assert.False(t, math.IsNaN(x[i][j]), "Normalized data shouldn't hold NaNs")
+		}
+	}
+
+	model := NewLeastSquares(base.BatchGA, 1e-2, 0, 500, x, y)
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	guess, err := model.Predict([]float64{0, 0}, true)
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.False(t, math.IsNaN(guess[0]) || math.IsInf(guess[0], 0), "Prediction for the zero vector should be finite")
+	assert.Equal(t, model.Parameters[0], guess[0], "Prediction for the zero vector should be the intercept")
+}
+
+//* Test Standard Errors *//
+
+func TestNoisyLineStandardErrorsShouldPass1(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, noisyX, noisyY)
+	err := model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	se, err := model.StandardErrors()
+	assert.Nil(t, err, "Standard error error should be nil")
+	assert.Len(t, se, 2, "There should be one standard error per parameter")
+
+	// compare against the textbook formulas for
+	// simple linear regression
+	//
+	//     SE(slope) = σ/√Σ(x-x̄)²
+	//     SE(intercept) = σ√(1/m + x̄²/Σ(x-x̄)²)
+	m := float64(len(noisyX))
+	var xMean float64
+	for i := range noisyX {
+		xMean += noisyX[i][0]
+	}
+	xMean /= m
+
+	var sxx, rss float64
+	for i := range noisyX {
+		sxx += (noisyX[i][0] - xMean) * (noisyX[i][0] - xMean)
+
+		guess, err := model.Predict(noisyX[i])
+		assert.Nil(t, err, "Prediction error should be nil")
+		rss += (noisyY[i] - guess[0]) * (noisyY[i] - guess[0])
+	}
+	sigma := math.Sqrt(rss / (m - 2))
+
+	assert.InEpsilon(t, sigma/math.Sqrt(sxx), se[1], 1e-6, "Slope standard error should match the closed form")
+	assert.InEpsilon(t, sigma*math.Sqrt(1/m+xMean*xMean/sxx), se[0], 1e-6, "Intercept standard error should match the closed form")
+
+	tStats, err := model.TStatistics()
+	assert.Nil(t, err, "T-statistic error should be nil")
+	assert.InDelta(t, model.Parameters[1]/se[1], tStats[1], 1e-9, "T-statistic should be θ/SE")
+	assert.True(t, tStats[1] > 10, "Slope of y=x/2 should be very significant")
+}
+
+func TestStandardErrorsShouldFail1(t *testing.T) {
+	// no data
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, nil, nil, 1)
+	_, err := model.StandardErrors()
+	assert.NotNil(t, err, "Standard error error should not be nil")
+
+	// underdetermined
+	model = NewLeastSquares(base.BatchGA, 0, 0, 0, [][]float64{{1}, {2}}, []float64{1, 3})
+	model.Parameters = []float64{-1, 2}
+	_, err = model.StandardErrors()
+	assert.NotNil(t, err, "Standard error error should not be nil")
+
+	// XᵀX is singular because x[1] = 2x[0]
+	model = NewLeastSquares(base.BatchGA, 0, 0, 0, [][]float64{{1, 2}, {2, 4}, {3, 6}, {4, 8}, {5, 10}}, []float64{1, 2, 3, 4, 5})
+	_, err = model.TStatistics()
+	assert.NotNil(t, err, "T-statistic error should not be nil")
+}
+
+//* Test Batch Prediction *//
+
+func TestInclinedLinePredictBatchShouldPass1(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, nil, nil, 1)
+	model.Parameters = []float64{1, 2}
+
+	guesses, err := model.PredictBatch([][]float64{[]float64{0}, []float64{1}, []float64{-3}})
+	assert.Nil(t, err, "Batch prediction error should be nil")
+	assert.Equal(t, [][]float64{[]float64{1}, []float64{3}, []float64{-5}}, guesses, "Batch predictions should match y=2x+1")
+}
+
+func TestInclinedLinePredictBatchShouldFail1(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, nil, nil, 1)
+	model.Parameters = []float64{1, 2}
+
+	_, err := model.PredictBatch([][]float64{[]float64{0}, []float64{1, 2}})
+	assert.NotNil(t, err, "Batch prediction error should not be nil")
+}
+
 //* Test Online Learning through channels *//
 
 func TestOnlineLinearOneDXShouldPass1(t *testing.T) {
@@ -495,6 +1354,54 @@ func TestOnlineLinearOneDXShouldFail3(t *testing.T) {
 	assert.NotNil(t, err, "Learning error should not be nil")
 }
 
+func TestOnlineLinearOneDXShouldFail4(t *testing.T) {
+	// create the channel of data and errors
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewLeastSquares(base.StochasticGA, .0001, 0, 0, nil, nil, 1)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	// mix points with a missing or too long
+	// result in with valid ones. The invalid
+	// points should be reported and skipped
+	// without crashing the model
+	go func() {
+		for n := 0; n < 500; n++ {
+			i := float64(n)*0.16 - 40
+			y := i/10 + 20
+			stream <- base.Datapoint{
+				X: []float64{i},
+				Y: []float64{},
+			}
+			stream <- base.Datapoint{
+				X: []float64{i},
+				Y: []float64{y, 10},
+			}
+			stream <- base.Datapoint{
+				X: []float64{i},
+				Y: []float64{y},
+			}
+		}
+
+		// close the dataset
+		close(stream)
+	}()
+
+	count := 0
+	for {
+		_, more := <-errors
+		if !more {
+			break
+		}
+		count++
+	}
+
+	assert.Equal(t, 1000, count, "There should be an error for every invalid point")
+	assert.NotEqual(t, 0.0, model.Parameters[1], "The model should still learn from the valid points")
+}
+
 func TestOnlineLinearFourDXShouldPass1(t *testing.T) {
 	// create the channel of data and errors
 	stream := make(chan base.Datapoint, 100)
@@ -563,6 +1470,39 @@ func TestOnlineLinearFourDXShouldPass1(t *testing.T) {
 //* Test Persistance To File *//
 
 // test persisting y=x to file
+func TestOnlineLinearNoInterceptShouldPass1(t *testing.T) {
+	// create the channel of data and errors
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewLeastSquares(base.StochasticGA, .0001, 0, 0, nil, nil, 2)
+	model.FitIntercept = false
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	for iter := 0; iter < 20; iter++ {
+		for i := -10.0; i < 10; i++ {
+			for j := -10.0; j < 10; j++ {
+				stream <- base.Datapoint{
+					X: []float64{i, j},
+					Y: []float64{2*i - j},
+				}
+			}
+		}
+	}
+
+	// close the dataset
+	close(stream)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	assert.Len(t, model.Parameters, 2, "There should be one parameter per feature without an intercept")
+	assert.InDelta(t, 2, model.Parameters[0], 1e-3, "θ[0] should line up with x[0]")
+	assert.InDelta(t, -1, model.Parameters[1], 1e-3, "θ[1] should line up with x[1]")
+}
+
 func TestPersistLeastSquaresShouldPass1(t *testing.T) {
 	var err error
 
@@ -610,3 +1550,151 @@ func TestPersistLeastSquaresShouldPass1(t *testing.T) {
 		assert.Nil(t, err, "Prediction error should be nil")
 	}
 }
+
+func TestOnlineLinearContextShouldPass1(t *testing.T) {
+	// the stream is never closed, so learning
+	// should only stop when the context is
+	// cancelled
+	stream := make(chan base.Datapoint)
+	errors := make(chan error)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	model := NewLeastSquares(base.StochasticGA, .0001, 0, 0, nil, nil, 1)
+
+	go model.OnlineLearnContext(ctx, errors, stream, func(theta [][]float64) {})
+
+	for i := -40.0; i < 40; i += 0.15 {
+		stream <- base.Datapoint{
+			X: []float64{i},
+			Y: []float64{i/10 + 20},
+		}
+	}
+
+	cancel()
+
+	select {
+	case _, more := <-errors:
+		assert.False(t, more, "The errors channel should be closed once the context is cancelled")
+	case <-time.After(time.Second):
+		t.Errorf("Learning should stop when the context is cancelled")
+	}
+
+	assert.NotEqual(t, 0.0, model.Parameters[1], "The model should have learned from the data sent before cancelling")
+}
+
+func TestPersistLeastSquaresGobShouldPass1(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, .0001, 0, 500, threeDLineX, threeDLineY)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	err := model.PersistToGob("/tmp/.goml/LeastSquares.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+	err = model.PersistToFile("/tmp/.goml/LeastSquaresGobCheck.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	fromGob := NewLeastSquares(base.BatchGA, .0001, 0, 500, threeDLineX, threeDLineY)
+	err = fromGob.RestoreFromGob("/tmp/.goml/LeastSquares.gob")
+	assert.Nil(t, err, "Restoring error should be nil")
+
+	fromJSON := NewLeastSquares(base.BatchGA, .0001, 0, 500, threeDLineX, threeDLineY)
+	err = fromJSON.RestoreFromFile("/tmp/.goml/LeastSquaresGobCheck.json")
+	assert.Nil(t, err, "Restoring error should be nil")
+
+	assert.Equal(t, model.Parameters, fromGob.Parameters, "Parameters restored from gob should match the model")
+	assert.Equal(t, fromJSON.Parameters, fromGob.Parameters, "Gob and JSON should restore the same parameters")
+
+	assert.NotNil(t, model.PersistToGob(""), "Persistance error should not be nil")
+	assert.NotNil(t, model.RestoreFromGob(""), "Restoring error should not be nil")
+}
+
+func TestPersistLeastSquaresGobNamesShouldPass1(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, .0001, 0, 500, threeDLineX, threeDLineY)
+	model.FeatureNames = []string{"x", "y"}
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	err := model.PersistToGob("/tmp/.goml/LeastSquaresNames.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	fromGob := NewLeastSquares(base.BatchGA, .0001, 0, 500, nil, nil, 2)
+	err = fromGob.RestoreFromGob("/tmp/.goml/LeastSquaresNames.gob")
+	assert.Nil(t, err, "Restoring error should be nil")
+
+	assert.Equal(t, model.Parameters, fromGob.Parameters, "Parameters restored from gob should match the model")
+	assert.Equal(t, []string{"x", "y"}, fromGob.FeatureNames, "Feature names should be restored from gob")
+
+	// one name too many for the parameter vector
+	model.FeatureNames = []string{"x", "y", "z"}
+	err = model.PersistToGob("/tmp/.goml/LeastSquaresNames.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	err = fromGob.RestoreFromGob("/tmp/.goml/LeastSquaresNames.gob")
+	assert.NotNil(t, err, "Restoring mismatched feature names should return an error")
+	assert.Equal(t, []string{"x", "y"}, fromGob.FeatureNames, "A failed restore should leave the model untouched")
+}
+
+// the GLMs should all be usable through the
+// base.SupervisedModel interface, so you can
+// train and evaluate them the same way
+func TestSupervisedModelShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -5.0; i < 5; i++ {
+		for j := -5.0; j < 5; j++ {
+			x = append(x, []float64{i, j})
+			if i+j > 0 {
+				y = append(y, 1)
+			} else {
+				y = append(y, 0)
+			}
+		}
+	}
+
+	models := []base.SupervisedModel{
+		NewLeastSquares(base.BatchGA, 1e-3, 0, 200, nil, nil, 2),
+		NewLogistic(base.BatchGA, 1e-3, 0, 200, nil, nil, 2),
+		NewPoissonRegression(base.BatchGA, 1e-3, 0, 200, nil, nil, 2),
+		NewSoftmax(base.BatchGA, 1e-3, 0, 2, 200, nil, nil, 2),
+	}
+
+	for _, model := range models {
+		assert.NotNil(t, model.UpdateTrainingSet(x, nil), "Updating the training set without results should return an error")
+		assert.Nil(t, model.UpdateTrainingSet(x, y), "Updating the training set should not return an error")
+		assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+		guess, err := model.Predict([]float64{3, 3})
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.NotEmpty(t, guess, "Prediction should not be empty")
+
+		_, err = model.Predict([]float64{3, 3, 3})
+		assert.NotNil(t, err, "Predicting with the wrong number of features should return an error")
+	}
+}
+
+func TestGridSearchLogisticShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -5.0; i < 5; i++ {
+		for j := -5.0; j < 5; j++ {
+			x = append(x, []float64{i, j})
+			if i+j > 0 {
+				y = append(y, 1)
+			} else {
+				y = append(y, 0)
+			}
+		}
+	}
+
+	grid := base.ParamGrid{
+		"alpha":      {1e-8, 1e-3},
+		"iterations": {1, 200},
+	}
+
+	best, results, err := base.GridSearch(func(params map[string]float64) base.SupervisedModel {
+		return NewLogistic(base.BatchGA, params["alpha"], 0, int(params["iterations"]), nil, nil, 2)
+	}, grid, x, y, 5, base.AccuracyScore)
+	assert.Nil(t, err, "Grid search error should be nil")
+	assert.Len(t, results, 4, "There should be a result for each combination")
+
+	assert.Equal(t, map[string]float64{"alpha": 1e-3, "iterations": 200}, best.Params, "Training for longer with a larger learning rate should do best")
+	assert.True(t, best.Mean > 0.9, "Cross validated accuracy (%v) should be high", best.Mean)
+}
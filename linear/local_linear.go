@@ -2,10 +2,14 @@ package linear
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"os"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/cdipaolo/goml/base"
 )
@@ -77,9 +81,9 @@ import (
 //     guess, err := model.Predict([]float64{10.0, -13.666})
 type LocalLinear struct {
 	// alpha and maxIterations are used only for
-	// GradientAscent during learning. If maxIterations
-	// is 0, then GradientAscent will run until the
-	// algorithm detects convergance.
+	// GradientAscent during learning. Each fit stops
+	// early once θ converges (see Tolerance) or runs
+	// out of time (see MaxTime.)
 	//
 	// regularization is used as the regularization
 	// term to avoid overfitting within regression.
@@ -103,8 +107,62 @@ type LocalLinear struct {
 
 	Parameters []float64 `json:"theta"`
 
+	// FeatureNames optionally names each feature
+	// (in the same order as x,) so errors from
+	// Predict can say which feature an input is
+	// missing.
+	FeatureNames []string `json:"feature_names,omitempty"`
+
+	// ResetParametersEachPredict starts the fit around
+	// each point given to Predict from the zero vector.
+	// Defaults to true. Setting it to false warm-starts
+	// each fit from Parameters instead, which can
+	// converge faster if you set Parameters to a fit
+	// from a nearby point. Either way Predict fits its
+	// own copy of θ and never changes Parameters, so a
+	// prediction doesn't depend on the points predicted
+	// before it and it's safe to predict from multiple
+	// goroutines at once.
+	ResetParametersEachPredict bool
+
+	// Kernel weights the training examples by how far
+	// they are from the point being predicted (see
+	// WeightKernel.) Defaults to GaussianWeight if nil.
+	// Kernels with finite support, like
+	// EpanechnikovWeight or TricubeWeight, give no
+	// weight to examples further than a bandwidth
+	// away, which are then skipped entirely, so each
+	// fit is faster.
+	Kernel WeightKernel
+
+	// Tolerance is used to detect convergence when
+	// fitting θ around each point given to Predict:
+	// the fit stops once θ moves less than Tolerance
+	// (by the L2 norm) over an iteration. If left 0,
+	// base.DefaultTolerance is used, and a negative
+	// tolerance turns early stopping off.
+	Tolerance float64
+
+	// MaxTime optionally limits how long the fit
+	// around each point given to Predict can run.
+	// A fit that runs out of time stops with the θ
+	// it has so far and prints a warning to Output
+	// (even if the model isn't Verbose, because the
+	// prediction might not have converged.) If left
+	// 0 there's no time limit.
+	MaxTime time.Duration
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs (apart
+	// from the warning when a fit runs out of
+	// MaxTime.)
+	Verbose bool
+
 	// Output is the io.Writer used for logging
-	// and printing. Defaults to os.Stdout.
+	// and printing (the training output printed on
+	// every Predict when Verbose.) Defaults to
+	// os.Stdout.
 	Output io.Writer
 }
 
@@ -163,6 +221,8 @@ func NewLocalLinear(method base.OptimizationMethod, alpha, regularization, bandw
 		// the vector of all zeros)
 		Parameters: params,
 
+		ResetParametersEachPredict: true,
+
 		Output: os.Stdout,
 	}
 }
@@ -205,6 +265,17 @@ func (l *LocalLinear) Examples() int {
 	return len(l.trainingSet)
 }
 
+// Features returns the number of features the model
+// takes as input (the length of x given to Predict,)
+// not counting the constant term
+func (l *LocalLinear) Features() int {
+	if len(l.Parameters) == 0 {
+		return 0
+	}
+
+	return len(l.Parameters) - 1
+}
+
 // MaxIterations returns the number of maximum iterations
 // the model will go through in GradientAscent, in the
 // worst case
@@ -212,107 +283,509 @@ func (l *LocalLinear) MaxIterations() int {
 	return l.maxIterations
 }
 
-// Predict takes in a variable x (an array of floats,) and
-// finds the value of the hypothesis function given the
-// current parameter vector θ
+// tolerance returns the tolerance used to detect
+// convergence, using base.DefaultTolerance if the
+// model's Tolerance is left as 0
+func (l *LocalLinear) tolerance() float64 {
+	if l.Tolerance == 0 {
+		return base.DefaultTolerance
+	}
+
+	return l.Tolerance
+}
+
+// Predict takes in a variable x (an array of floats,)
+// fits a parameter vector θ to the training set
+// weighted around x, and returns the value of the
+// hypothesis function at x. The fit uses its own
+// θ, so the model's Parameters aren't changed.
 //
 // if normalize is given as true, then the input will
 // first be normalized to unit length. Only use this if
 // you trained off of normalized inputs and are feeding
 // an un-normalized input
 func (l *LocalLinear) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	norm := len(normalize) != 0 && normalize[0]
+	if norm {
+		x = base.NormalizedPoint(x)
+	}
+
+	guess, _, err := l.PredictWithCoefficients(x)
+	return guess, err
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+//
+// Unlike PredictMany every fit prints its own
+// training output.
+func (l *LocalLinear) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	return base.PredictBatch(x, len(l.Parameters)-1, func(row []float64) ([]float64, error) {
+		return l.Predict(row, normalize...)
+	})
+}
+
+// PredictWithCoefficients is the same as Predict, but
+// also returns the parameter vector θ fit around x
+// (with the intercept first.) The local coefficients
+// θ[1:] are the slope of the fit at x, so comparing
+// them across points shows how the relationship
+// between the features and the result changes over
+// the feature space.
+//
+// Example PredictWithCoefficients Usage:
+//
+//     guess, theta, err := model.PredictWithCoefficients([]float64{10.0, -13.666})
+//     if err != nil {
+//         panic("prediction error")
+//     }
+//
+//     // how much the result changes with each
+//     // feature around x
+//     fmt.Println(theta[1:])
+func (l *LocalLinear) PredictWithCoefficients(x []float64) (prediction []float64, theta []float64, err error) {
 	if len(x)+1 != len(l.Parameters) {
-		err := fmt.Errorf("ERROR: Parameter vector should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n", len(x), len(l.Parameters))
-		print(err.Error())
-		return nil, err
+		err = fmt.Errorf("ERROR: %w: Parameter vector should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n%v", base.ErrDimensionMismatch, len(x), len(l.Parameters), base.FeatureMismatch(l.FeatureNames, len(x)))
+		l.logf(err.Error())
+		return nil, nil, err
 	}
 
-	norm := len(normalize) != 0 && normalize[0]
-	if norm {
-		base.NormalizePoint(x)
+	err = l.checkTrainingSet()
+	if err != nil {
+		l.logf(err.Error())
+		return nil, nil, err
 	}
 
-	if l.trainingSet == nil || l.expectedResults == nil {
-		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		print(err.Error())
+	l.logf("Training:\n\tModel: Locally Weighted Linear Regression\n\tOptimization Method: %v\n\tCenter Point: %v\n\tTraining Examples: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", l.method, x, len(l.trainingSet), len(l.trainingSet[0]), l.alpha, l.regularization)
+
+	// fit a parameter vector of our own so the
+	// model isn't changed, which lets you predict
+	// from multiple goroutines at once
+	theta = make([]float64, len(l.Parameters))
+	if !l.ResetParametersEachPredict {
+		copy(theta, l.Parameters)
+	}
+
+	iter, err := l.fit(x, theta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l.logf("Training Completed. Went through %v iterations.\n\tθ: %v\n\n", iter, theta)
+
+	return []float64{hypothesis(theta, x, true)}, theta, nil
+}
+
+// PredictMany predicts every point in xs like Predict,
+// returning the predictions in the same order as xs.
+// Because each prediction is an independent fit, the
+// fits are run in parallel across runtime.NumCPU()
+// goroutines, and the training output is only printed
+// once for the whole batch rather than for every point.
+//
+// The model's parameter vector θ isn't changed. Each
+// fit starts from the zero vector, or from a copy of
+// the current θ if ResetParametersEachPredict is false.
+func (l *LocalLinear) PredictMany(xs [][]float64) ([][]float64, error) {
+	for i := range xs {
+		if len(xs[i])+1 != len(l.Parameters) {
+			err := fmt.Errorf("ERROR: %w: Parameter vector should be 1 longer than input vector!\n\tLength of x[%v] given: %v\n\tLength of parameters: %v\n%v", base.ErrDimensionMismatch, i, len(xs[i]), len(l.Parameters), base.FeatureMismatch(l.FeatureNames, len(xs[i])))
+			l.logf(err.Error())
+			return nil, err
+		}
+	}
+
+	err := l.checkTrainingSet()
+	if err != nil {
+		l.logf(err.Error())
 		return nil, err
 	}
 
+	l.logf("Training:\n\tModel: Locally Weighted Linear Regression\n\tOptimization Method: %v\n\tCenter Points: %v\n\tTraining Examples: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", l.method, len(xs), len(l.trainingSet), len(l.trainingSet[0]), l.alpha, l.regularization)
+
+	guesses := make([][]float64, len(xs))
+	errs := make([]error, len(xs))
+
+	workers := runtime.NumCPU()
+	if workers > len(xs) {
+		workers = len(xs)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				theta := make([]float64, len(l.Parameters))
+				if !l.ResetParametersEachPredict {
+					copy(theta, l.Parameters)
+				}
+
+				_, err := l.fit(xs[i], theta)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+
+				guesses[i] = []float64{hypothesis(theta, xs[i], true)}
+			}
+		}()
+	}
+
+	for i := range xs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range errs {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+	}
+
+	l.logf("Training Completed. Predicted %v points.\n\n", len(xs))
+
+	return guesses, nil
+}
+
+// SelectBandwidth picks the bandwidth (out of the given
+// candidates) which predicts the training set best by
+// leave-one-out cross validation: for each candidate,
+// every training example is predicted from a fit to all
+// the other examples, and the candidate with the smallest
+// mean squared error wins (ties go to the first.) The
+// best bandwidth is returned along with the mean squared
+// error of each candidate, and if set is true the model's
+// bandwidth is set to it.
+//
+// A bandwidth too small overfits to the nearest points,
+// while one too large ignores locality, so it's worth
+// trying candidates spread over a few orders of magnitude.
+// Candidates for which learning diverges (or, with a
+// finite support Kernel, which leave some example with
+// no neighbors) get an error of +Inf rather than stopping
+// the search.
+//
+// This fits the model once per training example for every
+// candidate, so it can be slow on large training sets. The
+// fits for each candidate are run in parallel like
+// PredictMany.
+//
+// Example SelectBandwidth Usage:
+//
+//     model := NewLocalLinear(base.BatchGA, 1e-3, 0, 1, 500, x, y)
+//
+//     // try each bandwidth and keep the best
+//     bandwidth, mse, err := model.SelectBandwidth([]float64{0.25, 0.5, 1, 2, 4}, true)
+//     if err != nil {
+//         panic("bandwidth selection error")
+//     }
+func (l *LocalLinear) SelectBandwidth(candidates []float64, set bool) (float64, []float64, error) {
+	if len(candidates) == 0 {
+		return 0, nil, fmt.Errorf("ERROR: Attempting to select a bandwidth with no candidates!\n")
+	}
+	for _, bandwidth := range candidates {
+		if bandwidth <= 0 || math.IsInf(bandwidth, 0) || math.IsNaN(bandwidth) {
+			return 0, nil, fmt.Errorf("ERROR: Candidate bandwidths should be positive and finite! Given %v\n", bandwidth)
+		}
+	}
+
+	err := l.checkTrainingSet()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(l.trainingSet) < 2 || len(l.trainingSet) != len(l.expectedResults) {
+		return 0, nil, fmt.Errorf("ERROR: Leave-one-out cross validation needs at least 2 training examples with one expected result each!\n\tTraining examples: %v\n\tExpected results: %v\n", len(l.trainingSet), len(l.expectedResults))
+	}
+	if len(l.trainingSet[0])+1 != len(l.Parameters) {
+		return 0, nil, fmt.Errorf("ERROR: %w: Parameter vector should be 1 longer than the training examples!\n\tLength of training examples: %v\n\tLength of parameters: %v\n", base.ErrDimensionMismatch, len(l.trainingSet[0]), len(l.Parameters))
+	}
+
+	l.logf("Selecting Bandwidth:\n\tModel: Locally Weighted Linear Regression\n\tCandidates: %v\n\tTraining Examples: %v\n...\n\n", candidates, len(l.trainingSet))
+
+	mse := make([]float64, len(candidates))
+	best := -1
+	for c, bandwidth := range candidates {
+		mse[c], err = l.looError(bandwidth)
+		if err != nil {
+			if !errors.Is(err, base.ErrDiverged) && !errors.Is(err, errNoWeight) {
+				return 0, nil, err
+			}
+
+			mse[c] = math.Inf(1)
+		}
+
+		l.logf("\tBandwidth %v: Mean Squared Error %v\n", bandwidth, mse[c])
+
+		if !math.IsInf(mse[c], 1) && (best == -1 || mse[c] < mse[best]) {
+			best = c
+		}
+	}
+
+	if best == -1 {
+		// err holds the last candidate's error
+		err = fmt.Errorf("ERROR: Every candidate bandwidth failed!\n\t%w", err)
+		l.logf(err.Error())
+		return 0, mse, err
+	}
+
+	l.logf("Bandwidth Selected: %v\n\n", candidates[best])
+
+	if set {
+		l.bandwidth = candidates[best]
+	}
+
+	return candidates[best], mse, nil
+}
+
+// looError returns the mean squared error of
+// predicting each training example from a fit
+// (with the given bandwidth) to all the other
+// examples. The fits are run in parallel and
+// the model isn't changed.
+func (l *LocalLinear) looError(bandwidth float64) (float64, error) {
 	examples := len(l.trainingSet)
-	if examples == 0 || len(l.trainingSet[0]) == 0 {
-		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		print(err.Error())
-		return nil, err
+	squared := make([]float64, examples)
+	errs := make([]error, examples)
+
+	workers := runtime.NumCPU()
+	if workers > examples {
+		workers = examples
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				// a copy of the model trained on
+				// every example but the i-th
+				loo := *l
+				loo.bandwidth = bandwidth
+				loo.trainingSet = make([][]float64, 0, examples-1)
+				loo.expectedResults = make([]float64, 0, examples-1)
+				for j := range l.trainingSet {
+					if j != i {
+						loo.trainingSet = append(loo.trainingSet, l.trainingSet[j])
+						loo.expectedResults = append(loo.expectedResults, l.expectedResults[j])
+					}
+				}
+
+				theta := make([]float64, len(l.Parameters))
+				if !l.ResetParametersEachPredict {
+					copy(theta, l.Parameters)
+				}
+
+				_, err := loo.fit(l.trainingSet[i], theta)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+
+				residual := l.expectedResults[i] - hypothesis(theta, l.trainingSet[i], true)
+				squared[i] = residual * residual
+			}
+		}()
+	}
+
+	for i := 0; i < examples; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var sum float64
+	for i := range squared {
+		if errs[i] != nil {
+			return 0, errs[i]
+		}
+
+		sum += squared[i]
+	}
+
+	return sum / float64(examples), nil
+}
+
+// checkTrainingSet returns an error if the model
+// doesn't have any training data to fit from
+func (l *LocalLinear) checkTrainingSet() error {
+	if l.trainingSet == nil || l.expectedResults == nil {
+		return fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
+	}
+
+	if len(l.trainingSet) == 0 || len(l.trainingSet[0]) == 0 {
+		return fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
 	}
 	if len(l.expectedResults) == 0 {
-		err := fmt.Errorf("ERROR: Attempting to learn with no expected results! This isn't an unsupervised model!! You'll need to include data before you learn :)\n")
-		print(err.Error())
-		return nil, err
+		return fmt.Errorf("ERROR: Attempting to learn with no expected results! This isn't an unsupervised model!! You'll need to include data before you learn :)\n")
 	}
 
-	fmt.Fprintf(l.Output, "Training:\n\tModel: Locally Weighted Linear Regression\n\tOptimization Method: %v\n\tCenter Point: %v\n\tTraining Examples: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", l.method, x, examples, len(l.trainingSet[0]), l.alpha, l.regularization)
+	return nil
+}
+
+// fit optimizes the given parameter vector theta
+// in place, weighting the training examples with
+// respect to the input x, and returns the number
+// of iterations it went through. It stops early
+// once theta converges (see Tolerance) or the fit
+// runs out of MaxTime. It only reads from the
+// model, so it's safe to run fits for different
+// points concurrently.
+//
+// Training examples with no weight (see Kernel)
+// are skipped, and an error is returned if no
+// example has any weight around x.
+//
+// The weight of each training example and the
+// prediction error for each example don't change
+// while computing the gradient, so they're only
+// calculated once per fit and once per update
+// respectively instead of for every parameter.
+func (l *LocalLinear) fit(x []float64, theta []float64) (int, error) {
+	examples := len(l.trainingSet)
+	features := len(theta)
+
+	// only the examples with some weight affect the
+	// fit, so the rest are skipped entirely (kernels
+	// with finite support give most of them none)
+	weights := make([]float64, examples)
+	points := make([]int, 0, examples)
+	for i := range l.trainingSet {
+		weights[i] = l.weight(l.trainingSet[i], x)
+		if weights[i] != 0 {
+			points = append(points, i)
+		}
+	}
+	if len(points) == 0 {
+		return 0, fmt.Errorf("ERROR: %w around %v! Try a larger bandwidth\n", errNoWeight, x)
+	}
 
 	var iter int
-	features := len(l.Parameters)
+	newTheta := make([]float64, features)
+	tolerance := l.tolerance()
+	start := time.Now()
 
 	if l.method == base.BatchGA {
-		for ; iter < l.maxIterations; iter++ {
-			newTheta := make([]float64, features)
-			for j := range l.Parameters {
-				dj, err := l.Dj(x, j)
-				if err != nil {
-					return nil, err
+		residuals := make([]float64, examples)
+		for iter < l.maxIterations && !l.outOfTime(start, x, iter) {
+			for _, i := range points {
+				residuals[i] = weights[i] * (l.expectedResults[i] - hypothesis(theta, l.trainingSet[i], true))
+			}
+
+			for j := range theta {
+				var dj float64
+				for _, i := range points {
+					dj += residuals[i] * feature(l.trainingSet[i], j, true)
 				}
 
-				newTheta[j] = l.Parameters[j] + l.alpha*dj
+				// add in the regularization term
+				// λ*θ[j]
+				//
+				// notice that we don't count the
+				// constant term
+				if j != 0 {
+					dj += l.regularization * theta[j]
+				}
+
+				newTheta[j] = theta[j] + l.alpha*dj
 			}
 
-			// now simultaneously update Theta
-			for j := range l.Parameters {
+			// now simultaneously update Theta,
+			// keeping track of how far it moves
+			var change float64
+			for j := range theta {
 				newθ := newTheta[j]
 				if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
-					return nil, fmt.Errorf("Sorry! Learning diverged. Some value of the parameter vector theta is ±Inf or NaN")
+					return iter, fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
 				}
-				l.Parameters[j] = newθ
+				change += (newθ - theta[j]) * (newθ - theta[j])
+				theta[j] = newθ
+			}
+			iter++
+
+			// stop once θ has converged
+			if math.Sqrt(change) < tolerance {
+				break
 			}
 		}
 	} else if l.method == base.StochasticGA {
-		for ; iter < l.maxIterations; iter++ {
-			newTheta := make([]float64, features)
-			for i := 0; i < examples; i++ {
-				for j := range l.Parameters {
-					dj, err := l.Dij(x, i, j)
-					if err != nil {
-						return nil, err
+		before := make([]float64, features)
+		for iter < l.maxIterations && !l.outOfTime(start, x, iter) {
+			copy(before, theta)
+
+			for _, i := range points {
+				residual := weights[i] * (l.expectedResults[i] - hypothesis(theta, l.trainingSet[i], true))
+
+				for j := range theta {
+					dj := residual * feature(l.trainingSet[i], j, true)
+					if j != 0 {
+						dj += l.regularization * theta[j]
 					}
 
-					newTheta[j] = l.Parameters[j] + l.alpha*dj
+					newTheta[j] = theta[j] + l.alpha*dj
 				}
 
 				// now simultaneously update Theta
-				for j := range l.Parameters {
+				for j := range theta {
 					newθ := newTheta[j]
 					if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
-						return nil, fmt.Errorf("Sorry! Learning diverged. Some value of the parameter vector theta is ±Inf or NaN")
+						return iter, fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
 					}
-					l.Parameters[j] = newθ
+					theta[j] = newθ
 				}
 			}
+			iter++
+
+			// stop once θ has converged over
+			// the whole pass through the data
+			if base.EuclideanDistance(before, theta) < tolerance {
+				break
+			}
 		}
 	} else {
-		return nil, fmt.Errorf("Chose a training method not implemented for LocalLinear regression")
+		return iter, fmt.Errorf("Chose a training method not implemented for LocalLinear regression")
 	}
 
-	fmt.Fprintf(l.Output, "Training Completed. Went through %v iterations.\n%v\n\n", iter, l)
+	return iter, nil
+}
 
-	// include constant term in sum
-	sum := l.Parameters[0]
+// outOfTime returns whether a fit around x which
+// started at start has run longer than MaxTime,
+// warning on Output (even if the model isn't
+// Verbose) if it has
+func (l *LocalLinear) outOfTime(start time.Time, x []float64, iter int) bool {
+	if l.MaxTime <= 0 || time.Since(start) < l.MaxTime {
+		return false
+	}
 
-	for i := range x {
-		sum += x[i] * l.Parameters[i+1]
+	if l.Output != nil {
+		fmt.Fprintf(l.Output, "WARNING: The fit around %v ran out of time (%v) after %v iterations and might not have converged. Returning the parameter vector θ so far.\n", x, l.MaxTime, iter)
 	}
 
-	return []float64{sum}, nil
+	return true
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (l *LocalLinear) logf(format string, a ...interface{}) {
+	if l.Verbose {
+		fmt.Fprintf(l.Output, format, a...)
+	}
 }
 
 // String implements the fmt interface for clean printing. Here
@@ -321,7 +794,7 @@ func (l *LocalLinear) Predict(x []float64, normalize ...bool) ([]float64, error)
 func (l *LocalLinear) String() string {
 	features := len(l.Parameters) - 1
 	if len(l.Parameters) == 0 {
-		fmt.Fprintf(l.Output, "ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
+		l.logf("ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
 	}
 	var buffer bytes.Buffer
 
@@ -339,11 +812,69 @@ func (l *LocalLinear) String() string {
 	return buffer.String()
 }
 
+// errNoWeight is returned by fit when no
+// training example has any weight around the
+// point being predicted
+var errNoWeight = errors.New("no training examples have any weight")
+
+// WeightKernel maps the distance between a training
+// example and the point being predicted, scaled by the
+// bandwidth (u = |x[i] - x| / bandwidth,) to the weight
+// the example gets in the fit around that point. The
+// kernels below all give a weight of 1 at a distance
+// of 0, so switching between them doesn't change the
+// scale of the gradient (or the learning rate you need.)
+type WeightKernel func(u float64) float64
+
+// GaussianWeight is the default weighting kernel,
+// which gives every training example some weight
+// (though far away examples get next to none):
+//
+//     w(u) = exp(-u^2 / 2)
+func GaussianWeight(u float64) float64 {
+	return math.Exp(-1 * u * u / 2)
+}
+
+// EpanechnikovWeight is a weighting kernel with finite
+// support: training examples further than a bandwidth
+// away from the point being predicted get no weight
+// at all (and are skipped during the fit.)
+//
+//     w(u) = 1 - u^2    if u < 1
+//     w(u) = 0          otherwise
+//
+// https://en.wikipedia.org/wiki/Kernel_(statistics)
+func EpanechnikovWeight(u float64) float64 {
+	if u >= 1 {
+		return 0
+	}
+
+	return 1 - u*u
+}
+
+// TricubeWeight is the weighting kernel used by LOESS,
+// which has finite support like EpanechnikovWeight but
+// falls off more smoothly at the edge of the bandwidth:
+//
+//     w(u) = (1 - u^3)^3    if u < 1
+//     w(u) = 0              otherwise
+//
+// https://en.wikipedia.org/wiki/Local_regression
+func TricubeWeight(u float64) float64 {
+	if u >= 1 {
+		return 0
+	}
+
+	v := 1 - u*u*u
+	return v * v * v
+}
+
 // weight corresponds to the weight given between
 // two datapoints (based on how 'far apart' they
-// are.)
+// are,) using the model's Kernel (GaussianWeight
+// if it's nil.)
 //
-// w[i] = exp(-1 * |x[i] - x|^2 / 2σ^2)
+// w[i] = Kernel(|x[i] - x| / bandwidth)
 func (l *LocalLinear) weight(X []float64, x []float64) float64 {
 	// don't throw error but fail peacefully
 	//
@@ -358,7 +889,12 @@ func (l *LocalLinear) weight(X []float64, x []float64) float64 {
 		diff += (X[i] - x[i]) * (X[i] - x[i])
 	}
 
-	return math.Exp(-1 * diff / (2 * l.bandwidth * l.bandwidth))
+	kernel := l.Kernel
+	if kernel == nil {
+		kernel = GaussianWeight
+	}
+
+	return kernel(math.Sqrt(diff) / l.bandwidth)
 }
 
 // Dj returns the partial derivative of the cost function J(θ)
@@ -370,7 +906,7 @@ func (l *LocalLinear) Dj(input []float64, j int) (float64, error) {
 		return 0, fmt.Errorf("J (%v) would index out of the bounds of the training set data (len: %v)", j, len(l.Parameters))
 	}
 	if len(input) != len(l.Parameters)-1 {
-		return 0, fmt.Errorf("Length of input x (%v) should be one less than the length of the parameter vector (len: %v)", len(input), len(l.Parameters))
+		return 0, fmt.Errorf("%w: Length of input x (%v) should be one less than the length of the parameter vector (len: %v)", base.ErrDimensionMismatch, len(input), len(l.Parameters))
 	}
 
 	var sum float64
@@ -378,7 +914,7 @@ func (l *LocalLinear) Dj(input []float64, j int) (float64, error) {
 	for i := range l.trainingSet {
 		prediction := l.Parameters[0]
 		for k := 1; k < len(l.Parameters); k++ {
-			prediction += l.Parameters[k] * input[k-1]
+			prediction += l.Parameters[k] * l.trainingSet[i][k-1]
 		}
 
 		// account for constant term
@@ -419,12 +955,12 @@ func (l *LocalLinear) Dij(input []float64, i, j int) (float64, error) {
 		return 0, fmt.Errorf("j (%v) or i (%v) would index out of the bounds of the training set data (len: %v)", j, i, len(l.Parameters))
 	}
 	if len(input) != len(l.Parameters)-1 {
-		return 0, fmt.Errorf("Length of input x (%v) should be one less than the length of the parameter vector (len: %v)", len(input), len(l.Parameters))
+		return 0, fmt.Errorf("%w: Length of input x (%v) should be one less than the length of the parameter vector (len: %v)", base.ErrDimensionMismatch, len(input), len(l.Parameters))
 	}
 
 	prediction := l.Parameters[0]
 	for k := 1; k < len(l.Parameters); k++ {
-		prediction += l.Parameters[k] * input[k-1]
+		prediction += l.Parameters[k] * l.trainingSet[i][k-1]
 	}
 
 	// account for constant term
@@ -1,9 +1,15 @@
 package linear
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"math"
 	"math/rand"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/cdipaolo/goml/base"
 
@@ -110,3 +116,459 @@ func TestLocalLinearShouldPass2(t *testing.T) {
 	assert.True(t, avgError < 0.4, "Average error should be less than 0.4 from the expected value of the linear data (currently %v)", avgError)
 	fmt.Printf("Average Error: %v\n\tPoints Tested: %v\n\tTotal Error: %v\n", avgError, count, err)
 }
+
+// predicting a point shouldn't depend on the
+// points predicted before it
+func TestLocalLinearShouldPass3(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10.0; i < 10; i++ {
+		for j := -10.0; j < 10; j++ {
+			x = append(x, []float64{i, j})
+			y = append(y, i*j/10+2*i-j)
+		}
+	}
+
+	model := NewLocalLinear(base.BatchGA, 1e-3, 0, 2, 100, x, y)
+	model.Output = ioutil.Discard
+
+	grid := [][]float64{}
+	for i := -5.0; i < 5; i += 2.5 {
+		for j := -5.0; j < 5; j += 2.5 {
+			grid = append(grid, []float64{i, j})
+		}
+	}
+
+	forward := []float64{}
+	for i := range grid {
+		guess, err := model.Predict(grid[i])
+		assert.Nil(t, err, "learning/prediction error should be nil")
+		forward = append(forward, guess[0])
+	}
+
+	for i := len(grid) - 1; i >= 0; i-- {
+		guess, err := model.Predict(grid[i])
+		assert.Nil(t, err, "learning/prediction error should be nil")
+		assert.Equal(t, forward[i], guess[0], "Prediction at %v should not depend on the order of predictions", grid[i])
+	}
+}
+
+// a fit should stop once θ converges, well
+// before the maximum number of iterations,
+// even with a tiny tolerance
+func TestLocalLinearToleranceShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10.0; i < 10; i++ {
+		for j := -10.0; j < 10; j++ {
+			x = append(x, []float64{i, j})
+			y = append(y, 2*i-j+3)
+		}
+	}
+
+	for _, method := range []base.OptimizationMethod{base.BatchGA, base.StochasticGA} {
+		model := NewLocalLinear(method, 1e-3, 0, 2, 100000, x, y)
+		model.Tolerance = 1e-9
+		model.Output = ioutil.Discard
+
+		theta := make([]float64, 3)
+		iter, err := model.fit([]float64{1, 2}, theta)
+		assert.Nil(t, err, "Fitting error should be nil")
+		assert.True(t, iter < 5000, "%v should stop well before the maximum number of iterations once θ converges - Went through %v iterations", method, iter)
+
+		guess, err := model.Predict([]float64{1, 2})
+		assert.Nil(t, err, "learning/prediction error should be nil")
+		assert.InDelta(t, 3, guess[0], 1e-6, "Prediction should be close to the true value after converging")
+	}
+}
+
+// a fit which runs out of time should return
+// the θ it has so far along with a warning
+func TestLocalLinearMaxTimeShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10.0; i < 10; i++ {
+		for j := -10.0; j < 10; j++ {
+			x = append(x, []float64{i, j})
+			y = append(y, 2*i-j+3)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	model := NewLocalLinear(base.BatchGA, 1e-3, 0, 2, math.MaxInt32, x, y)
+	model.Tolerance = -1
+	model.MaxTime = 50 * time.Millisecond
+	model.Output = buf
+
+	start := time.Now()
+	guess, err := model.Predict([]float64{1, 2})
+	assert.Nil(t, err, "learning/prediction error should be nil")
+	assert.True(t, time.Since(start) < 5*time.Second, "Prediction should stop once it runs out of time - Took %v", time.Since(start))
+	assert.InDelta(t, 3, guess[0], 1e-2, "Prediction should use the θ fit so far")
+	assert.Contains(t, buf.String(), "ran out of time", "A warning should be printed even though the model isn't Verbose")
+}
+
+// the local slope of a parabola is its
+// derivative, 2x
+func TestLocalLinearPredictWithCoefficientsShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -3.0; i <= 3; i += 0.1 {
+		x = append(x, []float64{i})
+		y = append(y, i*i)
+	}
+
+	model := NewLocalLinear(base.BatchGA, 1e-2, 0, 0.5, 20000, x, y)
+	model.Kernel = TricubeWeight
+	model.Tolerance = 1e-9
+	model.Output = ioutil.Discard
+
+	for _, point := range []float64{-2, 0, 1} {
+		guess, theta, err := model.PredictWithCoefficients([]float64{point})
+		assert.Nil(t, err, "learning/prediction error should be nil")
+		assert.Len(t, theta, 2, "The local parameter vector should have an intercept and a slope")
+		assert.InDelta(t, 2*point, theta[1], 0.1, "The local slope at %v should be the derivative of the parabola", point)
+		assert.Equal(t, guess[0], theta[0]+theta[1]*point, "The prediction should be the local fit evaluated at %v", point)
+
+		predicted, err := model.Predict([]float64{point})
+		assert.Nil(t, err, "learning/prediction error should be nil")
+		assert.Equal(t, predicted, guess, "Predict should return the same prediction")
+	}
+
+	assert.Equal(t, []float64{0, 0}, model.Parameters, "The model's parameters shouldn't be changed")
+
+	_, _, err := model.PredictWithCoefficients([]float64{1, 2})
+	assert.True(t, errors.Is(err, base.ErrDimensionMismatch), "Prediction error should wrap base.ErrDimensionMismatch - Given %v", err)
+}
+
+func TestLocalLinearPredictManyShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10.0; i < 10; i++ {
+		for j := -10.0; j < 10; j++ {
+			x = append(x, []float64{i, j})
+			y = append(y, i*j/10+2*i-j)
+		}
+	}
+
+	grid := [][]float64{}
+	for i := -5.0; i < 5; i += 2.5 {
+		for j := -5.0; j < 5; j += 2.5 {
+			grid = append(grid, []float64{i, j})
+		}
+	}
+
+	for _, method := range []base.OptimizationMethod{base.BatchGA, base.StochasticGA} {
+		model := NewLocalLinear(method, 1e-3, 0, 2, 100, x, y)
+		model.Output = ioutil.Discard
+
+		guesses, err := model.PredictMany(grid)
+		assert.Nil(t, err, "learning/prediction error should be nil")
+		assert.Len(t, guesses, len(grid), "There should be a prediction for every point")
+
+		// the model's θ shouldn't be touched
+		for j := range model.Parameters {
+			assert.Equal(t, 0.0, model.Parameters[j], "PredictMany shouldn't change the parameter vector")
+		}
+
+		for i := range grid {
+			guess, err := model.Predict(grid[i])
+			assert.Nil(t, err, "learning/prediction error should be nil")
+			assert.Equal(t, guess, guesses[i], "PredictMany should match Predict at %v", grid[i])
+		}
+	}
+}
+
+// batch predictions should match predicting
+// each point on its own
+func TestLocalLinearPredictBatchShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10.0; i < 10; i++ {
+		for j := -10.0; j < 10; j++ {
+			x = append(x, []float64{i, j})
+			y = append(y, i*j/10+2*i-j)
+		}
+	}
+
+	model := NewLocalLinear(base.BatchGA, 1e-3, 0, 2, 100, x, y)
+	model.Output = ioutil.Discard
+
+	grid := [][]float64{[]float64{-5, -5}, []float64{0, 2.5}, []float64{2.5, -2.5}}
+	guesses, err := model.PredictBatch(grid)
+	assert.Nil(t, err, "Batch prediction error should be nil")
+
+	for i := range grid {
+		guess, err := model.Predict(grid[i])
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, guess, guesses[i], "Batch predictions should match Predict")
+	}
+
+	_, err = model.PredictBatch([][]float64{grid[0], []float64{1}})
+	assert.True(t, errors.Is(err, base.ErrDimensionMismatch), "Batch prediction error should wrap base.ErrDimensionMismatch - Given %v", err)
+}
+
+// predicting from many goroutines at once should
+// give the same predictions as predicting serially
+// (run with -race to check for data races)
+func TestLocalLinearConcurrentPredictShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10.0; i < 10; i++ {
+		for j := -10.0; j < 10; j++ {
+			x = append(x, []float64{i, j})
+			y = append(y, i*j/10+2*i-j)
+		}
+	}
+
+	grid := [][]float64{}
+	for i := -5.0; i < 5; i += 2.5 {
+		for j := -5.0; j < 5; j += 2.5 {
+			grid = append(grid, []float64{i, j})
+		}
+	}
+
+	model := NewLocalLinear(base.BatchGA, 1e-3, 0, 2, 100, x, y)
+	model.ResetParametersEachPredict = false
+
+	expected := make([][]float64, len(grid))
+	for i := range grid {
+		guess, err := model.Predict(grid[i])
+		assert.Nil(t, err, "learning/prediction error should be nil")
+		expected[i] = guess
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range grid {
+				guess, err := model.Predict(grid[i])
+				assert.Nil(t, err, "learning/prediction error should be nil")
+				assert.Equal(t, expected[i], guess, "Concurrent predictions should match serial predictions at %v", grid[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	for j := range model.Parameters {
+		assert.Equal(t, 0.0, model.Parameters[j], "Predict shouldn't change the parameter vector")
+	}
+}
+
+func TestLocalLinearPredictManyShouldFail1(t *testing.T) {
+	x := [][]float64{{0, 0}, {1, 1}, {2, 2}}
+	y := []float64{0, 1, 2}
+
+	model := NewLocalLinear(base.BatchGA, 1e-3, 0, 2, 100, x, y)
+	model.Output = ioutil.Discard
+
+	_, err := model.PredictMany([][]float64{{0, 0}, {1, 1, 1}})
+	assert.NotNil(t, err, "Predicting a point with the wrong number of features should return an error")
+
+	model.UpdateTrainingSet([][]float64{{0, 0}}, []float64{})
+	model.expectedResults = nil
+
+	_, err = model.PredictMany([][]float64{{0, 0}})
+	assert.NotNil(t, err, "Predicting without expected results should return an error")
+
+	model = NewLocalLinear(base.OptimizationMethod("Not A Method"), 1e-3, 0, 2, 100, x, y)
+	model.Output = ioutil.Discard
+
+	_, err = model.PredictMany([][]float64{{0, 0}, {1, 1}})
+	assert.NotNil(t, err, "Predicting with an unknown optimization method should return an error")
+}
+
+// a bandwidth too small predicts each left out
+// point from almost nothing, and one too large
+// fits a line to a parabola
+func TestLocalLinearSelectBandwidthShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -3.0; i <= 3; i += 0.25 {
+		x = append(x, []float64{i})
+		y = append(y, i*i)
+	}
+
+	model := NewLocalLinear(base.BatchGA, 1e-2, 0, 1, 2000, x, y)
+	model.Output = ioutil.Discard
+
+	bandwidth, mse, err := model.SelectBandwidth([]float64{0.01, 0.5, 100}, false)
+	assert.Nil(t, err, "Bandwidth selection error should be nil")
+	assert.Equal(t, 0.5, bandwidth, "The bandwidth in between should predict the left out points best - Errors: %v", mse)
+	assert.Len(t, mse, 3, "There should be an error for each candidate")
+	assert.True(t, mse[1] < mse[0] && mse[1] < mse[2], "The selected bandwidth should have the smallest error - Errors: %v", mse)
+	assert.Equal(t, 1.0, model.bandwidth, "The bandwidth shouldn't be set unless asked")
+
+	bandwidth, _, err = model.SelectBandwidth([]float64{0.01, 0.5, 100}, true)
+	assert.Nil(t, err, "Bandwidth selection error should be nil")
+	assert.Equal(t, bandwidth, model.bandwidth, "The selected bandwidth should be set on the model")
+}
+
+func TestLocalLinearSelectBandwidthShouldFail1(t *testing.T) {
+	x := [][]float64{{0}, {1}, {2}}
+	y := []float64{0, 1, 4}
+
+	model := NewLocalLinear(base.BatchGA, 1e-3, 0, 1, 100, x, y)
+	model.Output = ioutil.Discard
+
+	_, _, err := model.SelectBandwidth(nil, true)
+	assert.NotNil(t, err, "Selecting a bandwidth with no candidates should return an error")
+
+	_, _, err = model.SelectBandwidth([]float64{1, -1}, true)
+	assert.NotNil(t, err, "Selecting a bandwidth with a negative candidate should return an error")
+
+	model = NewLocalLinear(base.BatchGA, 1e3, 0, 1, 100, x, y)
+	model.Output = ioutil.Discard
+
+	_, _, err = model.SelectBandwidth([]float64{1, 10}, true)
+	assert.True(t, errors.Is(err, base.ErrDiverged), "Selection error should wrap base.ErrDiverged when learning diverges with every candidate - Given %v", err)
+	assert.Equal(t, 1.0, model.bandwidth, "The bandwidth shouldn't change when selection fails")
+}
+
+// fitting the same parabola under each kernel,
+// the kernels with finite support only fit the
+// nearby points so they're less biased by the
+// curvature than the Gaussian
+func TestLocalLinearKernelShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -3.0; i <= 3; i += 0.1 {
+		x = append(x, []float64{i})
+		y = append(y, i*i)
+	}
+
+	predict := func(kernel WeightKernel, point float64) float64 {
+		model := NewLocalLinear(base.BatchGA, 1e-2, 0, 0.5, 5000, x, y)
+		model.Kernel = kernel
+		model.Output = ioutil.Discard
+
+		guess, err := model.Predict([]float64{point})
+		assert.Nil(t, err, "learning/prediction error should be nil")
+
+		return guess[0]
+	}
+
+	for _, point := range []float64{-2, 0, 1} {
+		gaussian := predict(GaussianWeight, point)
+		assert.Equal(t, gaussian, predict(nil, point), "The default kernel should be Gaussian")
+		assert.InDelta(t, point*point, gaussian, 0.3, "Gaussian prediction at %v should be close to the parabola", point)
+
+		for name, kernel := range map[string]WeightKernel{
+			"epanechnikov": EpanechnikovWeight,
+			"tricube":      TricubeWeight,
+		} {
+			guess := predict(kernel, point)
+			assert.InDelta(t, point*point, guess, 0.1, "%v prediction at %v should be close to the parabola", name, point)
+			assert.True(t, math.Abs(guess-point*point) < math.Abs(gaussian-point*point), "%v prediction at %v should be less biased than the Gaussian prediction - Given %v vs %v", name, point, guess, gaussian)
+		}
+	}
+}
+
+// points outside of a finite support kernel's
+// bandwidth are skipped, so they can't change
+// the prediction at all
+func TestLocalLinearKernelShouldPass2(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -3.0; i <= 3; i += 0.1 {
+		x = append(x, []float64{i})
+		y = append(y, i*i)
+	}
+
+	for _, kernel := range []WeightKernel{EpanechnikovWeight, TricubeWeight} {
+		model := NewLocalLinear(base.BatchGA, 1e-2, 0, 0.5, 500, x, y)
+		model.Kernel = kernel
+		model.Output = ioutil.Discard
+
+		guess, err := model.Predict([]float64{1})
+		assert.Nil(t, err, "learning/prediction error should be nil")
+
+		// junk far outside the bandwidth
+		junkX := append([][]float64{}, x...)
+		junkY := append([]float64{}, y...)
+		for i := 10.0; i < 12; i += 0.1 {
+			junkX = append(junkX, []float64{i})
+			junkY = append(junkY, 1000)
+		}
+		assert.Nil(t, model.UpdateTrainingSet(junkX, junkY), "Updating the training set should not return an error")
+
+		junkGuess, err := model.Predict([]float64{1})
+		assert.Nil(t, err, "learning/prediction error should be nil")
+		assert.Equal(t, guess, junkGuess, "Points outside of the kernel's support shouldn't change the prediction")
+	}
+}
+
+func TestLocalLinearKernelShouldFail1(t *testing.T) {
+	x := [][]float64{{0}, {1}, {2}}
+	y := []float64{0, 1, 4}
+
+	model := NewLocalLinear(base.BatchGA, 1e-2, 0, 0.5, 100, x, y)
+	model.Kernel = EpanechnikovWeight
+	model.Output = ioutil.Discard
+
+	_, err := model.Predict([]float64{10})
+	assert.NotNil(t, err, "Predicting a point with no training examples within the bandwidth should return an error")
+
+	model.Kernel = GaussianWeight
+	_, err = model.Predict([]float64{10})
+	assert.Nil(t, err, "Every point has some weight under the Gaussian kernel")
+}
+
+/* Benchmarks */
+
+func BenchmarkLocalLinearPredict1000Points(b *testing.B) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10.0; i < 10; i++ {
+		for j := -10.0; j < 10; j++ {
+			x = append(x, []float64{i, j})
+			y = append(y, 5*i-5*j-10)
+		}
+	}
+
+	grid := [][]float64{}
+	for i := -5.0; i < 5; i += 0.25 {
+		for j := -5.0; j < 5; j += 0.4 {
+			grid = append(grid, []float64{i, j})
+		}
+	}
+
+	model := NewLocalLinear(base.BatchGA, 1e-4, 0, 0.75, 50, x, y)
+	model.Output = ioutil.Discard
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := range grid {
+			model.Predict(grid[i])
+		}
+	}
+}
+
+func BenchmarkLocalLinearPredictMany1000Points(b *testing.B) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10.0; i < 10; i++ {
+		for j := -10.0; j < 10; j++ {
+			x = append(x, []float64{i, j})
+			y = append(y, 5*i-5*j-10)
+		}
+	}
+
+	grid := [][]float64{}
+	for i := -5.0; i < 5; i += 0.25 {
+		for j := -5.0; j < 5; j += 0.4 {
+			grid = append(grid, []float64{i, j})
+		}
+	}
+
+	model := NewLocalLinear(base.BatchGA, 1e-4, 0, 0.75, 50, x, y)
+	model.Output = ioutil.Discard
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		model.PredictMany(grid)
+	}
+}
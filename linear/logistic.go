@@ -2,11 +2,12 @@ package linear
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math"
+	"math/rand"
 	"os"
 
 	"github.com/cdipaolo/goml/base"
@@ -17,8 +18,12 @@ import (
 //
 // https://en.wikipedia.org/wiki/Logistic_regression
 //
-// The model is currently optimized using Gradient
-// Ascent, not Newton's method, etc.
+// The model can be optimized using Gradient Ascent
+// (base.BatchGA or base.StochasticGA) or with Newton's
+// method (base.NewtonMethod.) Newton's method uses the
+// Hessian of the log-likelihood so it typically converges
+// in a handful of iterations, but each iteration needs to
+// invert a square matrix with one row per feature.
 //
 // The model expects all expected results in the
 // []float64 to come as either a 0 or a 1, and
@@ -27,8 +32,9 @@ import (
 type Logistic struct {
 	// alpha and maxIterations are used only for
 	// GradientAscent during learning. If maxIterations
-	// is 0, then GradientAscent will run until the
-	// algorithm detects convergance.
+	// is 0, then a default maximum (250, or 25 for Newton's method iterations) is
+	// used. GradientAscent stops early once the
+	// algorithm detects convergence (see Tolerance.)
 	//
 	// regularization is used as the regularization
 	// term to avoid overfitting within regression.
@@ -43,6 +49,81 @@ type Logistic struct {
 	// the model
 	method base.OptimizationMethod
 
+	// batchSize is the number of examples used for each
+	// update when training with base.MiniBatchGA or
+	// base.Adam
+	batchSize int
+
+	// Adam holds the hyperparameters (β1, β2, and ε)
+	// used when training with base.Adam. If left nil
+	// the defaults from base.NewAdamOptimizer are used.
+	Adam *base.AdamOptimizer
+
+	// Schedule decays the learning rate α over the
+	// course of training (see base.LearningRateSchedule.)
+	// If left nil the learning rate stays constant, as it
+	// always does when learning online.
+	Schedule base.LearningRateSchedule
+
+	// Clipping limits the size of the gradient before
+	// every step of gradient ascent, scaling down huge
+	// gradients rather than letting learning diverge
+	// (see base.GradientClipping.) If left nil the
+	// gradient is never clipped.
+	Clipping *base.GradientClipping
+
+	// Shuffle is the source of randomness used to
+	// shuffle the order of the training set at the
+	// start of every iteration of base.StochasticGA
+	// (see base.Shuffled.) The constructor seeds it
+	// with base.DefaultShuffleSeed so training is
+	// reproducible. Set it to your own source to
+	// change the seed, or to nil to visit the
+	// examples in order.
+	Shuffle *rand.Rand
+
+	// Tolerance is used to detect convergence when
+	// training with base.BatchGA or Newton's method:
+	// learning stops once the parameter vector moves
+	// less than Tolerance (by the L2 norm) over an
+	// iteration. If left 0, base.DefaultTolerance is
+	// used, and a negative tolerance turns early
+	// stopping off.
+	Tolerance float64
+
+	// iterations is the number of iterations the
+	// last call to Learn actually went through
+	iterations int
+
+	// FitIntercept is whether the model fits the
+	// constant term θ[0] (the intercept.) Defaults
+	// to true. When false the model is forced
+	// through the origin and θ has one parameter
+	// per feature (so θ[j] lines up with x[j])
+	// instead of one extra. Set it before training:
+	// learning drops the intercept from the zero
+	// vector θ the constructor created.
+	FitIntercept bool
+
+	// RegularizationType is the penalty used along with
+	// the regularization term (base.L2 if left empty.)
+	// L1Ratio is the fraction of the regularization given
+	// to the L1 penalty when using base.ElasticNet (the
+	// rest goes to the L2 penalty.)
+	RegularizationType base.RegularizationType
+	L1Ratio            float64
+
+	// ClassWeights scales how much each training
+	// example counts towards the gradient (and the
+	// cost function J) by the weight of its class:
+	// ClassWeights[0] for examples labeled 0 and
+	// ClassWeights[1] for examples labeled 1.
+	// Up-weighting a rare class keeps the model from
+	// just predicting the common class on imbalanced
+	// data. A weight of 0 (the default) is treated
+	// as 1, so both classes count equally unless set.
+	ClassWeights [2]float64
+
 	// trainingSet and expectedResults are the
 	// 'x', and 'y' of the data, expressed as
 	// vectors, that the model can optimize from
@@ -51,6 +132,24 @@ type Logistic struct {
 
 	Parameters []float64 `json:"theta"`
 
+	// FeatureNames optionally names each feature
+	// (in the same order as x,) so errors from
+	// Predict can say which feature an input is
+	// missing. It's saved by PersistToFile and
+	// ExportSpec.
+	FeatureNames []string `json:"feature_names,omitempty"`
+
+	// Evaluation, if set, keeps a rolling measure of
+	// how well the model predicts datapoints before it
+	// learns from them when learning online (see
+	// base.Evaluation.)
+	Evaluation *base.Evaluation
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
 	// Output is the io.Writer used for logging
 	// and printing. Defaults to os.Stdout.
 	Output io.Writer
@@ -119,6 +218,10 @@ func NewLogistic(method base.OptimizationMethod, alpha, regularization float64,
 		// the vector of all zeros)
 		Parameters: params,
 
+		FitIntercept: true,
+
+		Shuffle: rand.New(rand.NewSource(base.DefaultShuffleSeed)),
+
 		Output: os.Stdout,
 	}
 }
@@ -148,6 +251,21 @@ func (l *Logistic) UpdateLearningRate(a float64) {
 	l.alpha = a
 }
 
+// UpdateBatchSize sets the number of examples used for
+// each update of the parameter vector when training with
+// base.MiniBatchGA or base.Adam. A batch size less than 1
+// uses the default of 32.
+func (l *Logistic) UpdateBatchSize(b int) {
+	l.batchSize = b
+}
+
+// BatchSize returns the number of examples used for each
+// update of the parameter vector when training with
+// base.MiniBatchGA or base.Adam.
+func (l *Logistic) BatchSize() int {
+	return l.batchSize
+}
+
 // LearningRate returns the learning rate α for gradient
 // descent to optimize the model. Could vary as a function
 // of something else later, potentially.
@@ -155,12 +273,48 @@ func (l *Logistic) LearningRate() float64 {
 	return l.alpha
 }
 
+// LearningRateSchedule returns the schedule the learning
+// rate decays with during training (nil for a constant
+// learning rate.) This lets the optimization methods in
+// base use it (see base.Scheduled.)
+func (l *Logistic) LearningRateSchedule() base.LearningRateSchedule {
+	return l.Schedule
+}
+
+// GradientClipping returns the limits the gradient is
+// clipped to during training (nil if it isn't clipped.)
+// This lets the optimization methods in base use them
+// (see base.Clipped.)
+func (l *Logistic) GradientClipping() *base.GradientClipping {
+	return l.Clipping
+}
+
+// ShuffleSource returns the source of randomness the
+// training set is shuffled with during stochastic
+// gradient ascent (nil if it isn't shuffled.) This lets
+// the optimization methods in base use it (see
+// base.Shuffled.)
+func (l *Logistic) ShuffleSource() *rand.Rand {
+	return l.Shuffle
+}
+
 // Examples returns the number of training examples (m)
 // that the model currently is training from.
 func (l *Logistic) Examples() int {
 	return len(l.trainingSet)
 }
 
+// Features returns the number of features the model
+// takes as input (the length of x given to Predict,)
+// not counting the intercept
+func (l *Logistic) Features() int {
+	if len(l.Parameters) == 0 {
+		return 0
+	}
+
+	return len(l.Parameters) - intercept(l.FitIntercept)
+}
+
 // MaxIterations returns the number of maximum iterations
 // the model will go through in GradientAscent, in the
 // worst case
@@ -168,6 +322,40 @@ func (l *Logistic) MaxIterations() int {
 	return l.maxIterations
 }
 
+// Iterations returns the number of iterations the
+// last call to Learn went through before converging
+// (or reaching the maximum number of iterations) when
+// training with base.BatchGA or Newton's method.
+func (l *Logistic) Iterations() int {
+	return l.iterations
+}
+
+// tolerance returns the tolerance used to detect
+// convergence, using base.DefaultTolerance if the
+// model's Tolerance is left as 0
+func (l *Logistic) tolerance() float64 {
+	if l.Tolerance == 0 {
+		return base.DefaultTolerance
+	}
+
+	return l.Tolerance
+}
+
+// classWeight returns the weight of the class of
+// an example with the result y (see ClassWeights)
+func (l *Logistic) classWeight(y float64) float64 {
+	w := l.ClassWeights[0]
+	if y >= 0.5 {
+		w = l.ClassWeights[1]
+	}
+
+	if w == 0 {
+		return 1
+	}
+
+	return w
+}
+
 // Predict takes in a variable x (an array of floats,) and
 // finds the value of the hypothesis function given the
 // current parameter vector θ
@@ -177,68 +365,247 @@ func (l *Logistic) MaxIterations() int {
 // you trained off of normalized inputs and are feeding
 // an un-normalized input
 func (l *Logistic) Predict(x []float64, normalize ...bool) ([]float64, error) {
-	if len(x)+1 != len(l.Parameters) {
-		return nil, fmt.Errorf("Error: Parameter vector should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n", len(x), len(l.Parameters))
+	if len(x)+intercept(l.FitIntercept) != len(l.Parameters) {
+		return nil, fmt.Errorf("Error: %w: Parameter vector should be %v longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n%v", base.ErrDimensionMismatch, intercept(l.FitIntercept), len(x), len(l.Parameters), base.FeatureMismatch(l.FeatureNames, len(x)))
 	}
 
 	if len(normalize) != 0 && normalize[0] {
-		base.NormalizePoint(x)
+		x = base.NormalizedPoint(x)
 	}
 
-	// include constant term in sum
-	sum := l.Parameters[0]
-
-	for i := range x {
-		sum += x[i] * l.Parameters[i+1]
-	}
+	sum := hypothesis(l.Parameters, x, l.FitIntercept)
 
 	result := 1 / (1 + math.Exp(-sum))
 
 	return []float64{result}, nil
 }
 
+// PredictLabel takes in a variable x (an array of floats,)
+// and returns the class it predicts: 1 if the probability
+// Predict gives is greater than threshold, and 0 otherwise.
+// Raising the threshold trades recall for precision (and
+// lowering it does the opposite,) which is useful on
+// imbalanced data. The threshold has to be in [0,1].
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (l *Logistic) PredictLabel(x []float64, threshold float64, normalize ...bool) (int, error) {
+	if !(threshold >= 0 && threshold <= 1) {
+		return 0, fmt.Errorf("Error: decision threshold (%v) should be in [0,1]!", threshold)
+	}
+
+	guess, err := l.Predict(x, normalize...)
+	if err != nil {
+		return 0, err
+	}
+
+	if guess[0] > threshold {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (l *Logistic) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	return base.PredictBatch(x, len(l.Parameters)-intercept(l.FitIntercept), func(row []float64) ([]float64, error) {
+		return l.Predict(row, normalize...)
+	})
+}
+
 // Learn takes the struct's dataset and expected results and runs
 // batch gradient descent on them, optimizing theta so you can
 // predict based on those results
 func (l *Logistic) Learn() error {
 	if l.trainingSet == nil || l.expectedResults == nil {
 		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		fmt.Fprintf(l.Output, err.Error())
+		l.logf(err.Error())
 		return err
 	}
 
 	examples := len(l.trainingSet)
 	if examples == 0 || len(l.trainingSet[0]) == 0 {
 		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		fmt.Fprintf(l.Output, err.Error())
+		l.logf(err.Error())
 		return err
 	}
 	if len(l.expectedResults) == 0 {
 		err := fmt.Errorf("ERROR: Attempting to learn with no expected results! This isn't an unsupervised model!! You'll need to include data before you learn :)\n")
-		fmt.Fprintf(l.Output, err.Error())
+		l.logf(err.Error())
 		return err
 	}
 
-	fmt.Fprintf(l.Output, "Training:\n\tModel: Logistic (Binary) Classification\n\tOptimization Method: %v\n\tTraining Examples: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", l.method, examples, len(l.trainingSet[0]), l.alpha, l.regularization)
+	l.Parameters = sizeParameters(l.Parameters, len(l.trainingSet[0]), l.FitIntercept)
+
+	l.logf("Training:\n\tModel: Logistic (Binary) Classification\n\tOptimization Method: %v\n\tTraining Examples: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", l.method, examples, len(l.trainingSet[0]), l.alpha, l.regularization)
 
 	var err error
 	if l.method == base.BatchGA {
-		err = base.GradientAscent(l)
+		l.iterations, err = base.ParallelGradientAscentWithTolerance(l, l.tolerance())
+		l.logf("Went through %v iterations.\n", l.iterations)
 	} else if l.method == base.StochasticGA {
 		err = base.StochasticGradientAscent(l)
+	} else if l.method == base.MiniBatchGA {
+		err = base.MiniBatchGradientAscent(l, l.batchSize)
+	} else if l.method == base.Adam {
+		err = base.AdamAscent(l, l.batchSize, l.Adam)
+	} else if l.method == base.NewtonMethod {
+		err = l.newtonMethod()
 	} else {
 		err = fmt.Errorf("Chose a training method not implemented for Logistic regression")
 	}
 
 	if err != nil {
-		fmt.Fprintf(l.Output, "\nERROR: Error while learning –\n\t%v\n\n", err)
+		l.logf("\nERROR: Error while learning –\n\t%v\n\n", err)
 		return err
 	}
 
-	fmt.Fprintf(l.Output, "Training Completed.\n%v\n\n", l)
+	l.logf("Training Completed.\n%v\n\n", l)
+	return nil
+}
+
+// newtonMethod optimizes the parameter vector θ using
+// Newton's method, where each iteration performs the
+// update
+//
+//     θ := θ - H⁻¹∇
+//
+// where ∇ and H are the gradient and Hessian of the
+// log-likelihood penalized with a ridge, ℓ(θ) - λ·||θ||²/2:
+//
+//     ∇[j] = Σ w(y[i])(y[i] - h(x[i]))x[i][j] - λ·θ[j]
+//     H[j][k] = -Σ w(y[i])h(x[i])(1 - h(x[i]))x[i][j]x[i][k] - λ·1{j == k}
+//
+// (w(y) is the weight of the example's class, see
+// ClassWeights, and the constant term isn't
+// regularized.) The ridge keeps -H positive definite,
+// so every step heads for the maximum. Only L2
+// regularization is supported because the L1 penalty
+// isn't twice differentiable. If the Hessian
+// is singular, which happens when the data is perfectly
+// separable and the predictions saturate, a small ridge
+// is added to the diagonal until it can be inverted.
+func (l *Logistic) newtonMethod() error {
+	maxIterations := l.maxIterations
+
+	// if the iterations given is 0, set it to be
+	// 25 (Newton's method converges much faster
+	// than gradient ascent)
+	if maxIterations == 0 {
+		maxIterations = 25
+	}
+
+	if l.RegularizationType == base.L1 || l.RegularizationType == base.ElasticNet {
+		return fmt.Errorf("Newton's method only supports L2 regularization, not %v", l.RegularizationType)
+	}
+
+	features := len(l.Parameters)
+	tolerance := l.tolerance()
+
+	for l.iterations = 0; l.iterations < maxIterations; {
+		gradient, err := l.PartialDj(0, len(l.trainingSet))
+		if err != nil {
+			return err
+		}
+
+		// negHessian is -H, which is positive
+		// semidefinite (and positive definite
+		// with regularization)
+		negHessian := make([][]float64, features)
+		for j := range negHessian {
+			negHessian[j] = make([]float64, features)
+		}
+
+		x := make([]float64, features)
+		for i := range l.trainingSet {
+			prediction, err := l.Predict(l.trainingSet[i])
+			if err != nil {
+				return err
+			}
+
+			// account for constant term
+			for j := range x {
+				x[j] = feature(l.trainingSet[i], j, l.FitIntercept)
+			}
+
+			w := l.classWeight(l.expectedResults[i]) * prediction[0] * (1 - prediction[0])
+			for j := range x {
+				for k := range x {
+					negHessian[j][k] += w * x[j] * x[k]
+				}
+			}
+		}
+
+		// apply the ridge penalty
+		//
+		// notice that we don't count the
+		// constant term
+		for j := intercept(l.FitIntercept); j < features; j++ {
+			gradient[j] -= l.regularization * l.Parameters[j]
+			negHessian[j][j] += l.regularization
+		}
+
+		inverse, err := invert(negHessian)
+		for ridge := 1e-8; err != nil && ridge < 1e8; ridge *= 10 {
+			for j := range negHessian {
+				negHessian[j][j] += ridge
+			}
+
+			inverse, err = invert(negHessian)
+		}
+		if err != nil {
+			return err
+		}
+
+		// θ - H⁻¹∇ == θ + (-H)⁻¹∇
+		step := matVec(inverse, gradient)
+		var change float64
+		for j := range l.Parameters {
+			newθ := l.Parameters[j] + step[j]
+			if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
+				return fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
+			}
+			l.Parameters[j] = newθ
+			change += step[j] * step[j]
+		}
+		l.iterations++
+
+		// stop once θ has converged
+		if math.Sqrt(change) < tolerance {
+			break
+		}
+	}
+
+	l.logf("Went through %v iterations.\n", l.iterations)
+
 	return nil
 }
 
+// WithEvaluation turns on evaluating the model while it
+// learns online, averaging the accuracy and cost of its
+// predictions over the last windowSize datapoints (see
+// base.Evaluation,) and returns the model so it can be
+// chained after the constructor:
+//
+//     model := NewLogistic(base.StochasticGA, 1e-4, 0, 0, nil, nil, 2).WithEvaluation(200)
+//
+// The results are read from the model's Evaluation.
+func (l *Logistic) WithEvaluation(windowSize int) *Logistic {
+	l.Evaluation = base.NewEvaluation(windowSize)
+	return l
+}
+
 // OnlineLearn runs similar to using a fixed dataset with
 // Stochastic Gradient Descent, but it handles data by
 // passing it as a channal, and returns errors through
@@ -342,6 +709,16 @@ func (l *Logistic) Learn() error {
 //         panic("AAAARGGGH! SHIVER ME TIMBERS! THESE ROTTEN SCOUNDRELS FOUND AN ERROR!!!")
 //     }
 func (l *Logistic) OnlineLearn(errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	l.OnlineLearnContext(context.Background(), errors, dataset, onUpdate, normalize...)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// dataset channel is still open. This lets you stop
+// training, ie. when shutting down a server, without
+// having to close the dataset yourself.
+func (l *Logistic) OnlineLearnContext(ctx context.Context, errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
 	if errors == nil {
 		errors = make(chan error)
 	}
@@ -351,22 +728,47 @@ func (l *Logistic) OnlineLearn(errors chan error, dataset chan base.Datapoint, o
 		return
 	}
 
-	fmt.Fprintf(l.Output, "Training:\n\tModel: Logistic (Binary) Classifier\n\tOptimization Method: Online Stochastic Gradient Descent\n\tFeatures: %v\n\tLearning Rate α: %v\n...\n\n", len(l.Parameters), l.alpha)
+	l.logf("Training:\n\tModel: Logistic (Binary) Classifier\n\tOptimization Method: Online Stochastic Gradient Descent\n\tFeatures: %v\n\tLearning Rate α: %v\n...\n\n", len(l.Parameters), l.alpha)
 
 	norm := len(normalize) != 0 && normalize[0]
 	var point base.Datapoint
 	var more bool
 
 	for {
-		point, more = <-dataset
+		select {
+		case <-ctx.Done():
+			l.logf("Training Cancelled.\n%v\n\n", l)
+			close(errors)
+			return
+		case point, more = <-dataset:
+		}
 
 		if more {
 			if len(point.Y) != 1 {
 				errors <- fmt.Errorf("ERROR: point.Y must have a length of 1. Point: %v", point)
+				continue
 			}
 
 			if norm {
-				base.NormalizePoint(point.X)
+				point.X = base.NormalizedPoint(point.X)
+			}
+
+			// drop the intercept from the constructor's
+			// zero vector before the first update
+			if isZero(l.Parameters) {
+				l.Parameters = sizeParameters(l.Parameters, len(point.X), l.FitIntercept)
+			}
+
+			// score the point before learning from it
+			if l.Evaluation != nil {
+				guess, err := l.Predict(point.X)
+				if err != nil {
+					errors <- err
+					continue
+				}
+
+				y := point.Y[0]
+				l.Evaluation.Add((guess[0] > 0.5) == (y >= 0.5), -y*logProbability(guess[0])-(1-y)*logProbability(1-guess[0]))
 			}
 
 			newTheta := make([]float64, len(l.Parameters))
@@ -385,23 +787,18 @@ func (l *Logistic) OnlineLearn(errors chan error, dataset chan base.Datapoint, o
 
 					// account for constant term
 					// x is x[i][j] via Andrew Ng's terminology
-					var x float64
-					if j == 0 {
-						x = 1
-					} else {
-						x = point.X[j-1]
-					}
+					x := feature(point.X, j, l.FitIntercept)
 
 					var gradient float64
-					gradient = (point.Y[0] - prediction[0]) * x
+					gradient = l.classWeight(point.Y[0]) * (point.Y[0] - prediction[0]) * x
 
-					// add in the regularization term
-					// λ*θ[j]
+					// apply the regularization term
+					// (λ*θ[j] for L2 regularization)
 					//
 					// notice that we don't count the
 					// constant term
-					if j != 0 {
-						gradient += l.regularization * l.Parameters[j]
+					if !l.FitIntercept || j != 0 {
+						gradient = regularize(l.RegularizationType, l.L1Ratio, l.regularization, l.Parameters[j], gradient)
 					}
 
 					return gradient, nil
@@ -411,14 +808,14 @@ func (l *Logistic) OnlineLearn(errors chan error, dataset chan base.Datapoint, o
 					continue
 				}
 
-				newTheta[j] = l.Parameters[j] + l.alpha*dj
+				newTheta[j] = l.Prox(j, l.Parameters[j]+l.alpha*dj, l.alpha)
 			}
 
 			// now simultaneously update Theta
 			for j := range l.Parameters {
 				newθ := newTheta[j]
 				if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
-					errors <- fmt.Errorf("Sorry! Learning diverged. Some value of the parameter vector theta is ±Inf or NaN")
+					errors <- fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
 					continue
 				}
 				l.Parameters[j] = newθ
@@ -427,28 +824,40 @@ func (l *Logistic) OnlineLearn(errors chan error, dataset chan base.Datapoint, o
 			go onUpdate([][]float64{l.Parameters})
 
 		} else {
-			fmt.Fprintf(l.Output, "Training Completed.\n%v\n\n", l)
+			l.logf("Training Completed.\n%v\n\n", l)
 			close(errors)
 			return
 		}
 	}
 }
 
+// logf prints training progress to Output
+// when the model is Verbose
+func (l *Logistic) logf(format string, a ...interface{}) {
+	if l.Verbose {
+		fmt.Fprintf(l.Output, format, a...)
+	}
+}
+
 // String implements the fmt interface for clean printing. Here
 // we're using it to print the model as the equation h(θ)=...
 // where h is the logistic hypothesis model
 func (l *Logistic) String() string {
-	features := len(l.Parameters) - 1
+	offset := intercept(l.FitIntercept)
+	features := len(l.Parameters) - offset
 	if len(l.Parameters) == 0 {
-		fmt.Fprintf(l.Output, "ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
+		l.logf("ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
 	}
 	var buffer bytes.Buffer
 
-	buffer.WriteString(fmt.Sprintf("h(θ,x) = 1 / (1 + exp(-θx))\nθx = %.3f + ", l.Parameters[0]))
+	buffer.WriteString("h(θ,x) = 1 / (1 + exp(-θx))\nθx = ")
+	if l.FitIntercept {
+		buffer.WriteString(fmt.Sprintf("%.3f + ", l.Parameters[0]))
+	}
 
 	length := features + 1
 	for i := 1; i < length; i++ {
-		buffer.WriteString(fmt.Sprintf("%.5f(x[%d])", l.Parameters[i], i))
+		buffer.WriteString(fmt.Sprintf("%.5f(x[%d])", l.Parameters[i-1+offset], i))
 
 		if i != features {
 			buffer.WriteString(fmt.Sprintf(" + "))
@@ -477,26 +886,78 @@ func (l *Logistic) Dj(j int) (float64, error) {
 
 		// account for constant term
 		// x is x[i][j] via Andrew Ng's terminology
-		var x float64
-		if j == 0 {
-			x = 1
-		} else {
-			x = l.trainingSet[i][j-1]
+		x := feature(l.trainingSet[i], j, l.FitIntercept)
+
+		sum += l.classWeight(l.expectedResults[i]) * (l.expectedResults[i] - prediction[0]) * x
+	}
+
+	return l.RegularizeDj(j, sum), nil
+}
+
+// PartialDj returns the derivative of the cost function
+// J(θ) with respect to every parameter of the hypothesis,
+// summed only over the training examples x[start] through
+// x[end-1] and without regularization. The training set
+// is split up this way to compute the gradient in parallel
+// (see base.ParallelGradientAscent.)
+func (l *Logistic) PartialDj(start, end int) ([]float64, error) {
+	if start < 0 || end > len(l.trainingSet) || start > end {
+		return nil, fmt.Errorf("Range [%v,%v) would index out of the bounds of the training set data (len: %v)", start, end, len(l.trainingSet))
+	}
+
+	sum := make([]float64, len(l.Parameters))
+
+	for i := start; i < end; i++ {
+		prediction, err := l.Predict(l.trainingSet[i])
+		if err != nil {
+			return nil, err
 		}
 
-		sum += (l.expectedResults[i] - prediction[0]) * x
+		diff := l.classWeight(l.expectedResults[i]) * (l.expectedResults[i] - prediction[0])
+
+		// account for constant term
+		if l.FitIntercept {
+			sum[0] += diff
+		}
+		offset := intercept(l.FitIntercept)
+		for j := range l.trainingSet[i] {
+			sum[j+offset] += diff * l.trainingSet[i][j]
+		}
 	}
 
-	// add in the regularization term
-	// λ*θ[j]
+	return sum, nil
+}
+
+// RegularizeDj applies the regularization term to the
+// derivative of the cost function J(θ) with respect to
+// θ[j] (over the whole training set,) returning the
+// regularized derivative.
+func (l *Logistic) RegularizeDj(j int, dj float64) float64 {
+	// apply the regularization term
+	// (λ*θ[j] for L2 regularization)
 	//
 	// notice that we don't count the
 	// constant term
-	if j != 0 {
-		sum += l.regularization * l.Parameters[j]
+	if !l.FitIntercept || j != 0 {
+		dj = regularize(l.RegularizationType, l.L1Ratio, l.regularization, l.Parameters[j], dj)
 	}
 
-	return sum, nil
+	return dj
+}
+
+// Prox applies the L1 part of the regularization
+// penalty to θ[j] after a step of gradient ascent was
+// taken to theta with learning rate alpha, returning
+// the new θ[j]. This lets the optimization methods in
+// base apply it (see base.Proximal.)
+func (l *Logistic) Prox(j int, theta, alpha float64) float64 {
+	// notice that we don't count the
+	// constant term
+	if l.FitIntercept && j == 0 {
+		return theta
+	}
+
+	return shrink(l.RegularizationType, l.L1Ratio, l.regularization, alpha, theta)
 }
 
 // Dij returns the derivative of the cost function
@@ -516,28 +977,57 @@ func (l *Logistic) Dij(i int, j int) (float64, error) {
 
 	// account for constant term
 	// x is x[i][j] via Andrew Ng's terminology
-	var x float64
-	if j == 0 {
-		x = 1
-	} else {
-		x = l.trainingSet[i][j-1]
-	}
+	x := feature(l.trainingSet[i], j, l.FitIntercept)
 
 	var gradient float64
-	gradient = (l.expectedResults[i] - prediction[0]) * x
+	gradient = l.classWeight(l.expectedResults[i]) * (l.expectedResults[i] - prediction[0]) * x
 
-	// add in the regularization term
-	// λ*θ[j]
+	// apply the regularization term
+	// (λ*θ[j] for L2 regularization)
 	//
 	// notice that we don't count the
 	// constant term
-	if j != 0 {
-		gradient += l.regularization * l.Parameters[j]
+	if !l.FitIntercept || j != 0 {
+		gradient = regularize(l.RegularizationType, l.L1Ratio, l.regularization, l.Parameters[j], gradient)
 	}
 
 	return gradient, nil
 }
 
+// J returns the cost function of the given logistic model,
+// which is the (regularized) negative log-likelihood of the
+// training set averaged over the number of examples:
+//
+//     J(θ) = -1/m Σ w(y)·(y·log(h(θ,x)) + (1-y)·log(1-h(θ,x)))
+//
+// (where w(y) is the weight of the example's class, see
+// ClassWeights) plus the regularization penalty. Predictions are kept
+// away from exactly 0 and 1 so a confidently wrong
+// prediction gives a large, but finite, cost. Could be
+// useful in testing convergence
+func (l *Logistic) J() (float64, error) {
+	var sum float64
+
+	for i := range l.trainingSet {
+		prediction, err := l.Predict(l.trainingSet[i])
+		if err != nil {
+			return 0, err
+		}
+
+		y := l.expectedResults[i]
+		sum -= l.classWeight(y) * (y*logProbability(prediction[0]) + (1-y)*logProbability(1-prediction[0]))
+	}
+
+	// add regularization term!
+	//
+	// notice that the constant term doesn't matter
+	for i := intercept(l.FitIntercept); i < len(l.Parameters); i++ {
+		sum += penalty(l.RegularizationType, l.L1Ratio, l.regularization, l.Parameters[i]) / 2
+	}
+
+	return sum / float64(len(l.trainingSet)), nil
+}
+
 // Theta returns the parameter vector θ for use in persisting
 // the model, and optimizing the model through gradient descent
 // ( or other methods like Newton's Method)
@@ -547,6 +1037,7 @@ func (l *Logistic) Theta() []float64 {
 
 // PersistToFile takes in an absolute filepath and saves the
 // parameter vector θ to the file, which can be restored later.
+// If the model has FeatureNames they're saved along with θ.
 // The function will take paths from the current directory, but
 // functions
 //
@@ -558,7 +1049,7 @@ func (l *Logistic) PersistToFile(path string) error {
 		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
 	}
 
-	bytes, err := json.Marshal(l.Parameters)
+	bytes, err := base.MarshalParameters(l.Parameters, l.FeatureNames)
 	if err != nil {
 		return err
 	}
@@ -573,7 +1064,7 @@ func (l *Logistic) PersistToFile(path string) error {
 
 // RestoreFromFile takes in a path to a parameter vector theta
 // and assigns the model it's operating on's parameter vector
-// to that.
+// to that, along with the FeatureNames saved with it (if any.)
 //
 // The path must ba an absolute path or a path from the current
 // directory
@@ -591,10 +1082,47 @@ func (l *Logistic) RestoreFromFile(path string) error {
 		return err
 	}
 
-	err = json.Unmarshal(bytes, &l.Parameters)
+	theta, names, err := base.UnmarshalParameters(bytes)
+	if err != nil {
+		return err
+	}
+
+	err = base.CheckFeatureNames(names, len(theta)-intercept(l.FitIntercept))
+	if err != nil {
+		return err
+	}
+
+	l.Parameters = theta
+	l.FeatureNames = names
+
+	return nil
+}
+
+// PersistToGob saves the parameter vector θ (and the
+// FeatureNames, if any) to the given file like
+// PersistToFile, but encoded with encoding/gob (see
+// base.PersistToGob.)
+func (l *Logistic) PersistToGob(path string) error {
+	return base.PersistParametersToGob(path, l.Parameters, l.FeatureNames)
+}
+
+// RestoreFromGob takes in a path to a parameter vector
+// saved with PersistToGob and assigns the model's
+// parameter vector and FeatureNames to it, like
+// RestoreFromFile.
+func (l *Logistic) RestoreFromGob(path string) error {
+	theta, names, err := base.RestoreParametersFromGob(path)
 	if err != nil {
 		return err
 	}
 
+	err = base.CheckFeatureNames(names, len(theta)-intercept(l.FitIntercept))
+	if err != nil {
+		return err
+	}
+
+	l.Parameters = theta
+	l.FeatureNames = names
+
 	return nil
 }
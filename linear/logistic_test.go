@@ -2,6 +2,8 @@ package linear
 
 import (
 	"fmt"
+	"io/ioutil"
+	"math"
 	"math/rand"
 	"os"
 	"testing"
@@ -146,6 +148,47 @@ func TestFourDimensionalPlaneShouldPass1(t *testing.T) {
 	}
 }
 
+// predicting with normalize should leave the
+// caller's input untouched
+func TestLogisticPredictNormalizeShouldPass1(t *testing.T) {
+	model := NewLogistic(base.BatchGA, .000001, 0, 800, fourDX, fourDY)
+	model.Parameters = []float64{0.5, 1, -2, 3}
+
+	x := []float64{3, 4, 12}
+
+	guess, err := model.Predict(x, true)
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, []float64{3, 4, 12}, x, "Predict should not change the input when normalizing")
+
+	expected, err := model.Predict([]float64{3.0 / 13, 4.0 / 13, 12.0 / 13})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.InDelta(t, expected[0], guess[0], 1e-9, "Prediction should be made off of the normalized input")
+}
+
+// computing the gradient in parallel should
+// give the same result as the serial version
+func TestFourDimensionalPlaneParallelShouldPass1(t *testing.T) {
+	serial := NewLogistic(base.BatchGA, .000001, 10, 800, fourDX, fourDY)
+	serial.Tolerance = -1
+
+	parallel := NewLogistic(base.BatchGA, .000001, 10, 800, fourDX, fourDY)
+	parallel.Tolerance = -1
+
+	iterations, err := base.GradientAscentWithTolerance(serial, serial.Tolerance)
+	assert.Nil(t, err, "Serial learning error should be nil")
+
+	parallelIterations, err := base.ParallelGradientAscentWithTolerance(parallel, parallel.Tolerance)
+	assert.Nil(t, err, "Parallel learning error should be nil")
+
+	assert.Equal(t, iterations, parallelIterations, "Both methods should go through the same number of iterations")
+	for j := range serial.Parameters {
+		assert.InDelta(t, serial.Parameters[j], parallel.Parameters[j], 1e-9, "Parameter %v should be the same with serial and parallel gradient ascent", j)
+	}
+
+	_, err = parallel.PartialDj(-1, len(fourDX))
+	assert.NotNil(t, err, "Partial gradient out of bounds of the training set should return an error")
+}
+
 // same as above but with StochasticGA
 func TestFourDimensionalPlaneShouldPass2(t *testing.T) {
 	var err error
@@ -340,11 +383,67 @@ func TestTwoDimensionalPlaneShouldPass1(t *testing.T) {
 	}
 }
 
-// same as above but with StochasticGA
+// same as above but with StochasticGA (which
+// shuffles the training set, so it needs a larger
+// learning rate than visiting the points in order)
 func TestTwoDimensionalPlaneShouldPass2(t *testing.T) {
 	var err error
 
-	model := NewLogistic(base.StochasticGA, .0001, 0, 3000, twoDX, twoDY)
+	model := NewLogistic(base.StochasticGA, .001, 0, 3000, twoDX, twoDY)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var guess []float64
+
+	for i := -40; i < 20; i++ {
+		guess, err = model.Predict([]float64{float64(i)})
+
+		if i/2+10 > 0 {
+			assert.True(t, guess[0] > 0.5, "Guess should be more likely to be 1 when i=%v", i)
+			assert.True(t, guess[0] < 1.001, "Guess should not exceed 1 ever when")
+		} else {
+			assert.True(t, guess[0] < 0.5, "Guess should be more likely to be 0 when i=%v", i)
+			assert.True(t, guess[0] > 0.0, "Guess should not be below 0 even")
+		}
+
+		assert.Len(t, guess, 1, "Length of a Logistic model output from the hypothesis should always be a 1 dimensional vector. Never multidimensional.")
+		assert.Nil(t, err, "Prediction error should be nil")
+	}
+}
+
+// same as above but with MiniBatchGA
+func TestTwoDimensionalPlaneShouldPass3(t *testing.T) {
+	var err error
+
+	model := NewLogistic(base.MiniBatchGA, .001, 0, 3000, twoDX, twoDY)
+	model.UpdateBatchSize(5)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var guess []float64
+
+	for i := -40; i < 20; i++ {
+		guess, err = model.Predict([]float64{float64(i)})
+
+		if i/2+10 > 0 {
+			assert.True(t, guess[0] > 0.5, "Guess should be more likely to be 1 when i=%v", i)
+			assert.True(t, guess[0] < 1.001, "Guess should not exceed 1 ever when")
+		} else {
+			assert.True(t, guess[0] < 0.5, "Guess should be more likely to be 0 when i=%v", i)
+			assert.True(t, guess[0] > 0.0, "Guess should not be below 0 even")
+		}
+
+		assert.Len(t, guess, 1, "Length of a Logistic model output from the hypothesis should always be a 1 dimensional vector. Never multidimensional.")
+		assert.Nil(t, err, "Prediction error should be nil")
+	}
+}
+
+// same as above but with Adam
+func TestTwoDimensionalPlaneShouldPass4(t *testing.T) {
+	var err error
+
+	model := NewLogistic(base.Adam, .001, 0, 500, twoDX, twoDY)
+	model.UpdateBatchSize(5)
 	err = model.Learn()
 	assert.Nil(t, err, "Learning error should be nil")
 
@@ -535,8 +634,322 @@ func TestThreeDimensionalPlaneNormalizedShouldPass2(t *testing.T) {
 	}
 }
 
+// test i > -20 using Newton's method, which
+// should only need a handful of iterations
+func TestTwoDimensionalPlaneNewtonShouldPass1(t *testing.T) {
+	var err error
+
+	model := NewLogistic(base.NewtonMethod, 0, 0, 8, twoDX, twoDY)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var guess []float64
+
+	for i := -40; i < 20; i++ {
+		guess, err = model.Predict([]float64{float64(i)})
+
+		if i/2+10 > 0 {
+			assert.True(t, guess[0] > 0.5, "Guess should be more likely to be 1 when i=%v", i)
+			assert.True(t, guess[0] < 1.001, "Guess should not exceed 1 ever when")
+		} else {
+			assert.True(t, guess[0] < 0.5, "Guess should be more likely to be 0 when i=%v", i)
+			assert.True(t, guess[0] > -0.001, "Guess should not be below 0 even")
+		}
+
+		assert.Len(t, guess, 1, "Length of a Logistic model output from the hypothesis should always be a 1 dimensional vector. Never multidimensional.")
+		assert.Nil(t, err, "Prediction error should be nil")
+	}
+}
+
+// test x > y, which goes through the origin
+func TestTwoDimensionalPlaneNoInterceptShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -10.0; i < 10; i++ {
+		for j := -10.0; j < 10; j++ {
+			if i == j {
+				continue
+			}
+
+			x = append(x, []float64{i, j})
+			if i > j {
+				y = append(y, 1.0)
+			} else {
+				y = append(y, 0.0)
+			}
+		}
+	}
+
+	for _, method := range []base.OptimizationMethod{base.BatchGA, base.NewtonMethod} {
+		model := NewLogistic(method, 1e-4, 0, 500, x, y)
+		model.FitIntercept = false
+
+		err := model.Learn()
+		assert.Nil(t, err, "Learning error should be nil")
+		assert.Len(t, model.Parameters, 2, "There should be one parameter per feature without an intercept")
+
+		for i := range x {
+			guess, err := model.Predict(x[i])
+			assert.Nil(t, err, "Prediction error should be nil")
+			assert.Equal(t, y[i], math.Round(guess[0]), "Guess should be the right class for %v", x[i])
+		}
+
+		// a point on the boundary through the
+		// origin should be a coin flip
+		guess, err := model.Predict([]float64{0, 0})
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, 0.5, guess[0], "The decision boundary should go through the origin")
+	}
+}
+
+// test i+j > 5 using Newton's method
+func TestThreeDimensionalPlaneNewtonShouldPass1(t *testing.T) {
+	var err error
+
+	model := NewLogistic(base.NewtonMethod, 0, 0, 8, threeDX, threeDY)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var guess []float64
+
+	for i := -20; i < 20; i++ {
+		for j := -20; j < 20; j++ {
+			guess, err = model.Predict([]float64{float64(i), float64(j)})
+
+			if i+j > 5 {
+				assert.True(t, guess[0] > 0.5, "Guess should be more likely to be 1")
+				assert.True(t, guess[0] < 1.001, "Guess should not exceed 1 ever")
+			} else {
+				assert.True(t, guess[0] < 0.5, "Guess should be more likely to be 0")
+				assert.True(t, guess[0] > -0.001, "Guess should not be below 0 even")
+			}
+
+			assert.Len(t, guess, 1, "Length of a Logistic model output from the hypothesis should always be a 1 dimensional vector. Never multidimensional.")
+			assert.Nil(t, err, "Prediction error should be nil")
+		}
+	}
+}
+
+// Newton's method on overlapping (non-separable)
+// gaussian clusters should reach the maximum
+// likelihood, where the gradient vanishes
+func TestGaussianNewtonShouldPass1(t *testing.T) {
+	var err error
+
+	model := NewLogistic(base.NewtonMethod, 0, 0, 15, gaussianX, gaussianY)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	for j := range model.Parameters {
+		dj, err := model.Dj(j)
+		assert.Nil(t, err, "Derivative error should be nil")
+		assert.InDelta(t, 0, dj, 1e-6, "Gradient of the log-likelihood should be ~0 at the optimum")
+	}
+}
+
+// Newton's method converges quickly on non-separable
+// data, so it should stop well before the maximum
+// number of iterations
+func TestGaussianNewtonConvergenceShouldPass1(t *testing.T) {
+	model := NewLogistic(base.NewtonMethod, 0, 0, 100, gaussianX, gaussianY)
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	assert.True(t, model.Iterations() > 0, "Model should have gone through at least one iteration")
+	assert.True(t, model.Iterations() < 100, "Model should have converged before the maximum number of iterations - went through %v", model.Iterations())
+
+	for j := range model.Parameters {
+		dj, err := model.Dj(j)
+		assert.Nil(t, err, "Derivative error should be nil")
+		assert.InDelta(t, 0, dj, 1e-6, "Gradient of the log-likelihood should be ~0 at the optimum")
+	}
+}
+
+// with regularization Newton's method should reach
+// the maximum of the ridge penalized log-likelihood,
+// shrinking θ, even when λ is larger than the
+// smallest eigenvalue of the data's Hessian
+func TestGaussianNewtonRidgeShouldPass1(t *testing.T) {
+	unregularized := NewLogistic(base.NewtonMethod, 0, 0, 25, gaussianX, gaussianY)
+	assert.Nil(t, unregularized.Learn(), "Learning error should be nil")
+
+	model := NewLogistic(base.NewtonMethod, 0, 50, 25, gaussianX, gaussianY)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	gradient, err := model.PartialDj(0, len(gaussianX))
+	assert.Nil(t, err, "Derivative error should be nil")
+	for j := range gradient {
+		if j != 0 {
+			gradient[j] -= 50 * model.Parameters[j]
+		}
+		assert.InDelta(t, 0, gradient[j], 1e-6, "Gradient of the penalized log-likelihood should be ~0 at the optimum")
+	}
+
+	for j := 1; j < len(model.Parameters); j++ {
+		assert.True(t, math.Abs(model.Parameters[j]) < math.Abs(unregularized.Parameters[j]), "Regularization should shrink θ[%v] (%v vs %v)", j, model.Parameters[j], unregularized.Parameters[j])
+	}
+}
+
+// Newton's method needs a twice differentiable
+// penalty, so L1 regularization should error
+func TestGaussianNewtonShouldFail1(t *testing.T) {
+	model := NewLogistic(base.NewtonMethod, 0, 1, 15, gaussianX, gaussianY)
+	model.RegularizationType = base.L1
+	err := model.Learn()
+	assert.NotNil(t, err, "Learning error should not be nil")
+}
+
 //* Test Online Learning through channels *//
 
+func TestLogisticCostShouldPass1(t *testing.T) {
+	model := NewLogistic(base.BatchGA, .0001, 0, 4000, twoDX, twoDY)
+
+	// with θ = 0 every prediction is 1/2
+	before, err := model.J()
+	assert.Nil(t, err, "Cost error should be nil")
+	assert.InDelta(t, math.Log(2), before, 1e-9, "Cost of the zero parameter vector should be log(2)")
+
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	after, err := model.J()
+	assert.Nil(t, err, "Cost error should be nil")
+	assert.True(t, after < before, "Cost (%v) should go down after learning (from %v)", after, before)
+
+	// a confidently wrong model should have a
+	// large but finite cost
+	for i := range model.Parameters {
+		model.Parameters[i] *= -1e6
+	}
+	wrong, err := model.J()
+	assert.Nil(t, err, "Cost error should be nil")
+	assert.False(t, math.IsInf(wrong, 0) || math.IsNaN(wrong), "Cost should be finite even when predictions are exactly wrong")
+	assert.True(t, wrong > before, "Cost (%v) of a confidently wrong model should be large", wrong)
+}
+
+// imbalanced data with 20 negative examples for
+// every positive one, where the classes overlap
+func imbalancedData() ([][]float64, []float64) {
+	r := rand.New(rand.NewSource(1))
+
+	x := [][]float64{}
+	y := []float64{}
+	for i := 0; i < 2100; i++ {
+		if i%21 == 0 {
+			x = append(x, []float64{r.NormFloat64() + 2})
+			y = append(y, 1)
+		} else {
+			x = append(x, []float64{r.NormFloat64()})
+			y = append(y, 0)
+		}
+	}
+
+	return x, y
+}
+
+// recall returns the fraction of positive
+// examples the model predicts as positive
+func recall(model *Logistic, x [][]float64, y []float64) float64 {
+	var found, positives float64
+	for i := range x {
+		if y[i] != 1 {
+			continue
+		}
+		positives++
+
+		guess, _ := model.Predict(x[i])
+		if guess[0] > 0.5 {
+			found++
+		}
+	}
+
+	return found / positives
+}
+
+func TestClassWeightsShouldPass1(t *testing.T) {
+	x, y := imbalancedData()
+
+	model := NewLogistic(base.NewtonMethod, 0, 0, 0, x, y)
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	unweighted := recall(model, x, y)
+
+	weighted := NewLogistic(base.NewtonMethod, 0, 0, 0, x, y)
+	weighted.ClassWeights = [2]float64{1, 20}
+	err = weighted.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	assert.True(t, unweighted < 0.5, "Recall on the rare class (%v) should be poor without class weights", unweighted)
+	assert.True(t, recall(weighted, x, y) > 0.75, "Recall on the rare class (%v) should improve when it's up-weighted (from %v)", recall(weighted, x, y), unweighted)
+
+	// weights of 1 are the same as no weights
+	ones := NewLogistic(base.BatchGA, 1e-3, 0, 500, x, y)
+	ones.ClassWeights = [2]float64{1, 1}
+	err = ones.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	none := NewLogistic(base.BatchGA, 1e-3, 0, 500, x, y)
+	err = none.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.Equal(t, none.Parameters, ones.Parameters, "Class weights of 1 shouldn't change the model")
+}
+
+func TestPredictLabelShouldPass1(t *testing.T) {
+	x, y := imbalancedData()
+
+	model := NewLogistic(base.NewtonMethod, 0, 0, 0, x, y)
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	// lowering the threshold should find more
+	// of the rare class
+	var atHalf, atTenth, positives int
+	for i := range x {
+		if y[i] != 1 {
+			continue
+		}
+		positives++
+
+		guess, err := model.Predict(x[i])
+		assert.Nil(t, err, "Prediction error should be nil")
+
+		label, err := model.PredictLabel(x[i], 0.5)
+		assert.Nil(t, err, "Prediction error should be nil")
+		if guess[0] > 0.5 {
+			assert.Equal(t, 1, label, "Label should be 1 when the probability is over the threshold")
+		} else {
+			assert.Equal(t, 0, label, "Label should be 0 when the probability isn't over the threshold")
+		}
+		atHalf += label
+
+		label, err = model.PredictLabel(x[i], 0.1)
+		assert.Nil(t, err, "Prediction error should be nil")
+		atTenth += label
+	}
+
+	assert.True(t, atTenth > atHalf, "A lower threshold (%v of %v found) should have better recall than 0.5 (%v found)", atTenth, positives, atHalf)
+
+	label, err := model.PredictLabel([]float64{100}, 1)
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, 0, label, "Nothing should be over a threshold of 1")
+}
+
+func TestPredictLabelShouldFail1(t *testing.T) {
+	model := NewLogistic(base.BatchGA, 1e-4, 0, 0, nil, nil, 2)
+
+	_, err := model.PredictLabel([]float64{1, 2}, 1.5)
+	assert.NotNil(t, err, "Prediction error should not be nil with a threshold over 1")
+
+	_, err = model.PredictLabel([]float64{1, 2}, -0.1)
+	assert.NotNil(t, err, "Prediction error should not be nil with a negative threshold")
+
+	_, err = model.PredictLabel([]float64{1, 2}, math.NaN())
+	assert.NotNil(t, err, "Prediction error should not be nil with a NaN threshold")
+
+	_, err = model.PredictLabel([]float64{1}, 0.5)
+	assert.NotNil(t, err, "Prediction error should not be nil with the wrong number of features")
+}
+
 func TestOnlineOneDXShouldPass1(t *testing.T) {
 	// create the channel of data and errors
 	stream := make(chan base.Datapoint, 100)
@@ -591,6 +1004,93 @@ func TestOnlineOneDXShouldPass1(t *testing.T) {
 	fmt.Printf("Iter: %v\n", iter)
 }
 
+// onlineEvaluation streams 2000 points labeled by
+// whether x > 0 to an online Logistic model,
+// then drift more points with the labels flipped, and
+// returns the model's rolling accuracy over the
+// last 200 points
+func onlineEvaluation(t *testing.T, drift int) float64 {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewLogistic(base.StochasticGA, 1e-3, 0, 0, nil, nil, 1).WithEvaluation(200)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	go func() {
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 2000+drift; i++ {
+			x := 20*r.Float64() - 10
+
+			y := 0.0
+			if (x > 0) != (i >= 2000) {
+				y = 1
+			}
+
+			stream <- base.Datapoint{X: []float64{x}, Y: []float64{y}}
+		}
+
+		close(stream)
+	}()
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	assert.Equal(t, 2000+drift, model.Evaluation.Seen(), "Every datapoint should be evaluated")
+	assert.True(t, model.Evaluation.Cost() > 0, "Rolling cost should be positive")
+
+	return model.Evaluation.Accuracy()
+}
+
+func TestOnlineEvaluationShouldPass1(t *testing.T) {
+	accuracy := onlineEvaluation(t, 0)
+	assert.True(t, accuracy > 0.95, "Rolling accuracy (%v) should be high on points the model hasn't trained on", accuracy)
+
+	// when the labels flip the model should
+	// start getting points wrong
+	drifted := onlineEvaluation(t, 200)
+	assert.True(t, drifted < 0.5, "Rolling accuracy (%v) should drop when the data drifts", drifted)
+}
+
+// learning online with normalization shouldn't
+// change the datapoints the producer sent
+func TestOnlineNormalizeShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewLogistic(base.StochasticGA, .0001, 0, 0, nil, nil, 2)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {}, true)
+
+	var sent [][]float64
+	for i := -50.0; i < 50; i++ {
+		x := []float64{i, 2*i + 1}
+		sent = append(sent, x)
+
+		y := 0.0
+		if i > 0 {
+			y = 1
+		}
+
+		stream <- base.Datapoint{
+			X: x,
+			Y: []float64{y},
+		}
+	}
+
+	close(stream)
+
+	err, more := <-errors
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.False(t, more, "There should be no errors returned")
+
+	for i, x := range sent {
+		j := float64(i) - 50
+		assert.Equal(t, []float64{j, 2*j + 1}, x, "Streamed datapoints should not be normalized in place")
+	}
+}
+
 func TestOnlineOneDXShouldFail1(t *testing.T) {
 	// create the channel of data and errors
 	stream := make(chan base.Datapoint, 1000)
@@ -665,6 +1165,57 @@ func TestOnlineOneDXShouldFail3(t *testing.T) {
 	assert.NotNil(t, err, "Learning error should not be nil")
 }
 
+func TestOnlineOneDXShouldFail4(t *testing.T) {
+	// create the channel of data and errors
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewLogistic(base.StochasticGA, .0001, 0, 0, nil, nil, 1)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	// mix points with a missing or too long
+	// result in with valid ones. The invalid
+	// points should be reported and skipped
+	// without crashing the model
+	go func() {
+		for n := 0; n < 500; n++ {
+			i := float64(n)*0.16 - 40
+			var y float64
+			if 10+i/2 > 0 {
+				y = 1
+			}
+			stream <- base.Datapoint{
+				X: []float64{i},
+				Y: []float64{},
+			}
+			stream <- base.Datapoint{
+				X: []float64{i},
+				Y: []float64{y, 1},
+			}
+			stream <- base.Datapoint{
+				X: []float64{i},
+				Y: []float64{y},
+			}
+		}
+
+		// close the dataset
+		close(stream)
+	}()
+
+	count := 0
+	for {
+		_, more := <-errors
+		if !more {
+			break
+		}
+		count++
+	}
+
+	assert.Equal(t, 1000, count, "There should be an error for every invalid point")
+	assert.NotEqual(t, 0.0, model.Parameters[1], "The model should still learn from the valid points")
+}
+
 func TestOnlineFourDXShouldPass1(t *testing.T) {
 	// create the channel of data and errors
 	stream := make(chan base.Datapoint, 100)
@@ -793,6 +1344,45 @@ func TestOnlineTwoDXNormalizedShouldPass1(t *testing.T) {
 //* Test Persistance to file *//
 
 // test persisting y=x to file
+func TestLogisticFeatureNamesShouldPass1(t *testing.T) {
+	model := NewLogistic(base.BatchGA, .000001, 0, 800, nil, nil, 3)
+	model.Parameters = []float64{0.5, 1, -2, 3}
+	model.FeatureNames = []string{"height", "weight", "age"}
+
+	assert.Equal(t, 3, model.Features(), "Model should take 3 features")
+
+	_, err := model.Predict([]float64{1, 2})
+	assert.NotNil(t, err, "Predicting with a missing feature should return an error")
+	assert.Contains(t, err.Error(), `Expected feature "age" at index 2`, "Error should name the missing feature")
+
+	err = model.PersistToFile("/tmp/.goml/LogisticNames.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	restored := NewLogistic(base.BatchGA, .000001, 0, 800, nil, nil, 3)
+	err = restored.RestoreFromFile("/tmp/.goml/LogisticNames.json")
+	assert.Nil(t, err, "Restoring error should be nil")
+	assert.Equal(t, model.Parameters, restored.Parameters, "Parameters should be restored")
+	assert.Equal(t, model.FeatureNames, restored.FeatureNames, "Feature names should be restored")
+
+	// without an intercept there's one
+	// parameter per feature
+	model.FitIntercept = false
+	model.Parameters = model.Parameters[1:]
+	assert.Equal(t, 3, model.Features(), "Model should take 3 features without an intercept")
+}
+
+func TestLogisticFeatureNamesShouldFail1(t *testing.T) {
+	model := NewLogistic(base.BatchGA, .000001, 0, 800, nil, nil, 3)
+
+	// 3 features but only 2 names
+	err := ioutil.WriteFile("/tmp/.goml/LogisticBadNames.json", []byte(`{"theta":[1,2,3,4],"feature_names":["a","b"]}`), os.ModePerm)
+	assert.Nil(t, err, "Writing file error should be nil")
+
+	err = model.RestoreFromFile("/tmp/.goml/LogisticBadNames.json")
+	assert.NotNil(t, err, "Restoring the wrong number of feature names should return an error")
+	assert.Equal(t, []float64{0, 0, 0, 0}, model.Parameters, "Model shouldn't change after a failed restore")
+}
+
 func TestPersistLogisticShouldPass1(t *testing.T) {
 	var err error
 
@@ -0,0 +1,53 @@
+package linear
+
+import (
+	"math"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+// lossGradient returns the derivative of the loss
+// function of the given type with respect to the
+// prediction, for the residual r = y - h(x). This
+// takes the place of the residual in the gradient
+//
+//     dj = ψ(r)·x[j]
+//
+// For the squared loss ψ(r) is just the residual,
+// while for the Huber loss with threshold δ the
+// residual is clamped to [-δ,δ] so an outlier can
+// only pull on θ as hard as a residual of δ does.
+func lossGradient(kind base.LossType, delta, r float64) float64 {
+	if kind != base.HuberLoss {
+		return r
+	}
+
+	return math.Max(-delta, math.Min(delta, r))
+}
+
+// loss returns the loss of the given type for the
+// residual r = y - h(x), scaled to match the squared
+// loss r² (so J(θ) is the same for both for small
+// residuals.) The Huber loss with threshold δ is
+//
+//     r²           if |r| <= δ
+//     2δ|r| - δ²   otherwise
+func loss(kind base.LossType, delta, r float64) float64 {
+	if kind != base.HuberLoss || math.Abs(r) <= delta {
+		return r * r
+	}
+
+	return 2*delta*math.Abs(r) - delta*delta
+}
+
+// probabilityEpsilon is how far predicted probabilities
+// are kept from 0 and 1 when taking their log, so the
+// log-likelihood stays finite even when the model is
+// confidently wrong
+const probabilityEpsilon = 1e-15
+
+// logProbability returns log(p) with p clamped to
+// [ε, 1-ε] (see probabilityEpsilon)
+func logProbability(p float64) float64 {
+	return math.Log(math.Max(probabilityEpsilon, math.Min(1-probabilityEpsilon, p)))
+}
@@ -0,0 +1,167 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// singularTolerance is the relative size a pivot
+// must exceed (compared to the largest entry of
+// the matrix) for the matrix to be considered
+// invertible
+const singularTolerance = 1e-12
+
+// normalMatrix builds XᵀX + λI and Xᵀy from the
+// training set x and results y, where X is x with
+// the constant term 1 prepended to each row (just
+// like the hypothesis in Predict does) if
+// fitIntercept is true, and just x otherwise.
+//
+// The regularization λ is only added to the diagonal
+// for the non-constant terms so the bias isn't
+// penalized, matching the gradient methods.
+//
+// If weights isn't nil then each example's row is
+// scaled by its weight, giving XᵀWX + λI and XᵀWy
+// for weighted least squares.
+func normalMatrix(x [][]float64, y []float64, weights []float64, regularization float64, fitIntercept bool) ([][]float64, []float64) {
+	offset := intercept(fitIntercept)
+	features := len(x[0]) + offset
+
+	xTx := make([][]float64, features)
+	for i := range xTx {
+		xTx[i] = make([]float64, features)
+	}
+	xTy := make([]float64, features)
+
+	row := make([]float64, features)
+	for i := range x {
+		if fitIntercept {
+			row[0] = 1
+		}
+		copy(row[offset:], x[i])
+
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+
+		for a := range row {
+			xTy[a] += w * row[a] * y[i]
+			for b := range row {
+				xTx[a][b] += w * row[a] * row[b]
+			}
+		}
+	}
+
+	for j := offset; j < features; j++ {
+		xTx[j][j] += regularization
+	}
+
+	return xTx, xTy
+}
+
+// invert returns the inverse of the square matrix
+// a using Gauss-Jordan elimination with partial
+// pivoting. a is not modified. An error is returned
+// if a is not square or is (numerically) singular.
+func invert(a [][]float64) ([][]float64, error) {
+	n := len(a)
+
+	// scale is the magnitude of the largest element,
+	// used to decide when a pivot is effectively 0
+	var scale float64
+	for i := range a {
+		if len(a[i]) != n {
+			return nil, fmt.Errorf("ERROR: matrix must be square to invert! Row %v has length %v but there are %v rows", i, len(a[i]), n)
+		}
+		for j := range a[i] {
+			scale = math.Max(scale, math.Abs(a[i][j]))
+		}
+	}
+
+	// augment a with the identity matrix
+	aug := make([][]float64, n)
+	for i := range aug {
+		aug[i] = make([]float64, 2*n)
+		copy(aug[i], a[i])
+		aug[i][n+i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		// find the row with the largest pivot
+		pivot := col
+		for i := col + 1; i < n; i++ {
+			if math.Abs(aug[i][col]) > math.Abs(aug[pivot][col]) {
+				pivot = i
+			}
+		}
+
+		if scale == 0 || math.Abs(aug[pivot][col]) <= singularTolerance*scale {
+			return nil, fmt.Errorf("ERROR: matrix is singular (or very nearly so) and can't be inverted")
+		}
+
+		aug[col], aug[pivot] = aug[pivot], aug[col]
+
+		p := aug[col][col]
+		for j := range aug[col] {
+			aug[col][j] /= p
+		}
+
+		for i := range aug {
+			if i == col || aug[i][col] == 0 {
+				continue
+			}
+
+			factor := aug[i][col]
+			for j := range aug[i] {
+				aug[i][j] -= factor * aug[col][j]
+			}
+		}
+	}
+
+	inverse := make([][]float64, n)
+	for i := range inverse {
+		inverse[i] = aug[i][n:]
+	}
+
+	return inverse, nil
+}
+
+// matVec returns the matrix-vector product a·v
+func matVec(a [][]float64, v []float64) []float64 {
+	result := make([]float64, len(a))
+	for i := range a {
+		for j := range v {
+			result[i] += a[i][j] * v[j]
+		}
+	}
+
+	return result
+}
+
+// normalVector builds Xᵀy (or XᵀWy if weights isn't
+// nil) for the training set x and results y, where X
+// is built just like in normalMatrix. This lets models
+// with several outputs share XᵀX and only compute the
+// right hand side once per output.
+func normalVector(x [][]float64, y []float64, weights []float64, fitIntercept bool) []float64 {
+	offset := intercept(fitIntercept)
+	xTy := make([]float64, len(x[0])+offset)
+
+	for i := range x {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+
+		if fitIntercept {
+			xTy[0] += w * y[i]
+		}
+		for j := range x[i] {
+			xTy[j+offset] += w * x[i][j] * y[i]
+		}
+	}
+
+	return xTy
+}
@@ -0,0 +1,802 @@
+package linear
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+// MultiLeastSquares implements multi-output (or
+// multivariate) linear regression with a Least
+// Squares cost function. It's a generalization of
+// LeastSquares in the same way Softmax generalizes
+// Logistic: each example has a vector of expected
+// results y, and the model holds one parameter vector
+// θ[k] per output, so Predict returns the full vector
+// of outputs h(θ,x)[k] = θ[k]x.
+//
+// https://en.wikipedia.org/wiki/General_linear_model
+//
+// The squared error of each output only depends on
+// its own parameter vector, so the outputs are fit
+// independently, but the work that only depends on the
+// training set is shared between them. Gradient ascent
+// goes over each example once per iteration to update
+// every output, and LearnNormalEquation only builds and
+// inverts XᵀX + λI once, no matter how many outputs
+// there are.
+type MultiLeastSquares struct {
+	// alpha and maxIterations are used only for
+	// GradientAscent during learning. If maxIterations
+	// is 0, then a default maximum (250 iterations) is
+	// used. GradientAscent stops early once the
+	// algorithm detects convergence (see Tolerance.)
+	//
+	// regularization is used as the regularization
+	// term to avoid overfitting within regression.
+	// Having a regularization term of 0 is like having
+	// _no_ data regularization. The higher the term,
+	// the greater the bias on the regression
+	alpha          float64
+	regularization float64
+	maxIterations  int
+
+	// method is the optimization method used when training
+	// the model
+	method base.OptimizationMethod
+
+	// Schedule decays the learning rate α over the
+	// course of training (see base.LearningRateSchedule.)
+	// If left nil the learning rate stays constant.
+	Schedule base.LearningRateSchedule
+
+	// Clipping limits the size of the gradient before
+	// every step of gradient ascent, scaling down huge
+	// gradients rather than letting learning diverge
+	// (see base.GradientClipping.) Each output's
+	// gradient is clipped separately. If left nil
+	// the gradient is never clipped.
+	Clipping *base.GradientClipping
+
+	// Tolerance is used to detect convergence when
+	// training with base.BatchGA: learning stops once
+	// the parameter vectors move less than Tolerance
+	// (by the L2 norm) over an iteration. If left 0,
+	// base.DefaultTolerance is used, and a negative
+	// tolerance turns early stopping off.
+	Tolerance float64
+
+	// iterations is the number of iterations the
+	// last call to Learn actually went through
+	iterations int
+
+	// FitIntercept is whether the model fits the
+	// constant term θ[k][0] (the intercept) of each
+	// output. Defaults to true. When false the model
+	// is forced through the origin and each θ[k] has
+	// one parameter per feature instead of one extra.
+	FitIntercept bool
+
+	// RegularizationType is the penalty used along with
+	// the regularization term (base.L2 if left empty.)
+	// L1Ratio is the fraction of the regularization given
+	// to the L1 penalty when using base.ElasticNet (the
+	// rest goes to the L2 penalty.)
+	RegularizationType base.RegularizationType
+	L1Ratio            float64
+
+	// trainingSet and expectedResults are the
+	// 'x', and 'y' of the data, expressed as
+	// vectors, that the model can optimize from.
+	// expectedResults[i] holds one value per
+	// output for the example trainingSet[i]
+	trainingSet     [][]float64
+	expectedResults [][]float64
+
+	// Parameters holds the model's parameter vector
+	// θ[k] for each output k
+	Parameters [][]float64 `json:"theta"`
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
+	// Output is the io.Writer used for logging
+	// and printing. Defaults to os.Stdout.
+	Output io.Writer
+}
+
+// NewMultiLeastSquares returns a pointer to the multi-output
+// linear model initialized with the learning rate alpha, the
+// training set trainingSet, and the expected results (with
+// one value per output for each example) expectedResults.
+// The number of outputs is taken from expectedResults, and
+// each output's parameter vector starts as the zero vector.
+//
+// Example Multi-Output Least Squares (Batch GA):
+//
+//     // optimization method: Batch Gradient Ascent
+//     // Learning rate: 1e-4
+//     // Regularization term: 6
+//     // Max Iterations: 800
+//     // Dataset to learn from: testX
+//     // Expected results dataset: testY ([][]float64)
+//     model := NewMultiLeastSquares(base.BatchGA, 1e-4, 6, 800, testX, testY)
+//
+//     err := model.Learn()
+//     if err != nil {
+//         panic("SOME ERROR!! RUN!")
+//     }
+//
+//     // guess holds one prediction per output
+//     guess, err = model.Predict([]float64{10000,6})
+//     if err != nil {
+//         panic("AAAARGGGH! SHIVER ME TIMBERS! THESE ROTTEN SCOUNDRELS FOUND AN ERROR!!!")
+//     }
+func NewMultiLeastSquares(method base.OptimizationMethod, alpha, regularization float64, maxIterations int, trainingSet [][]float64, expectedResults [][]float64) *MultiLeastSquares {
+	var outputs int
+	if len(expectedResults) != 0 {
+		outputs = len(expectedResults[0])
+	}
+
+	params := make([][]float64, outputs)
+	for k := range params {
+		if len(trainingSet) == 0 {
+			params[k] = []float64{}
+		} else {
+			params[k] = make([]float64, len(trainingSet[0])+1)
+		}
+	}
+
+	return &MultiLeastSquares{
+		alpha:          alpha,
+		regularization: regularization,
+		maxIterations:  maxIterations,
+
+		method: method,
+
+		trainingSet:     trainingSet,
+		expectedResults: expectedResults,
+
+		// initialize each θ[k] as the zero vector
+		// (that is, the vector of all zeros)
+		Parameters: params,
+
+		FitIntercept: true,
+
+		Output: os.Stdout,
+	}
+}
+
+// UpdateTrainingSet takes in a new training set (variable x)
+// as well as a new result set (y). This could be useful if
+// you want to retrain a model starting with the parameter
+// vectors of a previous training session, but most of the
+// time wouldn't be used.
+func (m *MultiLeastSquares) UpdateTrainingSet(trainingSet [][]float64, expectedResults [][]float64) error {
+	if len(trainingSet) == 0 {
+		return fmt.Errorf("Error: length of given training set is 0! Need data!")
+	}
+	if len(expectedResults) == 0 {
+		return fmt.Errorf("Error: length of given result data set is 0! Need expected results!")
+	}
+
+	m.trainingSet = trainingSet
+	m.expectedResults = expectedResults
+
+	return nil
+}
+
+// UpdateLearningRate set's the learning rate of the model
+// to the given float64.
+func (m *MultiLeastSquares) UpdateLearningRate(a float64) {
+	m.alpha = a
+}
+
+// LearningRate returns the learning rate α for gradient
+// descent to optimize the model. Could vary as a function
+// of something else later, potentially.
+func (m *MultiLeastSquares) LearningRate() float64 {
+	return m.alpha
+}
+
+// LearningRateSchedule returns the schedule the learning
+// rate decays with during training (nil for a constant
+// learning rate.)
+func (m *MultiLeastSquares) LearningRateSchedule() base.LearningRateSchedule {
+	return m.Schedule
+}
+
+// GradientClipping returns the limits the gradient is
+// clipped to during training (nil if it isn't clipped.)
+func (m *MultiLeastSquares) GradientClipping() *base.GradientClipping {
+	return m.Clipping
+}
+
+// Examples returns the number of training examples (m)
+// that the model currently is training from.
+func (m *MultiLeastSquares) Examples() int {
+	return len(m.trainingSet)
+}
+
+// Outputs returns the number of outputs the model
+// predicts (the length of the vector returned by
+// Predict.)
+func (m *MultiLeastSquares) Outputs() int {
+	return len(m.Parameters)
+}
+
+// Features returns the number of features the model
+// takes as input (the length of x given to Predict,)
+// not counting the intercept
+func (m *MultiLeastSquares) Features() int {
+	if len(m.Parameters) == 0 || len(m.Parameters[0]) == 0 {
+		return 0
+	}
+
+	return len(m.Parameters[0]) - intercept(m.FitIntercept)
+}
+
+// MaxIterations returns the number of maximum iterations
+// the model will go through in GradientAscent, in the
+// worst case
+func (m *MultiLeastSquares) MaxIterations() int {
+	return m.maxIterations
+}
+
+// Iterations returns the number of iterations the last
+// call to Learn actually went through, which can be less
+// than MaxIterations if learning converged early.
+func (m *MultiLeastSquares) Iterations() int {
+	return m.iterations
+}
+
+// tolerance returns the tolerance used to detect
+// convergence, using base.DefaultTolerance if the
+// model's Tolerance is left as 0
+func (m *MultiLeastSquares) tolerance() float64 {
+	if m.Tolerance == 0 {
+		return base.DefaultTolerance
+	}
+
+	return m.Tolerance
+}
+
+// Predict takes in a variable x (an array of floats,) and
+// finds the value of the hypothesis function for each output
+// given the current parameter vectors θ, returning them in
+// order of the outputs
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (m *MultiLeastSquares) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	if len(m.Parameters) == 0 {
+		return nil, fmt.Errorf("Error: Model has no outputs to predict! Train with at least one expected result per example first\n")
+	}
+	if len(x)+intercept(m.FitIntercept) != len(m.Parameters[0]) {
+		return nil, fmt.Errorf("Error: %w: Parameter vector should be %v longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n", base.ErrDimensionMismatch, intercept(m.FitIntercept), len(x), len(m.Parameters[0]))
+	}
+
+	if len(normalize) != 0 && normalize[0] {
+		x = base.NormalizedPoint(x)
+	}
+
+	result := make([]float64, len(m.Parameters))
+	for k := range m.Parameters {
+		result[k] = hypothesis(m.Parameters[k], x, m.FitIntercept)
+	}
+
+	return result, nil
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (m *MultiLeastSquares) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	var features int
+	if len(m.Parameters) != 0 {
+		features = len(m.Parameters[0]) - intercept(m.FitIntercept)
+	}
+
+	return base.PredictBatch(x, features, func(row []float64) ([]float64, error) {
+		return m.Predict(row, normalize...)
+	})
+}
+
+// checkTrainingSet returns an error (printing it to
+// the model's Output) if the training set or the
+// expected results are missing or don't line up
+func (m *MultiLeastSquares) checkTrainingSet() error {
+	var err error
+
+	examples := len(m.trainingSet)
+	if examples == 0 || len(m.trainingSet[0]) == 0 {
+		err = fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
+	} else if len(m.expectedResults) == 0 || len(m.expectedResults[0]) == 0 {
+		err = fmt.Errorf("ERROR: Attempting to learn with no expected results! This isn't an unsupervised model!! You'll need to include data before you learn :)\n")
+	} else if len(m.expectedResults) != examples {
+		err = fmt.Errorf("ERROR: Number of expected results (%v) doesn't match the number of training examples (%v)!\n", len(m.expectedResults), examples)
+	} else {
+		for i := range m.expectedResults {
+			if len(m.expectedResults[i]) != len(m.expectedResults[0]) {
+				err = fmt.Errorf("ERROR: Expected results for example %v have %v outputs, but the first example has %v!\n", i, len(m.expectedResults[i]), len(m.expectedResults[0]))
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		m.logf(err.Error())
+	}
+
+	return err
+}
+
+// sizeParameters sets up the parameter vectors for the
+// dimensions of the training set, keeping the current
+// vectors if they already fit so training can pick up
+// where a previous session left off
+func (m *MultiLeastSquares) sizeParameters() {
+	features := len(m.trainingSet[0]) + intercept(m.FitIntercept)
+	outputs := len(m.expectedResults[0])
+
+	if len(m.Parameters) == outputs {
+		fits := true
+		for k := range m.Parameters {
+			m.Parameters[k] = sizeParameters(m.Parameters[k], len(m.trainingSet[0]), m.FitIntercept)
+			fits = fits && len(m.Parameters[k]) == features
+		}
+		if fits {
+			return
+		}
+	}
+
+	m.Parameters = make([][]float64, outputs)
+	for k := range m.Parameters {
+		m.Parameters[k] = make([]float64, features)
+	}
+}
+
+// Learn takes the struct's dataset and expected results and runs
+// gradient ascent on them, optimizing each output's parameter
+// vector θ[k] so you can predict based on those results. Only
+// base.BatchGA and base.StochasticGA are supported.
+func (m *MultiLeastSquares) Learn() error {
+	err := m.checkTrainingSet()
+	if err != nil {
+		return err
+	}
+
+	m.sizeParameters()
+
+	m.logf("Training:\n\tModel: Multi-Output Least Squares Regression\n\tOptimization Method: %v\n\tTraining Examples: %v\n\tOutputs: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", m.method, len(m.trainingSet), len(m.Parameters), len(m.trainingSet[0]), m.alpha, m.regularization)
+
+	// if the iterations given is 0, set it to be
+	// 250 (seems reasonable base value)
+	if m.maxIterations == 0 {
+		m.maxIterations = 250
+	}
+
+	if m.method == base.BatchGA {
+		err = func() error {
+			tolerance := m.tolerance()
+			m.iterations = 0
+
+			// Stop iterating if the number of iterations exceeds
+			// the limit
+			for m.iterations < m.maxIterations {
+				alpha := m.Schedule.Rate(m.alpha, m.iterations)
+
+				dj, err := m.Dj()
+				if err != nil {
+					return err
+				}
+				for k := range dj {
+					m.Clipping.Clip(dj[k])
+				}
+
+				// simultaneously update every output's
+				// parameter vector, keeping track of how
+				// far they all move
+				var change float64
+				for k := range m.Parameters {
+					for j := range m.Parameters[k] {
+						newθ := m.prox(j, m.Parameters[k][j]+alpha*dj[k][j], alpha)
+						if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
+							return fmt.Errorf("Sorry dude! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
+						}
+
+						change += (newθ - m.Parameters[k][j]) * (newθ - m.Parameters[k][j])
+						m.Parameters[k][j] = newθ
+					}
+				}
+
+				m.iterations++
+
+				// stop once θ has converged
+				if math.Sqrt(change) < tolerance {
+					break
+				}
+			}
+
+			m.logf("Went through %v iterations.\n", m.iterations)
+
+			return nil
+		}()
+	} else if m.method == base.StochasticGA {
+		err = func() error {
+			for m.iterations = 0; m.iterations < m.maxIterations; m.iterations++ {
+				alpha := m.Schedule.Rate(m.alpha, m.iterations)
+
+				for i := range m.trainingSet {
+					dij, err := m.Dij(i)
+					if err != nil {
+						return err
+					}
+					for k := range dij {
+						m.Clipping.Clip(dij[k])
+					}
+
+					for k := range m.Parameters {
+						for j := range m.Parameters[k] {
+							m.Parameters[k][j] = m.prox(j, m.Parameters[k][j]+alpha*dij[k][j], alpha)
+							if math.IsInf(m.Parameters[k][j], 0) || math.IsNaN(m.Parameters[k][j]) {
+								return fmt.Errorf("Sorry dude! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
+							}
+						}
+					}
+				}
+			}
+
+			m.logf("Went through %v iterations.\n", m.iterations)
+
+			return nil
+		}()
+	} else {
+		err = fmt.Errorf("Chose a training method not implemented for MultiLeastSquares regression")
+	}
+
+	if err != nil {
+		m.logf("\nERROR: Error while learning –\n\t%v\n\n", err)
+		return err
+	}
+
+	m.logf("Training Completed.\n%v\n\n", m)
+	return nil
+}
+
+// LearnNormalEquation takes the struct's dataset and expected
+// results and solves for the optimal parameter vectors directly
+// using the normal equations (see LeastSquares.LearnNormalEquation):
+//
+//     θ[k] = (XᵀX + λI)⁻¹Xᵀy[k]
+//
+// where y[k] is the column of expected results for output k.
+// (XᵀX + λI)⁻¹ doesn't depend on the outputs, so it's only
+// built and inverted once and then shared by every output.
+// Only L2 regularization is supported because the L1 penalty
+// has no closed form solution.
+//
+// An error is returned if XᵀX + λI is singular (for example
+// when one feature is a multiple of another and there is no
+// regularization.)
+func (m *MultiLeastSquares) LearnNormalEquation() error {
+	err := m.checkTrainingSet()
+	if err != nil {
+		return err
+	}
+
+	if m.RegularizationType == base.L1 || m.RegularizationType == base.ElasticNet {
+		err := fmt.Errorf("ERROR: The normal equations only have a closed form solution with L2 regularization! Use Learn for %v regularization\n", m.RegularizationType)
+		m.logf(err.Error())
+		return err
+	}
+
+	outputs := len(m.expectedResults[0])
+
+	m.logf("Training:\n\tModel: Multi-Output Least Squares Regression\n\tOptimization Method: Normal Equations\n\tTraining Examples: %v\n\tOutputs: %v\n\tFeatures: %v\n\tRegularization Parameter λ: %v\n...\n\n", len(m.trainingSet), outputs, len(m.trainingSet[0]), m.regularization)
+
+	// split the expected results up into
+	// one column per output
+	columns := make([][]float64, outputs)
+	for k := range columns {
+		columns[k] = make([]float64, len(m.expectedResults))
+		for i := range m.expectedResults {
+			columns[k][i] = m.expectedResults[i][k]
+		}
+	}
+
+	xTx, xTy := normalMatrix(m.trainingSet, columns[0], nil, m.regularization, m.FitIntercept)
+
+	inverse, err := invert(xTx)
+	if err != nil {
+		err = fmt.Errorf("ERROR: Can't solve the normal equations because XᵀX + λI is singular. Try adding regularization or removing redundant features.\n\t%v", err)
+		m.logf("\nERROR: Error while learning –\n\t%v\n\n", err)
+		return err
+	}
+
+	m.Parameters = make([][]float64, outputs)
+	m.Parameters[0] = matVec(inverse, xTy)
+	for k := 1; k < outputs; k++ {
+		m.Parameters[k] = matVec(inverse, normalVector(m.trainingSet, columns[k], nil, m.FitIntercept))
+	}
+
+	m.logf("Training Completed.\n%v\n\n", m)
+	return nil
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (m *MultiLeastSquares) logf(format string, a ...interface{}) {
+	if m.Verbose {
+		fmt.Fprintf(m.Output, format, a...)
+	}
+}
+
+// String implements the fmt interface for clean printing. Here
+// we're using it to print the model as the equation h(θ,x)[k]=...
+// for each output k, where h is the linear hypothesis model
+func (m *MultiLeastSquares) String() string {
+	if len(m.Parameters) == 0 {
+		m.logf("ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
+	}
+	offset := intercept(m.FitIntercept)
+	var buffer bytes.Buffer
+
+	for k, theta := range m.Parameters {
+		buffer.WriteString(fmt.Sprintf("h(θ,x)[%d] = ", k))
+		if m.FitIntercept {
+			buffer.WriteString(fmt.Sprintf("%.3f + ", theta[0]))
+		}
+
+		features := len(theta) - offset
+		for i := 1; i <= features; i++ {
+			buffer.WriteString(fmt.Sprintf("%.5f(x[%d])", theta[i-1+offset], i))
+
+			if i != features {
+				buffer.WriteString(" + ")
+			}
+		}
+
+		if k != len(m.Parameters)-1 {
+			buffer.WriteString("\n")
+		}
+	}
+
+	return buffer.String()
+}
+
+// Dj returns the derivative of the cost function J(θ) with
+// respect to every parameter of every output's parameter
+// vector, so Dj()[k][j] is the derivative with respect to
+// θ[k][j]. The whole gradient is computed in one pass over
+// the training set, with each example's predictions shared
+// by all of the outputs.
+func (m *MultiLeastSquares) Dj() ([][]float64, error) {
+	grad := make([][]float64, len(m.Parameters))
+	for k := range grad {
+		grad[k] = make([]float64, len(m.Parameters[k]))
+	}
+
+	for i := range m.trainingSet {
+		err := m.addDij(grad, i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m.regularizeDj(grad)
+
+	return grad, nil
+}
+
+// Dij returns the derivative of the cost function J(θ)
+// with respect to every parameter of every output's
+// parameter vector (like Dj) for only the training
+// example x[i]. Used in Stochastic Gradient Descent.
+func (m *MultiLeastSquares) Dij(i int) ([][]float64, error) {
+	if i < 0 || i > len(m.trainingSet)-1 {
+		return nil, fmt.Errorf("i (%v) would index out of the bounds of the training set data (len: %v)", i, len(m.trainingSet))
+	}
+
+	grad := make([][]float64, len(m.Parameters))
+	for k := range grad {
+		grad[k] = make([]float64, len(m.Parameters[k]))
+	}
+
+	err := m.addDij(grad, i)
+	if err != nil {
+		return nil, err
+	}
+
+	m.regularizeDj(grad)
+
+	return grad, nil
+}
+
+// addDij adds the unregularized derivative of the cost
+// function for the training example x[i] to grad
+func (m *MultiLeastSquares) addDij(grad [][]float64, i int) error {
+	prediction, err := m.Predict(m.trainingSet[i])
+	if err != nil {
+		return err
+	}
+
+	offset := intercept(m.FitIntercept)
+	for k := range grad {
+		diff := m.expectedResults[i][k] - prediction[k]
+
+		// account for constant term
+		if m.FitIntercept {
+			grad[k][0] += diff
+		}
+		for j := range m.trainingSet[i] {
+			grad[k][j+offset] += diff * m.trainingSet[i][j]
+		}
+	}
+
+	return nil
+}
+
+// regularizeDj applies the regularization term to
+// each value of the gradient grad
+func (m *MultiLeastSquares) regularizeDj(grad [][]float64) {
+	// notice that we don't count the
+	// constant term
+	for k := range grad {
+		for j := intercept(m.FitIntercept); j < len(grad[k]); j++ {
+			grad[k][j] = regularize(m.RegularizationType, m.L1Ratio, m.regularization, m.Parameters[k][j], grad[k][j])
+		}
+	}
+}
+
+// prox applies the L1 part of the regularization
+// penalty to the j-th value of a parameter vector
+// after a step of gradient ascent was taken to theta
+// with learning rate alpha (see shrink,) returning
+// the new value
+func (m *MultiLeastSquares) prox(j int, theta, alpha float64) float64 {
+	// notice that we don't count the
+	// constant term
+	if m.FitIntercept && j == 0 {
+		return theta
+	}
+
+	return shrink(m.RegularizationType, m.L1Ratio, m.regularization, alpha, theta)
+}
+
+// J returns the Least Squares cost function of the given
+// model, summed over every output. Could be useful in
+// testing convergence
+func (m *MultiLeastSquares) J() (float64, error) {
+	var sum float64
+
+	for i := range m.trainingSet {
+		prediction, err := m.Predict(m.trainingSet[i])
+		if err != nil {
+			return 0, err
+		}
+
+		for k := range prediction {
+			sum += (m.expectedResults[i][k] - prediction[k]) * (m.expectedResults[i][k] - prediction[k])
+		}
+	}
+
+	// add regularization term!
+	//
+	// notice that the constant term doesn't matter
+	for k := range m.Parameters {
+		for j := intercept(m.FitIntercept); j < len(m.Parameters[k]); j++ {
+			sum += penalty(m.RegularizationType, m.L1Ratio, m.regularization, m.Parameters[k][j])
+		}
+	}
+
+	return sum / float64(2*len(m.trainingSet)), nil
+}
+
+// Theta returns the parameter vectors θ (one per output)
+// for use in persisting the model
+func (m *MultiLeastSquares) Theta() [][]float64 {
+	return m.Parameters
+}
+
+// PersistToFile takes in an absolute filepath and saves the
+// parameter vectors θ (as a JSON array with one array per
+// output) to the file, which can be restored later.
+func (m *MultiLeastSquares) PersistToFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := json.Marshal(m.Parameters)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, bytes, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreFromFile takes in a path to the parameter vectors
+// saved with PersistToFile and assigns the model's parameter
+// vectors to them. An error is returned (and the model is
+// left untouched) if the saved vectors aren't all the same
+// length.
+//
+// The path must ba an absolute path or a path from the current
+// directory
+func (m *MultiLeastSquares) RestoreFromFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to restore your model from a file with no path! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var params [][]float64
+	err = json.Unmarshal(bytes, &params)
+	if err != nil {
+		return err
+	}
+
+	return m.restore(params)
+}
+
+// PersistToGob saves the parameter vectors θ to the given
+// file like PersistToFile, but encoded with encoding/gob
+// (see base.PersistToGob.)
+func (m *MultiLeastSquares) PersistToGob(path string) error {
+	return base.PersistToGob(path, m.Parameters)
+}
+
+// RestoreFromGob takes in a path to the parameter vectors
+// saved with PersistToGob and assigns the model's parameter
+// vectors to them, like RestoreFromFile.
+func (m *MultiLeastSquares) RestoreFromGob(path string) error {
+	var params [][]float64
+	err := base.RestoreFromGob(path, &params)
+	if err != nil {
+		return err
+	}
+
+	return m.restore(params)
+}
+
+// restore validates the dimensions of persisted
+// parameter vectors and assigns them to the model
+func (m *MultiLeastSquares) restore(params [][]float64) error {
+	if len(params) == 0 {
+		return fmt.Errorf("ERROR: restored model has no parameter vectors!")
+	}
+	for k := range params {
+		if len(params[k]) != len(params[0]) {
+			return fmt.Errorf("ERROR: restored parameter vector %v has length %v, but the first one has length %v!", k, len(params[k]), len(params[0]))
+		}
+	}
+
+	m.Parameters = params
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package linear
+
+import (
+	"testing"
+
+	"github.com/cdipaolo/goml/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// multiX and multiY hold the two planes
+// y[0] = 3 + x[0]/2 - x[1] and
+// y[1] = -1 + 2x[0] + x[1]/4
+var multiX [][]float64
+var multiY [][]float64
+
+func init() {
+	multiX = [][]float64{}
+	multiY = [][]float64{}
+	for i := -5.0; i < 5; i++ {
+		for j := -5.0; j < 5; j++ {
+			multiX = append(multiX, []float64{i, j})
+			multiY = append(multiY, []float64{3 + i/2 - j, -1 + 2*i + j/4})
+		}
+	}
+}
+
+func TestMultiLeastSquaresShouldPass1(t *testing.T) {
+	for _, method := range []base.OptimizationMethod{base.BatchGA, base.StochasticGA} {
+		model := NewMultiLeastSquares(method, 1e-3, 0, 2000, multiX, multiY)
+
+		err := model.Learn()
+		assert.Nil(t, err, "Learning error should be nil")
+		assert.Equal(t, 2, model.Outputs(), "Model should have one parameter vector per output")
+
+		for i := range multiX {
+			guess, err := model.Predict(multiX[i])
+			assert.Nil(t, err, "Prediction error should be nil")
+			assert.Len(t, guess, 2, "Prediction should have one value per output")
+			assert.InDelta(t, multiY[i][0], guess[0], 1e-2, "First output should be close to the expected result")
+			assert.InDelta(t, multiY[i][1], guess[1], 1e-2, "Second output should be close to the expected result")
+		}
+	}
+}
+
+// the normal equations should agree with
+// fitting each output with LeastSquares
+func TestMultiLeastSquaresNormalEquationShouldPass1(t *testing.T) {
+	model := NewMultiLeastSquares(base.BatchGA, 0, 1.5, 0, multiX, multiY)
+	err := model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	for k := 0; k < 2; k++ {
+		y := make([]float64, len(multiY))
+		for i := range multiY {
+			y[i] = multiY[i][k]
+		}
+
+		single := NewLeastSquares(base.BatchGA, 0, 1.5, 0, multiX, y)
+		err = single.LearnNormalEquation()
+		assert.Nil(t, err, "Learning error should be nil")
+
+		for j := range single.Parameters {
+			assert.InDelta(t, single.Parameters[j], model.Parameters[k][j], 1e-9, "Parameters should match fitting the output on its own")
+		}
+	}
+
+	guesses, err := model.PredictBatch(multiX)
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Len(t, guesses, len(multiX), "There should be one prediction per row")
+}
+
+func TestMultiLeastSquaresShouldFail1(t *testing.T) {
+	model := NewMultiLeastSquares(base.BatchGA, 1e-2, 0, 100, nil, nil)
+	err := model.Learn()
+	assert.NotNil(t, err, "Learning error should not be nil without data")
+
+	model = NewMultiLeastSquares(base.BatchGA, 1e-2, 0, 100, multiX, multiY[:10])
+	err = model.LearnNormalEquation()
+	assert.NotNil(t, err, "Learning error should not be nil when the expected results don't line up")
+
+	model = NewMultiLeastSquares(base.BatchGA, 1e-2, 0, 100, [][]float64{{1}, {2}}, [][]float64{{1, 2}, {3}})
+	err = model.Learn()
+	assert.NotNil(t, err, "Learning error should not be nil with ragged expected results")
+
+	model = NewMultiLeastSquares(base.NewtonMethod, 1e-2, 0, 100, multiX, multiY)
+	err = model.Learn()
+	assert.NotNil(t, err, "Learning error should not be nil with an unimplemented optimization method")
+
+	model = NewMultiLeastSquares(base.BatchGA, 10, 0, 100, multiX, multiY)
+	err = model.Learn()
+	assert.NotNil(t, err, "Learning error should not be nil when learning diverges")
+
+	_, err = model.Predict([]float64{1, 2, 3})
+	assert.NotNil(t, err, "Prediction error should not be nil with the wrong number of features")
+}
+
+func TestPersistMultiLeastSquaresShouldPass1(t *testing.T) {
+	model := NewMultiLeastSquares(base.BatchGA, 0, 0, 0, multiX, multiY)
+	err := model.LearnNormalEquation()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	err = model.PersistToFile("/tmp/.goml/MultiLeastSquares.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	err = model.PersistToGob("/tmp/.goml/MultiLeastSquares.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	fromJSON := NewMultiLeastSquares(base.BatchGA, 0, 0, 0, nil, nil)
+	err = fromJSON.RestoreFromFile("/tmp/.goml/MultiLeastSquares.json")
+	assert.Nil(t, err, "Restoration error should be nil")
+	assert.Equal(t, model.Parameters, fromJSON.Parameters, "Restored parameters should be the same")
+
+	fromGob := NewMultiLeastSquares(base.BatchGA, 0, 0, 0, nil, nil)
+	err = fromGob.RestoreFromGob("/tmp/.goml/MultiLeastSquares.gob")
+	assert.Nil(t, err, "Restoration error should be nil")
+	assert.Equal(t, model.Parameters, fromGob.Parameters, "Restored parameters should be the same")
+
+	guess, err := fromGob.Predict([]float64{1, 2})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.InDelta(t, 1.5, guess[0], 1e-9, "Restored model should predict the first plane")
+	assert.InDelta(t, 1.5, guess[1], 1e-9, "Restored model should predict the second plane")
+
+	assert.NotNil(t, model.PersistToFile(""), "Persisting to an empty path should return an error")
+	assert.NotNil(t, model.RestoreFromFile(""), "Restoring from an empty path should return an error")
+}
@@ -0,0 +1,826 @@
+package linear
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+// PoissonRegression represents the Poisson
+// regression model, a generalized linear model
+// for count data (the number of times something
+// happened) with a log link, so the hypothesis
+// is the expected count
+//
+//     h(θ,x) = exp(θx)
+//
+// https://en.wikipedia.org/wiki/Poisson_regression
+//
+// The log-likelihood of the data is maximized with
+// gradient ascent, where the gradient works out to
+//
+//     dj = Σ(y[i] - exp(θx[i]))x[i][j]
+//
+// just like the other linear models (with the
+// hypothesis swapped out,) so the model can be
+// optimized with base.BatchGA, base.StochasticGA,
+// base.MiniBatchGA, base.Adam, or online.
+//
+// The model expects all expected results to be
+// non-negative counts, though they don't need to
+// be whole numbers (rates work too.) Because the
+// hypothesis is exponential, large features can
+// make the learning diverge quickly, so use a small
+// learning rate or normalize your data.
+type PoissonRegression struct {
+	// alpha and maxIterations are used only for
+	// GradientAscent during learning. If maxIterations
+	// is 0, then a default maximum (250) is used.
+	// GradientAscent stops early once the algorithm
+	// detects convergence (see Tolerance.)
+	//
+	// regularization is used as the regularization
+	// term to avoid overfitting within regression.
+	// Having a regularization term of 0 is like having
+	// _no_ data regularization. The higher the term,
+	// the greater the bias on the regression
+	alpha          float64
+	regularization float64
+	maxIterations  int
+
+	// method is the optimization method used when training
+	// the model
+	method base.OptimizationMethod
+
+	// batchSize is the number of examples used for each
+	// update when training with base.MiniBatchGA or
+	// base.Adam
+	batchSize int
+
+	// Adam holds the hyperparameters (β1, β2, and ε)
+	// used when training with base.Adam. If left nil
+	// the defaults from base.NewAdamOptimizer are used.
+	Adam *base.AdamOptimizer
+
+	// Schedule decays the learning rate α over the
+	// course of training (see base.LearningRateSchedule.)
+	// If left nil the learning rate stays constant, as it
+	// always does when learning online.
+	Schedule base.LearningRateSchedule
+
+	// Clipping limits the size of the gradient before
+	// every step of gradient ascent, scaling down huge
+	// gradients rather than letting learning diverge
+	// (see base.GradientClipping.) If left nil the
+	// gradient is never clipped.
+	Clipping *base.GradientClipping
+
+	// Shuffle is the source of randomness used to
+	// shuffle the order of the training set at the
+	// start of every iteration of base.StochasticGA
+	// (see base.Shuffled.) The constructor seeds it
+	// with base.DefaultShuffleSeed so training is
+	// reproducible. Set it to your own source to
+	// change the seed, or to nil to visit the
+	// examples in order.
+	Shuffle *rand.Rand
+
+	// Tolerance is used to detect convergence when
+	// training with base.BatchGA: learning stops once
+	// the parameter vector moves less than Tolerance
+	// (by the L2 norm) over an iteration. If left 0,
+	// base.DefaultTolerance is used, and a negative
+	// tolerance turns early stopping off.
+	Tolerance float64
+
+	// iterations is the number of iterations the
+	// last call to Learn actually went through
+	iterations int
+
+	// FitIntercept is whether the model fits the
+	// constant term θ[0] (the log of the baseline
+	// count.) Defaults to true. When false θ has one
+	// parameter per feature, and the expected count
+	// is 1 at the origin. Set it before training.
+	FitIntercept bool
+
+	// RegularizationType is the penalty used along with
+	// the regularization term (base.L2 if left empty.)
+	// L1Ratio is the fraction of the regularization given
+	// to the L1 penalty when using base.ElasticNet (the
+	// rest goes to the L2 penalty.)
+	RegularizationType base.RegularizationType
+	L1Ratio            float64
+
+	// trainingSet and expectedResults are the
+	// 'x', and 'y' of the data, expressed as
+	// vectors, that the model can optimize from
+	trainingSet     [][]float64
+	expectedResults []float64
+
+	Parameters []float64 `json:"theta"`
+
+	// FeatureNames optionally names each feature
+	// (in the same order as x,) so errors from
+	// Predict can say which feature an input is
+	// missing. It's saved by PersistToFile and
+	// ExportSpec.
+	FeatureNames []string `json:"feature_names,omitempty"`
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
+	// Output is the io.Writer used for logging
+	// and printing. Defaults to os.Stdout.
+	Output io.Writer
+}
+
+// NewPoissonRegression takes in a learning rate alpha, a regularization
+// parameter value (0 means no regularization, higher value
+// means higher bias on the model,) the maximum number of
+// iterations the data can go through in gradient descent,
+// as well as a training set and expected counts for that
+// training set.
+//
+// if you're passing in no training set directly because you want
+// to learn using the online method then just declare the number of
+// features (it's an integer) as an extra arg after the rest
+// of the arguments
+//
+// Example Poisson Regression (Batch GA):
+//
+//     // optimization method: Batch Gradient Ascent
+//     // Learning rate: 1e-4
+//     // Regularization term: 0
+//     // Max Iterations: 800
+//     // Dataset to learn from: testX
+//     // Expected counts dataset: testY
+//     model := NewPoissonRegression(base.BatchGA, 1e-4, 0, 800, testX, testY)
+//
+//     err := model.Learn()
+//     if err != nil {
+//         panic("SOME ERROR!! RUN!")
+//     }
+//
+//     // the expected number of events
+//     // given the inputs
+//     guess, err = model.Predict([]float64{0.5, 2})
+//     if err != nil {
+//         panic("AAAARGGGH! SHIVER ME TIMBERS! THESE ROTTEN SCOUNDRELS FOUND AN ERROR!!!")
+//     }
+func NewPoissonRegression(method base.OptimizationMethod, alpha, regularization float64, maxIterations int, trainingSet [][]float64, expectedResults []float64, features ...int) *PoissonRegression {
+	var params []float64
+	if len(features) != 0 {
+		params = make([]float64, features[0]+1)
+	} else if trainingSet == nil || len(trainingSet) == 0 {
+		params = []float64{}
+	} else {
+		params = make([]float64, len(trainingSet[0])+1)
+	}
+
+	return &PoissonRegression{
+		alpha:          alpha,
+		regularization: regularization,
+		maxIterations:  maxIterations,
+
+		method: method,
+
+		trainingSet:     trainingSet,
+		expectedResults: expectedResults,
+
+		// initialize θ as the zero vector (that is,
+		// the vector of all zeros)
+		Parameters: params,
+
+		FitIntercept: true,
+
+		Shuffle: rand.New(rand.NewSource(base.DefaultShuffleSeed)),
+
+		Output: os.Stdout,
+	}
+}
+
+// UpdateTrainingSet takes in a new training set (variable x)
+// as well as a new result set (y). This could be useful if
+// you want to retrain a model starting with the parameter
+// vector of a previous training session, but most of the time
+// wouldn't be used.
+func (p *PoissonRegression) UpdateTrainingSet(trainingSet [][]float64, expectedResults []float64) error {
+	if len(trainingSet) == 0 {
+		return fmt.Errorf("Error: length of given training set is 0! Need data!")
+	}
+	if len(expectedResults) == 0 {
+		return fmt.Errorf("Error: length of given result data set is 0! Need expected results!")
+	}
+
+	p.trainingSet = trainingSet
+	p.expectedResults = expectedResults
+
+	return nil
+}
+
+// UpdateLearningRate set's the learning rate of the model
+// to the given float64.
+func (p *PoissonRegression) UpdateLearningRate(a float64) {
+	p.alpha = a
+}
+
+// UpdateBatchSize sets the number of examples used for
+// each update of the parameter vector when training with
+// base.MiniBatchGA or base.Adam. A batch size less than 1
+// uses the default of 32.
+func (p *PoissonRegression) UpdateBatchSize(b int) {
+	p.batchSize = b
+}
+
+// BatchSize returns the number of examples used for each
+// update of the parameter vector when training with
+// base.MiniBatchGA or base.Adam.
+func (p *PoissonRegression) BatchSize() int {
+	return p.batchSize
+}
+
+// LearningRate returns the learning rate α for gradient
+// descent to optimize the model. Could vary as a function
+// of something else later, potentially.
+func (p *PoissonRegression) LearningRate() float64 {
+	return p.alpha
+}
+
+// LearningRateSchedule returns the schedule the learning
+// rate decays with during training (nil for a constant
+// learning rate.) This lets the optimization methods in
+// base use it (see base.Scheduled.)
+func (p *PoissonRegression) LearningRateSchedule() base.LearningRateSchedule {
+	return p.Schedule
+}
+
+// GradientClipping returns the limits the gradient is
+// clipped to during training (nil if it isn't clipped.)
+// This lets the optimization methods in base use them
+// (see base.Clipped.)
+func (p *PoissonRegression) GradientClipping() *base.GradientClipping {
+	return p.Clipping
+}
+
+// ShuffleSource returns the source of randomness the
+// training set is shuffled with during stochastic
+// gradient ascent (nil if it isn't shuffled.) This lets
+// the optimization methods in base use it (see
+// base.Shuffled.)
+func (p *PoissonRegression) ShuffleSource() *rand.Rand {
+	return p.Shuffle
+}
+
+// Examples returns the number of training examples (m)
+// that the model currently is training from.
+func (p *PoissonRegression) Examples() int {
+	return len(p.trainingSet)
+}
+
+// Features returns the number of features the model
+// takes as input (the length of x given to Predict,)
+// not counting the intercept
+func (p *PoissonRegression) Features() int {
+	if len(p.Parameters) == 0 {
+		return 0
+	}
+
+	return len(p.Parameters) - intercept(p.FitIntercept)
+}
+
+// MaxIterations returns the number of maximum iterations
+// the model will go through in GradientAscent, in the
+// worst case
+func (p *PoissonRegression) MaxIterations() int {
+	return p.maxIterations
+}
+
+// Iterations returns the number of iterations the
+// last call to Learn went through before converging
+// (or reaching the maximum number of iterations) when
+// training with base.BatchGA.
+func (p *PoissonRegression) Iterations() int {
+	return p.iterations
+}
+
+// tolerance returns the tolerance used to detect
+// convergence, using base.DefaultTolerance if the
+// model's Tolerance is left as 0
+func (p *PoissonRegression) tolerance() float64 {
+	if p.Tolerance == 0 {
+		return base.DefaultTolerance
+	}
+	return p.Tolerance
+}
+
+// Predict takes in a variable x (an array of floats,) and
+// finds the value of the hypothesis function given the
+// current parameter vector θ, which is the expected count
+// exp(θx) for the input.
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (p *PoissonRegression) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	if len(x)+intercept(p.FitIntercept) != len(p.Parameters) {
+		return nil, fmt.Errorf("Error: %w: Parameter vector should be %v longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n%v", base.ErrDimensionMismatch, intercept(p.FitIntercept), len(x), len(p.Parameters), base.FeatureMismatch(p.FeatureNames, len(x)))
+	}
+
+	if len(normalize) != 0 && normalize[0] {
+		x = base.NormalizedPoint(x)
+	}
+
+	sum := hypothesis(p.Parameters, x, p.FitIntercept)
+
+	return []float64{math.Exp(sum)}, nil
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (p *PoissonRegression) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	return base.PredictBatch(x, len(p.Parameters)-intercept(p.FitIntercept), func(row []float64) ([]float64, error) {
+		return p.Predict(row, normalize...)
+	})
+}
+
+// Learn takes the struct's dataset and expected results and runs
+// gradient ascent on them, optimizing theta so you can
+// predict based on those results
+func (p *PoissonRegression) Learn() error {
+	if p.trainingSet == nil || p.expectedResults == nil {
+		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
+		p.logf(err.Error())
+		return err
+	}
+
+	examples := len(p.trainingSet)
+	if examples == 0 || len(p.trainingSet[0]) == 0 {
+		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
+		p.logf(err.Error())
+		return err
+	}
+	if len(p.expectedResults) == 0 {
+		err := fmt.Errorf("ERROR: Attempting to learn with no expected results! This isn't an unsupervised model!! You'll need to include data before you learn :)\n")
+		p.logf(err.Error())
+		return err
+	}
+	for i := range p.expectedResults {
+		if p.expectedResults[i] < 0 {
+			err := fmt.Errorf("ERROR: Expected results of Poisson regression must be non-negative counts! Given %v for example %v\n", p.expectedResults[i], i)
+			p.logf(err.Error())
+			return err
+		}
+	}
+
+	p.Parameters = sizeParameters(p.Parameters, len(p.trainingSet[0]), p.FitIntercept)
+
+	p.logf("Training:\n\tModel: Poisson Regression\n\tOptimization Method: %v\n\tTraining Examples: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", p.method, examples, len(p.trainingSet[0]), p.alpha, p.regularization)
+
+	var err error
+	if p.method == base.BatchGA {
+		p.iterations, err = base.ParallelGradientAscentWithTolerance(p, p.tolerance())
+		p.logf("Went through %v iterations.\n", p.iterations)
+	} else if p.method == base.StochasticGA {
+		err = base.StochasticGradientAscent(p)
+	} else if p.method == base.MiniBatchGA {
+		err = base.MiniBatchGradientAscent(p, p.batchSize)
+	} else if p.method == base.Adam {
+		err = base.AdamAscent(p, p.batchSize, p.Adam)
+	} else {
+		err = fmt.Errorf("Chose a training method not implemented for Poisson regression")
+	}
+
+	if err != nil {
+		p.logf("\nERROR: Error while learning –\n\t%v\n\n", err)
+		return err
+	}
+
+	p.logf("Training Completed.\n%v\n\n", p)
+	return nil
+}
+
+// OnlineLearn runs similar to using a fixed dataset with
+// Stochastic Gradient Descent, but it handles data by
+// passing it as a channel, and returns errors through
+// a channel, which lets it run responsive to inputted data
+// from outside the model itself (see LeastSquares.OnlineLearn
+// for an example.)
+//
+// The onUpdate callback is called whenever the parameter
+// vector theta is changed, so you are able to persist the
+// model with the most up to date vector at all times.
+// The callback is spawned into another goroutine.
+//
+// If the optional parameter 'normalize' is true, all data
+// streamed through the channel will be normalized to unit
+// length.
+func (p *PoissonRegression) OnlineLearn(errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	p.OnlineLearnContext(context.Background(), errors, dataset, onUpdate, normalize...)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// dataset channel is still open.
+func (p *PoissonRegression) OnlineLearnContext(ctx context.Context, errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	if errors == nil {
+		errors = make(chan error)
+	}
+	if dataset == nil {
+		errors <- fmt.Errorf("ERROR: Attempting to learn with a nil data stream!\n")
+		close(errors)
+		return
+	}
+
+	p.logf("Training:\n\tModel: Poisson Regression\n\tOptimization Method: Online Stochastic Gradient Descent\n\tFeatures: %v\n\tLearning Rate α: %v\n...\n\n", len(p.Parameters), p.alpha)
+
+	norm := len(normalize) != 0 && normalize[0]
+	var point base.Datapoint
+	var more bool
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logf("Training Cancelled.\n%v\n\n", p)
+			close(errors)
+			return
+		case point, more = <-dataset:
+		}
+
+		if more {
+			if len(point.Y) != 1 {
+				errors <- fmt.Errorf("ERROR: point.Y must have a length of 1. Point: %v", point)
+				continue
+			}
+			if point.Y[0] < 0 {
+				errors <- fmt.Errorf("ERROR: point.Y must be a non-negative count. Point: %v", point)
+				continue
+			}
+
+			if norm {
+				point.X = base.NormalizedPoint(point.X)
+			}
+
+			// drop the intercept from the constructor's
+			// zero vector before the first update
+			if isZero(p.Parameters) {
+				p.Parameters = sizeParameters(p.Parameters, len(point.X), p.FitIntercept)
+			}
+
+			prediction, err := p.Predict(point.X)
+			if err != nil {
+				errors <- err
+				continue
+			}
+
+			newTheta := make([]float64, len(p.Parameters))
+			for j := range p.Parameters {
+				// account for constant term
+				// x is x[i][j] via Andrew Ng's terminology
+				x := feature(point.X, j, p.FitIntercept)
+
+				gradient := (point.Y[0] - prediction[0]) * x
+
+				// apply the regularization term
+				// (λ*θ[j] for L2 regularization)
+				//
+				// notice that we don't count the
+				// constant term
+				if !p.FitIntercept || j != 0 {
+					gradient = regularize(p.RegularizationType, p.L1Ratio, p.regularization, p.Parameters[j], gradient)
+				}
+
+				newTheta[j] = p.Prox(j, p.Parameters[j]+p.alpha*gradient, p.alpha)
+			}
+
+			// now simultaneously update Theta
+			diverged := false
+			for j := range newTheta {
+				if math.IsInf(newTheta[j], 0) || math.IsNaN(newTheta[j]) {
+					diverged = true
+					break
+				}
+			}
+			if diverged {
+				errors <- fmt.Errorf("Sorry! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
+				continue
+			}
+			copy(p.Parameters, newTheta)
+
+			go onUpdate([][]float64{p.Parameters})
+
+		} else {
+			p.logf("Training Completed.\n%v\n\n", p)
+			close(errors)
+			return
+		}
+	}
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (p *PoissonRegression) logf(format string, a ...interface{}) {
+	if p.Verbose {
+		fmt.Fprintf(p.Output, format, a...)
+	}
+}
+
+// String implements the fmt interface for clean printing. Here
+// we're using it to print the model as the equation h(θ)=...
+// where h is the Poisson hypothesis model
+func (p *PoissonRegression) String() string {
+	offset := intercept(p.FitIntercept)
+	features := len(p.Parameters) - offset
+	if len(p.Parameters) == 0 {
+		p.logf("ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
+	}
+	var buffer bytes.Buffer
+
+	buffer.WriteString("h(θ,x) = exp(θx)\nθx = ")
+	if p.FitIntercept {
+		buffer.WriteString(fmt.Sprintf("%.3f + ", p.Parameters[0]))
+	}
+
+	length := features + 1
+	for i := 1; i < length; i++ {
+		buffer.WriteString(fmt.Sprintf("%.5f(x[%d])", p.Parameters[i-1+offset], i))
+
+		if i != features {
+			buffer.WriteString(fmt.Sprintf(" + "))
+		}
+	}
+
+	return buffer.String()
+}
+
+// Dj returns the partial derivative of the log-likelihood
+// with respect to theta[j] where theta is the parameter vector
+// associated with our hypothesis function Predict (upon which
+// we are optimizing
+func (p *PoissonRegression) Dj(j int) (float64, error) {
+	if j > len(p.Parameters)-1 {
+		return 0, fmt.Errorf("J (%v) would index out of the bounds of the training set data (len: %v)", j, len(p.Parameters))
+	}
+
+	var sum float64
+
+	for i := range p.trainingSet {
+		prediction, err := p.Predict(p.trainingSet[i])
+		if err != nil {
+			return 0, err
+		}
+
+		// account for constant term
+		// x is x[i][j] via Andrew Ng's terminology
+		x := feature(p.trainingSet[i], j, p.FitIntercept)
+
+		sum += (p.expectedResults[i] - prediction[0]) * x
+	}
+
+	return p.RegularizeDj(j, sum), nil
+}
+
+// PartialDj returns the derivative of the log-likelihood
+// with respect to every parameter of the hypothesis,
+// summed only over the training examples x[start] through
+// x[end-1] and without regularization. The training set
+// is split up this way to compute the gradient in parallel
+// (see base.ParallelGradientAscent.)
+func (p *PoissonRegression) PartialDj(start, end int) ([]float64, error) {
+	if start < 0 || end > len(p.trainingSet) || start > end {
+		return nil, fmt.Errorf("Range [%v,%v) would index out of the bounds of the training set data (len: %v)", start, end, len(p.trainingSet))
+	}
+
+	sum := make([]float64, len(p.Parameters))
+	offset := intercept(p.FitIntercept)
+
+	for i := start; i < end; i++ {
+		prediction, err := p.Predict(p.trainingSet[i])
+		if err != nil {
+			return nil, err
+		}
+
+		diff := p.expectedResults[i] - prediction[0]
+
+		// account for constant term
+		if p.FitIntercept {
+			sum[0] += diff
+		}
+		for j := range p.trainingSet[i] {
+			sum[j+offset] += diff * p.trainingSet[i][j]
+		}
+	}
+
+	return sum, nil
+}
+
+// RegularizeDj applies the regularization term to the
+// derivative of the log-likelihood with respect to θ[j]
+// (over the whole training set,) returning the
+// regularized derivative.
+func (p *PoissonRegression) RegularizeDj(j int, dj float64) float64 {
+	// apply the regularization term
+	// (λ*θ[j] for L2 regularization)
+	//
+	// notice that we don't count the
+	// constant term
+	if !p.FitIntercept || j != 0 {
+		dj = regularize(p.RegularizationType, p.L1Ratio, p.regularization, p.Parameters[j], dj)
+	}
+
+	return dj
+}
+
+// Prox applies the L1 part of the regularization
+// penalty to θ[j] after a step of gradient ascent was
+// taken to theta with learning rate alpha, returning
+// the new θ[j]. This lets the optimization methods in
+// base apply it (see base.Proximal.)
+func (p *PoissonRegression) Prox(j int, theta, alpha float64) float64 {
+	// notice that we don't count the
+	// constant term
+	if p.FitIntercept && j == 0 {
+		return theta
+	}
+
+	return shrink(p.RegularizationType, p.L1Ratio, p.regularization, alpha, theta)
+}
+
+// Dij returns the derivative of the log-likelihood
+// with respect to the j-th parameter of the hypothesis,
+// θ[j], for the training example x[i]. Used in
+// Stochastic Gradient Descent.
+//
+// assumes that i,j is within the bounds of the
+// data they are looking up! (because this is getting
+// called so much, it needs to be efficient with
+// comparisons)
+func (p *PoissonRegression) Dij(i int, j int) (float64, error) {
+	prediction, err := p.Predict(p.trainingSet[i])
+	if err != nil {
+		return 0, err
+	}
+
+	// account for constant term
+	// x is x[i][j] via Andrew Ng's terminology
+	x := feature(p.trainingSet[i], j, p.FitIntercept)
+
+	gradient := (p.expectedResults[i] - prediction[0]) * x
+
+	// apply the regularization term
+	// (λ*θ[j] for L2 regularization)
+	//
+	// notice that we don't count the
+	// constant term
+	if !p.FitIntercept || j != 0 {
+		gradient = regularize(p.RegularizationType, p.L1Ratio, p.regularization, p.Parameters[j], gradient)
+	}
+
+	return gradient, nil
+}
+
+// J returns the negative log-likelihood of the training
+// set under the model, averaged over the examples
+//
+//     J(θ) = (1/m)Σ(exp(θx[i]) - y[i]θx[i])
+//
+// (leaving out the constant log(y[i]!) terms,) plus the
+// regularization penalty. Could be useful in testing
+// convergence
+func (p *PoissonRegression) J() (float64, error) {
+	var sum float64
+
+	for i := range p.trainingSet {
+		prediction, err := p.Predict(p.trainingSet[i])
+		if err != nil {
+			return 0, err
+		}
+
+		// log(exp(θx)) == θx
+		z := hypothesis(p.Parameters, p.trainingSet[i], p.FitIntercept)
+		sum += prediction[0] - p.expectedResults[i]*z
+	}
+
+	// add regularization term!
+	//
+	// notice that the constant term doesn't matter
+	for i := intercept(p.FitIntercept); i < len(p.Parameters); i++ {
+		sum += penalty(p.RegularizationType, p.L1Ratio, p.regularization, p.Parameters[i]) / 2
+	}
+
+	return sum / float64(len(p.trainingSet)), nil
+}
+
+// Theta returns the parameter vector θ for use in persisting
+// the model, and optimizing the model through gradient descent
+// ( or other methods like Newton's Method)
+func (p *PoissonRegression) Theta() []float64 {
+	return p.Parameters
+}
+
+// PersistToFile takes in an absolute filepath and saves the
+// parameter vector θ to the file, which can be restored later.
+// If the model has FeatureNames they're saved along with θ.
+// The function will take paths from the current directory, but
+// functions
+//
+// The data is stored as JSON because it's one of the most
+// efficient storage method (you only need one comma extra
+// per feature + two brackets, total!) And it's extendable.
+func (p *PoissonRegression) PersistToFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := base.MarshalParameters(p.Parameters, p.FeatureNames)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, bytes, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreFromFile takes in a path to a parameter vector theta
+// and assigns the model it's operating on's parameter vector
+// to that, along with the FeatureNames saved with it (if any.)
+//
+// The path must ba an absolute path or a path from the current
+// directory
+func (p *PoissonRegression) RestoreFromFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to restore your model from a file with no path! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	theta, names, err := base.UnmarshalParameters(bytes)
+	if err != nil {
+		return err
+	}
+
+	err = base.CheckFeatureNames(names, len(theta)-intercept(p.FitIntercept))
+	if err != nil {
+		return err
+	}
+
+	p.Parameters = theta
+	p.FeatureNames = names
+
+	return nil
+}
+
+// PersistToGob saves the parameter vector θ (and the
+// FeatureNames, if any) to the given file like
+// PersistToFile, but encoded with encoding/gob (see
+// base.PersistToGob.)
+func (p *PoissonRegression) PersistToGob(path string) error {
+	return base.PersistParametersToGob(path, p.Parameters, p.FeatureNames)
+}
+
+// RestoreFromGob takes in a path to a parameter vector
+// saved with PersistToGob and assigns the model's
+// parameter vector and FeatureNames to it, like
+// RestoreFromFile.
+func (p *PoissonRegression) RestoreFromGob(path string) error {
+	theta, names, err := base.RestoreParametersFromGob(path)
+	if err != nil {
+		return err
+	}
+
+	err = base.CheckFeatureNames(names, len(theta)-intercept(p.FitIntercept))
+	if err != nil {
+		return err
+	}
+
+	p.Parameters = theta
+	p.FeatureNames = names
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package linear
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/cdipaolo/goml/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// poissonX and poissonY hold the expected
+// counts exp(0.5 + 0.3x[0] - 0.2x[1]), and
+// countY holds counts sampled from them
+var poissonX [][]float64
+var poissonY []float64
+var countY []float64
+
+func init() {
+	r := rand.New(rand.NewSource(42))
+
+	poissonX = [][]float64{}
+	poissonY = []float64{}
+	countY = []float64{}
+	for i := -5.0; i < 5; i += 0.5 {
+		for j := -5.0; j < 5; j += 0.5 {
+			rate := math.Exp(0.5 + 0.3*i - 0.2*j)
+
+			poissonX = append(poissonX, []float64{i, j})
+			poissonY = append(poissonY, rate)
+
+			// sample from the Poisson distribution by
+			// counting exponential arrivals in [0,1]
+			var count float64
+			for t := r.ExpFloat64() / rate; t < 1; t += r.ExpFloat64() / rate {
+				count++
+			}
+			countY = append(countY, count)
+		}
+	}
+}
+
+func TestPoissonRegressionShouldPass1(t *testing.T) {
+	for _, method := range []base.OptimizationMethod{base.BatchGA, base.StochasticGA} {
+		model := NewPoissonRegression(method, 1e-4, 0, 2000, poissonX, poissonY)
+
+		err := model.Learn()
+		assert.Nil(t, err, "Learning error should be nil")
+
+		assert.InDelta(t, 0.5, model.Parameters[0], 1e-2, "Intercept should be close to 0.5")
+		assert.InDelta(t, 0.3, model.Parameters[1], 1e-2, "θ[1] should be close to 0.3")
+		assert.InDelta(t, -0.2, model.Parameters[2], 1e-2, "θ[2] should be close to -0.2")
+
+		for i := range poissonX {
+			guess, err := model.Predict(poissonX[i])
+			assert.Nil(t, err, "Prediction error should be nil")
+			assert.Len(t, guess, 1, "Length of a PoissonRegression model output from the hypothesis should always be a 1 dimensional vector. Never multidimensional.")
+			assert.InEpsilon(t, poissonY[i], guess[0], 5e-2, "Guess should be close to the expected count")
+		}
+	}
+}
+
+// learning from sampled counts should
+// recover the rates they came from
+func TestPoissonRegressionCountsShouldPass1(t *testing.T) {
+	model := NewPoissonRegression(base.BatchGA, 1e-4, 0, 2000, poissonX, countY)
+
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	assert.InDelta(t, 0.5, model.Parameters[0], 0.1, "Intercept should be close to 0.5")
+	assert.InDelta(t, 0.3, model.Parameters[1], 0.05, "θ[1] should be close to 0.3")
+	assert.InDelta(t, -0.2, model.Parameters[2], 0.05, "θ[2] should be close to -0.2")
+
+	// the fitted model should be more likely
+	// than the model of a constant rate
+	j, err := model.J()
+	assert.Nil(t, err, "Cost error should be nil")
+
+	constant := NewPoissonRegression(base.BatchGA, 1e-4, 0, 2000, poissonX, countY)
+	constantJ, err := constant.J()
+	assert.Nil(t, err, "Cost error should be nil")
+	assert.True(t, j < constantJ, "The fitted model should have a lower negative log-likelihood - Given %v vs %v", j, constantJ)
+}
+
+func TestPoissonRegressionShouldFail1(t *testing.T) {
+	model := NewPoissonRegression(base.BatchGA, 1e-4, 0, 100, [][]float64{{1}, {2}}, []float64{1, -1})
+	err := model.Learn()
+	assert.NotNil(t, err, "Learning error should not be nil with negative counts")
+
+	model = NewPoissonRegression(base.BatchGA, 1e-4, 0, 100, nil, nil)
+	err = model.Learn()
+	assert.NotNil(t, err, "Learning error should not be nil without data")
+
+	model = NewPoissonRegression(base.NewtonMethod, 1e-4, 0, 100, poissonX, poissonY)
+	err = model.Learn()
+	assert.NotNil(t, err, "Learning error should not be nil with an unimplemented optimization method")
+
+	// exp(θx) blows up with a huge learning rate
+	model = NewPoissonRegression(base.BatchGA, 10, 0, 100, poissonX, poissonY)
+	err = model.Learn()
+	assert.NotNil(t, err, "Learning error should not be nil when learning diverges")
+	assert.True(t, errors.Is(err, base.ErrDiverged), "Learning error should wrap base.ErrDiverged - Given %v", err)
+
+	_, err = model.Predict([]float64{1, 2, 3})
+	assert.NotNil(t, err, "Prediction error should not be nil with the wrong number of features")
+	assert.True(t, errors.Is(err, base.ErrDimensionMismatch), "Prediction error should wrap base.ErrDimensionMismatch - Given %v", err)
+}
+
+func TestOnlinePoissonRegressionShouldPass1(t *testing.T) {
+	// create the channel of data and errors
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewPoissonRegression(base.StochasticGA, 1e-3, 0, 0, nil, nil, 2)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	go func() {
+		for iter := 0; iter < 200; iter++ {
+			for i := range poissonX {
+				stream <- base.Datapoint{
+					X: poissonX[i],
+					Y: []float64{poissonY[i]},
+				}
+			}
+		}
+
+		stream <- base.Datapoint{
+			X: []float64{1, 1},
+			Y: []float64{-1},
+		}
+
+		// close the dataset
+		close(stream)
+	}()
+
+	var count int
+	for range errors {
+		count++
+	}
+
+	assert.Equal(t, 1, count, "There should be an error for the negative count")
+	assert.InDelta(t, 0.5, model.Parameters[0], 1e-2, "Intercept should be close to 0.5")
+	assert.InDelta(t, 0.3, model.Parameters[1], 1e-2, "θ[1] should be close to 0.3")
+	assert.InDelta(t, -0.2, model.Parameters[2], 1e-2, "θ[2] should be close to -0.2")
+}
+
+func TestPersistPoissonRegressionShouldPass1(t *testing.T) {
+	model := NewPoissonRegression(base.BatchGA, 1e-4, 0, 2000, poissonX, poissonY)
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	err = model.PersistToFile("/tmp/.goml/PoissonRegression.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	err = model.PersistToGob("/tmp/.goml/PoissonRegression.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	fromJSON := NewPoissonRegression(base.BatchGA, 1e-4, 0, 2000, nil, nil, 2)
+	err = fromJSON.RestoreFromFile("/tmp/.goml/PoissonRegression.json")
+	assert.Nil(t, err, "Restoration error should be nil")
+	assert.Equal(t, model.Parameters, fromJSON.Parameters, "Restored parameters should be the same")
+
+	fromGob := NewPoissonRegression(base.BatchGA, 1e-4, 0, 2000, nil, nil, 2)
+	err = fromGob.RestoreFromGob("/tmp/.goml/PoissonRegression.gob")
+	assert.Nil(t, err, "Restoration error should be nil")
+	assert.Equal(t, model.Parameters, fromGob.Parameters, "Restored parameters should be the same")
+
+	assert.NotNil(t, model.PersistToFile(""), "Persisting to an empty path should return an error")
+	assert.NotNil(t, model.RestoreFromFile(""), "Restoring from an empty path should return an error")
+}
@@ -0,0 +1,100 @@
+package linear
+
+import (
+	"math"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+// regularize takes the gradient (dj) of the log
+// likelihood with respect to a single non-constant
+// parameter θ[j] and returns the gradient after
+// applying the differentiable part of the
+// regularization penalty, such that a step of
+// θ[j] + α·regularize(...) is taken within gradient
+// ascent.
+//
+// For L2 regularization this is just
+//
+//     dj + λ·θ[j]
+//
+// which is the term the models have always added
+// to their gradient (so the default is unchanged.)
+//
+// The L1 penalty λ·|θ[j]| isn't differentiable at 0,
+// so it's left out of the gradient and applied after
+// each step by shrink instead.
+//
+// ElasticNet mixes the two, using ratio·λ for the
+// L1 term (see shrink) and (1 - ratio)·λ for an L2
+// term which shrinks θ[j] towards 0:
+//
+//     dj - (1 - ratio)·λ·θ[j]
+func regularize(kind base.RegularizationType, ratio, lambda, theta, dj float64) float64 {
+	switch kind {
+	case base.L1:
+		return dj
+	case base.ElasticNet:
+		return dj - (1-ratio)*lambda*theta
+	default:
+		return dj + lambda*theta
+	}
+}
+
+// shrink applies the L1 part of the regularization
+// penalty to a single non-constant parameter θ[j]
+// right after a step of gradient ascent was taken
+// with learning rate alpha. This is the soft
+// threshold (proximal) step: θ[j] is shrunk towards
+// 0 by α·λ (α·ratio·λ for ElasticNet,) stopping at
+// exactly 0 if it would cross it, which is what lets
+// L1 regularization drive parameters to exactly 0.
+// θ[j] is returned as is for L2 regularization.
+func shrink(kind base.RegularizationType, ratio, lambda, alpha, theta float64) float64 {
+	var l1 float64
+	switch kind {
+	case base.L1:
+		l1 = lambda
+	case base.ElasticNet:
+		l1 = ratio * lambda
+	default:
+		return theta
+	}
+
+	return math.Max(math.Abs(theta)-alpha*l1, 0) * sign(theta)
+}
+
+// penalty returns the regularization penalty added
+// to the cost function for a single non-constant
+// parameter θ[j]. For L1 and ElasticNet this is the
+// penalty whose (sub)gradient regularize and shrink
+// step against,
+//
+//     ratio·λ·|θ[j]| + (1 - ratio)·λ·θ[j]²/2
+//
+// (with a ratio of 1 for L1,) while the L2 penalty
+// is λ·θ[j]², the term the models have always
+// added to their cost.
+func penalty(kind base.RegularizationType, ratio, lambda, theta float64) float64 {
+	switch kind {
+	case base.L1:
+		return lambda * math.Abs(theta)
+	case base.ElasticNet:
+		return ratio*lambda*math.Abs(theta) + (1-ratio)*lambda*theta*theta/2
+	default:
+		return lambda * theta * theta
+	}
+}
+
+// sign returns -1 if x < 0, 1 if x > 0,
+// and 0 if x == 0
+func sign(x float64) float64 {
+	if x < 0 {
+		return -1
+	}
+	if x > 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -2,11 +2,13 @@ package linear
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math"
+	"math/rand"
 	"os"
 
 	"github.com/cdipaolo/goml/base"
@@ -29,8 +31,9 @@ import (
 type Softmax struct {
 	// alpha and maxIterations are used only for
 	// GradientAscent during learning. If maxIterations
-	// is 0, then GradientAscent will run until the
-	// algorithm detects convergance.
+	// is 0, then a default maximum (5000 iterations) is
+	// used. GradientAscent stops early once the
+	// algorithm detects convergence (see Tolerance.)
 	//
 	// regularization is used as the regularization
 	// term to avoid overfitting within regression.
@@ -49,6 +52,59 @@ type Softmax struct {
 	// the model
 	method base.OptimizationMethod
 
+	// batchSize is the number of examples used for each
+	// update when training with base.MiniBatchGA or
+	// base.Adam
+	batchSize int
+
+	// Adam holds the hyperparameters (β1, β2, and ε)
+	// used when training with base.Adam. If left nil
+	// the defaults from base.NewAdamOptimizer are used.
+	Adam *base.AdamOptimizer
+
+	// Schedule decays the learning rate α over the
+	// course of training (see base.LearningRateSchedule.)
+	// If left nil the learning rate stays constant, as it
+	// always does when learning online.
+	Schedule base.LearningRateSchedule
+
+	// Clipping limits the size of the gradient before
+	// every step of gradient ascent, scaling down huge
+	// gradients rather than letting learning diverge
+	// (see base.GradientClipping.) Each class's
+	// gradient is clipped separately. If left nil
+	// the gradient is never clipped.
+	Clipping *base.GradientClipping
+
+	// Shuffle is the source of randomness used to
+	// shuffle the order of the training set at the
+	// start of every iteration of base.MiniBatchGA
+	// and base.Adam. The constructor seeds it with
+	// base.DefaultShuffleSeed so training is
+	// reproducible. If it's set to nil a new source
+	// with the same seed is used.
+	Shuffle *rand.Rand
+
+	// Tolerance is used to detect convergence when
+	// training with base.BatchGA: learning stops once
+	// the parameter vector moves less than Tolerance
+	// (by the L2 norm) over an iteration. If left 0,
+	// base.DefaultTolerance is used, and a negative
+	// tolerance turns early stopping off.
+	Tolerance float64
+
+	// iterations is the number of iterations the
+	// last call to Learn actually went through
+	iterations int
+
+	// RegularizationType is the penalty used along with
+	// the regularization term (base.L2 if left empty.)
+	// L1Ratio is the fraction of the regularization given
+	// to the L1 penalty when using base.ElasticNet (the
+	// rest goes to the L2 penalty.)
+	RegularizationType base.RegularizationType
+	L1Ratio            float64
+
 	// trainingSet and expectedResults are the
 	// 'x', and 'y' of the data, expressed as
 	// vectors, that the model can optimize from
@@ -57,6 +113,24 @@ type Softmax struct {
 
 	Parameters [][]float64 `json:"theta"`
 
+	// FeatureNames optionally names each feature
+	// (in the same order as x,) so errors from
+	// Predict can say which feature an input is
+	// missing. It's saved by PersistToFile and
+	// PersistToGob.
+	FeatureNames []string `json:"feature_names,omitempty"`
+
+	// Evaluation, if set, keeps a rolling measure of
+	// how well the model predicts datapoints before it
+	// learns from them when learning online (see
+	// base.Evaluation.)
+	Evaluation *base.Evaluation
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
 	// Output is the io.Writer used for logging
 	// and printing. Defaults to os.Stdout.
 	Output io.Writer
@@ -109,6 +183,8 @@ func NewSoftmax(method base.OptimizationMethod, alpha, regularization float64, k
 		// the vector of all zeros)
 		Parameters: params,
 
+		Shuffle: rand.New(rand.NewSource(base.DefaultShuffleSeed)),
+
 		Output: os.Stdout,
 	}
 }
@@ -138,6 +214,21 @@ func (s *Softmax) UpdateLearningRate(a float64) {
 	s.alpha = a
 }
 
+// UpdateBatchSize sets the number of examples used for
+// each update of the parameter vector when training with
+// base.MiniBatchGA or base.Adam. A batch size less than 1
+// uses the default of 32.
+func (s *Softmax) UpdateBatchSize(b int) {
+	s.batchSize = b
+}
+
+// BatchSize returns the number of examples used for each
+// update of the parameter vector when training with
+// base.MiniBatchGA or base.Adam.
+func (s *Softmax) BatchSize() int {
+	return s.batchSize
+}
+
 // LearningRate returns the learning rate α for gradient
 // descent to optimize the model. Could vary as a function
 // of something else later, potentially.
@@ -145,12 +236,39 @@ func (s *Softmax) LearningRate() float64 {
 	return s.alpha
 }
 
+// LearningRateSchedule returns the schedule the learning
+// rate decays with during training (nil for a constant
+// learning rate.) This lets the optimization methods in
+// base use it (see base.Scheduled.)
+func (s *Softmax) LearningRateSchedule() base.LearningRateSchedule {
+	return s.Schedule
+}
+
+// GradientClipping returns the limits the gradient is
+// clipped to during training (nil if it isn't clipped.)
+// This lets the optimization methods in base use them
+// (see base.Clipped.)
+func (s *Softmax) GradientClipping() *base.GradientClipping {
+	return s.Clipping
+}
+
 // Examples returns the number of training examples (m)
 // that the model currently is training from.
 func (s *Softmax) Examples() int {
 	return len(s.trainingSet)
 }
 
+// Features returns the number of features the model
+// takes as input (the length of x given to Predict,)
+// not counting the constant term
+func (s *Softmax) Features() int {
+	if len(s.Parameters) == 0 || len(s.Parameters[0]) == 0 {
+		return 0
+	}
+
+	return len(s.Parameters[0]) - 1
+}
+
 // MaxIterations returns the number of maximum iterations
 // the model will go through in GradientAscent, in the
 // worst case
@@ -158,16 +276,35 @@ func (s *Softmax) MaxIterations() int {
 	return s.maxIterations
 }
 
+// Iterations returns the number of iterations the
+// last call to Learn went through before converging
+// (or reaching the maximum number of iterations) when
+// training with base.BatchGA.
+func (s *Softmax) Iterations() int {
+	return s.iterations
+}
+
+// tolerance returns the tolerance used to detect
+// convergence, using base.DefaultTolerance if the
+// model's Tolerance is left as 0
+func (s *Softmax) tolerance() float64 {
+	if s.Tolerance == 0 {
+		return base.DefaultTolerance
+	}
+
+	return s.Tolerance
+}
+
 // Predict takes in a variable x (an array of floats,) and
 // finds the value of the hypothesis function given the
 // current parameter vector θ
 func (s *Softmax) Predict(x []float64, normalize ...bool) ([]float64, error) {
 	if len(s.Parameters) != 0 && len(x)+1 != len(s.Parameters[0]) {
-		return nil, fmt.Errorf("Error: Parameter vector should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v (len(theta[0]) = %v)\n", len(x), len(s.Parameters), len(s.Parameters[0]))
+		return nil, fmt.Errorf("Error: %w: Parameter vector should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v (len(theta[0]) = %v)\n%v", base.ErrDimensionMismatch, len(x), len(s.Parameters), len(s.Parameters[0]), base.FeatureMismatch(s.FeatureNames, len(x)))
 	}
 
 	if len(normalize) != 0 && normalize[0] {
-		base.NormalizePoint(x)
+		x = base.NormalizedPoint(x)
 	}
 
 	result := make([]float64, s.k)
@@ -192,29 +329,75 @@ func (s *Softmax) Predict(x []float64, normalize ...bool) ([]float64, error) {
 	return result, nil
 }
 
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (s *Softmax) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	if len(s.Parameters) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: Attempting to predict with no parameters!\n", base.ErrNotTrained)
+	}
+
+	return base.PredictBatch(x, len(s.Parameters[0])-1, func(row []float64) ([]float64, error) {
+		return s.Predict(row, normalize...)
+	})
+}
+
+// PredictClass takes in a variable x (an array of floats,)
+// and returns the classification value (between 0 and k-1)
+// with the highest probability given the current parameter
+// vector θ. See Predict for the meaning of normalize.
+func (s *Softmax) PredictClass(x []float64, normalize ...bool) (int, error) {
+	class, _, err := s.PredictClassWithConfidence(x, normalize...)
+	return class, err
+}
+
+// PredictClassWithConfidence works just like PredictClass,
+// but also returns the probability the model gives to the
+// predicted classification value.
+func (s *Softmax) PredictClassWithConfidence(x []float64, normalize ...bool) (int, float64, error) {
+	guess, err := s.Predict(x, normalize...)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(guess) == 0 {
+		return 0, 0, fmt.Errorf("ERROR: Model has no classification values to predict from!\n")
+	}
+
+	class := base.ArgMax(guess)
+
+	return class, guess[class], nil
+}
+
 // Learn takes the struct's dataset and expected results and runs
 // gradient descent on them, optimizing theta so you can
 // predict accurately based on those results
 func (s *Softmax) Learn() error {
 	if s.trainingSet == nil || s.expectedResults == nil {
 		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		fmt.Fprintf(s.Output, err.Error())
+		s.logf(err.Error())
 		return err
 	}
 
 	examples := len(s.trainingSet)
 	if examples == 0 || len(s.trainingSet[0]) == 0 {
 		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
-		fmt.Fprintf(s.Output, err.Error())
+		s.logf(err.Error())
 		return err
 	}
 	if len(s.expectedResults) == 0 {
 		err := fmt.Errorf("ERROR: Attempting to learn with no expected results! This isn't an unsupervised model!! You'll need to include data before you learn :)\n")
-		fmt.Fprintf(s.Output, err.Error())
+		s.logf(err.Error())
 		return err
 	}
 
-	fmt.Fprintf(s.Output, "Training:\n\tModel: Softmax Classification\n\tOptimization Method: %v\n\tTraining Examples: %v\n\t Classification Dimensions: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", s.method, examples, s.k, len(s.trainingSet[0]), s.alpha, s.regularization)
+	s.logf("Training:\n\tModel: Softmax Classification\n\tOptimization Method: %v\n\tTraining Examples: %v\n\t Classification Dimensions: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tRegularization Parameter λ: %v\n...\n\n", s.method, examples, s.k, len(s.trainingSet[0]), s.alpha, s.regularization)
 
 	var err error
 	if s.method == base.BatchGA {
@@ -225,14 +408,18 @@ func (s *Softmax) Learn() error {
 				s.maxIterations = 5000
 			}
 
-			iter := 0
+			tolerance := s.tolerance()
+			s.iterations = 0
 
 			// Stop iterating if the number of iterations exceeds
 			// the limit
-			for ; iter < s.maxIterations; iter++ {
+			for s.iterations < s.maxIterations {
+				alpha := s.Schedule.Rate(s.alpha, s.iterations)
 
 				// go over each parameter vector for each
-				// classification value
+				// classification value, keeping track of
+				// how far they all move
+				var change float64
 				newTheta := make([][]float64, len(s.Parameters))
 				for k, theta := range s.Parameters {
 					newTheta[k] = make([]float64, len(theta))
@@ -241,19 +428,28 @@ func (s *Softmax) Learn() error {
 					if err != nil {
 						return err
 					}
+					s.Clipping.Clip(dj)
 
 					for j := range theta {
-						newTheta[k][j] = theta[j] + s.alpha*dj[j]
+						newTheta[k][j] = s.prox(j, theta[j]+alpha*dj[j], alpha)
 						if math.IsInf(newTheta[k][j], 0) || math.IsNaN(newTheta[k][j]) {
-							return fmt.Errorf("Sorry dude! Learning diverged. Some value of the parameter vector theta is ±Inf or NaN")
+							return fmt.Errorf("Sorry dude! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
 						}
+
+						change += (newTheta[k][j] - theta[j]) * (newTheta[k][j] - theta[j])
 					}
 				}
 
 				s.Parameters = newTheta
+				s.iterations++
+
+				// stop once θ has converged
+				if math.Sqrt(change) < tolerance {
+					break
+				}
 			}
 
-			fmt.Fprintf(s.Output, "Went through %v iterations.\n", iter)
+			s.logf("Went through %v iterations.\n", s.iterations)
 
 			return nil
 		}()
@@ -270,6 +466,8 @@ func (s *Softmax) Learn() error {
 			// Stop iterating if the number of iterations exceeds
 			// the limit
 			for ; iter < s.maxIterations; iter++ {
+				alpha := s.Schedule.Rate(s.alpha, iter)
+
 				for j := range s.trainingSet {
 					newTheta := make([][]float64, len(s.Parameters))
 					// go over each parameter vector for each
@@ -280,12 +478,118 @@ func (s *Softmax) Learn() error {
 						if err != nil {
 							return err
 						}
+						s.Clipping.Clip(dj)
+
+						// now simultaneously update theta
+						for j := range theta {
+							newTheta[k][j] = s.prox(j, theta[j]+alpha*dj[j], alpha)
+							if math.IsInf(newTheta[k][j], 0) || math.IsNaN(newTheta[k][j]) {
+								return fmt.Errorf("Sorry dude! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
+							}
+						}
+					}
+
+					s.Parameters = newTheta
+				}
+			}
+
+			s.logf("Went through %v iterations.\n", iter)
+
+			return nil
+		}()
+	} else if s.method == base.MiniBatchGA || s.method == base.Adam {
+		err = func() error {
+			// if the iterations given is 0, set it to be
+			// 5000 (seems reasonable base value)
+			if s.maxIterations == 0 {
+				s.maxIterations = 5000
+			}
+
+			// if the batch size given is less than 1, set
+			// it to be 32 (seems reasonable base value)
+			batchSize := s.batchSize
+			if batchSize < 1 {
+				batchSize = 32
+			}
+
+			// each classification value's parameter
+			// vector keeps its own moment estimates
+			// when using Adam
+			var optimizers []*base.AdamOptimizer
+			if s.method == base.Adam {
+				optimizers = make([]*base.AdamOptimizer, len(s.Parameters))
+				for k := range optimizers {
+					optimizers[k] = base.NewAdamOptimizer()
+					if s.Adam != nil {
+						optimizers[k].Beta1 = s.Adam.Beta1
+						optimizers[k].Beta2 = s.Adam.Beta2
+						optimizers[k].Epsilon = s.Adam.Epsilon
+					}
+				}
+			}
+
+			rng := s.Shuffle
+			if rng == nil {
+				rng = rand.New(rand.NewSource(base.DefaultShuffleSeed))
+			}
+
+			iter := 0
+
+			// Stop iterating if the number of iterations exceeds
+			// the limit
+			for ; iter < s.maxIterations; iter++ {
+				alpha := s.Schedule.Rate(s.alpha, iter)
+				order := rng.Perm(examples)
+
+				for start := 0; start < examples; start += batchSize {
+					end := start + batchSize
+					if end > examples {
+						end = examples
+					}
+					size := float64(end - start)
+
+					newTheta := make([][]float64, len(s.Parameters))
+					// go over each parameter vector for each
+					// classification value
+					for k, theta := range s.Parameters {
+						grad := make([]float64, len(theta))
+						for _, i := range order[start:end] {
+							dj, err := s.Dij(i, k)
+							if err != nil {
+								return err
+							}
+
+							for j := range grad {
+								grad[j] += dj[j]
+							}
+						}
 
 						// now simultaneously update theta
+						// using the average gradient of
+						// the batch
+						newTheta[k] = make([]float64, len(theta))
+						for j := range grad {
+							grad[j] /= size
+						}
+						s.Clipping.Clip(grad)
+
+						if optimizers != nil {
+							copy(newTheta[k], theta)
+
+							err := optimizers[k].Step(newTheta[k], grad, alpha)
+							if err != nil {
+								return err
+							}
+							for j := range theta {
+								newTheta[k][j] = s.prox(j, newTheta[k][j], optimizers[k].StepSize(j, alpha))
+							}
+							continue
+						}
+
 						for j := range theta {
-							newTheta[k][j] = theta[j] + s.alpha*dj[j]
+							newTheta[k][j] = s.prox(j, theta[j]+alpha*grad[j], alpha)
 							if math.IsInf(newTheta[k][j], 0) || math.IsNaN(newTheta[k][j]) {
-								return fmt.Errorf("Sorry dude! Learning diverged. Some value of the parameter vector theta is ±Inf or NaN")
+								return fmt.Errorf("Sorry dude! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
 							}
 						}
 					}
@@ -294,7 +598,7 @@ func (s *Softmax) Learn() error {
 				}
 			}
 
-			fmt.Fprintf(s.Output, "Went through %v iterations.\n", iter)
+			s.logf("Went through %v iterations.\n", iter)
 
 			return nil
 		}()
@@ -303,14 +607,28 @@ func (s *Softmax) Learn() error {
 	}
 
 	if err != nil {
-		fmt.Fprintf(s.Output, "\nERROR: Error while learning –\n\t%v\n\n", err)
+		s.logf("\nERROR: Error while learning –\n\t%v\n\n", err)
 		return err
 	}
 
-	fmt.Fprintf(s.Output, "Training Completed.\n%v\n\n", s)
+	s.logf("Training Completed.\n%v\n\n", s)
 	return nil
 }
 
+// WithEvaluation turns on evaluating the model while it
+// learns online, averaging the accuracy and cost of its
+// predictions over the last windowSize datapoints (see
+// base.Evaluation,) and returns the model so it can be
+// chained after the constructor:
+//
+//     model := NewSoftmax(base.StochasticGA, 1e-4, 0, 3, 0, nil, nil, 2).WithEvaluation(200)
+//
+// The results are read from the model's Evaluation.
+func (s *Softmax) WithEvaluation(windowSize int) *Softmax {
+	s.Evaluation = base.NewEvaluation(windowSize)
+	return s
+}
+
 // OnlineLearn runs similar to using a fixed dataset with
 // Stochastic Gradient Descent, but it handles data by
 // passing it as a channal, and returns errors through
@@ -420,6 +738,16 @@ func (s *Softmax) Learn() error {
 //         panic("AAAARGGGH! SHIVER ME TIMBERS! THESE ROTTEN SCOUNDRELS FOUND AN ERROR!!!")
 //     }
 func (s *Softmax) OnlineLearn(errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	s.OnlineLearnContext(context.Background(), errors, dataset, onUpdate, normalize...)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// dataset channel is still open. This lets you stop
+// training, ie. when shutting down a server, without
+// having to close the dataset yourself.
+func (s *Softmax) OnlineLearnContext(ctx context.Context, errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
 	if errors == nil {
 		errors = make(chan error)
 	}
@@ -429,14 +757,20 @@ func (s *Softmax) OnlineLearn(errors chan error, dataset chan base.Datapoint, on
 		return
 	}
 
-	fmt.Fprintf(s.Output, "Training:\n\tModel: Softmax Classifier (%v classes)\n\tOptimization Method: Online Stochastic Gradient Descent\n\tFeatures: %v\n\tLearning Rate α: %v\n...\n\n", s.k, len(s.Parameters), s.alpha)
+	s.logf("Training:\n\tModel: Softmax Classifier (%v classes)\n\tOptimization Method: Online Stochastic Gradient Descent\n\tFeatures: %v\n\tLearning Rate α: %v\n...\n\n", s.k, len(s.Parameters), s.alpha)
 
 	norm := len(normalize) != 0 && normalize[0]
 	var point base.Datapoint
 	var more bool
 
 	for {
-		point, more = <-dataset
+		select {
+		case <-ctx.Done():
+			s.logf("Training Cancelled.\n%v\n\n", s)
+			close(errors)
+			return
+		case point, more = <-dataset:
+		}
 
 		if more {
 			if len(point.Y) != 1 {
@@ -445,7 +779,24 @@ func (s *Softmax) OnlineLearn(errors chan error, dataset chan base.Datapoint, on
 			}
 
 			if norm {
-				base.NormalizePoint(point.X)
+				point.X = base.NormalizedPoint(point.X)
+			}
+
+			// score the point before learning from it
+			if s.Evaluation != nil {
+				guess, err := s.Predict(point.X)
+				if err != nil {
+					errors <- err
+					continue
+				}
+
+				class := int(point.Y[0])
+				if class < 0 || class >= len(guess) {
+					errors <- fmt.Errorf("ERROR: Expected result %v isn't a class between 0 and k-1 (%v)!\n", point.Y[0], s.k-1)
+					continue
+				}
+
+				s.Evaluation.Add(base.ArgMax(guess) == class, -logProbability(guess[class]))
 			}
 
 			// go over each parameter vector for each
@@ -468,7 +819,7 @@ func (s *Softmax) OnlineLearn(errors chan error, dataset chan base.Datapoint, on
 						var inside float64
 
 						// calculate theta * x
-						for l, val := range s.Parameters[int(k)] {
+						for l, val := range s.Parameters[a] {
 							inside += val * x[l]
 						}
 
@@ -483,14 +834,9 @@ func (s *Softmax) OnlineLearn(errors chan error, dataset chan base.Datapoint, on
 						grad[a] += x[a] * (ident - numerator/denom)
 					}
 
-					// add in the regularization term
-					// λ*θ[j]
-					//
-					// notice that we don't count the
-					// constant term
-					for j := range grad {
-						grad[j] += s.regularization * s.Parameters[k][j]
-					}
+					// apply the regularization term
+					// (λ*θ[j] for L2 regularization)
+					s.regularizeGradient(k, grad)
 
 					return grad, nil
 				}(point, k)
@@ -501,9 +847,9 @@ func (s *Softmax) OnlineLearn(errors chan error, dataset chan base.Datapoint, on
 
 				// now simultaneously update theta
 				for j := range theta {
-					newθ := theta[j] + s.alpha*dj[j]
+					newθ := s.prox(j, theta[j]+s.alpha*dj[j], s.alpha)
 					if math.IsInf(newθ, 0) || math.IsNaN(newθ) {
-						errors <- fmt.Errorf("Sorry dude! Learning diverged. Some value of the parameter vector theta is ±Inf or NaN")
+						errors <- fmt.Errorf("Sorry dude! %w. Some value of the parameter vector theta is ±Inf or NaN", base.ErrDiverged)
 						close(errors)
 						return
 					}
@@ -514,19 +860,27 @@ func (s *Softmax) OnlineLearn(errors chan error, dataset chan base.Datapoint, on
 			go onUpdate(s.Parameters)
 
 		} else {
-			fmt.Fprintf(s.Output, "Training Completed.\n%v\n\n", s)
+			s.logf("Training Completed.\n%v\n\n", s)
 			close(errors)
 			return
 		}
 	}
 }
 
+// logf prints training progress to Output
+// when the model is Verbose
+func (s *Softmax) logf(format string, a ...interface{}) {
+	if s.Verbose {
+		fmt.Fprintf(s.Output, format, a...)
+	}
+}
+
 // String implements the fmt interface for clean printing. Here
 // we're using it to print the model as the equation h(θ)=...
 // where h is the softmax hypothesis model
 func (s *Softmax) String() string {
 	if len(s.Parameters) == 0 {
-		fmt.Fprintf(s.Output, "ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
+		s.logf("ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
 	}
 	var buffer bytes.Buffer
 
@@ -543,14 +897,73 @@ func (s *Softmax) String() string {
 // k is the classification value you are finding the gradient
 // for (because the parameter vactor is actually a vector _of_
 // vectors!)
+//
+// The training set is split up across goroutines to
+// compute the gradient in parallel (see
+// base.ParallelGradient.)
 func (s *Softmax) Dj(k int) ([]float64, error) {
 	if k > s.k || k < 0 {
 		return nil, fmt.Errorf("Given k (%v) is not valid with respect to the model", k)
 	}
 
+	sum, err := base.ParallelGradient(len(s.trainingSet), len(s.Parameters[0]), func(start, end int) ([]float64, error) {
+		return s.partialDj(k, start, end), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// apply the regularization term
+	// (λ*θ[j] for L2 regularization)
+	s.regularizeGradient(k, sum)
+
+	return sum, nil
+}
+
+// regularizeGradient applies the regularization term
+// to the gradient of the k-th parameter vector. The
+// default L2 term is applied to every parameter,
+// including the constant term, as it always has been
+// for Softmax, while the L1 and elastic net penalties
+// leave the constant term alone.
+func (s *Softmax) regularizeGradient(k int, grad []float64) {
+	for j := s.firstRegularized(); j < len(grad); j++ {
+		grad[j] = regularize(s.RegularizationType, s.L1Ratio, s.regularization, s.Parameters[k][j], grad[j])
+	}
+}
+
+// prox applies the L1 part of the regularization
+// penalty to the j-th value of a parameter vector
+// after a step of gradient ascent was taken to theta
+// with learning rate alpha (see shrink,) returning
+// the new value
+func (s *Softmax) prox(j int, theta, alpha float64) float64 {
+	if j < s.firstRegularized() {
+		return theta
+	}
+
+	return shrink(s.RegularizationType, s.L1Ratio, s.regularization, alpha, theta)
+}
+
+// firstRegularized returns the index of the first
+// parameter of each parameter vector the penalty is
+// applied to (see regularizeGradient)
+func (s *Softmax) firstRegularized() int {
+	if s.RegularizationType == base.L1 || s.RegularizationType == base.ElasticNet {
+		return 1
+	}
+
+	return 0
+}
+
+// partialDj returns the unregularized partial derivative
+// of the cost function J(θ) with respect to theta[k],
+// summed only over the training examples x[start]
+// through x[end-1]
+func (s *Softmax) partialDj(k, start, end int) []float64 {
 	sum := make([]float64, len(s.Parameters[0]))
 
-	for i := range s.trainingSet {
+	for i := start; i < end; i++ {
 		// account for constant term
 		x := append([]float64{1}, s.trainingSet[i]...)
 
@@ -566,8 +979,8 @@ func (s *Softmax) Dj(k int) ([]float64, error) {
 			var inside float64
 
 			// calculate theta * x
-			for l := range s.Parameters[k] {
-				inside += s.Parameters[k][l] * x[l]
+			for l := range s.Parameters[a] {
+				inside += s.Parameters[a][l] * x[l]
 			}
 
 			if a == k {
@@ -584,16 +997,7 @@ func (s *Softmax) Dj(k int) ([]float64, error) {
 		}
 	}
 
-	// add in the regularization term
-	// λ*θ[j]
-	//
-	// notice that we don't count the
-	// constant term
-	for j := range sum {
-		sum[j] += s.regularization * s.Parameters[k][j]
-	}
-
-	return sum, nil
+	return sum
 }
 
 // Dij returns the derivative of the cost function
@@ -626,7 +1030,7 @@ func (s *Softmax) Dij(i, k int) ([]float64, error) {
 		var inside float64
 
 		// calculate theta * x
-		for l, val := range s.Parameters[int(k)] {
+		for l, val := range s.Parameters[a] {
 			inside += val * x[l]
 		}
 
@@ -641,18 +1045,52 @@ func (s *Softmax) Dij(i, k int) ([]float64, error) {
 		grad[a] += x[a] * (ident - numerator/denom)
 	}
 
-	// add in the regularization term
-	// λ*θ[j]
-	//
-	// notice that we don't count the
-	// constant term
-	for j := range grad {
-		grad[j] += s.regularization * s.Parameters[k][j]
-	}
+	// apply the regularization term
+	// (λ*θ[j] for L2 regularization)
+	s.regularizeGradient(k, grad)
 
 	return grad, nil
 }
 
+// J returns the cost function of the given softmax model,
+// which is the (regularized) cross-entropy between the
+// expected classes and the predicted probabilities,
+// averaged over the training set:
+//
+//     J(θ) = -1/m Σ log(h(θ,x)[y])
+//
+// plus the regularization penalty on every class's
+// parameter vector. Predictions are kept away from
+// exactly 0 so a confidently wrong prediction gives a
+// large, but finite, cost. Could be useful in testing
+// convergence
+func (s *Softmax) J() (float64, error) {
+	var sum float64
+
+	for i := range s.trainingSet {
+		prediction, err := s.Predict(s.trainingSet[i])
+		if err != nil {
+			return 0, err
+		}
+
+		class := int(s.expectedResults[i])
+		if class < 0 || class >= len(prediction) {
+			return 0, fmt.Errorf("ERROR: Expected result %v isn't a class between 0 and k-1 (%v)!\n", s.expectedResults[i], s.k-1)
+		}
+
+		sum -= logProbability(prediction[class])
+	}
+
+	// add regularization term!
+	for k := range s.Parameters {
+		for j := s.firstRegularized(); j < len(s.Parameters[k]); j++ {
+			sum += penalty(s.RegularizationType, s.L1Ratio, s.regularization, s.Parameters[k][j]) / 2
+		}
+	}
+
+	return sum / float64(len(s.trainingSet)), nil
+}
+
 // Theta returns the parameter vector θ for use in persisting
 // the model, and optimizing the model through gradient descent
 // ( or other methods like Newton's Method)
@@ -660,8 +1098,34 @@ func (s *Softmax) Theta() [][]float64 {
 	return s.Parameters
 }
 
+// persistedSoftmax is the format a Softmax model
+// is saved to file with. Along with the parameter
+// vectors it holds the number of classes and
+// features, so a restored model can't silently
+// end up with the wrong dimensions, as well as the
+// hyperparameters used to train it.
+type persistedSoftmax struct {
+	K        int `json:"k"`
+	Features int `json:"features"`
+
+	Method             base.OptimizationMethod `json:"method"`
+	Alpha              float64                 `json:"alpha"`
+	Regularization     float64                 `json:"regularization"`
+	RegularizationType base.RegularizationType `json:"regularization_type,omitempty"`
+	L1Ratio            float64                 `json:"l1_ratio,omitempty"`
+	MaxIterations      int                     `json:"max_iterations"`
+	BatchSize          int                     `json:"batch_size,omitempty"`
+	Tolerance          float64                 `json:"tolerance,omitempty"`
+
+	FeatureNames []string    `json:"feature_names,omitempty"`
+	Parameters   [][]float64 `json:"theta"`
+}
+
 // PersistToFile takes in an absolute filepath and saves the
-// parameter vector θ to the file, which can be restored later.
+// model to the file, which can be restored later. Along with
+// the parameter vector θ, the number of classes k, the number
+// of features, and the model's hyperparameters (learning rate,
+// regularization, optimization method, etc.) are saved.
 // The function will take paths from the current directory, but
 // functions
 //
@@ -673,7 +1137,7 @@ func (s *Softmax) PersistToFile(path string) error {
 		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
 	}
 
-	bytes, err := json.Marshal(s.Parameters)
+	bytes, err := json.Marshal(s.persisted())
 	if err != nil {
 		return err
 	}
@@ -686,9 +1150,16 @@ func (s *Softmax) PersistToFile(path string) error {
 	return nil
 }
 
-// RestoreFromFile takes in a path to a parameter vector theta
-// and assigns the model it's operating on's parameter vector
-// to that.
+// RestoreFromFile takes in a path to a persisted model and
+// restores the model it's operating on to it, including the
+// number of classes k and the hyperparameters the model was
+// persisted with. An error is returned (and the model is left
+// untouched) if the number of classes or features saved
+// doesn't match the dimensions of the saved parameter vector.
+//
+// Files saved by older versions only hold the parameter
+// vector θ, in which case k is taken from it and the rest
+// of the model is kept as is.
 //
 // The path must ba an absolute path or a path from the current
 // directory
@@ -706,10 +1177,108 @@ func (s *Softmax) RestoreFromFile(path string) error {
 		return err
 	}
 
-	err = json.Unmarshal(bytes, &s.Parameters)
+	var model persistedSoftmax
+	legacy := false
+	err = json.Unmarshal(bytes, &model)
+	if err != nil {
+		// older files only hold the parameter vector
+		err = json.Unmarshal(bytes, &model.Parameters)
+		if err != nil {
+			return err
+		}
+		legacy = true
+
+		model.K = len(model.Parameters)
+		if model.K != 0 {
+			model.Features = len(model.Parameters[0]) - 1
+		}
+	}
+
+	return s.restore(model, legacy)
+}
+
+// PersistToGob saves the same data as PersistToFile (the
+// parameter vector, dimensions, and hyperparameters) to
+// the given file, but encoded with encoding/gob (see
+// base.PersistToGob.)
+func (s *Softmax) PersistToGob(path string) error {
+	return base.PersistToGob(path, s.persisted())
+}
+
+// RestoreFromGob takes in a path to a model saved with
+// PersistToGob and restores the model it's operating on
+// to it, validating the dimensions like RestoreFromFile.
+func (s *Softmax) RestoreFromGob(path string) error {
+	var model persistedSoftmax
+	err := base.RestoreFromGob(path, &model)
+	if err != nil {
+		return err
+	}
+
+	return s.restore(model, false)
+}
+
+// persisted returns the data saved when
+// persisting the model
+func (s *Softmax) persisted() persistedSoftmax {
+	var features int
+	if len(s.Parameters) != 0 {
+		features = len(s.Parameters[0]) - 1
+	}
+
+	return persistedSoftmax{
+		K:        s.k,
+		Features: features,
+
+		Method:             s.method,
+		Alpha:              s.alpha,
+		Regularization:     s.regularization,
+		RegularizationType: s.RegularizationType,
+		L1Ratio:            s.L1Ratio,
+		MaxIterations:      s.maxIterations,
+		BatchSize:          s.batchSize,
+		Tolerance:          s.Tolerance,
+
+		FeatureNames: s.FeatureNames,
+		Parameters:   s.Parameters,
+	}
+}
+
+// restore validates the dimensions of a persisted
+// model and assigns it to the model. If legacy is
+// true only the parameter vector (and k) were
+// persisted, so the hyperparameters are kept.
+func (s *Softmax) restore(model persistedSoftmax, legacy bool) error {
+	if model.K < 1 || model.K != len(model.Parameters) {
+		return fmt.Errorf("ERROR: restored number of classes k (%v) doesn't match the number of parameter vectors (%v)!", model.K, len(model.Parameters))
+	}
+	for i := range model.Parameters {
+		if len(model.Parameters[i]) != model.Features+1 {
+			return fmt.Errorf("ERROR: restored parameter vector %v should have one value per feature plus the constant term!\n\tLength of parameter vector: %v\n\tFeatures: %v\n", i, len(model.Parameters[i]), model.Features)
+		}
+	}
+
+	err := base.CheckFeatureNames(model.FeatureNames, model.Features)
 	if err != nil {
 		return err
 	}
 
+	s.k = model.K
+	s.Parameters = model.Parameters
+	s.FeatureNames = model.FeatureNames
+
+	if legacy {
+		return nil
+	}
+
+	s.method = model.Method
+	s.alpha = model.Alpha
+	s.regularization = model.Regularization
+	s.RegularizationType = model.RegularizationType
+	s.L1Ratio = model.L1Ratio
+	s.maxIterations = model.MaxIterations
+	s.batchSize = model.BatchSize
+	s.Tolerance = model.Tolerance
+
 	return nil
 }
@@ -2,6 +2,9 @@ package linear
 
 import (
 	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
 	"os"
 	"testing"
 
@@ -83,16 +86,22 @@ func init() {
 	}
 }
 
-// maxI returns the index of the maximum value
-// of a slice of float64's
-func maxI(array []float64) int {
-	var i int
-	for j := range array {
-		if array[j] > array[i] {
-			i = j
-		}
-	}
-	return i
+// predicting with normalize should leave the
+// caller's input untouched
+func TestSoftmaxPredictNormalizeShouldPass1(t *testing.T) {
+	model := NewSoftmax(base.BatchGA, 1e-5, 0, 3, 10, fdx, fdy)
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	x := []float64{3, 4, 12}
+
+	guess, err := model.Predict(x, true)
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, []float64{3, 4, 12}, x, "Predict should not change the input when normalizing")
+
+	expected, err := model.Predict([]float64{3.0 / 13, 4.0 / 13, 12.0 / 13})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.InDeltaSlice(t, expected, guess, 1e-9, "Prediction should be made off of the normalized input")
 }
 
 // test ( 10*i + j/20 + k ) > 0
@@ -114,7 +123,7 @@ func TestFourDimensionalSoftmaxShouldPass1(t *testing.T) {
 				guess, err = model.Predict([]float64{i, j, k})
 				assert.Len(t, guess, 3, "Length of Softmax hypothesis output should be 3")
 
-				prediction := maxI(guess)
+				prediction := base.ArgMax(guess)
 
 				if i/2+j+2*k > 0 && -1*i-j-0.5*k > 0 {
 					if prediction != 2 {
@@ -167,7 +176,7 @@ func TestFourDimensionalSoftmaxShouldPass2(t *testing.T) {
 				guess, err = model.Predict([]float64{i, j, k})
 				assert.Len(t, guess, 3, "Length of Softmax hypothesis output should be 3")
 
-				prediction := maxI(guess)
+				prediction := base.ArgMax(guess)
 
 				if i/2+j+2*k > 0 && -1*i-j-0.5*k > 0 {
 					if prediction != 2 {
@@ -201,6 +210,93 @@ func TestFourDimensionalSoftmaxShouldPass2(t *testing.T) {
 	assert.True(t, float64(incorrect)/float64(count) < 0.14, "Accuracy should be greater than 86%")
 }
 
+// the gradient computed in parallel should be
+// the same as the one computed serially
+func TestFourDimensionalSoftmaxParallelShouldPass1(t *testing.T) {
+	model := NewSoftmax(base.BatchGA, 1e-5, 0, 3, 50, fdx, fdy)
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	for k := range model.Parameters {
+		dj, err := model.Dj(k)
+		assert.Nil(t, err, "Gradient error should be nil")
+
+		serial := model.partialDj(k, 0, len(fdx))
+		assert.Len(t, dj, len(serial), "Gradient should have one value per parameter")
+		for j := range serial {
+			assert.InDelta(t, serial[j], dj[j], 1e-6*(1+math.Abs(serial[j])), "Parallel gradient should match the serial gradient")
+		}
+	}
+}
+
+// test ( 10*i + j/20 + k ) > 0 with plenty of
+// iterations: with a small learning rate θ barely
+// moves once the predictions saturate, so batch
+// gradient ascent should stop early, before the
+// maximum number of iterations
+func TestFourDimensionalSoftmaxConvergenceShouldPass1(t *testing.T) {
+	model := NewSoftmax(base.BatchGA, 1e-4, 0, 3, 1000, fdx, fdy)
+	model.Tolerance = 1e-2
+
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	assert.True(t, model.Iterations() > 0, "Model should have gone through at least one iteration")
+	assert.True(t, model.Iterations() < 1000, "Model should have converged before the maximum number of iterations - went through %v", model.Iterations())
+
+	// a negative tolerance turns early stopping off
+	model = NewSoftmax(base.BatchGA, 1e-5, 0, 3, 10, fdx, fdy)
+	model.Tolerance = -1
+
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.Equal(t, 10, model.Iterations(), "Model should go through every iteration without early stopping")
+}
+
+// a learning rate that's too large to converge
+// with a constant rate should converge when it
+// decays with inverse time decay
+func TestFourDimensionalSoftmaxDecayShouldPass1(t *testing.T) {
+	model := NewSoftmax(base.BatchGA, 1e-2, 0, 3, 500, fdx, fdy)
+	model.Tolerance = 1e-2
+	model.Output = ioutil.Discard
+
+	err := model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.Equal(t, 500, model.Iterations(), "Model shouldn't converge with a constant learning rate this large")
+
+	model = NewSoftmax(base.BatchGA, 1e-2, 0, 3, 500, fdx, fdy)
+	model.Tolerance = 1e-2
+	model.Output = ioutil.Discard
+	model.Schedule = base.InverseTimeDecay(0.1)
+
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.True(t, model.Iterations() < 500, "Model should converge once the learning rate decays - went through %v", model.Iterations())
+}
+
+func TestFourDimensionalSoftmaxCostShouldPass1(t *testing.T) {
+	model := NewSoftmax(base.BatchGA, 1e-5, 0, 3, 10, fdx, fdy)
+	model.Output = ioutil.Discard
+
+	// with θ = 0 every class is equally likely
+	before, err := model.J()
+	assert.Nil(t, err, "Cost error should be nil")
+	assert.InDelta(t, math.Log(3), before, 1e-9, "Cost of the zero parameter vectors should be log(3)")
+
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	after, err := model.J()
+	assert.Nil(t, err, "Cost error should be nil")
+	assert.True(t, after < before, "Cost (%v) should go down after learning (from %v)", after, before)
+
+	// expected results have to be classes
+	model = NewSoftmax(base.BatchGA, 1e-5, 0, 3, 10, [][]float64{{1}}, []float64{3})
+	_, err = model.J()
+	assert.NotNil(t, err, "Cost error should not be nil when the expected result isn't a class")
+}
+
 // test ( 10*i + j/20 + k ) > 0 but don't have enough iterations
 func TestFourDimensionalSoftmaxShouldFail1(t *testing.T) {
 	var err error
@@ -220,7 +316,7 @@ func TestFourDimensionalSoftmaxShouldFail1(t *testing.T) {
 				guess, err = model.Predict([]float64{i, j, k})
 				assert.Len(t, guess, 3, "Length of Softmax hypothesis output should be 3")
 
-				prediction := maxI(guess)
+				prediction := base.ArgMax(guess)
 
 				if i/2+j+2*k > 0 && -1*i-j-0.5*k > 0 {
 					if prediction != 2 {
@@ -273,7 +369,7 @@ func TestFourDimensionalSoftmaxShouldFail2(t *testing.T) {
 				guess, err = model.Predict([]float64{i, j, k})
 				assert.Len(t, guess, 3, "Length of Softmax hypothesis output should be 3")
 
-				prediction := maxI(guess)
+				prediction := base.ArgMax(guess)
 
 				if i/2+j+2*k > 0 && -1*i-j-0.5*k > 0 {
 					if prediction != 2 {
@@ -401,7 +497,7 @@ func TestTwoDimensionalSoftmaxShouldPass1(t *testing.T) {
 	for i := -2.0; i < 2; i += 0.0372345432 {
 		guess, err = model.Predict([]float64{float64(i)})
 
-		prediction := maxI(guess)
+		prediction := base.ArgMax(guess)
 
 		if i > 0.75 {
 			if prediction != 4 {
@@ -455,7 +551,7 @@ func TestTwoDimensionalSoftmaxShouldPass2(t *testing.T) {
 	for i := -2.0; i < 2; i += 0.0372345432 {
 		guess, err = model.Predict([]float64{float64(i)})
 
-		prediction := maxI(guess)
+		prediction := base.ArgMax(guess)
 
 		if i > 0.75 {
 			if prediction != 4 {
@@ -509,7 +605,7 @@ func TestTwoDimensionalSoftmaxShouldFail1(t *testing.T) {
 	for i := -1.0; i < 1; i += 0.037 {
 		guess, err = model.Predict([]float64{float64(i)})
 
-		prediction := maxI(guess)
+		prediction := base.ArgMax(guess)
 
 		if i > 0.75 {
 			if prediction != 4 {
@@ -563,7 +659,7 @@ func TestTwoDimensionalSoftmaxShouldFail2(t *testing.T) {
 	for i := -1.0; i < 1; i += 0.037 {
 		guess, err = model.Predict([]float64{float64(i)})
 
-		prediction := maxI(guess)
+		prediction := base.ArgMax(guess)
 
 		if i > 0.75 {
 			if prediction != 4 {
@@ -618,7 +714,7 @@ func TestThreeDimensionalSoftmaxShouldPass1(t *testing.T) {
 		for j := -1.0; j < 1.0; j += 0.112 {
 			guess, err = model.Predict([]float64{float64(i), float64(j)})
 
-			prediction := maxI(guess)
+			prediction := base.ArgMax(guess)
 
 			if -2*i+j/2-0.5 > 0 && -1*i-j < 0 {
 				if prediction != 2 {
@@ -664,7 +760,101 @@ func TestThreeDimensionalSoftmaxShouldPass2(t *testing.T) {
 		for j := -1.0; j < 1.0; j += 0.112 {
 			guess, err = model.Predict([]float64{float64(i), float64(j)})
 
-			prediction := maxI(guess)
+			prediction := base.ArgMax(guess)
+
+			if -2*i+j/2-0.5 > 0 && -1*i-j < 0 {
+				if prediction != 2 {
+					incorrect++
+				}
+
+			} else if -2*i+j/2-0.5 > 0 && -1*i-j > 0 {
+				if prediction != 1 {
+					incorrect++
+				}
+
+			} else {
+				if prediction != 0 {
+					incorrect++
+				}
+
+			}
+
+			assert.Len(t, guess, 3, "Length of a Softmax model output from hypothesis should reflect the input dimensions")
+			assert.Nil(t, err, "Prediction error should be nil")
+
+			count++
+		}
+	}
+
+	fmt.Printf("Predictions: %v\n\tIncorrect: %v\n\tAccuracy Rate: %v percent\n", count, incorrect, 100*(1.0-float64(incorrect)/float64(count)))
+	assert.True(t, float64(incorrect)/float64(count) < 0.14, "Accuracy should be greater than 86%")
+}
+
+// same as above but with MiniBatchGA
+func TestThreeDimensionalSoftmaxShouldPass3(t *testing.T) {
+	var err error
+
+	model := NewSoftmax(base.MiniBatchGA, 5e-4, 0, 3, 500, tdx, tdy)
+	model.UpdateBatchSize(10)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var guess []float64
+	var count int
+	var incorrect int
+
+	for i := -1.0; i < 1.0; i += 0.112 {
+		for j := -1.0; j < 1.0; j += 0.112 {
+			guess, err = model.Predict([]float64{float64(i), float64(j)})
+
+			prediction := base.ArgMax(guess)
+
+			if -2*i+j/2-0.5 > 0 && -1*i-j < 0 {
+				if prediction != 2 {
+					incorrect++
+				}
+
+			} else if -2*i+j/2-0.5 > 0 && -1*i-j > 0 {
+				if prediction != 1 {
+					incorrect++
+				}
+
+			} else {
+				if prediction != 0 {
+					incorrect++
+				}
+
+			}
+
+			assert.Len(t, guess, 3, "Length of a Softmax model output from hypothesis should reflect the input dimensions")
+			assert.Nil(t, err, "Prediction error should be nil")
+
+			count++
+		}
+	}
+
+	fmt.Printf("Predictions: %v\n\tIncorrect: %v\n\tAccuracy Rate: %v percent\n", count, incorrect, 100*(1.0-float64(incorrect)/float64(count)))
+	assert.True(t, float64(incorrect)/float64(count) < 0.14, "Accuracy should be greater than 86%")
+}
+
+// same as above but with Adam
+func TestThreeDimensionalSoftmaxShouldPass4(t *testing.T) {
+	var err error
+
+	model := NewSoftmax(base.Adam, .01, 0, 3, 100, tdx, tdy)
+	model.UpdateBatchSize(10)
+	err = model.Learn()
+	assert.Nil(t, err, "Learning error should be nil")
+
+	var guess []float64
+	var count int
+	var incorrect int
+
+	for i := -1.0; i < 1.0; i += 0.112 {
+		for j := -1.0; j < 1.0; j += 0.112 {
+			guess, err = model.Predict([]float64{float64(i), float64(j)})
+
+			prediction := base.ArgMax(guess)
 
 			if -2*i+j/2-0.5 > 0 && -1*i-j < 0 {
 				if prediction != 2 {
@@ -694,6 +884,115 @@ func TestThreeDimensionalSoftmaxShouldPass2(t *testing.T) {
 	assert.True(t, float64(incorrect)/float64(count) < 0.14, "Accuracy should be greater than 86%")
 }
 
+// mini-batches should be shuffled with the model's
+// source of randomness rather than the global one,
+// so training is reproducible
+func TestSoftmaxShuffleShouldPass1(t *testing.T) {
+	for _, method := range []base.OptimizationMethod{base.MiniBatchGA, base.Adam} {
+		learn := func(seed int64) [][]float64 {
+			// moving the global source shouldn't matter
+			rand.Int()
+
+			model := NewSoftmax(method, 5e-4, 0, 3, 20, tdx, tdy)
+			model.UpdateBatchSize(10)
+			model.Shuffle = rand.New(rand.NewSource(seed))
+			model.Output = ioutil.Discard
+
+			err := model.Learn()
+			assert.Nil(t, err, "Learning error should be nil")
+
+			return model.Parameters
+		}
+
+		assert.Equal(t, learn(7), learn(7), "Training with the same seed should be reproducible with %v", method)
+		assert.NotEqual(t, learn(7), learn(8), "Training with a different seed should visit the examples in a different order with %v", method)
+	}
+}
+
+// test the softmax hypothesis and gradient
+// against a small hand-computed example where
+// θ0·x = 1, θ1·x = 1, and θ2·x = -1
+func TestSoftmaxGradientShouldPass1(t *testing.T) {
+	model := NewSoftmax(base.BatchGA, 1e-4, 0, 3, 1, [][]float64{[]float64{1}}, []float64{0})
+	model.Parameters = [][]float64{
+		[]float64{0, 1},
+		[]float64{1, 0},
+		[]float64{0, -1},
+	}
+
+	denom := 2*math.E + 1/math.E
+	expected := []float64{math.E / denom, math.E / denom, 1 / math.E / denom}
+
+	guess, err := model.Predict([]float64{1})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Len(t, guess, 3, "Length of a Softmax model output from hypothesis should reflect the input dimensions")
+
+	var total float64
+	for i := range guess {
+		assert.InDelta(t, expected[i], guess[i], 1e-9, "Probability of class %v should match the hand-computed value", i)
+		total += guess[i]
+	}
+	assert.InDelta(t, 1, total, 1e-9, "Probabilities should sum to 1")
+
+	// the gradient for class k is x·(1{y == k} - P(y = k|x))
+	// (the constant term included in x)
+	for k := range expected {
+		var ident float64
+		if k == 0 {
+			ident = 1
+		}
+
+		dj, err := model.Dj(k)
+		assert.Nil(t, err, "Derivative error should be nil")
+
+		dij, err := model.Dij(0, k)
+		assert.Nil(t, err, "Derivative error should be nil")
+
+		for j := range dj {
+			assert.InDelta(t, ident-expected[k], dj[j], 1e-9, "Dj should match the hand-computed gradient for class %v", k)
+			assert.InDelta(t, ident-expected[k], dij[j], 1e-9, "Dij should match the hand-computed gradient for class %v", k)
+		}
+	}
+}
+
+func TestSoftmaxPredictClassShouldPass1(t *testing.T) {
+	model := NewSoftmax(base.BatchGA, 1e-4, 0, 3, 1, nil, nil, 1)
+	model.Parameters = [][]float64{
+		[]float64{0, 1},
+		[]float64{1, 0},
+		[]float64{0, -1},
+	}
+
+	for _, x := range []float64{-5, -1.5, 0.5, 2, 5} {
+		guess, err := model.Predict([]float64{x})
+		assert.Nil(t, err, "Prediction error should be nil")
+
+		class, err := model.PredictClass([]float64{x})
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, base.ArgMax(guess), class, "PredictClass should return the most likely class")
+
+		class, confidence, err := model.PredictClassWithConfidence([]float64{x})
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, base.ArgMax(guess), class, "PredictClassWithConfidence should return the most likely class")
+		assert.Equal(t, guess[class], confidence, "Confidence should be the probability of the predicted class")
+	}
+
+	// θ2·x is largest for x < -1/2
+	class, err := model.PredictClass([]float64{-5})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, 2, class, "Class should be 2 for x = -5")
+}
+
+func TestSoftmaxPredictClassShouldFail1(t *testing.T) {
+	model := NewSoftmax(base.BatchGA, 1e-4, 0, 3, 1, nil, nil, 1)
+
+	_, err := model.PredictClass([]float64{1, 2, 3})
+	assert.NotNil(t, err, "Prediction error should not be nil")
+
+	_, _, err = model.PredictClassWithConfidence([]float64{1, 2, 3})
+	assert.NotNil(t, err, "Prediction error should not be nil")
+}
+
 //* Test Online Learning through channels *//
 
 func TestThreeDimensionalSoftmaxOnlineShouldPass2(t *testing.T) {
@@ -757,7 +1056,7 @@ func TestThreeDimensionalSoftmaxOnlineShouldPass2(t *testing.T) {
 		for j := -1.0; j < 1.0; j += 0.113 {
 			guess, err = model.Predict([]float64{float64(i), float64(j)})
 
-			prediction := maxI(guess)
+			prediction := base.ArgMax(guess)
 
 			if -2*i+j/2-0.5 > 0 && -1*i-j < 0 {
 				if prediction != 2 {
@@ -848,7 +1147,7 @@ func TestThreeDimensionalSoftmaxOnlineNormalizedShouldPass2(t *testing.T) {
 		for j := -1.0; j < 1.0; j += 0.113 {
 			guess, err = model.Predict([]float64{float64(i), float64(j)}, true)
 
-			prediction := maxI(guess)
+			prediction := base.ArgMax(guess)
 
 			if -2*i+j/2-0.5 > 0 && -1*i-j < 0 {
 				if prediction != 2 {
@@ -896,7 +1195,7 @@ func TestPersistSoftmaxShouldPass1(t *testing.T) {
 		for j := -1.0; j < 1.0; j += 0.112 {
 			guess, err = model.Predict([]float64{float64(i), float64(j)})
 
-			prediction := maxI(guess)
+			prediction := base.ArgMax(guess)
 
 			if -2*i+j/2-0.5 > 0 && -1*i-j < 0 {
 				if prediction != 2 {
@@ -960,7 +1259,7 @@ func TestPersistSoftmaxShouldPass1(t *testing.T) {
 		for j := -1.0; j < 1.0; j += 0.112 {
 			guess, err = model.Predict([]float64{float64(i), float64(j)})
 
-			prediction := maxI(guess)
+			prediction := base.ArgMax(guess)
 
 			if -2*i+j/2-0.5 > 0 && -1*i-j < 0 {
 				if prediction != 2 {
@@ -989,3 +1288,174 @@ func TestPersistSoftmaxShouldPass1(t *testing.T) {
 	fmt.Printf("Predictions: %v\n\tIncorrect: %v\n\tAccuracy Rate: %v percent\n", count, incorrect, 100*(1.0-float64(incorrect)/float64(count)))
 	assert.True(t, float64(incorrect)/float64(count) < 0.14, "Accuracy should be greater than 86%")
 }
+
+func TestPersistSoftmaxShouldPass2(t *testing.T) {
+	model := NewSoftmax(base.MiniBatchGA, 1e-4, 0.5, 3, 700, nil, nil, 2)
+	model.UpdateBatchSize(16)
+	model.Tolerance = 1e-3
+	model.Parameters = [][]float64{
+		{1, 2, 3},
+		{4, 5, 6},
+		{7, 8, 9},
+	}
+
+	err := model.PersistToFile("/tmp/.goml/SoftmaxFull.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	// restore into a model with the wrong k
+	restored := NewSoftmax(base.BatchGA, 1, 0, 2, 10, nil, nil, 2)
+	err = restored.RestoreFromFile("/tmp/.goml/SoftmaxFull.json")
+	assert.Nil(t, err, "Restoring error should be nil")
+
+	assert.Equal(t, model.Parameters, restored.Parameters, "Restored parameters should match the persisted ones")
+	assert.Equal(t, 3, restored.k, "Restored model should have the persisted number of classes")
+	assert.Equal(t, base.MiniBatchGA, restored.method, "Restored model should have the persisted optimization method")
+	assert.Equal(t, 1e-4, restored.LearningRate(), "Restored model should have the persisted learning rate")
+	assert.Equal(t, 0.5, restored.regularization, "Restored model should have the persisted regularization")
+	assert.Equal(t, 700, restored.MaxIterations(), "Restored model should have the persisted maximum iterations")
+	assert.Equal(t, 16, restored.BatchSize(), "Restored model should have the persisted batch size")
+	assert.Equal(t, 1e-3, restored.Tolerance, "Restored model should have the persisted tolerance")
+
+	guess, err := restored.Predict([]float64{0.1, -0.3})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Len(t, guess, 3, "Restored model should predict a probability for each persisted class")
+
+	// files which only hold the parameter vector
+	// should still restore
+	err = ioutil.WriteFile("/tmp/.goml/SoftmaxLegacy.json", []byte(`[[1,2,3],[4,5,6]]`), os.ModePerm)
+	assert.Nil(t, err, "Writing file error should be nil")
+
+	err = restored.RestoreFromFile("/tmp/.goml/SoftmaxLegacy.json")
+	assert.Nil(t, err, "Restoring error should be nil")
+	assert.Equal(t, [][]float64{{1, 2, 3}, {4, 5, 6}}, restored.Parameters, "Restored parameters should match the persisted ones")
+	assert.Equal(t, 2, restored.k, "Number of classes should be taken from the parameter vector")
+	assert.Equal(t, 1e-4, restored.LearningRate(), "Hyperparameters shouldn't change restoring only the parameter vector")
+}
+
+func TestPersistSoftmaxShouldFail1(t *testing.T) {
+	model := NewSoftmax(base.BatchGA, 1e-4, 0, 3, 700, nil, nil, 2)
+
+	// k doesn't match the number of parameter vectors
+	err := ioutil.WriteFile("/tmp/.goml/SoftmaxBad.json", []byte(`{"k":4,"features":2,"theta":[[1,2,3],[4,5,6],[7,8,9]]}`), os.ModePerm)
+	assert.Nil(t, err, "Writing file error should be nil")
+
+	err = model.RestoreFromFile("/tmp/.goml/SoftmaxBad.json")
+	assert.NotNil(t, err, "Restoring a mismatched number of classes should return an error")
+	assert.Equal(t, 3, model.k, "Model shouldn't change after a failed restore")
+	assert.Equal(t, [][]float64{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}}, model.Parameters, "Model shouldn't change after a failed restore")
+
+	// parameter vector doesn't match the number of features
+	err = ioutil.WriteFile("/tmp/.goml/SoftmaxBad.json", []byte(`{"k":2,"features":3,"theta":[[1,2,3],[4,5,6]]}`), os.ModePerm)
+	assert.Nil(t, err, "Writing file error should be nil")
+
+	err = model.RestoreFromFile("/tmp/.goml/SoftmaxBad.json")
+	assert.NotNil(t, err, "Restoring a mismatched number of features should return an error")
+
+	// ragged parameter vector from an older file
+	err = ioutil.WriteFile("/tmp/.goml/SoftmaxBad.json", []byte(`[[1,2,3],[4,5]]`), os.ModePerm)
+	assert.Nil(t, err, "Writing file error should be nil")
+
+	err = model.RestoreFromFile("/tmp/.goml/SoftmaxBad.json")
+	assert.NotNil(t, err, "Restoring a ragged parameter vector should return an error")
+}
+
+func TestPersistSoftmaxGobShouldPass1(t *testing.T) {
+	model := NewSoftmax(base.MiniBatchGA, 1e-4, 0.5, 3, 700, nil, nil, 2)
+	model.UpdateBatchSize(16)
+	model.Parameters = [][]float64{
+		{1, 2, 3},
+		{4, 5, 6},
+		{7, 8, 9},
+	}
+
+	err := model.PersistToGob("/tmp/.goml/Softmax.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+	err = model.PersistToFile("/tmp/.goml/SoftmaxGobCheck.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	fromGob := NewSoftmax(base.BatchGA, 1, 0, 2, 10, nil, nil, 2)
+	err = fromGob.RestoreFromGob("/tmp/.goml/Softmax.gob")
+	assert.Nil(t, err, "Restoring error should be nil")
+
+	fromJSON := NewSoftmax(base.BatchGA, 1, 0, 2, 10, nil, nil, 2)
+	err = fromJSON.RestoreFromFile("/tmp/.goml/SoftmaxGobCheck.json")
+	assert.Nil(t, err, "Restoring error should be nil")
+
+	assert.Equal(t, model.Parameters, fromGob.Parameters, "Parameters restored from gob should match the model")
+	assert.Equal(t, 3, fromGob.k, "Number of classes should be restored from gob")
+	assert.Equal(t, 16, fromGob.BatchSize(), "Hyperparameters should be restored from gob")
+	assert.Equal(t, fromJSON.persisted(), fromGob.persisted(), "Gob and JSON should restore the same model")
+}
+
+func TestPersistSoftmaxFeatureNamesShouldPass1(t *testing.T) {
+	model := NewSoftmax(base.BatchGA, 1e-4, 0, 3, 700, nil, nil, 2)
+	model.FeatureNames = []string{"x", "y"}
+
+	assert.Equal(t, 2, model.Features(), "Model should take 2 features")
+
+	_, err := model.Predict([]float64{1})
+	assert.NotNil(t, err, "Predicting with a missing feature should return an error")
+	assert.Contains(t, err.Error(), `Expected feature "y" at index 1`, "Error should name the missing feature")
+
+	err = model.PersistToGob("/tmp/.goml/SoftmaxNames.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+	err = model.PersistToFile("/tmp/.goml/SoftmaxNames.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	fromGob := NewSoftmax(base.BatchGA, 1e-4, 0, 3, 700, nil, nil, 2)
+	err = fromGob.RestoreFromGob("/tmp/.goml/SoftmaxNames.gob")
+	assert.Nil(t, err, "Restoring error should be nil")
+	assert.Equal(t, model.FeatureNames, fromGob.FeatureNames, "Feature names should be restored from gob")
+
+	fromJSON := NewSoftmax(base.BatchGA, 1e-4, 0, 3, 700, nil, nil, 2)
+	err = fromJSON.RestoreFromFile("/tmp/.goml/SoftmaxNames.json")
+	assert.Nil(t, err, "Restoring error should be nil")
+	assert.Equal(t, model.FeatureNames, fromJSON.FeatureNames, "Feature names should be restored from JSON")
+
+	err = ioutil.WriteFile("/tmp/.goml/SoftmaxBadNames.json", []byte(`{"k":3,"features":2,"feature_names":["x"],"theta":[[1,2,3],[4,5,6],[7,8,9]]}`), os.ModePerm)
+	assert.Nil(t, err, "Writing file error should be nil")
+
+	err = fromJSON.RestoreFromFile("/tmp/.goml/SoftmaxBadNames.json")
+	assert.NotNil(t, err, "Restoring the wrong number of feature names should return an error")
+}
+
+func TestSoftmaxOnlineEvaluationShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewSoftmax(base.StochasticGA, 1e-2, 0, 3, 0, nil, nil, 1).WithEvaluation(100)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	go func() {
+		for iter := 0; iter < 30; iter++ {
+			for i := -3.0; i < 3.0; i += 0.1 {
+				class := 1.0
+				if i < -1 {
+					class = 0
+				} else if i > 1 {
+					class = 2
+				}
+
+				stream <- base.Datapoint{X: []float64{i}, Y: []float64{class}}
+			}
+		}
+
+		// an invalid class can't be scored
+		stream <- base.Datapoint{X: []float64{0}, Y: []float64{5}}
+
+		close(stream)
+	}()
+
+	var count int
+	for err := range errors {
+		assert.NotNil(t, err, "Only the invalid class should return an error")
+		count++
+	}
+	assert.Equal(t, 1, count, "The invalid class should return an error")
+
+	assert.Equal(t, 1800, model.Evaluation.Seen(), "Every valid datapoint should be evaluated")
+
+	accuracy := model.Evaluation.Accuracy()
+	assert.True(t, accuracy > 0.8, "Rolling accuracy (%v) should be high on points the model hasn't trained on", accuracy)
+}
@@ -0,0 +1,186 @@
+package linear
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+// ModelSpec is a self-describing description of a
+// trained generalized linear model, which is what
+// ExportSpec writes (as JSON.) Unlike the bare
+// parameter vector PersistToFile saves, it has
+// everything a program in any language needs to
+// evaluate the model on its own:
+//
+//	η = intercept + Σ coefficients[i] * x[i]
+//	y = link⁻¹(η)
+//
+// where the inverse link function is
+//
+//	identity: y = η                   (least squares)
+//	logit:    y = 1 / (1 + exp(-η))   (logistic regression)
+//	log:      y = exp(η)              (poisson regression)
+//
+// The coefficients are in the same order as the
+// features the model was trained on.
+type ModelSpec struct {
+	// Model is the type of model ("least_squares",
+	// "logistic", or "poisson") and Link the link
+	// function ("identity", "logit", or "log")
+	Model string `json:"model"`
+	Link  string `json:"link"`
+
+	// FitIntercept is whether the model has an
+	// intercept (if it's false Intercept is 0)
+	FitIntercept bool    `json:"fit_intercept"`
+	Intercept    float64 `json:"intercept"`
+
+	// Features is the number of features the model
+	// takes, with one coefficient for each, and
+	// FeatureNames their names (if the model has
+	// any)
+	Features     int       `json:"features"`
+	FeatureNames []string  `json:"feature_names,omitempty"`
+	Coefficients []float64 `json:"coefficients"`
+}
+
+// exportSpec returns the JSON ModelSpec of a model
+// with the parameter vector θ
+func exportSpec(model, link string, theta []float64, fitIntercept bool, featureNames []string) ([]byte, error) {
+	if len(theta) == 0 {
+		return nil, fmt.Errorf("ERROR: %w: you just tried to export a model with no parameters! Train the model first", base.ErrNotTrained)
+	}
+
+	spec := ModelSpec{
+		Model:        model,
+		Link:         link,
+		FitIntercept: fitIntercept,
+		FeatureNames: featureNames,
+		Coefficients: append([]float64{}, theta...),
+	}
+
+	if fitIntercept {
+		spec.Intercept = theta[0]
+		spec.Coefficients = spec.Coefficients[1:]
+	}
+	spec.Features = len(spec.Coefficients)
+
+	err := base.CheckFeatureNames(featureNames, spec.Features)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// importSpec parses a JSON ModelSpec, checking that
+// it's for the given type of model, and returns it
+// along with the parameter vector θ it describes
+func importSpec(data []byte, model, link string) (ModelSpec, []float64, error) {
+	var spec ModelSpec
+	err := json.Unmarshal(data, &spec)
+	if err != nil {
+		return spec, nil, err
+	}
+
+	if spec.Model != model || spec.Link != link {
+		return spec, nil, fmt.Errorf("ERROR: the spec is for a %q model with a %q link, not a %q model with a %q link", spec.Model, spec.Link, model, link)
+	}
+
+	if len(spec.Coefficients) == 0 || spec.Features != len(spec.Coefficients) {
+		return spec, nil, fmt.Errorf("ERROR: the spec should have one coefficient for each feature!\n\tFeatures: %v\n\tCoefficients: %v\n", spec.Features, len(spec.Coefficients))
+	}
+
+	err = base.CheckFeatureNames(spec.FeatureNames, spec.Features)
+	if err != nil {
+		return spec, nil, err
+	}
+
+	if !spec.FitIntercept {
+		if spec.Intercept != 0 {
+			return spec, nil, fmt.Errorf("ERROR: the spec has an intercept (%v) but doesn't fit one", spec.Intercept)
+		}
+
+		return spec, spec.Coefficients, nil
+	}
+
+	return spec, append([]float64{spec.Intercept}, spec.Coefficients...), nil
+}
+
+// ExportSpec returns a self-describing JSON document
+// (see ModelSpec) of the trained model, which a service
+// written in any language can use to make predictions.
+// Import it back into a goml model with ImportSpec.
+func (l *LeastSquares) ExportSpec() ([]byte, error) {
+	return exportSpec("least_squares", "identity", l.Parameters, l.FitIntercept, l.FeatureNames)
+}
+
+// ImportSpec sets the model's parameters (along with
+// whether it fits an intercept and its FeatureNames)
+// from a document written by ExportSpec, returning an
+// error if it isn't for a least squares model.
+func (l *LeastSquares) ImportSpec(data []byte) error {
+	spec, theta, err := importSpec(data, "least_squares", "identity")
+	if err != nil {
+		return err
+	}
+
+	l.Parameters = theta
+	l.FitIntercept = spec.FitIntercept
+	l.FeatureNames = spec.FeatureNames
+	l.fitted = true
+
+	return nil
+}
+
+// ExportSpec returns a self-describing JSON document
+// (see ModelSpec) of the trained model, which a service
+// written in any language can use to make predictions.
+// Import it back into a goml model with ImportSpec.
+func (l *Logistic) ExportSpec() ([]byte, error) {
+	return exportSpec("logistic", "logit", l.Parameters, l.FitIntercept, l.FeatureNames)
+}
+
+// ImportSpec sets the model's parameters (along with
+// whether it fits an intercept and its FeatureNames)
+// from a document written by ExportSpec, returning an
+// error if it isn't for a logistic regression model.
+func (l *Logistic) ImportSpec(data []byte) error {
+	spec, theta, err := importSpec(data, "logistic", "logit")
+	if err != nil {
+		return err
+	}
+
+	l.Parameters = theta
+	l.FitIntercept = spec.FitIntercept
+	l.FeatureNames = spec.FeatureNames
+
+	return nil
+}
+
+// ExportSpec returns a self-describing JSON document
+// (see ModelSpec) of the trained model, which a service
+// written in any language can use to make predictions.
+// Import it back into a goml model with ImportSpec.
+func (p *PoissonRegression) ExportSpec() ([]byte, error) {
+	return exportSpec("poisson", "log", p.Parameters, p.FitIntercept, p.FeatureNames)
+}
+
+// ImportSpec sets the model's parameters (along with
+// whether it fits an intercept and its FeatureNames)
+// from a document written by ExportSpec, returning an
+// error if it isn't for a poisson regression model.
+func (p *PoissonRegression) ImportSpec(data []byte) error {
+	spec, theta, err := importSpec(data, "poisson", "log")
+	if err != nil {
+		return err
+	}
+
+	p.Parameters = theta
+	p.FitIntercept = spec.FitIntercept
+	p.FeatureNames = spec.FeatureNames
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package linear
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/cdipaolo/goml/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a consumer should be able to evaluate the model
+// from the spec alone, and importing it should give
+// back the same model
+func TestLogisticSpecShouldPass1(t *testing.T) {
+	model := NewLogistic(base.BatchGA, .000001, 0, 800, fourDX, fourDY)
+	assert.Nil(t, model.Learn(), "Learning error should be nil")
+
+	data, err := model.ExportSpec()
+	assert.Nil(t, err, "Export error should be nil")
+
+	// evaluate it like a service in another language
+	// would, only knowing the JSON
+	var spec map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &spec), "Spec should be valid JSON")
+	assert.Equal(t, "logistic", spec["model"], "Spec should have the model type")
+	assert.Equal(t, "logit", spec["link"], "Spec should have the link function")
+	assert.Equal(t, true, spec["fit_intercept"], "Spec should have the intercept")
+	assert.Equal(t, 3.0, spec["features"], "Spec should have the number of features")
+
+	x := []float64{1, -2, 3}
+	eta := spec["intercept"].(float64)
+	for i, c := range spec["coefficients"].([]interface{}) {
+		eta += c.(float64) * x[i]
+	}
+
+	guess, err := model.Predict(x)
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.InDelta(t, guess[0], 1/(1+math.Exp(-eta)), 1e-12, "Evaluating the spec should match Predict")
+
+	imported := NewLogistic(base.BatchGA, .000001, 0, 800, nil, nil)
+	assert.Nil(t, imported.ImportSpec(data), "Import error should be nil")
+	assert.Equal(t, model.Parameters, imported.Parameters, "Imported parameters should match")
+
+	importedGuess, err := imported.Predict(x)
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, guess, importedGuess, "Imported model should predict the same")
+}
+
+func TestLeastSquaresSpecShouldPass1(t *testing.T) {
+	model := NewLeastSquares(base.BatchGA, 0, 0, 0, threeDLineX, threeDLineY)
+	model.FitIntercept = false
+	assert.Nil(t, model.LearnNormalEquation(), "Learning error should be nil")
+
+	data, err := model.ExportSpec()
+	assert.Nil(t, err, "Export error should be nil")
+
+	var spec ModelSpec
+	assert.Nil(t, json.Unmarshal(data, &spec), "Spec should be valid JSON")
+	assert.False(t, spec.FitIntercept, "Spec shouldn't have an intercept")
+	assert.Equal(t, 0.0, spec.Intercept, "Intercept should be 0")
+	assert.Equal(t, model.Parameters, spec.Coefficients, "Coefficients should be the parameters")
+
+	imported := NewLeastSquares(base.BatchGA, 0, 0, 0, nil, nil)
+	assert.Nil(t, imported.ImportSpec(data), "Import error should be nil")
+	assert.False(t, imported.FitIntercept, "Imported model shouldn't fit an intercept")
+
+	guess, err := model.Predict([]float64{3, -2})
+	assert.Nil(t, err, "Prediction error should be nil")
+
+	importedGuess, err := imported.Predict([]float64{3, -2})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, guess, importedGuess, "Imported model should predict the same")
+}
+
+func TestSpecShouldFail1(t *testing.T) {
+	// untrained models have nothing to export
+	_, err := NewLogistic(base.BatchGA, .000001, 0, 800, nil, nil).ExportSpec()
+	assert.NotNil(t, err, "Export error should not be nil")
+
+	model := NewLogistic(base.BatchGA, .000001, 0, 800, nil, nil, 2)
+	model.Parameters = []float64{1, 2, 3}
+	data, err := model.ExportSpec()
+	assert.Nil(t, err, "Export error should be nil")
+
+	// a logistic spec isn't a least squares or
+	// poisson spec
+	assert.NotNil(t, NewLeastSquares(base.BatchGA, 0, 0, 0, nil, nil).ImportSpec(data), "Import error should not be nil")
+	assert.NotNil(t, NewPoissonRegression(base.BatchGA, 0, 0, 0, nil, nil).ImportSpec(data), "Import error should not be nil")
+
+	assert.NotNil(t, model.ImportSpec([]byte("[1, 2, 3]")), "Import error should not be nil with a bare parameter vector")
+	assert.NotNil(t, model.ImportSpec([]byte(`{"model": "logistic", "link": "logit", "features": 3, "coefficients": [1, 2]}`)), "Import error should not be nil with the wrong number of coefficients")
+	assert.NotNil(t, model.ImportSpec([]byte(`{"model": "logistic", "link": "logit", "features": 2, "feature_names": ["a"], "coefficients": [1, 2]}`)), "Import error should not be nil with the wrong number of feature names")
+
+	model.FeatureNames = []string{"a"}
+	_, err = model.ExportSpec()
+	assert.NotNil(t, err, "Export error should not be nil with the wrong number of feature names")
+}
+
+func TestSpecFeatureNamesShouldPass1(t *testing.T) {
+	model := NewPoissonRegression(base.BatchGA, .000001, 0, 800, nil, nil, 2)
+	model.Parameters = []float64{0.5, 1, -1}
+	model.FeatureNames = []string{"visits", "age"}
+
+	data, err := model.ExportSpec()
+	assert.Nil(t, err, "Export error should be nil")
+
+	restored := NewPoissonRegression(base.BatchGA, 0, 0, 0, nil, nil)
+	assert.Nil(t, restored.ImportSpec(data), "Import error should be nil")
+	assert.Equal(t, model.FeatureNames, restored.FeatureNames, "Feature names should be imported")
+	assert.Equal(t, 2, restored.Features(), "Imported model should take 2 features")
+}
@@ -1,6 +1,7 @@
 package perceptron
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -46,13 +47,69 @@ import (
 // You must pass in a valid kernel function with
 // the NewKernelPerceptron function. You can find
 // premade, valid kernels in the `base` package
-// if you want to use those.
+// if you want to use those, or you can pass in
+// any function of your own as a base.Kernel.
 type KernelPerceptron struct {
 	// SV stores the KernelPerceptron's support
 	// vectors
 	SV []base.Datapoint `json:"support_vectors,omitempty"`
 
-	Kernel func([]float64, []float64) float64
+	// Kernel is the kernel function used to
+	// compare inputs to the support vectors.
+	// Functions can't be saved to a file, so
+	// it needs to be set again (to the same
+	// kernel!) before restoring a model.
+	Kernel base.Kernel
+
+	// KernelName is an optional name for the
+	// kernel, which is saved with the model
+	// when persisting it. If it's set when
+	// restoring, it has to match the saved
+	// name, which guards against restoring
+	// support vectors with the wrong kernel.
+	KernelName string
+
+	// Budget caps the number of support vectors
+	// the model keeps. When learning adds a support
+	// vector past the budget, the oldest one is
+	// removed, which keeps memory use and the time
+	// it takes to predict bounded when learning off
+	// of a long running stream. A Budget of 0 (the
+	// default) means there's no cap.
+	Budget int
+
+	// Cache, if not nil, caches the kernel
+	// between the points the model sees and
+	// its support vectors, so making several
+	// passes over a fixed training set (or
+	// training several models off of it) only
+	// computes the kernel once for each pair of
+	// points. Points are cached by identity, so
+	// send the model the same slices each pass,
+	// and only share a cache between models
+	// using the same Kernel (see base.KernelCache.)
+	// Only learning uses the cache: Predict and
+	// Score compute the kernel directly so they
+	// stay safe to call from multiple goroutines.
+	// Learning with normalized points skips the
+	// cache (each normalized point is a new slice,)
+	// and support vectors dropped by Budget or
+	// Prune are released from it. It isn't
+	// persisted with the model.
+	Cache *base.KernelCache
+
+	// svIndex holds the index of each support
+	// vector within svCache (as of the cache's
+	// svGeneration,) so scoring doesn't have to
+	// look them up every time
+	svIndex      []int
+	svCache      *base.KernelCache
+	svGeneration int
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
 
 	// Output is the io.Writer used for logging
 	// and printing. Defaults to os.Stdout.
@@ -76,32 +133,145 @@ type KernelPerceptron struct {
 //
 // Weight is the importance given to newer support
 // vectors in prediction. Should be 0 < w
-func NewKernelPerceptron(kernel func([]float64, []float64) float64) *KernelPerceptron {
+func NewKernelPerceptron(kernel base.Kernel) *KernelPerceptron {
 	return &KernelPerceptron{
 		Kernel: kernel,
 		Output: os.Stdout,
 	}
 }
 
+// NewBudgetedKernelPerceptron returns a KernelPerceptron
+// using the given kernel which keeps at most maxSV support
+// vectors, removing the oldest support vector whenever
+// learning adds one past the budget. See the Budget field
+// of KernelPerceptron.
+func NewBudgetedKernelPerceptron(kernel base.Kernel, maxSV int) *KernelPerceptron {
+	p := NewKernelPerceptron(kernel)
+	p.Budget = maxSV
+
+	return p
+}
+
 // Predict takes in a variable x (an array of floats,) and
 // finds the value of the hypothesis function given the
 // current parameter vector θ
 func (p *KernelPerceptron) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	sum, err := p.Score(x, normalize...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := -1.0
+	if sum > 0 {
+		result = 1
+	}
+
+	return []float64{result}, nil
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (p *KernelPerceptron) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	// without any support vectors the model doesn't
+	// know the dimension yet, so every row just has
+	// to match the first
+	var features int
+	if len(p.SV) != 0 {
+		features = len(p.SV[0].X)
+	} else if len(x) != 0 {
+		features = len(x[0])
+	}
+
+	return base.PredictBatch(x, features, func(row []float64) ([]float64, error) {
+		return p.Predict(row, normalize...)
+	})
+}
+
+// Score takes in a variable x (an array of floats,) and
+// returns the kernel-weighted sum over the support vectors
+//      Σ y[i] * K(x[i], x)
+// before taking its sign. Its sign is the class Predict
+// gives, and its magnitude is how confident the model is,
+// so you can threshold or rank predictions.
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (p *KernelPerceptron) Score(x []float64, normalize ...bool) (float64, error) {
 	if len(normalize) != 0 && normalize[0] {
-		base.NormalizePoint(x)
+		x = base.NormalizedPoint(x)
+	}
+
+	return p.score(x, false)
+}
+
+// score returns the kernel-weighted sum over the
+// support vectors like Score, looking the kernel
+// values up in the model's Cache if cached is true.
+// Using the cache changes it, so only learning
+// (which already changes the model) does.
+func (p *KernelPerceptron) score(x []float64, cached bool) (float64, error) {
+	if len(p.SV) != 0 && len(x) != len(p.SV[0].X) {
+		return 0, fmt.Errorf("Error: %w: Support vectors should be the same length as input vector!\n\tLength of x given: %v\n\tLength of support vectors: %v\n", base.ErrDimensionMismatch, len(x), len(p.SV[0].X))
 	}
 
 	var sum float64
+	if !cached || p.Cache == nil || len(x) == 0 {
+		for i := range p.SV {
+			sum += p.SV[i].Y[0] * p.Kernel(p.SV[i].X, x)
+		}
+
+		return sum, nil
+	}
+
+	p.indexSV()
+	j := p.Cache.Index(x)
 	for i := range p.SV {
-		sum += p.SV[i].Y[0] * p.Kernel(p.SV[i].X, x)
+		sum += p.SV[i].Y[0] * p.Cache.Value(p.Kernel, p.svIndex[i], j)
 	}
 
-	result := -1.0
-	if sum > 0 {
-		result = 1
+	return sum, nil
+}
+
+// indexSV makes sure svIndex holds the index of
+// each support vector within the model's Cache,
+// looking them all up again if the cache or the
+// support vectors were changed
+func (p *KernelPerceptron) indexSV() {
+	if p.indexed() && len(p.svIndex) == len(p.SV) {
+		return
 	}
 
-	return []float64{result}, nil
+	p.svCache = p.Cache
+	p.svGeneration = p.Cache.Generation()
+	p.svIndex = make([]int, len(p.SV))
+	for i := range p.SV {
+		p.svIndex[i] = p.Cache.Index(p.SV[i].X)
+	}
+}
+
+// indexed returns whether svIndex was looked up
+// in the model's Cache since a point was last
+// released from it
+func (p *KernelPerceptron) indexed() bool {
+	return p.Cache != nil && p.svCache == p.Cache && p.svGeneration == p.Cache.Generation()
+}
+
+// release lets the model's Cache free a support
+// vector which was dropped
+func (p *KernelPerceptron) release(sv base.Datapoint) {
+	if p.Cache != nil {
+		p.Cache.Release(sv.X)
+	}
 }
 
 // OnlineLearn runs off of the datastream within the Perceptron
@@ -214,6 +384,16 @@ func (p *KernelPerceptron) Predict(x []float64, normalize ...bool) ([]float64, e
 //         panic("AAAARGGGH! SHIVER ME TIMBERS! THESE ROTTEN SCOUNDRELS FOUND AN ERROR!!!")
 //     }
 func (p *KernelPerceptron) OnlineLearn(errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	p.OnlineLearnContext(context.Background(), errors, dataset, onUpdate, normalize...)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// dataset channel is still open. This lets you stop
+// training, ie. when shutting down a server, without
+// having to close the dataset yourself.
+func (p *KernelPerceptron) OnlineLearnContext(ctx context.Context, errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
 	if dataset == nil {
 		errors <- fmt.Errorf("ERROR: Attempting to learn with a nil data stream!\n")
 		close(errors)
@@ -224,7 +404,7 @@ func (p *KernelPerceptron) OnlineLearn(errors chan error, dataset chan base.Data
 		errors = make(chan error)
 	}
 
-	fmt.Fprintf(p.Output, "Training:\n\tModel: Kernel Perceptron Classifier\n\tOptimization Method: Online Kernel Perceptron\n...\n\n")
+	p.logf("Training:\n\tModel: Kernel Perceptron Classifier\n\tOptimization Method: Online Kernel Perceptron\n...\n\n")
 
 	norm := len(normalize) != 0 && normalize[0]
 
@@ -232,18 +412,27 @@ func (p *KernelPerceptron) OnlineLearn(errors chan error, dataset chan base.Data
 	var more bool
 
 	for {
-		point, more = <-dataset
+		select {
+		case <-ctx.Done():
+			p.logf("Training Cancelled.\n%v\n\n", p)
+			close(errors)
+			return
+		case point, more = <-dataset:
+		}
 
 		if more {
 			// have a datapoint, predict and update!
 			//
-			// Predict also checks if the point is of the
-			// correct dimensions
+			// score also checks if the point is of the
+			// correct dimensions. Normalizing makes a new
+			// slice for every point, so it would never be
+			// found in the cache again, and the cache is
+			// skipped.
 			if norm {
-				base.NormalizePoint(point.X)
+				point.X = base.NormalizedPoint(point.X)
 			}
 
-			guess, err := p.Predict(point.X)
+			score, err := p.score(point.X, !norm)
 			if err != nil {
 				// send the error channel some info and
 				// skip this datapoint
@@ -256,10 +445,35 @@ func (p *KernelPerceptron) OnlineLearn(errors chan error, dataset chan base.Data
 				continue
 			}
 
+			guess := -1.0
+			if score > 0 {
+				guess = 1
+			}
+
 			// update the parameters if the guess
 			// is wrong
-			if guess[0] != point.Y[0] {
+			if guess != point.Y[0] {
 				p.SV = append(p.SV, point)
+				if !norm && p.indexed() && len(p.svIndex) == len(p.SV)-1 {
+					p.svIndex = append(p.svIndex, p.Cache.Index(point.X))
+				} else {
+					p.svIndex = nil
+				}
+
+				// drop the oldest support vector if
+				// the model is over budget, shifting
+				// the rest down so the backing array
+				// doesn't keep growing
+				if p.Budget > 0 && len(p.SV) > p.Budget {
+					p.release(p.SV[0])
+					copy(p.SV, p.SV[1:])
+					p.SV = p.SV[:len(p.SV)-1]
+
+					if len(p.svIndex) != 0 {
+						copy(p.svIndex, p.svIndex[1:])
+						p.svIndex = p.svIndex[:len(p.svIndex)-1]
+					}
+				}
 
 				// call the OnUpdate callback with the new vector
 				// appended to a blank slice so the vector is
@@ -268,13 +482,119 @@ func (p *KernelPerceptron) OnlineLearn(errors chan error, dataset chan base.Data
 			}
 
 		} else {
-			fmt.Fprintf(p.Output, "Training Completed.\n%v\n\n", p)
+			p.logf("Training Completed.\n%v\n\n", p)
 			close(errors)
 			return
 		}
 	}
 }
 
+// Features returns the number of features the model
+// takes as input (the length of x given to Predict,)
+// which it only knows once it has support vectors,
+// so it's 0 before the model has learned anything
+func (p *KernelPerceptron) Features() int {
+	if len(p.SV) == 0 {
+		return 0
+	}
+
+	return len(p.SV[0].X)
+}
+
+// NumSupportVectors returns the number of support
+// vectors the model holds, which is how big the
+// model is (and how long it takes to predict.)
+func (p *KernelPerceptron) NumSupportVectors() int {
+	return len(p.SV)
+}
+
+// Prune shrinks an already trained model down to at
+// most maxSV support vectors, removing the ones with
+// the least impact on its predictions, and returns
+// the number of support vectors removed.
+//
+// Support vectors are removed one at a time, and each
+// time the one removed is the most redundant: the one
+// the rest of the support vectors already classify
+// correctly by the largest margin, so removing it
+// changes the model the least. The margin of support
+// vector i without itself is
+//
+//      margin[i] = Σ_(j≠i) y[i] * y[j] * K(x[j], x[i])
+//
+// The support vectors kept stay in the same order.
+//
+// This is meant for making a trained model smaller
+// before persisting it. To keep the model small while
+// it's learning use Budget instead. Note that it takes
+// time quadratic in the number of support vectors.
+func (p *KernelPerceptron) Prune(maxSV int) int {
+	if maxSV < 0 {
+		maxSV = 0
+	}
+	if len(p.SV) <= maxSV {
+		return 0
+	}
+
+	n := len(p.SV)
+
+	// contribution[i][j] is how much support vector
+	// i adds to the margin of support vector j
+	contribution := make([][]float64, n)
+	margins := make([]float64, n)
+	for i := range p.SV {
+		contribution[i] = make([]float64, n)
+		for j := range p.SV {
+			if i == j {
+				continue
+			}
+
+			contribution[i][j] = p.SV[i].Y[0] * p.SV[j].Y[0] * p.Cache.Get(p.Kernel, p.SV[i].X, p.SV[j].X)
+			margins[j] += contribution[i][j]
+		}
+	}
+
+	removed := make([]bool, n)
+	for count := n; count > maxSV; count-- {
+		best := -1
+		for i := range p.SV {
+			if !removed[i] && (best == -1 || margins[i] > margins[best]) {
+				best = i
+			}
+		}
+
+		removed[best] = true
+		for j := range margins {
+			margins[j] -= contribution[best][j]
+		}
+	}
+
+	sv := make([]base.Datapoint, 0, maxSV)
+	for i := range p.SV {
+		if removed[i] {
+			p.release(p.SV[i])
+			continue
+		}
+
+		sv = append(sv, p.SV[i])
+	}
+
+	p.SV = sv
+	p.svIndex = nil
+
+	p.logf("Pruned %v support vectors, leaving %v.\n", n-len(sv), len(sv))
+
+	return n - len(sv)
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (p *KernelPerceptron) logf(format string, a ...interface{}) {
+	if p.Verbose {
+		fmt.Fprintf(p.Output, format, a...)
+	}
+}
+
 // String implements the fmt interface for clean printing. Here
 // we're using it to print the model as the equation h(θ)=...
 // where h is the perceptron hypothesis model.
@@ -286,10 +606,21 @@ func (p *KernelPerceptron) String() string {
 	return fmt.Sprintf("h(θ,x) = Σ y[i]*K(x[i], x`) > 0 ? 1 : 0\n\tTotal Support Vectors: %v\n", len(p.SV))
 }
 
+// persistedKernelPerceptron is the format a
+// KernelPerceptron is saved to file with. The
+// kernel itself can't be saved, so only its name is.
+type persistedKernelPerceptron struct {
+	KernelName string           `json:"kernel,omitempty"`
+	SV         []base.Datapoint `json:"support_vectors"`
+}
+
 // PersistToFile takes in an absolute filepath and saves the
-// parameter vector θ to the file, which can be restored later.
-// The function will take paths from the current directory, but
-// functions
+// support vectors (and KernelName) to the file, which can be
+// restored later. The function will take paths from the
+// current directory, but functions
+//
+// The kernel function itself can't be saved, so you'll need
+// to set it again before restoring.
 //
 // The data is stored as JSON because it's one of the most
 // efficient storage method (you only need one comma extra
@@ -299,7 +630,10 @@ func (p *KernelPerceptron) PersistToFile(path string) error {
 		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
 	}
 
-	bytes, err := json.Marshal(p.SV)
+	bytes, err := json.Marshal(persistedKernelPerceptron{
+		KernelName: p.KernelName,
+		SV:         p.SV,
+	})
 	if err != nil {
 		return err
 	}
@@ -312,9 +646,16 @@ func (p *KernelPerceptron) PersistToFile(path string) error {
 	return nil
 }
 
-// RestoreFromFile takes in a path to a parameter vector theta
-// and assigns the model it's operating on's parameter vector
-// to that.
+// RestoreFromFile takes in a path to a persisted model
+// and assigns the model it's operating on's support
+// vectors to those saved.
+//
+// Because the kernel can't be saved to file, the model's
+// Kernel must already be set (to the same kernel used when
+// it was persisted!) or an error is returned. If both the
+// model and the file have a KernelName they must match.
+// Files containing only the support vectors (from older
+// versions) can be restored too.
 //
 // The path must ba an absolute path or a path from the current
 // directory
@@ -332,10 +673,61 @@ func (p *KernelPerceptron) RestoreFromFile(path string) error {
 		return err
 	}
 
-	err = json.Unmarshal(bytes, &p.SV)
+	var model persistedKernelPerceptron
+	err = json.Unmarshal(bytes, &model)
+	if err != nil {
+		// older files only hold the support vectors
+		err = json.Unmarshal(bytes, &model.SV)
+		if err != nil {
+			return err
+		}
+	}
+
+	return p.restore(model)
+}
+
+// PersistToGob saves the support vectors (and KernelName)
+// to the given file like PersistToFile, but encoded with
+// encoding/gob (see base.PersistToGob.) The kernel
+// function itself still can't be saved.
+func (p *KernelPerceptron) PersistToGob(path string) error {
+	return base.PersistToGob(path, persistedKernelPerceptron{
+		KernelName: p.KernelName,
+		SV:         p.SV,
+	})
+}
+
+// RestoreFromGob takes in a path to a model saved with
+// PersistToGob and restores the support vectors from it,
+// like RestoreFromFile. The model's Kernel has to be set
+// first.
+func (p *KernelPerceptron) RestoreFromGob(path string) error {
+	var model persistedKernelPerceptron
+	err := base.RestoreFromGob(path, &model)
 	if err != nil {
 		return err
 	}
 
+	return p.restore(model)
+}
+
+// restore assigns the persisted support vectors
+// to the model after checking the kernel it was
+// persisted with matches the model's
+func (p *KernelPerceptron) restore(model persistedKernelPerceptron) error {
+	if p.Kernel == nil {
+		return fmt.Errorf("ERROR: the kernel function can't be restored from file! Set the model's Kernel (the %q kernel it was persisted with) before restoring", model.KernelName)
+	}
+
+	if p.KernelName != "" && model.KernelName != "" && p.KernelName != model.KernelName {
+		return fmt.Errorf("ERROR: the model's kernel (%q) doesn't match the kernel it was persisted with (%q)", p.KernelName, model.KernelName)
+	}
+
+	p.SV = model.SV
+	p.svIndex = nil
+	if model.KernelName != "" {
+		p.KernelName = model.KernelName
+	}
+
 	return nil
 }
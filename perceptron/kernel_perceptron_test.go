@@ -1,8 +1,11 @@
 package perceptron
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/cdipaolo/goml/base"
@@ -396,6 +399,59 @@ func TestGaussianKernelXORShouldPass1(t *testing.T) {
 	}
 }
 
+// same as above, but the second feature is on
+// a scale 1000 times larger than the first
+func TestGaussianKernelDiagXORShouldPass1(t *testing.T) {
+	// create the channel of data and errors
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewKernelPerceptron(base.GaussianKernelDiag([]float64{1, 1000}))
+
+	go model.OnlineLearn(errors, stream, func(supportVector [][]float64) {})
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			stream <- base.Datapoint{
+				X: []float64{0, 0},
+				Y: []float64{-1},
+			}
+			stream <- base.Datapoint{
+				X: []float64{0, 1000},
+				Y: []float64{1},
+			}
+			stream <- base.Datapoint{
+				X: []float64{1, 0},
+				Y: []float64{1},
+			}
+			stream <- base.Datapoint{
+				X: []float64{1, 1000},
+				Y: []float64{-1},
+			}
+		}
+
+		// close the dataset
+		close(stream)
+	}()
+
+	err, more := <-errors
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.False(t, more, "There should be no errors returned")
+
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			guess, err := model.Predict([]float64{float64(i), float64(1000 * j)})
+			assert.Nil(t, err, "Prediction error should be nil")
+
+			expected := 1.0
+			if i^j == 0 {
+				expected = -1.0
+			}
+			assert.Equal(t, expected, guess[0], "Guess should equal i^j (%v^%v = %v)", i, j, i^j)
+		}
+	}
+}
+
 func TestPolynomialKernelFourDXShouldPass1(t *testing.T) {
 	// create the channel of data and errors
 	stream := make(chan base.Datapoint, 100)
@@ -556,6 +612,154 @@ func TestTanhKernelFourDXShouldPass1(t *testing.T) {
 	fmt.Printf("Accuracy: %v\n\tPoints Tested: %v\n\tMisclassifications: %v\n", accuracy, count, wrong)
 }
 
+func TestLaplacianKernelFourDXShouldPass1(t *testing.T) {
+	// create the channel of data and errors
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewKernelPerceptron(base.LaplacianKernel(0.005))
+
+	go model.OnlineLearn(errors, stream, func(supportVector [][]float64) {})
+
+	var count int
+	for i := -200.0; abs(i) > 1; i *= -0.7 {
+		for j := -200.0; abs(j) > 1; j *= -0.7 {
+			for k := -200.0; abs(k) > 1; k *= -0.7 {
+				for l := -200.0; abs(l) > 1; l *= -0.7 {
+					if i/2+2*k-4*j+2*l+3 > 0 {
+						stream <- base.Datapoint{
+							X: []float64{i, j, k, l},
+							Y: []float64{1.0},
+						}
+					} else {
+						stream <- base.Datapoint{
+							X: []float64{i, j, k, l},
+							Y: []float64{-1.0},
+						}
+					}
+
+					count++
+				}
+			}
+		}
+	}
+
+	fmt.Printf("%v Training Examples Pushed\n", count)
+
+	// close the dataset
+	close(stream)
+
+	err, more := <-errors
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.False(t, more, "There should be no errors returned")
+
+	count = 0
+	wrong := 0
+
+	for i := -200.0; i < 200; i += 100 {
+		for j := -200.0; j < 200; j += 100 {
+			for k := -200.0; k < 200; k += 100 {
+				for l := -200.0; l < 200; l += 100 {
+					guess, err := model.Predict([]float64{i, j, k, l})
+					assert.Nil(t, err, "Prediction error should be nil")
+					assert.Len(t, guess, 1, "Guess should have length 1")
+
+					count++
+
+					if i/2+2*k-4*j+2*l+3 > 0 {
+						if guess[0] != 1.0 {
+							wrong++
+						}
+					} else {
+						if guess[0] != -1.0 {
+							wrong++
+						}
+					}
+				}
+			}
+		}
+	}
+
+	accuracy := 100 * (1 - float64(wrong)/float64(count))
+
+	assert.True(t, accuracy > 95, "There should be greater than 95 percent accuracy (currently %v)", accuracy)
+	fmt.Printf("Accuracy: %v\n\tPoints Tested: %v\n\tMisclassifications: %v\n", accuracy, count, wrong)
+}
+
+func TestSigmoidKernelFourDXShouldPass1(t *testing.T) {
+	// create the channel of data and errors
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewKernelPerceptron(base.SigmoidKernel(1e-3, -1))
+
+	go model.OnlineLearn(errors, stream, func(supportVector [][]float64) {})
+
+	var count int
+	for i := -200.0; abs(i) > 1; i *= -0.44 {
+		for j := -200.0; abs(j) > 1; j *= -0.44 {
+			for k := -200.0; abs(k) > 1; k *= -0.44 {
+				for l := -200.0; abs(l) > 1; l *= -0.44 {
+					if i/2+2*k-4*j+2*l > 0 {
+						stream <- base.Datapoint{
+							X: []float64{i, j, k, l},
+							Y: []float64{1.0},
+						}
+					} else {
+						stream <- base.Datapoint{
+							X: []float64{i, j, k, l},
+							Y: []float64{-1.0},
+						}
+					}
+
+					count++
+				}
+			}
+		}
+	}
+
+	fmt.Printf("%v Training Examples Pushed\n", count)
+
+	// close the dataset
+	close(stream)
+
+	err, more := <-errors
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.False(t, more, "There should be no errors returned")
+
+	count = 0
+	wrong := 0
+
+	for i := -200.0; i < 200; i += 99.9 {
+		for j := -200.0; j < 200; j += 99.9 {
+			for k := -200.0; k < 200; k += 99.9 {
+				for l := -200.0; l < 200; l += 99.9 {
+					guess, err := model.Predict([]float64{i, j, k, l})
+					assert.Nil(t, err, "Prediction error should be nil")
+					assert.Len(t, guess, 1, "Guess should have length 1")
+
+					count++
+
+					if i/2+2*k-4*j+2*l > 0 {
+						if guess[0] != 1.0 {
+							wrong++
+						}
+					} else {
+						if guess[0] != -1.0 {
+							wrong++
+						}
+					}
+				}
+			}
+		}
+	}
+
+	accuracy := 100 * (1 - float64(wrong)/float64(count))
+
+	assert.True(t, accuracy > 75, "There should be greater than 75 percent accuracy (currently %v)", accuracy)
+	fmt.Printf("Accuracy: %v\n\tPoints Tested: %v\n\tMisclassifications: %v\n", accuracy, count, wrong)
+}
+
 func TestLinearKernelTwoDXNormalizedShouldPass1(t *testing.T) {
 	// create the channel of data and errors
 	stream := make(chan base.Datapoint, 100)
@@ -624,6 +828,38 @@ func TestLinearKernelTwoDXNormalizedShouldPass1(t *testing.T) {
 	assert.True(t, float64(incorrect)/float64(count) < 0.14, "Accuracy should be greater than 86%")
 }
 
+func TestLinearKernelScoreShouldPass1(t *testing.T) {
+	model := NewKernelPerceptron(base.LinearKernel())
+	model.SV = []base.Datapoint{
+		base.Datapoint{X: []float64{1, 2}, Y: []float64{1}},
+		base.Datapoint{X: []float64{-1, 3}, Y: []float64{-1}},
+	}
+
+	// (1*2 + 2*1) - (-1*2 + 3*1) = 3
+	score, err := model.Score([]float64{2, 1})
+	assert.Nil(t, err, "Score error should be nil")
+	assert.InDelta(t, 3, score, 1e-9, "Score should be the kernel-weighted sum over support vectors")
+
+	guess, err := model.Predict([]float64{2, 1})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, 1.0, guess[0], "Prediction should be the sign of the score")
+
+	// (0 + 2) - (0 + 3) = -1
+	score, err = model.Score([]float64{0, 1})
+	assert.Nil(t, err, "Score error should be nil")
+	assert.InDelta(t, -1, score, 1e-9, "Score should be the kernel-weighted sum over support vectors")
+}
+
+func TestLinearKernelScoreShouldFail1(t *testing.T) {
+	model := NewKernelPerceptron(base.LinearKernel())
+	model.SV = []base.Datapoint{
+		base.Datapoint{X: []float64{1, 2}, Y: []float64{1}},
+	}
+
+	_, err := model.Score([]float64{1, 2, 3})
+	assert.NotNil(t, err, "Score error should not be nil")
+}
+
 func TestGaussianKernelPersistPerceptronShouldPass1(t *testing.T) {
 	// create the channel of data and errors
 	stream := make(chan base.Datapoint, 100)
@@ -780,3 +1016,380 @@ func TestGaussianKernelPersistPerceptronShouldPass1(t *testing.T) {
 	assert.True(t, accuracy > 95, "There should be greater than 95 percent accuracy (currently %v)", accuracy)
 	fmt.Printf("Accuracy: %v\n\tPoints Tested: %v\n\tMisclassifications: %v\n", accuracy, count, wrong)
 }
+
+// a custom kernel should be usable directly,
+// and restoring should require it to be
+// supplied again
+func TestCustomKernelPersistPerceptronShouldPass1(t *testing.T) {
+	// similar when the vectors have the same sign
+	// in their first dimension
+	var sameSign base.Kernel = func(X []float64, x []float64) float64 {
+		return X[0] * x[0] / (abs(X[0]*x[0]) + 1)
+	}
+
+	model := NewKernelPerceptron(sameSign)
+	model.KernelName = "same sign"
+	model.SV = []base.Datapoint{
+		base.Datapoint{X: []float64{1}, Y: []float64{1}},
+	}
+
+	guess, err := model.Predict([]float64{5})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, 1.0, guess[0], "Custom kernel should be used to predict")
+
+	guess, err = model.Predict([]float64{-5})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, -1.0, guess[0], "Custom kernel should be used to predict")
+
+	err = model.PersistToFile("/tmp/.goml/CustomKernelPerceptron.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	restored := NewKernelPerceptron(sameSign)
+	err = restored.RestoreFromFile("/tmp/.goml/CustomKernelPerceptron.json")
+	assert.Nil(t, err, "Restoration error should be nil")
+	assert.Equal(t, model.SV, restored.SV, "Support vectors should be restored")
+	assert.Equal(t, "same sign", restored.KernelName, "Kernel name should be restored")
+
+	guess, err = restored.Predict([]float64{5})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, 1.0, guess[0], "Restored model should predict the same")
+}
+
+func TestCustomKernelPersistPerceptronShouldFail1(t *testing.T) {
+	model := NewKernelPerceptron(base.GaussianKernel(1))
+	model.KernelName = "gaussian"
+	model.SV = []base.Datapoint{
+		base.Datapoint{X: []float64{1}, Y: []float64{1}},
+	}
+
+	err := model.PersistToFile("/tmp/.goml/NamedKernelPerceptron.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	// the kernel wasn't supplied again
+	restored := NewKernelPerceptron(nil)
+	err = restored.RestoreFromFile("/tmp/.goml/NamedKernelPerceptron.json")
+	assert.NotNil(t, err, "Restoration error should not be nil")
+	assert.Contains(t, err.Error(), "gaussian", "Error should name the kernel to supply")
+	assert.Len(t, restored.SV, 0, "Support vectors should not be restored")
+
+	// the wrong kernel was supplied
+	restored = NewKernelPerceptron(base.LinearKernel())
+	restored.KernelName = "linear"
+	err = restored.RestoreFromFile("/tmp/.goml/NamedKernelPerceptron.json")
+	assert.NotNil(t, err, "Restoration error should not be nil")
+	assert.Len(t, restored.SV, 0, "Support vectors should not be restored")
+}
+
+// a long stream shouldn't grow the support
+// vectors past the budget
+func TestBudgetedKernelPerceptronShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewBudgetedKernelPerceptron(base.LinearKernel(), 100)
+
+	go model.OnlineLearn(errors, stream, func(supportVector [][]float64) {})
+
+	r := rand.New(rand.NewSource(1))
+	go func() {
+		for i := 0; i < 50000; i++ {
+			x := []float64{20*r.Float64() - 10, 20*r.Float64() - 10, 1}
+			y := -1.0
+			if x[0]+2*x[1]-3 > 0 {
+				y = 1
+			}
+
+			stream <- base.Datapoint{X: x, Y: []float64{y}}
+		}
+
+		close(stream)
+	}()
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	assert.True(t, len(model.SV) <= 100, "Support vectors (%v) should stay within the budget", len(model.SV))
+
+	var wrong, count int
+	for i := 0; i < 1000; i++ {
+		x := []float64{20*r.Float64() - 10, 20*r.Float64() - 10, 1}
+		guess, err := model.Predict(x)
+		assert.Nil(t, err, "Prediction error should be nil")
+
+		if (x[0]+2*x[1]-3 > 0) != (guess[0] == 1) {
+			wrong++
+		}
+		count++
+	}
+
+	accuracy := 100 * (1 - float64(wrong)/float64(count))
+	assert.True(t, accuracy > 85, "There should be greater than 85 percent accuracy (currently %v)", accuracy)
+}
+
+// ringData returns a fixed training set of points
+// inside (-1) and outside (1) a circle, which
+// needs a non-linear kernel to separate
+func ringData(n int) []base.Datapoint {
+	r := rand.New(rand.NewSource(1))
+
+	data := make([]base.Datapoint, n)
+	for i := range data {
+		x := []float64{10*r.Float64() - 5, 10*r.Float64() - 5}
+		y := -1.0
+		if x[0]*x[0]+x[1]*x[1] > 9 {
+			y = 1
+		}
+
+		data[i] = base.Datapoint{X: x, Y: []float64{y}}
+	}
+
+	return data
+}
+
+// learnPasses trains the model off of the same
+// datapoints (the same slices) passes times
+func learnPasses(model *KernelPerceptron, data []base.Datapoint, passes int, normalize ...bool) error {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	go model.OnlineLearn(errors, stream, func(supportVector [][]float64) {}, normalize...)
+
+	go func() {
+		for pass := 0; pass < passes; pass++ {
+			for i := range data {
+				stream <- data[i]
+			}
+		}
+
+		close(stream)
+	}()
+
+	var err error
+	for e := range errors {
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+// caching the kernel shouldn't change what the
+// model learns
+func TestKernelCachePerceptronShouldPass1(t *testing.T) {
+	data := ringData(500)
+
+	model := NewKernelPerceptron(base.GaussianKernel(1))
+	assert.Nil(t, learnPasses(model, data, 5), "Learning error should be nil")
+
+	cached := NewKernelPerceptron(base.GaussianKernel(1))
+	cached.Cache = base.NewKernelCache()
+	assert.Nil(t, learnPasses(cached, data, 5), "Learning error should be nil")
+
+	assert.True(t, cached.Cache.Len() > 0, "The cache should have kernel values in it")
+	assert.Equal(t, model.SV, cached.SV, "Support vectors should match without the cache")
+
+	for i := range data {
+		s1, err := model.Score(data[i].X)
+		assert.Nil(t, err, "Score error should be nil")
+
+		s2, err := cached.Score(data[i].X)
+		assert.Nil(t, err, "Score error should be nil")
+
+		assert.Equal(t, s1, s2, "Scores should match without the cache")
+	}
+}
+
+// the cache should keep up with support vectors
+// being dropped to stay within the budget
+func TestKernelCachePerceptronShouldPass2(t *testing.T) {
+	data := ringData(500)
+
+	model := NewBudgetedKernelPerceptron(base.GaussianKernel(1), 50)
+	assert.Nil(t, learnPasses(model, data, 5), "Learning error should be nil")
+
+	cached := NewBudgetedKernelPerceptron(base.GaussianKernel(1), 50)
+	cached.Cache = base.NewKernelCache()
+	assert.Nil(t, learnPasses(cached, data, 5), "Learning error should be nil")
+
+	assert.Len(t, cached.SV, 50, "Support vectors should be at the budget")
+	assert.Equal(t, model.SV, cached.SV, "Support vectors should match without the cache")
+
+	// the support vectors dropped were released
+	// from the cache
+	assert.True(t, cached.Cache.Generation() > 0, "Dropped support vectors should be released from the cache")
+	assert.True(t, cached.Cache.Len() <= len(data), "The cache should only hold the training set")
+}
+
+// normalizing makes a new slice for every point,
+// so the cache should be skipped rather than fill
+// up with points it never sees again
+func TestKernelCachePerceptronShouldPass3(t *testing.T) {
+	data := ringData(500)
+
+	model := NewKernelPerceptron(base.GaussianKernel(1))
+	assert.Nil(t, learnPasses(model, data, 3, true), "Learning error should be nil")
+
+	cached := NewKernelPerceptron(base.GaussianKernel(1))
+	cached.Cache = base.NewKernelCache()
+	assert.Nil(t, learnPasses(cached, data, 3, true), "Learning error should be nil")
+
+	assert.Equal(t, 0, cached.Cache.Len(), "Normalized points shouldn't be cached")
+	assert.Equal(t, model.SV, cached.SV, "Support vectors should match without the cache")
+}
+
+// predicting from many goroutines at once should be
+// safe even with a cache (run with -race to check
+// for data races)
+func TestKernelPerceptronConcurrentPredictShouldPass1(t *testing.T) {
+	data := ringData(500)
+
+	model := NewKernelPerceptron(base.GaussianKernel(1))
+	model.Cache = base.NewKernelCache()
+	assert.Nil(t, learnPasses(model, data, 5), "Learning error should be nil")
+
+	expected := make([]float64, len(data))
+	for i := range data {
+		guess, err := model.Predict(data[i].X)
+		assert.Nil(t, err, "Prediction error should be nil")
+		expected[i] = guess[0]
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range data {
+				guess, err := model.Predict(data[i].X)
+				assert.Nil(t, err, "Prediction error should be nil")
+
+				score, err := model.Score(data[i].X)
+				assert.Nil(t, err, "Score error should be nil")
+
+				assert.Equal(t, expected[i], guess[0], "Concurrent predictions should match serial predictions")
+				assert.Equal(t, expected[i] > 0, score > 0, "Score should agree with Predict")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// batch predictions should match predicting
+// each point on its own
+func TestKernelPerceptronPredictBatchShouldPass1(t *testing.T) {
+	data := ringData(500)
+
+	model := NewKernelPerceptron(base.GaussianKernel(1))
+	assert.Nil(t, learnPasses(model, data, 5), "Learning error should be nil")
+
+	x := make([][]float64, len(data))
+	for i := range data {
+		x[i] = data[i].X
+	}
+
+	guesses, err := model.PredictBatch(x)
+	assert.Nil(t, err, "Batch prediction error should be nil")
+	assert.Len(t, guesses, len(x), "There should be a prediction for every row")
+
+	for i := range x {
+		guess, err := model.Predict(x[i])
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, guess, guesses[i], "Batch predictions should match Predict")
+	}
+
+	_, err = model.PredictBatch([][]float64{x[0], []float64{1, 2, 3}})
+	assert.True(t, errors.Is(err, base.ErrDimensionMismatch), "Batch prediction error should wrap base.ErrDimensionMismatch - Given %v", err)
+}
+
+func TestKernelPerceptronPruneShouldPass1(t *testing.T) {
+	data := ringData(500)
+
+	model := NewKernelPerceptron(base.GaussianKernel(1))
+	assert.Nil(t, learnPasses(model, data, 5), "Learning error should be nil")
+
+	accuracy := func() float64 {
+		var wrong int
+		for i := range data {
+			guess, err := model.Predict(data[i].X)
+			assert.Nil(t, err, "Prediction error should be nil")
+
+			if guess[0] != data[i].Y[0] {
+				wrong++
+			}
+		}
+
+		return 100 * (1 - float64(wrong)/float64(len(data)))
+	}
+
+	before := model.NumSupportVectors()
+	assert.Equal(t, len(model.SV), before, "NumSupportVectors should count the support vectors")
+	fmt.Printf("Accuracy before pruning: %v percent (%v support vectors)\n", accuracy(), before)
+
+	assert.Equal(t, 0, model.Prune(before), "Nothing should be pruned at the current size")
+
+	target := before / 2
+	assert.Equal(t, before-target, model.Prune(target), "Prune should return the number removed")
+	assert.Equal(t, target, model.NumSupportVectors(), "Model should be pruned to the target")
+
+	pruned := accuracy()
+	fmt.Printf("Accuracy after pruning: %v percent (%v support vectors)\n", pruned, target)
+	assert.True(t, pruned > 85, "There should be greater than 85 percent accuracy after pruning (currently %v)", pruned)
+
+	assert.Equal(t, target, model.Prune(-1), "Negative targets should prune everything")
+	assert.Equal(t, 0, model.NumSupportVectors(), "Model should have no support vectors")
+}
+
+func benchmarkKernelPerceptronPasses(b *testing.B, cache bool) {
+	data := ringData(500)
+
+	var c *base.KernelCache
+	if cache {
+		c = base.NewKernelCache()
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		model := NewKernelPerceptron(base.GaussianKernel(1))
+		model.Cache = c
+
+		err := learnPasses(model, data, 10)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGaussianKernelPerceptron10Passes(b *testing.B) {
+	benchmarkKernelPerceptronPasses(b, false)
+}
+
+func BenchmarkGaussianKernelPerceptron10PassesCached(b *testing.B) {
+	benchmarkKernelPerceptronPasses(b, true)
+}
+
+func TestGaussianKernelPersistPerceptronGobShouldPass1(t *testing.T) {
+	model := NewKernelPerceptron(base.GaussianKernel(1))
+	model.KernelName = "gaussian"
+	model.SV = []base.Datapoint{
+		base.Datapoint{X: []float64{1, 2}, Y: []float64{1}},
+		base.Datapoint{X: []float64{-1, -2}, Y: []float64{-1}},
+	}
+
+	err := model.PersistToGob("/tmp/.goml/KernelPerceptron.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	restored := NewKernelPerceptron(base.GaussianKernel(1))
+	err = restored.RestoreFromGob("/tmp/.goml/KernelPerceptron.gob")
+	assert.Nil(t, err, "Restoration error should be nil")
+	assert.Equal(t, model.SV, restored.SV, "Support vectors should be restored")
+	assert.Equal(t, "gaussian", restored.KernelName, "Kernel name should be restored")
+
+	// the kernel wasn't supplied again
+	restored = NewKernelPerceptron(nil)
+	err = restored.RestoreFromGob("/tmp/.goml/KernelPerceptron.gob")
+	assert.NotNil(t, err, "Restoration error should not be nil")
+	assert.Len(t, restored.SV, 0, "Support vectors should not be restored")
+}
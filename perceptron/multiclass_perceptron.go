@@ -0,0 +1,408 @@
+package perceptron
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+// MultiClassPerceptron extends the binary Perceptron
+// to classify inputs into k classes using one-vs-all
+// classification. The model holds one Perceptron for
+// each class, and each one learns to tell its class
+// apart from all of the others.
+//
+// When learning, each datapoint is treated as a
+// positive (1) example by the perceptron of its class
+// and a negative (-1) example by every other
+// perceptron, which each update as usual if they
+// guess wrong. The hypothesis picks the class whose
+// perceptron is the most confident, ie. gives the
+// highest raw score before the step function:
+//     h(θ,x) = argmax_j θ[j]x
+//
+// https://en.wikipedia.org/wiki/Multiclass_classification#One-vs.-rest
+//
+// Data results in this model are expected to be the
+// class of the point, between 0 and k-1 (ie. the
+// base.Datapoint's you pass should, called point,
+// have point.Y be [j] for some 0 <= j < k)
+type MultiClassPerceptron struct {
+	// alpha is the learning rate of each of
+	// the perceptrons
+	alpha float64
+
+	// Models holds the binary perceptron for
+	// each class, where Models[j] tells class
+	// j apart from the rest
+	Models []*Perceptron
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
+	// Output is the io.Writer used for logging
+	// and printing. Defaults to os.Stdout.
+	Output io.Writer
+}
+
+// NewMultiClassPerceptron takes in a learning rate alpha,
+// the number of features (not including the constant
+// term) being evaluated by the model, and the number of
+// classes k, and returns an instantiated model with one
+// Perceptron for each class.
+func NewMultiClassPerceptron(alpha float64, features, k int) *MultiClassPerceptron {
+	models := make([]*Perceptron, k)
+	for i := range models {
+		models[i] = NewPerceptron(alpha, features)
+	}
+
+	return &MultiClassPerceptron{
+		alpha: alpha,
+
+		Models: models,
+		Output: os.Stdout,
+	}
+}
+
+// UpdateLearningRate set's the learning rate of each of
+// the model's perceptrons to the given float64.
+func (p *MultiClassPerceptron) UpdateLearningRate(a float64) {
+	p.alpha = a
+	for i := range p.Models {
+		p.Models[i].UpdateLearningRate(a)
+	}
+}
+
+// Features returns the number of features the model
+// takes as input (the length of x given to Predict,)
+// not counting the constant term
+func (p *MultiClassPerceptron) Features() int {
+	if len(p.Models) == 0 {
+		return 0
+	}
+
+	return p.Models[0].Features()
+}
+
+// Predict takes in a variable x (an array of floats,) and
+// finds the class whose perceptron gives x the highest
+// raw score θx
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (p *MultiClassPerceptron) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	if len(p.Models) == 0 {
+		return nil, fmt.Errorf("ERROR: Attempting to predict with no classes!\n")
+	}
+	if len(x)+1 != len(p.Models[0].Parameters) {
+		return nil, fmt.Errorf("Error: %w: Parameter vector should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n", base.ErrDimensionMismatch, len(x), len(p.Models[0].Parameters))
+	}
+
+	if len(normalize) != 0 && normalize[0] {
+		x = base.NormalizedPoint(x)
+	}
+
+	var guess int
+	max := p.Models[0].score(x)
+	for j := 1; j < len(p.Models); j++ {
+		score := p.Models[j].score(x)
+		if score > max {
+			max = score
+			guess = j
+		}
+	}
+
+	return []float64{float64(guess)}, nil
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (p *MultiClassPerceptron) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	if len(p.Models) == 0 {
+		return nil, fmt.Errorf("ERROR: Attempting to predict with no classes!\n")
+	}
+
+	return base.PredictBatch(x, len(p.Models[0].Parameters)-1, func(row []float64) ([]float64, error) {
+		return p.Predict(row, normalize...)
+	})
+}
+
+// OnlineLearn runs off of the datastream within the
+// MultiClassPerceptron structure. Each datapoint is
+// passed to every class' perceptron, as a positive
+// example for its own class and a negative example
+// for the rest, and any perceptron which guesses
+// wrong updates its parameter vector. Learning will
+// stop when the data channel is closed and all
+// remaining datapoints within the channel have been
+// read.
+//
+// The errors channel will be closed when learning is
+// completed so you know when it's done if you're relying
+// on that for whatever reason
+//
+// onUpdate func ([][]float64):
+//
+// onUpdate is a function that is called whenever
+// any of the perceptrons update their parameter
+// vectors. It's passed a copy of every class'
+// parameter vector, where theta[j] is the vector
+// of class j.
+//
+// This will be spawned into a new goroutine, so
+// don't worry about the function taking a long
+// time, or blocking.
+//
+// NOTE that there is an optional last parameter which,
+// when true, will normalize all data given on the
+// stream.
+func (p *MultiClassPerceptron) OnlineLearn(errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	p.OnlineLearnContext(context.Background(), errors, dataset, onUpdate, normalize...)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// dataset channel is still open. This lets you stop
+// training, ie. when shutting down a server, without
+// having to close the dataset yourself.
+func (p *MultiClassPerceptron) OnlineLearnContext(ctx context.Context, errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	if errors == nil {
+		errors = make(chan error)
+	}
+	if dataset == nil {
+		errors <- fmt.Errorf("ERROR: Attempting to learn with a nil data stream!\n")
+		close(errors)
+		return
+	}
+	if len(p.Models) == 0 {
+		errors <- fmt.Errorf("ERROR: Attempting to learn with no classes!\n")
+		close(errors)
+		return
+	}
+
+	p.logf("Training:\n\tModel: Multiclass Perceptron Classifier\n\tOptimization Method: Online One-vs-All Perceptron\n\tFeatures: %v\n\tClasses: %v\n\tLearning Rate α: %v\n...\n\n", len(p.Models[0].Parameters), len(p.Models), p.alpha)
+
+	norm := len(normalize) != 0 && normalize[0]
+
+	var point base.Datapoint
+	var more bool
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logf("Training Cancelled.\n%v\n\n", p)
+			close(errors)
+			return
+		case point, more = <-dataset:
+		}
+
+		if more {
+			if len(point.Y) != 1 {
+				errors <- fmt.Errorf("The multiclass perceptron model requires that the data results (y) have length 1 - given %v", len(point.Y))
+				continue
+			}
+
+			class := int(point.Y[0])
+			if float64(class) != point.Y[0] || class < 0 || class >= len(p.Models) {
+				errors <- fmt.Errorf("The multiclass perceptron model requires that the data results (y) be a class in [0,%v) - given %v", len(p.Models), point.Y[0])
+				continue
+			}
+
+			if len(point.X)+1 != len(p.Models[0].Parameters) {
+				errors <- fmt.Errorf("%w: The multiclass perceptron model requires that the length of input data (currently %v) be one less than the length of the parameter vector (%v)", base.ErrDimensionMismatch, len(point.X), len(p.Models[0].Parameters))
+				continue
+			}
+
+			if norm {
+				point.X = base.NormalizedPoint(point.X)
+			}
+
+			// each perceptron sees the point as a
+			// positive example if it's of its class
+			// and a negative example otherwise
+			var updated bool
+			for j, model := range p.Models {
+				y := -1.0
+				if j == class {
+					y = 1
+				}
+
+				guess := -1.0
+				if model.score(point.X) > 0 {
+					guess = 1
+				}
+
+				if guess != y {
+					model.update(point.X, y, guess)
+					updated = true
+				}
+			}
+
+			// call the OnUpdate callback with a copy
+			// of the new parameter vectors
+			if updated {
+				theta := make([][]float64, len(p.Models))
+				for j := range p.Models {
+					theta[j] = append([]float64{}, p.Models[j].Parameters...)
+				}
+
+				go onUpdate(theta)
+			}
+
+		} else {
+			p.logf("Training Completed.\n%v\n\n", p)
+			close(errors)
+			return
+		}
+	}
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (p *MultiClassPerceptron) logf(format string, a ...interface{}) {
+	if p.Verbose {
+		fmt.Fprintf(p.Output, format, a...)
+	}
+}
+
+// String implements the fmt interface for clean printing. Here
+// we're using it to print the model as the equation h(θ)=...
+// where h is the one-vs-all perceptron hypothesis model
+func (p *MultiClassPerceptron) String() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("h(θ,x) = argmax_j θ[j]x\n")
+	for j := range p.Models {
+		buffer.WriteString(fmt.Sprintf("θ[%d] = %v\n", j, p.Models[j].Parameters))
+	}
+
+	return buffer.String()
+}
+
+// PersistToFile takes in an absolute filepath and saves the
+// parameter vectors θ of every class' perceptron together
+// to the file, which can be restored later. The function
+// will take paths from the current directory, but functions
+//
+// The data is stored as JSON because it's one of the most
+// efficient storage method (you only need one comma extra
+// per feature + two brackets, total!) And it's extendable.
+func (p *MultiClassPerceptron) PersistToFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := json.Marshal(p.theta())
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, bytes, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreFromFile takes in a path to the parameter vectors
+// of a persisted MultiClassPerceptron and assigns the model
+// it's operating on's perceptrons to those, keeping the
+// current learning rate. The number of classes (and features)
+// are taken from the file.
+//
+// The path must ba an absolute path or a path from the current
+// directory
+func (p *MultiClassPerceptron) RestoreFromFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to restore your model from a file with no path! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var theta [][]float64
+	err = json.Unmarshal(bytes, &theta)
+	if err != nil {
+		return err
+	}
+
+	return p.restore(theta)
+}
+
+// PersistToGob saves the parameter vectors θ of every
+// class' perceptron to the given file like PersistToFile,
+// but encoded with encoding/gob (see base.PersistToGob.)
+func (p *MultiClassPerceptron) PersistToGob(path string) error {
+	return base.PersistToGob(path, p.theta())
+}
+
+// RestoreFromGob takes in a path to parameter vectors
+// saved with PersistToGob and restores the model's
+// perceptrons from them, like RestoreFromFile.
+func (p *MultiClassPerceptron) RestoreFromGob(path string) error {
+	var theta [][]float64
+	err := base.RestoreFromGob(path, &theta)
+	if err != nil {
+		return err
+	}
+
+	return p.restore(theta)
+}
+
+// theta returns the parameter vectors of
+// every class' perceptron, in order
+func (p *MultiClassPerceptron) theta() [][]float64 {
+	theta := make([][]float64, len(p.Models))
+	for j := range p.Models {
+		theta[j] = p.Models[j].Parameters
+	}
+
+	return theta
+}
+
+// restore replaces the model's perceptrons with
+// ones using the given parameter vectors, keeping
+// the current learning rate
+func (p *MultiClassPerceptron) restore(theta [][]float64) error {
+	if len(theta) == 0 {
+		return fmt.Errorf("ERROR: the restored model has no classes!")
+	}
+
+	models := make([]*Perceptron, len(theta))
+	for j := range theta {
+		if len(theta[j]) != len(theta[0]) {
+			return fmt.Errorf("ERROR: the restored parameter vectors aren't all the same length!")
+		}
+
+		models[j] = NewPerceptron(p.alpha, len(theta[j])-1)
+		models[j].Parameters = theta[j]
+		models[j].Output = p.Output
+		models[j].Verbose = p.Verbose
+	}
+
+	p.Models = models
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package perceptron
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/cdipaolo/goml/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// three blobs, each of which can be separated
+// from the other two by a line
+var blobCenters = [][]float64{
+	[]float64{0, 10},
+	[]float64{-10, -5},
+	[]float64{10, -5},
+}
+
+func streamBlobs(stream chan base.Datapoint, seed int64, points int) {
+	r := rand.New(rand.NewSource(seed))
+	for i := 0; i < points; i++ {
+		class := i % len(blobCenters)
+		stream <- base.Datapoint{
+			X: []float64{blobCenters[class][0] + 2*r.NormFloat64(), blobCenters[class][1] + 2*r.NormFloat64()},
+			Y: []float64{float64(class)},
+		}
+	}
+
+	close(stream)
+}
+
+func TestMultiClassPerceptronShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewMultiClassPerceptron(0.1, 2, 3)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	go streamBlobs(stream, 1, 3000)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	r := rand.New(rand.NewSource(2))
+	var wrong, count int
+	for i := 0; i < 300; i++ {
+		class := i % len(blobCenters)
+		guess, err := model.Predict([]float64{blobCenters[class][0] + 2*r.NormFloat64(), blobCenters[class][1] + 2*r.NormFloat64()})
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Len(t, guess, 1, "Length of a prediction should be 1")
+
+		if int(guess[0]) != class {
+			wrong++
+		}
+		count++
+	}
+
+	accuracy := 100 * (1 - float64(wrong)/float64(count))
+	assert.True(t, accuracy > 95, "Accuracy (%v) should be greater than 95 percent", accuracy)
+
+	// the centers themselves should be exact
+	for class := range blobCenters {
+		guess, err := model.Predict(blobCenters[class])
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, float64(class), guess[0], "Blob center should be predicted as its class")
+	}
+}
+
+func TestMultiClassPerceptronShouldFail1(t *testing.T) {
+	stream := make(chan base.Datapoint, 10)
+	errors := make(chan error, 10)
+
+	model := NewMultiClassPerceptron(0.1, 2, 3)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	// class out of range, non-integer class,
+	// wrong number of results, and wrong
+	// dimension
+	stream <- base.Datapoint{X: []float64{1, 2}, Y: []float64{3}}
+	stream <- base.Datapoint{X: []float64{1, 2}, Y: []float64{0.5}}
+	stream <- base.Datapoint{X: []float64{1, 2}, Y: []float64{0, 1}}
+	stream <- base.Datapoint{X: []float64{1, 2, 3}, Y: []float64{0}}
+	close(stream)
+
+	var count int
+	for err := range errors {
+		assert.NotNil(t, err, "Learning error should not be nil")
+		count++
+	}
+	assert.Equal(t, 4, count, "Every bad datapoint should send an error")
+
+	_, err := model.Predict([]float64{1, 2, 3})
+	assert.NotNil(t, err, "Prediction error should not be nil")
+
+	model = NewMultiClassPerceptron(0.1, 2, 0)
+	_, err = model.Predict([]float64{1, 2})
+	assert.NotNil(t, err, "Prediction error should not be nil")
+}
+
+func TestPersistMultiClassPerceptronShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewMultiClassPerceptron(0.1, 2, 3)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+	go streamBlobs(stream, 1, 3000)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	assert.Nil(t, model.PersistToFile("/tmp/.goml/MultiClassPerceptron.json"), "Persistance error should be nil")
+
+	restored := NewMultiClassPerceptron(0.1, 0, 0)
+	assert.Nil(t, restored.RestoreFromFile("/tmp/.goml/MultiClassPerceptron.json"), "Restoration error should be nil")
+	assert.Len(t, restored.Models, 3, "Every class should be restored")
+
+	for j := range model.Models {
+		assert.Equal(t, model.Models[j].Parameters, restored.Models[j].Parameters, "Restored parameters should match")
+	}
+
+	for class := range blobCenters {
+		guess, err := restored.Predict(blobCenters[class])
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, float64(class), guess[0], "Blob center should be predicted as its class")
+	}
+
+	assert.NotNil(t, restored.PersistToFile(""), "Persistance error should not be nil")
+	assert.NotNil(t, restored.RestoreFromFile(""), "Restoration error should not be nil")
+}
+
+func TestPersistMultiClassPerceptronGobShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewMultiClassPerceptron(0.1, 2, 3)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+	go streamBlobs(stream, 1, 3000)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	assert.Nil(t, model.PersistToGob("/tmp/.goml/MultiClassPerceptron.gob"), "Persistance error should be nil")
+
+	restored := NewMultiClassPerceptron(0.1, 0, 0)
+	assert.Nil(t, restored.RestoreFromGob("/tmp/.goml/MultiClassPerceptron.gob"), "Restoration error should be nil")
+	assert.Len(t, restored.Models, 3, "Every class should be restored")
+
+	for j := range model.Models {
+		assert.Equal(t, model.Models[j].Parameters, restored.Models[j].Parameters, "Restored parameters should match")
+	}
+
+	for class := range blobCenters {
+		guess, err := restored.Predict(blobCenters[class])
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Equal(t, float64(class), guess[0], "Blob center should be predicted as its class")
+	}
+
+	assert.NotNil(t, restored.PersistToGob(""), "Persistance error should not be nil")
+	assert.NotNil(t, restored.RestoreFromGob(""), "Restoration error should not be nil")
+}
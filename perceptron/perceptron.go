@@ -92,10 +92,11 @@ package perceptron
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
 
 	"github.com/cdipaolo/goml/base"
@@ -144,6 +145,27 @@ type Perceptron struct {
 
 	Parameters []float64 `json:"theta"`
 
+	// FeatureNames optionally names each feature
+	// (in the same order as x,) so errors from
+	// Predict can say which feature an input is
+	// missing. It's saved by PersistToFile.
+	FeatureNames []string `json:"feature_names,omitempty"`
+
+	// updates is the number of times the parameter
+	// vector was updated by the last call to LearnBatch
+	updates int
+
+	// Evaluation, if set, keeps a rolling measure of
+	// how well the model predicts datapoints before it
+	// learns from them when learning online (see
+	// base.Evaluation.)
+	Evaluation *base.Evaluation
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
 	// Output is the io.Writer used for logging
 	// and printing. Defaults to os.Stdout.
 	Output io.Writer
@@ -188,28 +210,92 @@ func (p *Perceptron) UpdateLearningRate(a float64) {
 // current parameter vector θ
 func (p *Perceptron) Predict(x []float64, normalize ...bool) ([]float64, error) {
 	if len(x)+1 != len(p.Parameters) {
-		return nil, fmt.Errorf("Error: Parameter vector should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n", len(x), len(p.Parameters))
+		return nil, fmt.Errorf("Error: %w: Parameter vector should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n%v", base.ErrDimensionMismatch, len(x), len(p.Parameters), base.FeatureMismatch(p.FeatureNames, len(x)))
 	}
 
 	if len(normalize) != 0 && normalize[0] {
-		base.NormalizePoint(x)
-	}
-
-	// include constant term in sum
-	sum := p.Parameters[0]
-
-	for i := range x {
-		sum += x[i] * p.Parameters[i+1]
+		x = base.NormalizedPoint(x)
 	}
 
 	result := -1.0
-	if sum > 0 {
+	if p.score(x) > 0 {
 		result = 1
 	}
 
 	return []float64{result}, nil
 }
 
+// Score takes in a variable x (an array of floats,) and
+// returns θx, the raw value of the hypothesis before it's
+// run through the step function. Its sign is the class
+// Predict gives, and its magnitude is how confident the
+// model is, so you can threshold or rank predictions.
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (p *Perceptron) Score(x []float64, normalize ...bool) (float64, error) {
+	if len(x)+1 != len(p.Parameters) {
+		return 0, fmt.Errorf("Error: %w: Parameter vector should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n%v", base.ErrDimensionMismatch, len(x), len(p.Parameters), base.FeatureMismatch(p.FeatureNames, len(x)))
+	}
+
+	if len(normalize) != 0 && normalize[0] {
+		x = base.NormalizedPoint(x)
+	}
+
+	return p.score(x), nil
+}
+
+// score returns θx, the raw value of the hypothesis
+// before it's run through the step function. x is
+// assumed to have the right dimension.
+func (p *Perceptron) score(x []float64) float64 {
+	return score(p.Parameters, x)
+}
+
+// update changes the parameter vector after the
+// model guessed wrong on x, moving it towards the
+// correct result y:
+//     θ := θ + α(y - guess)x
+func (p *Perceptron) update(x []float64, y, guess float64) {
+	p.Parameters[0] += p.alpha * (y - guess)
+
+	for i := 1; i < len(p.Parameters); i++ {
+		p.Parameters[i] += p.alpha * (y - guess) * x[i-1]
+	}
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (p *Perceptron) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	return base.PredictBatch(x, len(p.Parameters)-1, func(row []float64) ([]float64, error) {
+		return p.Predict(row, normalize...)
+	})
+}
+
+// WithEvaluation turns on evaluating the model while it
+// learns online, averaging the accuracy and cost of its
+// predictions over the last windowSize datapoints (see
+// base.Evaluation,) and returns the model so it can be
+// chained after the constructor:
+//
+//     model := NewPerceptron(0.1, 2).WithEvaluation(200)
+//
+// The results are read from the model's Evaluation.
+func (p *Perceptron) WithEvaluation(windowSize int) *Perceptron {
+	p.Evaluation = base.NewEvaluation(windowSize)
+	return p
+}
+
 // OnlineLearn runs off of the datastream within the Perceptron
 // structure. Whenever the model makes a wrong prediction
 // the parameter vector theta is updated to reflect that,
@@ -309,6 +395,16 @@ func (p *Perceptron) Predict(x []float64, normalize ...bool) ([]float64, error)
 //           panic("EGATZ!! I FOUND AN ERROR! BETTER CHECK YOUR INPUT DIMENSIONS!")
 //      }
 func (p *Perceptron) OnlineLearn(errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	p.OnlineLearnContext(context.Background(), errors, dataset, onUpdate, normalize...)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// dataset channel is still open. This lets you stop
+// training, ie. when shutting down a server, without
+// having to close the dataset yourself.
+func (p *Perceptron) OnlineLearnContext(ctx context.Context, errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
 	if errors == nil {
 		errors = make(chan error)
 	}
@@ -318,7 +414,7 @@ func (p *Perceptron) OnlineLearn(errors chan error, dataset chan base.Datapoint,
 		return
 	}
 
-	fmt.Fprintf(p.Output, "Training:\n\tModel: Perceptron Classifier\n\tOptimization Method: Online Perceptron\n\tFeatures: %v\n\tLearning Rate α: %v\n...\n\n", len(p.Parameters), p.alpha)
+	p.logf("Training:\n\tModel: Perceptron Classifier\n\tOptimization Method: Online Perceptron\n\tFeatures: %v\n\tLearning Rate α: %v\n...\n\n", len(p.Parameters), p.alpha)
 
 	norm := len(normalize) != 0 && normalize[0]
 
@@ -326,7 +422,13 @@ func (p *Perceptron) OnlineLearn(errors chan error, dataset chan base.Datapoint,
 	var more bool
 
 	for {
-		point, more = <-dataset
+		select {
+		case <-ctx.Done():
+			p.logf("Training Cancelled.\n%v\n\n", p)
+			close(errors)
+			return
+		case point, more = <-dataset:
+		}
 
 		if more {
 			// have a datapoint, predict and update!
@@ -334,7 +436,7 @@ func (p *Perceptron) OnlineLearn(errors chan error, dataset chan base.Datapoint,
 			// Predict also checks if the point is of the
 			// correct dimensions
 			if norm {
-				base.NormalizePoint(point.X)
+				point.X = base.NormalizedPoint(point.X)
 			}
 
 			guess, err := p.Predict(point.X)
@@ -351,18 +453,21 @@ func (p *Perceptron) OnlineLearn(errors chan error, dataset chan base.Datapoint,
 			}
 
 			if len(point.X) != len(p.Parameters)-1 {
-				errors <- fmt.Errorf("The binary perceptron model requires that the length of input data (currently %v) be one less than the length of the parameter vector (%v)", len(point.X), len(p.Parameters))
+				errors <- fmt.Errorf("%w: The binary perceptron model requires that the length of input data (currently %v) be one less than the length of the parameter vector (%v)", base.ErrDimensionMismatch, len(point.X), len(p.Parameters))
 				continue
 			}
 
+			// score the point before learning from
+			// it, using the perceptron criterion
+			// max(0, -yθx) as the cost
+			if p.Evaluation != nil {
+				p.Evaluation.Add(guess[0] == point.Y[0], math.Max(0, -point.Y[0]*p.score(point.X)))
+			}
+
 			// update the parameters if the guess
 			// is wrong
 			if guess[0] != point.Y[0] {
-				p.Parameters[0] += p.alpha * (point.Y[0] - guess[0])
-
-				for i := 1; i < len(p.Parameters); i++ {
-					p.Parameters[i] += p.alpha * (point.Y[0] - guess[0]) * point.X[i-1]
-				}
+				p.update(point.X, point.Y[0], guess[0])
 
 				// call the OnUpdate callback with the new theta
 				// appended to a blank slice so the vector is
@@ -371,13 +476,102 @@ func (p *Perceptron) OnlineLearn(errors chan error, dataset chan base.Datapoint,
 			}
 
 		} else {
-			fmt.Fprintf(p.Output, "Training Completed.\n%v\n\n", p)
+			p.logf("Training Completed.\n%v\n\n", p)
 			close(errors)
 			return
 		}
 	}
 }
 
+// LearnBatch trains the perceptron on an in-memory
+// dataset, passing over every row of x (with results y,
+// either -1 or 1) the given number of epochs and
+// applying the same update rule as OnlineLearn whenever
+// the model guesses wrong. This saves setting up a data
+// channel and goroutine when you already have the whole
+// training set.
+//
+// The dimensions of the dataset are checked before any
+// learning is done. The number of updates made is
+// printed to Output when learning is done, and is also
+// given by Updates.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (in place!)
+func (p *Perceptron) LearnBatch(x [][]float64, y []float64, epochs int, normalize ...bool) error {
+	if len(x) == 0 {
+		err := fmt.Errorf("ERROR: Attempting to learn with no training examples!\n")
+		p.logf(err.Error())
+		return err
+	}
+	if len(x) != len(y) {
+		err := fmt.Errorf("ERROR: Length of training set (%v) doesn't match the number of results (%v)!\n", len(x), len(y))
+		p.logf(err.Error())
+		return err
+	}
+	if epochs < 1 {
+		err := fmt.Errorf("ERROR: Attempting to learn with %v epochs! Pass over the dataset at least once.\n", epochs)
+		p.logf(err.Error())
+		return err
+	}
+	for i := range x {
+		if len(x[i])+1 != len(p.Parameters) {
+			err := fmt.Errorf("ERROR: %w: Row %v of the training set has %v features, but the model expects %v!\n", base.ErrDimensionMismatch, i, len(x[i]), len(p.Parameters)-1)
+			p.logf(err.Error())
+			return err
+		}
+	}
+
+	p.logf("Training:\n\tModel: Perceptron Classifier\n\tOptimization Method: Batch Perceptron\n\tTraining Examples: %v\n\tFeatures: %v\n\tLearning Rate α: %v\n\tEpochs: %v\n...\n\n", len(x), len(p.Parameters), p.alpha, epochs)
+
+	if len(normalize) != 0 && normalize[0] {
+		base.Normalize(x)
+	}
+
+	p.updates = 0
+	for epoch := 0; epoch < epochs; epoch++ {
+		for i := range x {
+			guess := -1.0
+			if p.score(x[i]) > 0 {
+				guess = 1
+			}
+
+			if guess != y[i] {
+				p.update(x[i], y[i], guess)
+				p.updates++
+			}
+		}
+	}
+
+	p.logf("Training Completed.\n\tUpdates: %v\n%v\n\n", p.updates, p)
+	return nil
+}
+
+// Features returns the number of features the model
+// takes as input (the length of x given to Predict,)
+// not counting the constant term
+func (p *Perceptron) Features() int {
+	if len(p.Parameters) == 0 {
+		return 0
+	}
+
+	return len(p.Parameters) - 1
+}
+
+// Updates returns the number of times the parameter
+// vector was updated by the last call to LearnBatch
+func (p *Perceptron) Updates() int {
+	return p.updates
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (p *Perceptron) logf(format string, a ...interface{}) {
+	if p.Verbose {
+		fmt.Fprintf(p.Output, format, a...)
+	}
+}
+
 // String implements the fmt interface for clean printing. Here
 // we're using it to print the model as the equation h(θ)=...
 // where h is the perceptron hypothesis model.
@@ -388,7 +582,7 @@ func (p *Perceptron) OnlineLearn(errors chan error, dataset chan base.Datapoint,
 func (p *Perceptron) String() string {
 	features := len(p.Parameters) - 1
 	if len(p.Parameters) == 0 {
-		fmt.Fprintf(p.Output, "ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
+		p.logf("ERROR: Attempting to print model with the 0 vector as it's parameter vector! Train first!\n")
 	}
 	var buffer bytes.Buffer
 
@@ -408,6 +602,7 @@ func (p *Perceptron) String() string {
 
 // PersistToFile takes in an absolute filepath and saves the
 // parameter vector θ to the file, which can be restored later.
+// If the model has FeatureNames they're saved along with θ.
 // The function will take paths from the current directory, but
 // functions
 //
@@ -419,7 +614,7 @@ func (p *Perceptron) PersistToFile(path string) error {
 		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
 	}
 
-	bytes, err := json.Marshal(p.Parameters)
+	bytes, err := base.MarshalParameters(p.Parameters, p.FeatureNames)
 	if err != nil {
 		return err
 	}
@@ -434,7 +629,7 @@ func (p *Perceptron) PersistToFile(path string) error {
 
 // RestoreFromFile takes in a path to a parameter vector theta
 // and assigns the model it's operating on's parameter vector
-// to that.
+// to that, along with the FeatureNames saved with it (if any.)
 //
 // The path must ba an absolute path or a path from the current
 // directory
@@ -452,10 +647,47 @@ func (p *Perceptron) RestoreFromFile(path string) error {
 		return err
 	}
 
-	err = json.Unmarshal(bytes, &p.Parameters)
+	theta, names, err := base.UnmarshalParameters(bytes)
+	if err != nil {
+		return err
+	}
+
+	err = base.CheckFeatureNames(names, len(theta)-1)
+	if err != nil {
+		return err
+	}
+
+	p.Parameters = theta
+	p.FeatureNames = names
+
+	return nil
+}
+
+// PersistToGob saves the parameter vector θ (and the
+// FeatureNames, if any) to the given file like
+// PersistToFile, but encoded with encoding/gob (see
+// base.PersistToGob.)
+func (p *Perceptron) PersistToGob(path string) error {
+	return base.PersistParametersToGob(path, p.Parameters, p.FeatureNames)
+}
+
+// RestoreFromGob takes in a path to a parameter vector
+// saved with PersistToGob and assigns the model's
+// parameter vector and FeatureNames to it, like
+// RestoreFromFile.
+func (p *Perceptron) RestoreFromGob(path string) error {
+	theta, names, err := base.RestoreParametersFromGob(path)
 	if err != nil {
 		return err
 	}
 
+	err = base.CheckFeatureNames(names, len(theta)-1)
+	if err != nil {
+		return err
+	}
+
+	p.Parameters = theta
+	p.FeatureNames = names
+
 	return nil
 }
@@ -1,9 +1,12 @@
 package perceptron
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/cdipaolo/goml/base"
 
@@ -79,6 +82,44 @@ func TestOneDXShouldPass1(t *testing.T) {
 	fmt.Printf("Iter: %v\n", iter)
 }
 
+// learning online with normalization shouldn't
+// change the datapoints the producer sent
+func TestOnlineNormalizeShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewPerceptron(0.1, 2)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {}, true)
+
+	var sent [][]float64
+	for i := -50.0; i < 50; i++ {
+		x := []float64{i, 2*i + 1}
+		sent = append(sent, x)
+
+		y := -1.0
+		if i > 0 {
+			y = 1
+		}
+
+		stream <- base.Datapoint{
+			X: x,
+			Y: []float64{y},
+		}
+	}
+
+	close(stream)
+
+	err, more := <-errors
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.False(t, more, "There should be no errors returned")
+
+	for i, x := range sent {
+		j := float64(i) - 50
+		assert.Equal(t, []float64{j, 2*j + 1}, x, "Streamed datapoints should not be normalized in place")
+	}
+}
+
 func TestOneDXShouldFail1(t *testing.T) {
 	// create the channel of data and errors
 	stream := make(chan base.Datapoint, 1000)
@@ -312,6 +353,58 @@ func TestTwoDXNormalizedShouldPass1(t *testing.T) {
 	assert.True(t, float64(incorrect)/float64(count) < 0.14, "Accuracy should be greater than 86%")
 }
 
+func TestPerceptronScoreShouldPass1(t *testing.T) {
+	model := NewPerceptron(0.1, 2)
+	model.Parameters = []float64{-1, 2, 0.5}
+
+	score, err := model.Score([]float64{3, -4})
+	assert.Nil(t, err, "Score error should be nil")
+	assert.InDelta(t, 3, score, 1e-9, "Score should be θx")
+
+	guess, err := model.Predict([]float64{3, -4})
+	assert.Nil(t, err, "Prediction error should be nil")
+	assert.Equal(t, 1.0, guess[0], "Prediction should be the sign of the score")
+
+	score, err = model.Score([]float64{0, 0})
+	assert.Nil(t, err, "Score error should be nil")
+	assert.InDelta(t, -1, score, 1e-9, "Score should be θx")
+}
+
+func TestPerceptronScoreShouldFail1(t *testing.T) {
+	model := NewPerceptron(0.1, 2)
+
+	_, err := model.Score([]float64{3})
+	assert.NotNil(t, err, "Score error should not be nil")
+}
+
+func TestPerceptronFeatureNamesShouldPass1(t *testing.T) {
+	model := NewPerceptron(0.1, 2)
+	model.FeatureNames = []string{"x", "y"}
+
+	assert.Equal(t, 2, model.Features(), "Model should take 2 features")
+
+	_, err := model.Predict([]float64{1, 2, 3})
+	assert.NotNil(t, err, "Predicting with an extra value should return an error")
+	assert.Contains(t, err.Error(), `Unexpected value at index 2, after the last feature "y"`, "Error should point out the extra value")
+
+	err = model.PersistToFile("/tmp/.goml/PerceptronNames.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	restored := NewPerceptron(0.1, 2)
+	err = restored.RestoreFromFile("/tmp/.goml/PerceptronNames.json")
+	assert.Nil(t, err, "Restoring error should be nil")
+	assert.Equal(t, model.FeatureNames, restored.FeatureNames, "Feature names should be restored")
+
+	multi := NewMultiClassPerceptron(0.1, 4, 3)
+	assert.Equal(t, 4, multi.Features(), "Multiclass model should take 4 features")
+
+	voted := NewVotedPerceptron(0.1, 4)
+	assert.Equal(t, 4, voted.Features(), "Voted model should take 4 features")
+
+	kernel := NewKernelPerceptron(base.GaussianKernel(1))
+	assert.Equal(t, 0, kernel.Features(), "Kernel model shouldn't know its features before learning")
+}
+
 func TestPersistPerceptronShouldPass1(t *testing.T) {
 	// create the channel of data and errors
 	stream := make(chan base.Datapoint, 100)
@@ -394,3 +487,154 @@ func TestPersistPerceptronShouldPass1(t *testing.T) {
 		}
 	}
 }
+
+func TestPerceptronContextShouldPass1(t *testing.T) {
+	// the stream is never closed, so learning
+	// should only stop when the context is
+	// cancelled
+	stream := make(chan base.Datapoint)
+	errors := make(chan error)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	model := NewPerceptron(0.1, 2)
+
+	go model.OnlineLearnContext(ctx, errors, stream, func(theta [][]float64) {})
+
+	for i := 0; i < 100; i++ {
+		stream <- base.Datapoint{
+			X: []float64{float64(i), float64(-i)},
+			Y: []float64{1},
+		}
+	}
+
+	cancel()
+
+	select {
+	case _, more := <-errors:
+		assert.False(t, more, "The errors channel should be closed once the context is cancelled")
+	case <-time.After(time.Second):
+		t.Errorf("Learning should stop when the context is cancelled")
+	}
+}
+
+func TestFourDXBatchShouldPass1(t *testing.T) {
+	x := [][]float64{}
+	y := []float64{}
+	for i := -200.0; abs(i) > 1; i *= -0.82 {
+		for j := -200.0; abs(j) > 1; j *= -0.82 {
+			for k := -200.0; abs(k) > 1; k *= -0.82 {
+				for l := -200.0; abs(l) > 1; l *= -0.82 {
+					x = append(x, []float64{i, j, k, l})
+					if i/2+2*k-4*j+2*l+3 > 0 {
+						y = append(y, 1.0)
+					} else {
+						y = append(y, -1.0)
+					}
+				}
+			}
+		}
+	}
+
+	model := NewPerceptron(0.1, 4)
+
+	err := model.LearnBatch(x, y, 3)
+	assert.Nil(t, err, "Learning error should be nil")
+	assert.True(t, model.Updates() > 100, "There should be more than 100 updates of theta")
+
+	for i := -200.0; i < 200; i += 100 {
+		for j := -200.0; j < 200; j += 100 {
+			for k := -200.0; k < 200; k += 100 {
+				for l := -200.0; l < 200; l += 100 {
+					guess, err := model.Predict([]float64{i, j, k, l})
+					assert.Nil(t, err, "Prediction error should be nil")
+
+					if i/2+2*k-4*j+2*l+3 > 0 {
+						assert.Equal(t, 1.0, guess[0], "Guess should be 1")
+					} else {
+						assert.Equal(t, -1.0, guess[0], "Guess should be -1")
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFourDXBatchShouldFail1(t *testing.T) {
+	model := NewPerceptron(0.1, 2)
+
+	err := model.LearnBatch(nil, nil, 1)
+	assert.NotNil(t, err, "Learning error should not be nil without data")
+
+	err = model.LearnBatch([][]float64{{1, 2}, {3, 4}}, []float64{1}, 1)
+	assert.NotNil(t, err, "Learning error should not be nil when the results don't line up")
+
+	err = model.LearnBatch([][]float64{{1, 2}, {3}}, []float64{1, -1}, 1)
+	assert.NotNil(t, err, "Learning error should not be nil with the wrong number of features")
+
+	err = model.LearnBatch([][]float64{{1, 2}}, []float64{1}, 0)
+	assert.NotNil(t, err, "Learning error should not be nil without any epochs")
+
+	assert.Equal(t, []float64{0, 0, 0}, model.Parameters, "Parameters shouldn't change when the dataset is invalid")
+}
+
+func TestPerceptronEvaluationShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewPerceptron(0.1, 2).WithEvaluation(500)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	var count int
+	go func() {
+		for iter := 0; iter < 5; iter++ {
+			for i := -10.0; i < 10; i++ {
+				for j := -10.0; j < 10; j++ {
+					y := -1.0
+					if i+2*j-3 > 0 {
+						y = 1
+					}
+
+					stream <- base.Datapoint{X: []float64{i, j}, Y: []float64{y}}
+					count++
+				}
+			}
+		}
+
+		close(stream)
+	}()
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	assert.Equal(t, count, model.Evaluation.Seen(), "Every datapoint should be evaluated")
+
+	accuracy := model.Evaluation.Accuracy()
+	assert.True(t, accuracy > 0.9, "Rolling accuracy (%v) should be high once the model has learned", accuracy)
+}
+
+func TestPerceptronVerboseShouldPass1(t *testing.T) {
+	for _, verbose := range []bool{false, true} {
+		var output bytes.Buffer
+
+		stream := make(chan base.Datapoint, 10)
+		errors := make(chan error)
+
+		model := NewPerceptron(0.1, 1)
+		model.Output = &output
+		model.Verbose = verbose
+
+		go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+		stream <- base.Datapoint{X: []float64{1}, Y: []float64{1}}
+		close(stream)
+
+		for err := range errors {
+			assert.Nil(t, err, "Learning error should be nil")
+		}
+
+		assert.Equal(t, verbose, output.Len() != 0, "Training progress should only be logged when the model is verbose")
+	}
+}
@@ -0,0 +1,426 @@
+package perceptron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+// VotedPerceptron represents Freund and Schapire's
+// voted perceptron. It learns exactly like the regular
+// Perceptron, updating the current parameter vector θ
+// only when it guesses wrong:
+//     if (θx * y < 0) {
+//         θ := θ + α*yx
+//     }
+//
+// but instead of throwing the old parameter vectors
+// away it keeps every one of them along with how many
+// examples in a row it classified correctly before it
+// was replaced (its number of votes.) Predictions are
+// a weighted vote of every stored vector:
+//     sgn(Σ c[i] * sgn(θ[i]x))
+//
+// so a parameter vector that was thrown off by a single
+// noisy example late in training doesn't decide the
+// prediction on its own. This tends to generalize
+// better than the last parameter vector on noisy data.
+//
+// The tradeoff is memory (and prediction time): the
+// model stores one parameter vector for every mistake
+// it made while learning, and predicting takes one dot
+// product per stored vector. Set MaxVectors to cap the
+// number of vectors kept when learning off of a long
+// running stream.
+//
+// https://cseweb.ucsd.edu/~yfreund/papers/LargeMarginsUsingPerceptron.pdf
+//
+// Like the Perceptron, VotedPerceptron implements the
+// OnlineModel interface and expects data results to be
+// either -1 or 1.
+type VotedPerceptron struct {
+	// alpha is the learning rate of the perceptron
+	// algorithm
+	alpha float64
+
+	// Vectors holds every parameter vector the model
+	// has learned, oldest first. The last one is the
+	// current parameter vector, which is the one
+	// updated while learning.
+	Vectors [][]float64 `json:"vectors"`
+
+	// Votes holds the number of votes of each
+	// parameter vector in Vectors (the number of
+	// examples it classified correctly in a row
+	// while it was the current vector)
+	Votes []int `json:"votes"`
+
+	// MaxVectors caps the number of parameter vectors
+	// the model keeps. When learning adds a vector past
+	// the cap, the oldest one is removed. A MaxVectors
+	// of 0 (the default) means there's no cap.
+	MaxVectors int
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool
+
+	// Output is the io.Writer used for logging
+	// and printing. Defaults to os.Stdout.
+	Output io.Writer
+}
+
+// NewVotedPerceptron takes in a learning rate alpha and
+// the number of features (not including the constant
+// term) being evaluated by the model and returns an
+// instantiated model, starting with the zero vector as
+// its only parameter vector.
+func NewVotedPerceptron(alpha float64, features int) *VotedPerceptron {
+	return &VotedPerceptron{
+		alpha: alpha,
+
+		Vectors: [][]float64{make([]float64, features+1)},
+		Votes:   []int{0},
+		Output:  os.Stdout,
+	}
+}
+
+// UpdateLearningRate set's the learning rate of the model
+// to the given float64.
+func (p *VotedPerceptron) UpdateLearningRate(a float64) {
+	p.alpha = a
+}
+
+// Predict takes in a variable x (an array of floats,) and
+// returns the class given by the weighted vote of every
+// stored parameter vector
+func (p *VotedPerceptron) Predict(x []float64, normalize ...bool) ([]float64, error) {
+	sum, err := p.Score(x, normalize...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := -1.0
+	if sum > 0 {
+		result = 1
+	}
+
+	return []float64{result}, nil
+}
+
+// Score takes in a variable x (an array of floats,) and
+// returns the weighted vote
+//      Σ c[i] * sgn(θ[i]x)
+// before taking its sign. Its sign is the class Predict
+// gives, and its magnitude is how many votes the class
+// won by, so you can threshold or rank predictions.
+//
+// if normalize is given as true, then the input will
+// first be normalized to unit length. Only use this if
+// you trained off of normalized inputs and are feeding
+// an un-normalized input
+func (p *VotedPerceptron) Score(x []float64, normalize ...bool) (float64, error) {
+	if len(p.Vectors) == 0 || len(x)+1 != len(p.Vectors[0]) {
+		return 0, fmt.Errorf("Error: %w: Parameter vectors should be 1 longer than input vector!\n\tLength of x given: %v\n\tLength of parameters: %v\n", base.ErrDimensionMismatch, len(x), len(p.Parameters()))
+	}
+
+	if len(normalize) != 0 && normalize[0] {
+		x = base.NormalizedPoint(x)
+	}
+
+	var sum float64
+	for i := range p.Vectors {
+		if score(p.Vectors[i], x) > 0 {
+			sum += float64(p.Votes[i])
+		} else {
+			sum -= float64(p.Votes[i])
+		}
+	}
+
+	return sum, nil
+}
+
+// score returns θx for the given parameter
+// vector. x is assumed to have the right
+// dimension.
+func score(theta, x []float64) float64 {
+	// include constant term in sum
+	sum := theta[0]
+
+	for i := range x {
+		sum += x[i] * theta[i+1]
+	}
+
+	return sum
+}
+
+// Parameters returns the current parameter vector θ
+// (the last one in Vectors), or nil if the model has
+// none.
+func (p *VotedPerceptron) Parameters() []float64 {
+	if len(p.Vectors) == 0 {
+		return nil
+	}
+
+	return p.Vectors[len(p.Vectors)-1]
+}
+
+// Features returns the number of features the model
+// takes as input (the length of x given to Predict,)
+// not counting the constant term
+func (p *VotedPerceptron) Features() int {
+	if len(p.Vectors) == 0 {
+		return 0
+	}
+
+	return len(p.Vectors[0]) - 1
+}
+
+// PredictBatch runs Predict on every row of x,
+// returning the predictions in the same order.
+// Large batches are predicted in parallel (see
+// base.PredictBatch.) An error is returned, along
+// with the row's index, for the first row with the
+// wrong dimension.
+//
+// if normalize is given as true, then each row will
+// first be normalized to unit length (x itself isn't
+// changed)
+func (p *VotedPerceptron) PredictBatch(x [][]float64, normalize ...bool) ([][]float64, error) {
+	return base.PredictBatch(x, len(p.Parameters())-1, func(row []float64) ([]float64, error) {
+		return p.Predict(row, normalize...)
+	})
+}
+
+// OnlineLearn runs off of the datastream like the
+// Perceptron's OnlineLearn. Whenever the current
+// parameter vector makes a wrong prediction a new,
+// updated parameter vector is stored with one vote and
+// the OnUpdate function is called with it. Otherwise
+// the current parameter vector gets another vote.
+// Learning will stop when the data channel is closed
+// and all remaining datapoints within the channel have
+// been read, and the errors channel is closed when
+// learning is completed.
+//
+// Note that the model is trained with the current
+// parameter vector's predictions, not the vote Predict
+// takes.
+//
+// NOTE that there is an optional last parameter which,
+// when true, will normalize all data given on the
+// stream.
+func (p *VotedPerceptron) OnlineLearn(errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	p.OnlineLearnContext(context.Background(), errors, dataset, onUpdate, normalize...)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// dataset channel is still open.
+func (p *VotedPerceptron) OnlineLearnContext(ctx context.Context, errors chan error, dataset chan base.Datapoint, onUpdate func([][]float64), normalize ...bool) {
+	if errors == nil {
+		errors = make(chan error)
+	}
+	if dataset == nil {
+		errors <- fmt.Errorf("ERROR: Attempting to learn with a nil data stream!\n")
+		close(errors)
+		return
+	}
+
+	p.logf("Training:\n\tModel: Voted Perceptron Classifier\n\tOptimization Method: Online Voted Perceptron\n\tFeatures: %v\n\tLearning Rate α: %v\n...\n\n", len(p.Parameters()), p.alpha)
+
+	norm := len(normalize) != 0 && normalize[0]
+
+	var point base.Datapoint
+	var more bool
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logf("Training Cancelled.\n%v\n\n", p)
+			close(errors)
+			return
+		case point, more = <-dataset:
+		}
+
+		if more {
+			theta := p.Parameters()
+			if len(point.X)+1 != len(theta) {
+				errors <- fmt.Errorf("%w: The binary perceptron model requires that the length of input data (currently %v) be one less than the length of the parameter vector (%v)", base.ErrDimensionMismatch, len(point.X), len(theta))
+				continue
+			}
+
+			if len(point.Y) != 1 {
+				errors <- fmt.Errorf("The binary perceptron model requires that the data results (y) have length 1 - given %v", len(point.Y))
+				continue
+			}
+
+			if norm {
+				point.X = base.NormalizedPoint(point.X)
+			}
+
+			guess := -1.0
+			if score(theta, point.X) > 0 {
+				guess = 1
+			}
+
+			// the current vector survived another
+			// example, so it gets another vote
+			if guess == point.Y[0] {
+				p.Votes[len(p.Votes)-1]++
+				continue
+			}
+
+			// otherwise store a new, updated vector
+			//     θ := θ + α(y - guess)x
+			next := make([]float64, len(theta))
+			next[0] = theta[0] + p.alpha*(point.Y[0]-guess)
+			for i := 1; i < len(next); i++ {
+				next[i] = theta[i] + p.alpha*(point.Y[0]-guess)*point.X[i-1]
+			}
+
+			p.Vectors = append(p.Vectors, next)
+			p.Votes = append(p.Votes, 1)
+
+			// drop the oldest vector if the model
+			// is over its cap, shifting the rest
+			// down so the backing arrays don't keep
+			// growing
+			if p.MaxVectors > 0 && len(p.Vectors) > p.MaxVectors {
+				copy(p.Vectors, p.Vectors[1:])
+				p.Vectors = p.Vectors[:len(p.Vectors)-1]
+
+				copy(p.Votes, p.Votes[1:])
+				p.Votes = p.Votes[:len(p.Votes)-1]
+			}
+
+			go onUpdate([][]float64{next})
+		} else {
+			p.logf("Training Completed.\n%v\n\n", p)
+			close(errors)
+			return
+		}
+	}
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (p *VotedPerceptron) logf(format string, a ...interface{}) {
+	if p.Verbose {
+		fmt.Fprintf(p.Output, format, a...)
+	}
+}
+
+// String implements the fmt interface for clean printing. Here
+// we're using it to print the model as the equation h(θ)=...
+// where h is the voted perceptron hypothesis model.
+func (p *VotedPerceptron) String() string {
+	return fmt.Sprintf("h(θ,x) = Σ c[i]*sgn(θ[i]x) > 0 ? 1 : 0\n\tTotal Parameter Vectors: %v\n", len(p.Vectors))
+}
+
+// persistedVotedPerceptron is the format a
+// VotedPerceptron is saved to file with
+type persistedVotedPerceptron struct {
+	Vectors [][]float64 `json:"vectors"`
+	Votes   []int       `json:"votes"`
+}
+
+// PersistToFile takes in an absolute filepath and saves the
+// stored parameter vectors and their votes to the file,
+// which can be restored later.
+func (p *VotedPerceptron) PersistToFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := json.Marshal(persistedVotedPerceptron{
+		Vectors: p.Vectors,
+		Votes:   p.Votes,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, bytes, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreFromFile takes in a path to a model saved with
+// PersistToFile and assigns the model it's operating on's
+// parameter vectors and votes to those saved.
+func (p *VotedPerceptron) RestoreFromFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to restore your model from a file with no path! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var model persistedVotedPerceptron
+	err = json.Unmarshal(bytes, &model)
+	if err != nil {
+		return err
+	}
+
+	return p.restore(model)
+}
+
+// PersistToGob saves the parameter vectors and votes to
+// the given file like PersistToFile, but encoded with
+// encoding/gob (see base.PersistToGob.)
+func (p *VotedPerceptron) PersistToGob(path string) error {
+	return base.PersistToGob(path, persistedVotedPerceptron{
+		Vectors: p.Vectors,
+		Votes:   p.Votes,
+	})
+}
+
+// RestoreFromGob takes in a path to a model saved with
+// PersistToGob and restores the parameter vectors and
+// votes from it, like RestoreFromFile.
+func (p *VotedPerceptron) RestoreFromGob(path string) error {
+	var model persistedVotedPerceptron
+	err := base.RestoreFromGob(path, &model)
+	if err != nil {
+		return err
+	}
+
+	return p.restore(model)
+}
+
+// restore assigns the persisted parameter vectors and
+// votes to the model after checking that there's one
+// vote per vector and every vector has the same length
+func (p *VotedPerceptron) restore(model persistedVotedPerceptron) error {
+	if len(model.Vectors) == 0 {
+		return fmt.Errorf("ERROR: the persisted model doesn't have any parameter vectors")
+	}
+
+	if len(model.Vectors) != len(model.Votes) {
+		return fmt.Errorf("ERROR: the persisted model has %v parameter vectors but %v votes", len(model.Vectors), len(model.Votes))
+	}
+
+	for i := range model.Vectors {
+		if len(model.Vectors[i]) != len(model.Vectors[0]) {
+			return fmt.Errorf("ERROR: parameter vector %v of the persisted model has length %v, but the first has length %v", i, len(model.Vectors[i]), len(model.Vectors[0]))
+		}
+	}
+
+	p.Vectors = model.Vectors
+	p.Votes = model.Votes
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package perceptron
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/cdipaolo/goml/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// votedPlane streams n points from the plane
+// x + 2y - 3 = 0, flipping the label of each
+// point with the given probability
+func votedPlane(r *rand.Rand, stream chan base.Datapoint, n int, noise float64) {
+	for i := 0; i < n; i++ {
+		x := []float64{20*r.Float64() - 10, 20*r.Float64() - 10}
+		y := -1.0
+		if x[0]+2*x[1]-3 > 0 {
+			y = 1
+		}
+		if r.Float64() < noise {
+			y *= -1
+		}
+
+		stream <- base.Datapoint{X: x, Y: []float64{y}}
+	}
+
+	close(stream)
+}
+
+func TestVotedPerceptronShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewVotedPerceptron(0.1, 2)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	r := rand.New(rand.NewSource(1))
+	go votedPlane(r, stream, 5000, 0.1)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	assert.Len(t, model.Votes, len(model.Vectors), "There should be one vote count per parameter vector")
+	assert.True(t, len(model.Vectors) > 10, "The model should have stored the parameter vectors it replaced")
+
+	var votes int
+	for i := range model.Votes {
+		votes += model.Votes[i]
+	}
+	assert.Equal(t, 5000, votes, "Every example should count as a vote for exactly one vector")
+
+	var wrong, count int
+	for i := 0; i < 1000; i++ {
+		x := []float64{20*r.Float64() - 10, 20*r.Float64() - 10}
+		guess, err := model.Predict(x)
+		assert.Nil(t, err, "Prediction error should be nil")
+		assert.Len(t, guess, 1, "Guess should have length 1")
+
+		if (x[0]+2*x[1]-3 > 0) != (guess[0] == 1) {
+			wrong++
+		}
+		count++
+	}
+
+	accuracy := 100 * (1 - float64(wrong)/float64(count))
+	assert.True(t, accuracy > 90, "There should be greater than 90 percent accuracy on noisy data (currently %v)", accuracy)
+}
+
+func TestVotedPerceptronMaxVectorsShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewVotedPerceptron(0.1, 2)
+	model.MaxVectors = 20
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	r := rand.New(rand.NewSource(2))
+	go votedPlane(r, stream, 5000, 0.1)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	assert.Len(t, model.Vectors, 20, "The model should keep at most MaxVectors parameter vectors")
+	assert.Len(t, model.Votes, 20, "There should be one vote count per parameter vector")
+}
+
+func TestVotedPerceptronShouldFail1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewVotedPerceptron(0.1, 2)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	stream <- base.Datapoint{X: []float64{1}, Y: []float64{1}}
+	stream <- base.Datapoint{X: []float64{1, 2}, Y: []float64{1, -1}}
+	close(stream)
+
+	var count int
+	for err := range errors {
+		assert.NotNil(t, err, "Learning error should not be nil")
+		count++
+	}
+	assert.Equal(t, 2, count, "Both invalid datapoints should return an error")
+
+	_, err := model.Predict([]float64{1, 2, 3})
+	assert.NotNil(t, err, "Prediction error should not be nil with the wrong number of features")
+}
+
+func TestPersistVotedPerceptronShouldPass1(t *testing.T) {
+	stream := make(chan base.Datapoint, 100)
+	errors := make(chan error)
+
+	model := NewVotedPerceptron(0.1, 2)
+
+	go model.OnlineLearn(errors, stream, func(theta [][]float64) {})
+
+	r := rand.New(rand.NewSource(3))
+	go votedPlane(r, stream, 1000, 0)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	err := model.PersistToFile("/tmp/.goml/VotedPerceptron.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	err = model.PersistToGob("/tmp/.goml/VotedPerceptron.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	fromJSON := NewVotedPerceptron(0.1, 2)
+	err = fromJSON.RestoreFromFile("/tmp/.goml/VotedPerceptron.json")
+	assert.Nil(t, err, "Restoration error should be nil")
+	assert.Equal(t, model.Vectors, fromJSON.Vectors, "Restored parameter vectors should be the same")
+	assert.Equal(t, model.Votes, fromJSON.Votes, "Restored votes should be the same")
+
+	fromGob := NewVotedPerceptron(0.1, 2)
+	err = fromGob.RestoreFromGob("/tmp/.goml/VotedPerceptron.gob")
+	assert.Nil(t, err, "Restoration error should be nil")
+	assert.Equal(t, model.Vectors, fromGob.Vectors, "Restored parameter vectors should be the same")
+	assert.Equal(t, model.Votes, fromGob.Votes, "Restored votes should be the same")
+
+	assert.NotNil(t, model.PersistToFile(""), "Persisting to an empty path should return an error")
+	assert.NotNil(t, model.RestoreFromFile(""), "Restoring from an empty path should return an error")
+}
@@ -72,7 +72,9 @@ Example Online Naive Bayes Text Classifier (multiclass):
 package text
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -80,6 +82,7 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 
@@ -151,6 +154,34 @@ type NaiveBayes struct {
 	// NaiveBayes model's vocabulary
 	DictCount uint64 `json:"vocabulary_size"`
 
+	// UseTFIDF, when true, weights the
+	// contribution of each word in Predict
+	// and Probability by its inverse
+	// document frequency
+	//     idf = log(DocumentCount / (1 + DocsSeen))
+	// so common filler words which show up
+	// in most documents count for much less
+	// than rarer, more informative words.
+	// Words in (nearly) every document get a
+	// weight of 0 rather than a negative one.
+	UseTFIDF bool `json:"use_tfidf"`
+
+	// Smoothing is the additive (Lidstone)
+	// smoothing constant α used when estimating
+	// the probability of a word given a class
+	//     P(w|c) = (Count[c] + α) / (Seen + α*DictCount)
+	// It defaults to 1 (Laplace, or add-one
+	// smoothing.) Smaller values smooth less,
+	// which often helps with large vocabularies.
+	// A value of 0 or less uses the default.
+	Smoothing float64 `json:"smoothing"`
+
+	// StopWords holds words (after sanitization
+	// and tokenization) which are ignored when
+	// learning and predicting. Set it with
+	// UpdateStopWords, ie. with EnglishStopWords
+	StopWords map[string]struct{} `json:"stop_words,omitempty"`
+
 	// sanitize is used by a model
 	// to sanitize input of text
 	sanitize transform.Transformer
@@ -162,6 +193,11 @@ type NaiveBayes struct {
 	// to split the input into tokens
 	Tokenizer Tokenizer `json:"tokenizer"`
 
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool `json:"-"`
+
 	// Output is the io.Writer used for logging
 	// and printing. Defaults to os.Stdout.
 	Output io.Writer `json:"-"`
@@ -189,6 +225,55 @@ func (t *SimpleTokenizer) Tokenize(sentence string) []string {
 	return strings.Split(strings.ToLower(sentence), t.SplitOn)
 }
 
+// NGramTokenizer splits sentences into
+// contiguous word n-grams, so models can
+// pick up on word order (like 'los angeles'
+// versus 'los' and 'angeles' on their own.)
+// Words are lowercased and split on SplitOn
+// just like the SimpleTokenizer, and then
+// joined into n-grams by Separator.
+//
+// Every n-gram of length Min through N is
+// returned. If Min is 0 (or greater than
+// N) only n-grams of length N are returned.
+//
+//    t := NGramTokenizer{SplitOn: " ", Separator: " ", N: 2, Min: 1}
+//    t.Tokenize("I love Los Angeles")
+//    // [i love los angeles i love love los los angeles]
+type NGramTokenizer struct {
+	SplitOn   string
+	Separator string
+
+	N   int
+	Min int
+}
+
+// Tokenize splits input sentences into a lowercase slice
+// of n-grams. Empty words (from repeated delimiters) are
+// skipped so they don't end up within an n-gram.
+func (t *NGramTokenizer) Tokenize(sentence string) []string {
+	words := []string{}
+	for _, word := range strings.Split(strings.ToLower(sentence), t.SplitOn) {
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+
+	min := t.Min
+	if min < 1 || min > t.N {
+		min = t.N
+	}
+
+	tokens := []string{}
+	for n := min; n <= t.N; n++ {
+		for i := 0; i+n <= len(words); i++ {
+			tokens = append(tokens, strings.Join(words[i:i+n], t.Separator))
+		}
+	}
+
+	return tokens
+}
+
 // concurrentMap allows concurrency-friendly map
 // access via its exported Get and Set methods
 type concurrentMap struct {
@@ -248,7 +333,7 @@ type Word struct {
 	// DocsSeen is the same as Seen but
 	// a word is only counted once even
 	// if it's in a document multiple times
-	DocsSeen uint64 `json:"-"`
+	DocsSeen uint64 `json:"docs_seen"`
 }
 
 // NewNaiveBayes returns a NaiveBayes model the
@@ -261,6 +346,7 @@ func NewNaiveBayes(stream <-chan base.TextDatapoint, classes uint8, sanitize fun
 		Words:         concurrentMap{sync.RWMutex{}, make(map[string]Word)},
 		Count:         make([]uint64, classes),
 		Probabilities: make([]float64, classes),
+		Smoothing:     1,
 
 		sanitize:  transform.RemoveFunc(sanitize),
 		stream:    stream,
@@ -275,18 +361,128 @@ func NewNaiveBayes(stream <-chan base.TextDatapoint, classes uint8, sanitize fun
 // data passed so far, and returns the class
 // estimated for the document.
 func (b *NaiveBayes) Predict(sentence string) uint8 {
+	sums := b.logProbabilities(sentence)
+
+	// find best class
+	var maxI int
+	for i := range sums {
+		if sums[i] > sums[maxI] {
+			maxI = i
+		}
+	}
+
+	return uint8(maxI)
+}
+
+// PredictStream reads documents from the given reader,
+// one per line, and predicts the class of each one as
+// it's read (see Predict,) so you can classify a file
+// or network connection without holding it all in
+// memory. The predicted classes are sent on the
+// returned channel in the same order as the lines,
+// including one for every empty line.
+//
+// Both channels are closed once the reader hits EOF.
+// If reading fails (including for lines longer than
+// bufio.MaxScanTokenSize) the error is sent on the
+// error channel, which is buffered, before they're
+// closed, so you can range over the classes and then
+// check for an error.
+//
+//     classes, errs := model.PredictStream(file)
+//     for class := range classes {
+//         fmt.Println(class)
+//     }
+//     if err := <-errs; err != nil {
+//         panic(err)
+//     }
+func (b *NaiveBayes) PredictStream(r io.Reader) (<-chan uint8, <-chan error) {
+	classes := make(chan uint8)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(classes)
+		defer close(errs)
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			classes <- b.Predict(scanner.Text())
+		}
+
+		if err := scanner.Err(); err != nil {
+			errs <- err
+		}
+	}()
+
+	return classes, errs
+}
+
+// ClassProbabilities takes in a document and returns
+// the probability that it's part of each class, where
+// the probability of class i is at index i. The
+// probabilities sum to 1.
+//
+// Unlike Probability, this is normalized in log space
+// (subtracting the largest log probability before
+// exponentiating) so it won't underflow for longer
+// documents. This lets you threshold predictions or
+// look for near-ties between classes.
+//
+// (The model's Probabilities field already holds the
+// prior probability of each class, hence the name.)
+func (b *NaiveBayes) ClassProbabilities(sentence string) []float64 {
+	sums := b.logProbabilities(sentence)
+
+	max := math.Inf(-1)
+	for i := range sums {
+		if sums[i] > max {
+			max = sums[i]
+		}
+	}
+
+	// no documents have been seen yet, so
+	// every class is just as likely
+	if math.IsInf(max, -1) {
+		for i := range sums {
+			sums[i] = 1 / float64(len(sums))
+		}
+		return sums
+	}
+
+	var denom float64
+	for i := range sums {
+		sums[i] = math.Exp(sums[i] - max)
+		denom += sums[i]
+	}
+
+	for i := range sums {
+		sums[i] /= denom
+	}
+
+	return sums
+}
+
+// logProbabilities returns the log of the
+// (unnormalized) probability that the given
+// document is each class
+func (b *NaiveBayes) logProbabilities(sentence string) []float64 {
 	sums := make([]float64, len(b.Count))
 
 	sentence, _, _ = transform.String(b.sanitize, sentence)
 	words := b.Tokenizer.Tokenize(sentence)
 	for _, word := range words {
+		if b.isStopWord(word) {
+			continue
+		}
+
 		w, ok := b.Words.Get(word)
 		if !ok {
 			continue
 		}
 
+		weight := b.weight(w)
 		for i := range sums {
-			sums[i] += math.Log(float64(w.Count[i]+1) / float64(w.Seen+b.DictCount))
+			sums[i] += weight * math.Log(b.wordProbability(w, i))
 		}
 	}
 
@@ -294,15 +490,121 @@ func (b *NaiveBayes) Predict(sentence string) uint8 {
 		sums[i] += math.Log(b.Probabilities[i])
 	}
 
-	// find best class
-	var maxI int
-	for i := range sums {
-		if sums[i] > sums[maxI] {
-			maxI = i
+	return sums
+}
+
+// TopWords returns the n words in the model's vocabulary
+// which most strongly indicate that a document is part
+// of the given class, best first. Words are ranked by
+// the log-likelihood ratio of the class against the
+// average of the other classes
+//
+//     log((Count[class]+1) / avg_{c≠class}(Count[c]+1))
+//
+// which is how much seeing the word raises the class'
+// score over the others in Predict. Stop words are
+// skipped, and ties are broken alphabetically. If the
+// vocabulary has fewer than n words all of them are
+// returned.
+//
+// An error is returned if the class is out of range
+// or n isn't positive.
+func (b *NaiveBayes) TopWords(class int, n int) ([]string, error) {
+	if class < 0 || class >= len(b.Count) {
+		return nil, fmt.Errorf("ERROR: class %v is out of the range of the model's classes [0,%v)!", class, len(b.Count))
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("ERROR: number of words should be positive! Given %v", n)
+	}
+
+	type rankedWord struct {
+		word  string
+		ratio float64
+	}
+
+	others := float64(len(b.Count) - 1)
+
+	b.Words.RLock()
+	ranked := make([]rankedWord, 0, len(b.Words.words))
+	for word, w := range b.Words.words {
+		if b.isStopWord(word) {
+			continue
 		}
+
+		ratio := math.Log(float64(w.Count[class] + 1))
+		if others > 0 {
+			var rest float64
+			for c := range w.Count {
+				if c != class {
+					rest += float64(w.Count[c] + 1)
+				}
+			}
+			ratio -= math.Log(rest / others)
+		}
+
+		ranked = append(ranked, rankedWord{word, ratio})
 	}
+	b.Words.RUnlock()
 
-	return uint8(maxI)
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].ratio != ranked[j].ratio {
+			return ranked[i].ratio > ranked[j].ratio
+		}
+		return ranked[i].word < ranked[j].word
+	})
+
+	if n > len(ranked) {
+		n = len(ranked)
+	}
+
+	words := make([]string, n)
+	for i := range words {
+		words[i] = ranked[i].word
+	}
+
+	return words, nil
+}
+
+// weight returns how much the given word counts
+// towards a prediction. This is 1 unless the model
+// is using TFIDF weighting, in which case it's the
+// word's inverse document frequency (floored at 0.)
+func (b *NaiveBayes) weight(w Word) float64 {
+	if !b.UseTFIDF {
+		return 1
+	}
+
+	idf := math.Log(float64(b.DocumentCount) / float64(1+w.DocsSeen))
+	if idf < 0 {
+		return 0
+	}
+
+	return idf
+}
+
+// smoothing returns the model's smoothing
+// constant α, defaulting to 1 if it isn't
+// set (ie. when restoring older models)
+func (b *NaiveBayes) smoothing() float64 {
+	if b.Smoothing <= 0 {
+		return 1
+	}
+	return b.Smoothing
+}
+
+// wordProbability returns the smoothed estimate
+// of the probability of word w given the class
+//     P(w|class) = (Count[class] + α) / (Seen + α*DictCount)
+func (b *NaiveBayes) wordProbability(w Word, class int) float64 {
+	alpha := b.smoothing()
+	return (float64(w.Count[class]) + alpha) / (float64(w.Seen) + alpha*float64(b.DictCount))
+}
+
+// isStopWord returns whether the given token
+// is one of the model's stop words
+func (b *NaiveBayes) isStopWord(word string) bool {
+	_, ok := b.StopWords[word]
+	return ok
 }
 
 // Probability takes in a small document, returns the
@@ -321,7 +623,8 @@ func (b *NaiveBayes) Predict(sentence string) uint8 {
 // Basically, use Predict to be robust for larger
 // documents. Use Probability only on relatively small
 // (MAX of maybe a dozen words - basically just
-// sentences and words) documents.
+// sentences and words) documents. ClassProbabilities
+// gives robust probabilities for larger documents.
 func (b *NaiveBayes) Probability(sentence string) (uint8, float64) {
 	sums := make([]float64, len(b.Count))
 	for i := range sums {
@@ -331,13 +634,18 @@ func (b *NaiveBayes) Probability(sentence string) (uint8, float64) {
 	sentence, _, _ = transform.String(b.sanitize, sentence)
 	words := b.Tokenizer.Tokenize(sentence)
 	for _, word := range words {
+		if b.isStopWord(word) {
+			continue
+		}
+
 		w, ok := b.Words.Get(word)
 		if !ok {
 			continue
 		}
 
+		weight := b.weight(w)
 		for i := range sums {
-			sums[i] *= float64(w.Count[i]+1) / float64(w.Seen+b.DictCount)
+			sums[i] *= math.Pow(b.wordProbability(w, i), weight)
 		}
 	}
 
@@ -362,6 +670,16 @@ func (b *NaiveBayes) Probability(sentence string) (uint8, float64) {
 // from the datastream, waiting for new data to
 // come into the stream from a separate goroutine
 func (b *NaiveBayes) OnlineLearn(errors chan<- error) {
+	b.OnlineLearnContext(context.Background(), errors)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// data stream is still open. This lets you stop
+// training, ie. when shutting down a server, without
+// having to close the stream yourself.
+func (b *NaiveBayes) OnlineLearnContext(ctx context.Context, errors chan<- error) {
 	if errors == nil {
 		errors = make(chan error)
 	}
@@ -371,13 +689,19 @@ func (b *NaiveBayes) OnlineLearn(errors chan<- error) {
 		return
 	}
 
-	fmt.Fprintf(b.Output, "Training:\n\tModel: Multinomial Naïve Bayes\n\tClasses: %v\n", len(b.Count))
+	b.logf("Training:\n\tModel: Multinomial Naïve Bayes\n\tClasses: %v\n", len(b.Count))
 
 	var point base.TextDatapoint
 	var more bool
 
 	for {
-		point, more = <-b.stream
+		select {
+		case <-ctx.Done():
+			b.logf("Training Cancelled.\n%v\n\n", b)
+			close(errors)
+			return
+		case point, more = <-b.stream:
+		}
 
 		if more {
 			// sanitize and break up document
@@ -403,7 +727,7 @@ func (b *NaiveBayes) OnlineLearn(errors chan<- error) {
 
 			// update probabilities for words
 			for _, word := range words {
-				if len(word) < 3 {
+				if len(word) < 3 || b.isStopWord(word) {
 					continue
 				}
 
@@ -433,13 +757,97 @@ func (b *NaiveBayes) OnlineLearn(errors chan<- error) {
 				b.Words.Set(term, tmp)
 			}
 		} else {
-			fmt.Fprintf(b.Output, "Training Completed.\n%v\n\n", b)
+			b.logf("Training Completed.\n%v\n\n", b)
 			close(errors)
 			return
 		}
 	}
 }
 
+// Unlearn removes a document the model has already
+// learned from, undoing exactly what OnlineLearn did
+// when it saw the document: the class and document
+// counts, as well as the count of every word in the
+// document, are decremented and the class
+// probabilities are recomputed. Words which are no
+// longer seen at all are removed from the vocabulary.
+// This lets you correct a mislabeled document without
+// retraining the whole model.
+//
+// The document has to be sanitized and tokenized the
+// same way it was when it was learned (so don't change
+// the tokenizer or stop words in between.) An error is
+// returned, and the model is left unchanged, if the
+// class is out of range or if the model hasn't seen
+// enough of the class or words in the document to
+// unlearn it. Don't call this while the model is
+// learning from its stream.
+func (b *NaiveBayes) Unlearn(point base.TextDatapoint) error {
+	C := int(point.Y)
+	if C > len(b.Count)-1 {
+		return fmt.Errorf("ERROR: given document class is greater than the number of classes in the model!\n")
+	}
+	if b.Count[C] == 0 || b.DocumentCount == 0 {
+		return fmt.Errorf("ERROR: can't unlearn a document of class %v because the model hasn't seen any!\n", C)
+	}
+
+	sanitized, _, _ := transform.String(b.sanitize, point.X)
+	words := b.Tokenizer.Tokenize(sanitized)
+
+	// count the occurrences of each word the same
+	// way OnlineLearn would have
+	occurrences := make(map[string]uint64)
+	for _, word := range words {
+		if len(word) < 3 || b.isStopWord(word) {
+			continue
+		}
+
+		occurrences[word]++
+	}
+
+	b.Words.Lock()
+	defer b.Words.Unlock()
+
+	// make sure the whole document can be
+	// unlearned before changing anything
+	for word, n := range occurrences {
+		w, ok := b.Words.words[word]
+		if !ok || w.Count[C] < n || w.Seen < n || w.DocsSeen == 0 {
+			return fmt.Errorf("ERROR: can't unlearn the word %q from class %v more times than the model has seen it!\n", word, C)
+		}
+	}
+
+	for word, n := range occurrences {
+		w := b.Words.words[word]
+
+		w.Count[C] -= n
+		w.Seen -= n
+		w.DocsSeen--
+
+		if w.Seen == 0 {
+			delete(b.Words.words, word)
+			b.DictCount--
+			continue
+		}
+
+		b.Words.words[word] = w
+	}
+
+	// update global class probabilities
+	b.Count[C]--
+	b.DocumentCount--
+	for i := range b.Probabilities {
+		if b.DocumentCount == 0 {
+			b.Probabilities[i] = 0
+			continue
+		}
+
+		b.Probabilities[i] = float64(b.Count[i]) / float64(b.DocumentCount)
+	}
+
+	return nil
+}
+
 // UpdateStream updates the NaiveBayes model's
 // text datastream
 func (b *NaiveBayes) UpdateStream(stream chan base.TextDatapoint) {
@@ -452,13 +860,44 @@ func (b *NaiveBayes) UpdateSanitize(sanitize func(rune) bool) {
 	b.sanitize = transform.RemoveFunc(sanitize)
 }
 
+// UpdateStopWords sets the words the NaiveBayes model
+// ignores when learning and predicting, replacing any
+// stop words set before. Words are lowercased to match
+// the default tokenizer. Passing no words turns stop
+// word filtering off.
+//
+// Words already learned by the model aren't removed
+// from it, but will be ignored while predicting.
+func (b *NaiveBayes) UpdateStopWords(words []string) {
+	if len(words) == 0 {
+		b.StopWords = nil
+		return
+	}
+
+	stopWords := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		stopWords[strings.ToLower(word)] = struct{}{}
+	}
+
+	b.StopWords = stopWords
+}
+
 // UpdateTokenizer updates NaiveBayes model's tokenizer function.
 // The default implementation will convert the input to lower
-// case and split on the space character.
+// case and split on the space character. Pass an NGramTokenizer
+// to learn from n-grams of words instead.
 func (b *NaiveBayes) UpdateTokenizer(tokenizer Tokenizer) {
 	b.Tokenizer = tokenizer
 }
 
+// logf prints training progress to Output
+// when the model is Verbose
+func (b *NaiveBayes) logf(format string, a ...interface{}) {
+	if b.Verbose {
+		fmt.Fprintf(b.Output, format, a...)
+	}
+}
+
 // String implements the fmt interface for clean printing. Here
 // we're using it to print the model as the equation h(θ)=...
 // where h is the perceptron hypothesis model.
@@ -550,3 +989,76 @@ func (b *NaiveBayes) RestoreFromFile(path string) error {
 
 	return nil
 }
+
+// persistedNaiveBayes holds the fields of a NaiveBayes
+// model that are persisted with PersistToGob. The
+// model can't be gob encoded itself because of its
+// Output writer and Tokenizer interface.
+type persistedNaiveBayes struct {
+	Words         map[string]Word
+	Count         []uint64
+	Probabilities []float64
+	DocumentCount uint64
+	DictCount     uint64
+	UseTFIDF      bool
+	Smoothing     float64
+	StopWords     map[string]struct{}
+}
+
+// PersistToGob saves the model to the given file like
+// PersistToFile, but encoded with encoding/gob (see
+// base.PersistToGob,) which is much smaller and faster
+// to restore than JSON for large vocabularies.
+func (b *NaiveBayes) PersistToGob(path string) error {
+	b.Words.RLock()
+	defer b.Words.RUnlock()
+
+	return base.PersistToGob(path, persistedNaiveBayes{
+		Words:         b.Words.words,
+		Count:         b.Count,
+		Probabilities: b.Probabilities,
+		DocumentCount: b.DocumentCount,
+		DictCount:     b.DictCount,
+		UseTFIDF:      b.UseTFIDF,
+		Smoothing:     b.Smoothing,
+		StopWords:     b.StopWords,
+	})
+}
+
+// RestoreFromGob takes in a path to a model saved with
+// PersistToGob and restores the model it's operating on
+// to it. Like RestoreFromFile, the sanitization and
+// tokenization functions default to base.OnlyWordsAndNumbers
+// and SimpleTokenizer{SplitOn: " "}
+func (b *NaiveBayes) RestoreFromGob(path string) error {
+	if b == nil {
+		return errors.New("Cannot restore a model to a nil pointer")
+	}
+
+	var model persistedNaiveBayes
+	err := base.RestoreFromGob(path, &model)
+	if err != nil {
+		return err
+	}
+
+	if model.Words == nil {
+		model.Words = make(map[string]Word)
+	}
+
+	b.Words.Lock()
+	b.Words.words = model.Words
+	b.Words.Unlock()
+
+	b.Count = model.Count
+	b.Probabilities = model.Probabilities
+	b.DocumentCount = model.DocumentCount
+	b.DictCount = model.DictCount
+	b.UseTFIDF = model.UseTFIDF
+	b.Smoothing = model.Smoothing
+	b.StopWords = model.StopWords
+
+	b.sanitize = transform.RemoveFunc(base.OnlyWordsAndNumbers)
+	b.Tokenizer = &SimpleTokenizer{SplitOn: " "}
+
+	return nil
+}
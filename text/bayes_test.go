@@ -1,12 +1,17 @@
 package text
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/cdipaolo/goml/base"
 
@@ -357,6 +362,403 @@ func TestPersistNaiveBayesShouldPass1(t *testing.T) {
 	assert.True(t, p > 0.75, "There should be a greater than 75 percent chance the document is positive - Given %v", p)
 }
 
+// filler words which show up in every document
+// (but more often in one class) shouldn't
+// outweigh informative words with TFIDF weighting
+func TestTFIDFWeightingShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	for i := 0; i < 10; i++ {
+		stream <- base.TextDatapoint{
+			X: "good movie really",
+			Y: 0,
+		}
+
+		stream <- base.TextDatapoint{
+			X: "bad movie really really really really really",
+			Y: 1,
+		}
+	}
+
+	close(stream)
+
+	for {
+		err, more := <-errors
+		if more {
+			fmt.Printf("Error passed: %v", err)
+		} else {
+			// training is done!
+			break
+		}
+	}
+
+	document := "good movie really really really really"
+
+	class := model.Predict(document)
+	assert.EqualValues(t, 1, class, "Without TFIDF the filler words should win")
+
+	model.UseTFIDF = true
+
+	class = model.Predict(document)
+	assert.EqualValues(t, 0, class, "With TFIDF the informative word should win")
+
+	class, p := model.Probability(document)
+	assert.EqualValues(t, 0, class, "With TFIDF the informative word should win")
+	assert.True(t, p > 0.75, "There should be a greater than 75 percent chance the document is positive - Given %v", p)
+
+	// the weighting (and document counts it
+	// relies on) should be persisted
+	err := model.PersistToFile("/tmp/.goml/TFIDFBayes.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	restored := NewNaiveBayes(nil, 2, base.OnlyWordsAndNumbers)
+	err = restored.RestoreFromFile("/tmp/.goml/TFIDFBayes.json")
+	assert.Nil(t, err, "Restoration error should be nil")
+
+	assert.True(t, restored.UseTFIDF, "TFIDF weighting should be restored")
+
+	w, ok := restored.Words.Get("good")
+	assert.True(t, ok, "Words should be restored")
+	assert.EqualValues(t, 10, w.DocsSeen, "Document counts should be restored")
+
+	class = restored.Predict(document)
+	assert.EqualValues(t, 0, class, "Restored model should predict the same")
+}
+
+func TestPredictStreamShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	for i := 1; i < 10; i++ {
+		stream <- base.TextDatapoint{
+			X: "I love the city",
+			Y: 1,
+		}
+
+		stream <- base.TextDatapoint{
+			X: "I hate Los Angeles",
+			Y: 0,
+		}
+	}
+
+	close(stream)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	docs := []string{"My mother is in Los Angeles", "love the CiTy", "", "I hate Los Angeles"}
+
+	classes, errs := model.PredictStream(strings.NewReader(strings.Join(docs, "\n")))
+
+	var guesses []uint8
+	for class := range classes {
+		guesses = append(guesses, class)
+	}
+	assert.Nil(t, <-errs, "Stream error should be nil")
+
+	assert.Len(t, guesses, len(docs), "There should be a prediction for every line")
+	for i := range docs {
+		assert.Equal(t, model.Predict(docs[i]), guesses[i], "Streamed prediction should match Predict for line %v", i)
+	}
+	assert.EqualValues(t, []uint8{0, 1}, []uint8{guesses[0], guesses[1]}, "Documents should be classified correctly")
+}
+
+func TestPredictStreamShouldFail1(t *testing.T) {
+	model := NewNaiveBayes(nil, 2, base.OnlyWordsAndNumbers)
+
+	reader := io.MultiReader(strings.NewReader("love the city\n"), iotest.ErrReader(fmt.Errorf("connection reset")))
+	classes, errs := model.PredictStream(reader)
+
+	var count int
+	for range classes {
+		count++
+	}
+	assert.Equal(t, 1, count, "Lines read before the error should be predicted")
+
+	err := <-errs
+	assert.NotNil(t, err, "Stream error should be passed")
+	assert.Contains(t, err.Error(), "connection reset", "Stream error should be the reader's error")
+}
+
+func TestTopWordsShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	for i := 1; i < 10; i++ {
+		stream <- base.TextDatapoint{
+			X: "I love the city",
+			Y: 1,
+		}
+
+		stream <- base.TextDatapoint{
+			X: "I hate Los Angeles",
+			Y: 0,
+		}
+	}
+
+	stream <- base.TextDatapoint{
+		X: "love love love",
+		Y: 1,
+	}
+
+	close(stream)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	words, err := model.TopWords(1, 3)
+	assert.Nil(t, err, "Top words error should be nil")
+	assert.Equal(t, []string{"love", "city", "the"}, words, "Most common word should be first, with ties broken alphabetically")
+
+	words, err = model.TopWords(0, 100)
+	assert.Nil(t, err, "Top words error should be nil")
+	assert.Len(t, words, 6, "Every word in the vocabulary should be returned")
+	assert.Equal(t, []string{"angeles", "hate", "los"}, words[:3], "Words only seen in the class should be first")
+	assert.Equal(t, "love", words[5], "Word seen most in the other class should be last")
+
+	model.UpdateStopWords([]string{"the"})
+	words, err = model.TopWords(1, 3)
+	assert.Nil(t, err, "Top words error should be nil")
+	assert.Equal(t, []string{"love", "city", "angeles"}, words, "Stop words should be skipped")
+}
+
+func TestTopWordsShouldFail1(t *testing.T) {
+	model := NewNaiveBayes(nil, 2, base.OnlyWordsAndNumbers)
+
+	_, err := model.TopWords(2, 5)
+	assert.NotNil(t, err, "Class out of range should return an error")
+
+	_, err = model.TopWords(-1, 5)
+	assert.NotNil(t, err, "Negative class should return an error")
+
+	_, err = model.TopWords(0, 0)
+	assert.NotNil(t, err, "Asking for no words should return an error")
+}
+
+func TestUnlearnShouldPass1(t *testing.T) {
+	train := func(docs []base.TextDatapoint) *NaiveBayes {
+		stream := make(chan base.TextDatapoint, len(docs))
+		errors := make(chan error)
+
+		model := NewNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+		go model.OnlineLearn(errors)
+
+		for _, doc := range docs {
+			stream <- doc
+		}
+		close(stream)
+
+		for err := range errors {
+			assert.Nil(t, err, "Learning error should be nil")
+		}
+
+		return model
+	}
+
+	docs := []base.TextDatapoint{
+		{X: "I love the city", Y: 1},
+		{X: "I hate Los Angeles", Y: 0},
+		{X: "My mother is not a nice lady", Y: 0},
+		{X: "the city is lovely lovely", Y: 1},
+	}
+	mislabeled := base.TextDatapoint{X: "what a lovely lovely day", Y: 0}
+
+	expected := train(docs)
+	model := train(append(append([]base.TextDatapoint{}, docs...), mislabeled))
+
+	err := model.Unlearn(mislabeled)
+	assert.Nil(t, err, "Unlearning error should be nil")
+
+	assert.Equal(t, expected.Words.words, model.Words.words, "Unlearning should restore the word counts")
+	assert.Equal(t, expected.Count, model.Count, "Unlearning should restore the class counts")
+	assert.Equal(t, expected.Probabilities, model.Probabilities, "Unlearning should restore the class probabilities")
+	assert.Equal(t, expected.DocumentCount, model.DocumentCount, "Unlearning should restore the document count")
+	assert.Equal(t, expected.DictCount, model.DictCount, "Unlearning should remove words which aren't seen anymore")
+
+	_, ok := model.Words.Get("day")
+	assert.False(t, ok, "Words only in the unlearned document should be removed")
+
+	// unlearn everything
+	for _, doc := range docs {
+		assert.Nil(t, model.Unlearn(doc), "Unlearning error should be nil")
+	}
+	assert.Len(t, model.Words.words, 0, "Vocabulary should be empty")
+	assert.EqualValues(t, 0, model.DictCount, "Vocabulary size should be 0")
+	assert.EqualValues(t, 0, model.DocumentCount, "Document count should be 0")
+	assert.Equal(t, []float64{0, 0}, model.Probabilities, "Class probabilities should be 0")
+}
+
+func TestUnlearnShouldFail1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 1)
+	errors := make(chan error)
+
+	model := NewNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	err := model.Unlearn(base.TextDatapoint{X: "I love the city", Y: 1})
+	assert.NotNil(t, err, "Unlearning from an untrained model should return an error")
+
+	err = model.Unlearn(base.TextDatapoint{X: "I love the city", Y: 2})
+	assert.NotNil(t, err, "Unlearning a class out of range should return an error")
+
+	go model.OnlineLearn(errors)
+	stream <- base.TextDatapoint{X: "I love the city", Y: 1}
+	close(stream)
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	// the model never saw 'hate'
+	err = model.Unlearn(base.TextDatapoint{X: "I hate the city", Y: 1})
+	assert.NotNil(t, err, "Unlearning a word the model hasn't seen should return an error")
+
+	// 'love' was only seen once
+	err = model.Unlearn(base.TextDatapoint{X: "love love", Y: 1})
+	assert.NotNil(t, err, "Unlearning a word more times than it was seen should return an error")
+
+	// the model never saw class 0
+	err = model.Unlearn(base.TextDatapoint{X: "I love the city", Y: 0})
+	assert.NotNil(t, err, "Unlearning a class the model hasn't seen should return an error")
+
+	// nothing should have changed
+	assert.EqualValues(t, []uint64{0, 1}, model.Count, "Failed unlearning shouldn't change the model")
+	assert.EqualValues(t, 3, model.DictCount, "Failed unlearning shouldn't change the model")
+	w, ok := model.Words.Get("love")
+	assert.True(t, ok, "Failed unlearning shouldn't change the model")
+	assert.EqualValues(t, 1, w.Seen, "Failed unlearning shouldn't change the model")
+}
+
+func TestClassProbabilitiesShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewNaiveBayes(stream, 3, base.OnlyWordsAndNumbers)
+
+	// every class is just as likely
+	// before anything is learned
+	probs := model.ClassProbabilities("I love the city")
+	assert.InDeltaSlice(t, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}, probs, 1e-12, "Probabilities should be uniform for an untrained model")
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "I love the city",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "I hate Los Angeles",
+		Y: 0,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "My mother is not a nice lady",
+		Y: 0,
+	}
+
+	close(stream)
+
+	for {
+		_, more := <-errors
+		if !more {
+			break
+		}
+	}
+
+	for _, doc := range []string{"My mother is in Los Angeles", "love the CiTy", "nothing learned here", strings.Repeat("My mother is not in Los Angeles ", 500)} {
+		probs := model.ClassProbabilities(doc)
+		assert.Len(t, probs, 3, "There should be a probability for every class")
+
+		var sum float64
+		for _, p := range probs {
+			assert.False(t, math.IsNaN(p), "Probability should not be NaN")
+			assert.True(t, p >= 0 && p <= 1, "Probability should be on [0,1] - Given %v", p)
+			sum += p
+		}
+		assert.InDelta(t, 1, sum, 1e-9, "Probabilities should sum to 1")
+		assert.EqualValues(t, model.Predict(doc), base.ArgMax(probs), "The most likely class should match Predict")
+	}
+
+	// the single class probability should match
+	// for small documents
+	class, p := model.Probability("Mother Los Angeles")
+	probs = model.ClassProbabilities("Mother Los Angeles")
+	assert.InDelta(t, p, probs[class], 1e-9, "Probability and ClassProbabilities should agree on small documents")
+
+	// class 2 was never seen
+	assert.EqualValues(t, 0, probs[2], "Unseen class should have no probability")
+}
+
+func TestStopWordsShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+	model.UpdateStopWords(EnglishStopWords)
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "This is what they would love about the city",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "There isn't anything worse than those sad people",
+		Y: 0,
+	}
+
+	close(stream)
+
+	for {
+		_, more := <-errors
+		if !more {
+			break
+		}
+	}
+
+	for _, word := range []string{"this", "what", "they", "would", "about", "the", "there", "isnt", "than", "those"} {
+		_, ok := model.Words.Get(word)
+		assert.False(t, ok, "Stop word '%v' shouldn't be in the vocabulary", word)
+	}
+
+	for _, word := range []string{"love", "city", "anything", "worse", "sad", "people"} {
+		_, ok := model.Words.Get(word)
+		assert.True(t, ok, "Word '%v' should be in the vocabulary", word)
+	}
+	assert.EqualValues(t, 6, model.DictCount, "Only the non stop words should be counted in the vocabulary")
+
+	class := model.Predict("Those people would love this city")
+	assert.EqualValues(t, 1, class, "Class should be 1")
+
+	// stop words should survive persistance
+	err := model.PersistToFile("/tmp/.goml/StopWordsNaiveBayes.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	model = NewNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+	err = model.RestoreFromFile("/tmp/.goml/StopWordsNaiveBayes.json")
+	assert.Nil(t, err, "Restore error should be nil")
+	assert.Len(t, model.StopWords, len(EnglishStopWords), "Restored model should have the same stop words")
+
+	// turning filtering off
+	model.UpdateStopWords(nil)
+	assert.Nil(t, model.StopWords, "Stop words should be cleared")
+}
+
 func TestSimpleTokenizer(t *testing.T) {
 	// now you can predict like normal
 	type test struct {
@@ -390,6 +792,96 @@ func TestSimpleTokenizer(t *testing.T) {
 
 }
 
+func TestNGramTokenizer(t *testing.T) {
+	type test struct {
+		tokenizer NGramTokenizer
+		input     string
+		output    []string
+	}
+	tests := []test{
+		test{
+			tokenizer: NGramTokenizer{SplitOn: " ", Separator: " ", N: 2},
+			input:     "I love Los Angeles",
+			output:    []string{"i love", "love los", "los angeles"},
+		},
+		test{
+			tokenizer: NGramTokenizer{SplitOn: " ", Separator: " ", N: 2, Min: 1},
+			input:     "I love Los Angeles",
+			output:    []string{"i", "love", "los", "angeles", "i love", "love los", "los angeles"},
+		},
+		test{
+			tokenizer: NGramTokenizer{SplitOn: ",", Separator: "_", N: 3},
+			input:     "Los,,Angeles,CA",
+			output:    []string{"los_angeles_ca"},
+		},
+		test{
+			tokenizer: NGramTokenizer{SplitOn: " ", Separator: " ", N: 1},
+			input:     "Mother  Los Angeles",
+			output:    []string{"mother", "los", "angeles"},
+		},
+		test{
+			tokenizer: NGramTokenizer{SplitOn: " ", Separator: " ", N: 3},
+			input:     "Los Angeles",
+			output:    []string{},
+		},
+	}
+	for _, testCase := range tests {
+		if !equalStringSlices(testCase.output, testCase.tokenizer.Tokenize(testCase.input)) {
+			t.Errorf("incorrectly tokenized a sentence, got %s, want %s\n", testCase.tokenizer.Tokenize(testCase.input), testCase.output)
+		}
+	}
+}
+
+// bigrams let the model tell 'new york' apart
+// from 'york' and 'new' on their own
+func TestNGramClassificationShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+	model.UpdateTokenizer(&NGramTokenizer{SplitOn: " ", Separator: " ", N: 2, Min: 1})
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "I love New York",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "New York is great",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "My new car is from York and is bad",
+		Y: 0,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "York has new problems",
+		Y: 0,
+	}
+
+	close(stream)
+
+	for {
+		_, more := <-errors
+		if !more {
+			break
+		}
+	}
+
+	_, ok := model.Words.Get("new york")
+	assert.True(t, ok, "The bigram 'new york' should be in the vocabulary")
+
+	class := model.Predict("visiting new york")
+	assert.EqualValues(t, 1, class, "Class should be 1")
+
+	class = model.Predict("york has new problems")
+	assert.EqualValues(t, 0, class, "Class should be 0")
+}
+
 func equalStringSlices(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -401,3 +893,173 @@ func equalStringSlices(a, b []string) bool {
 	}
 	return true
 }
+
+func TestOnlineLearnContextShouldPass1(t *testing.T) {
+	// the stream is never closed, so learning
+	// should only stop when the context is
+	// cancelled
+	stream := make(chan base.TextDatapoint)
+	errors := make(chan error)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	model := NewNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearnContext(ctx, errors)
+
+	stream <- base.TextDatapoint{
+		X: "I love the city",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "I hate Los Angeles",
+		Y: 0,
+	}
+
+	cancel()
+
+	select {
+	case _, more := <-errors:
+		assert.False(t, more, "The errors channel should be closed once the context is cancelled")
+	case <-time.After(time.Second):
+		t.Errorf("Learning should stop when the context is cancelled")
+	}
+
+	assert.EqualValues(t, 2, model.DocumentCount, "The model should have learned from the documents sent before cancelling")
+}
+
+func TestSmoothingShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+	assert.Equal(t, 1.0, model.Smoothing, "Smoothing should default to add-one smoothing")
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "I love the city",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "I hate Los Angeles",
+		Y: 0,
+	}
+
+	close(stream)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	document := "love the city"
+
+	// the default should match the usual add-one smoothing
+	//     P(w|c) = (Count[c] + 1) / (Seen + DictCount)
+	expected := []float64{0.5, 0.5}
+	for _, word := range []string{"love", "the", "city"} {
+		w, ok := model.Words.Get(word)
+		assert.True(t, ok, "Word should be in the vocabulary")
+		for i := range expected {
+			expected[i] *= float64(w.Count[i]+1) / float64(w.Seen+model.DictCount)
+		}
+	}
+
+	class, p := model.Probability(document)
+	assert.EqualValues(t, 1, class, "Class should be 1")
+	assert.InDelta(t, expected[1]/(expected[0]+expected[1]), p, 1e-12, "Default smoothing should be add-one smoothing")
+
+	// an unset smoothing constant (ie. from an older
+	// persisted model) should fall back to add-one
+	model.Smoothing = 0
+	_, unset := model.Probability(document)
+	assert.InDelta(t, p, unset, 1e-12, "Unset smoothing should be add-one smoothing")
+
+	// smoothing less should trust the counts more
+	model.Smoothing = 0.01
+	class, lidstone := model.Probability(document)
+	assert.EqualValues(t, 1, class, "Class should be 1")
+	assert.True(t, lidstone > p, "Smoothing less should make the model more confident - Given %v vs %v", lidstone, p)
+
+	class = model.Predict(document)
+	assert.EqualValues(t, 1, class, "Class should be 1")
+
+	// the smoothing constant should be persisted
+	err := model.PersistToFile("/tmp/.goml/SmoothingBayes.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	restored := NewNaiveBayes(nil, 2, base.OnlyWordsAndNumbers)
+	err = restored.RestoreFromFile("/tmp/.goml/SmoothingBayes.json")
+	assert.Nil(t, err, "Restoration error should be nil")
+	assert.Equal(t, 0.01, restored.Smoothing, "Smoothing should be restored")
+
+	err = model.PersistToGob("/tmp/.goml/SmoothingBayes.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	restored = NewNaiveBayes(nil, 2, base.OnlyWordsAndNumbers)
+	err = restored.RestoreFromGob("/tmp/.goml/SmoothingBayes.gob")
+	assert.Nil(t, err, "Restoration error should be nil")
+	assert.Equal(t, 0.01, restored.Smoothing, "Smoothing should be restored")
+
+	_, restoredP := restored.Probability(document)
+	assert.InDelta(t, lidstone, restoredP, 1e-12, "Restored model should predict the same probability")
+}
+
+func TestPersistNaiveBayesGobShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewNaiveBayes(stream, 3, base.OnlyWordsAndNumbers)
+	model.UpdateStopWords([]string{"the"})
+
+	go model.OnlineLearn(errors)
+
+	for i := 1; i < 10; i++ {
+		stream <- base.TextDatapoint{
+			X: "I love the city",
+			Y: 1,
+		}
+
+		stream <- base.TextDatapoint{
+			X: "I hate Los Angeles",
+			Y: 0,
+		}
+	}
+
+	close(stream)
+
+	for err := range errors {
+		assert.Nil(t, err, "Learning error should be nil")
+	}
+
+	err := model.PersistToGob("/tmp/.goml/NaiveBayes.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+	err = model.PersistToFile("/tmp/.goml/NaiveBayesGobCheck.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	fromGob := NewNaiveBayes(nil, 3, base.OnlyWordsAndNumbers)
+	err = fromGob.RestoreFromGob("/tmp/.goml/NaiveBayes.gob")
+	assert.Nil(t, err, "Restoring error should be nil")
+
+	fromJSON := NewNaiveBayes(nil, 3, base.OnlyWordsAndNumbers)
+	err = fromJSON.RestoreFromFile("/tmp/.goml/NaiveBayesGobCheck.json")
+	assert.Nil(t, err, "Restoring error should be nil")
+
+	assert.Equal(t, fromJSON.Words.words, fromGob.Words.words, "Gob and JSON should restore the same vocabulary")
+	assert.Equal(t, fromJSON.Count, fromGob.Count, "Gob and JSON should restore the same class counts")
+	assert.Equal(t, fromJSON.Probabilities, fromGob.Probabilities, "Gob and JSON should restore the same class probabilities")
+	assert.Equal(t, fromJSON.DocumentCount, fromGob.DocumentCount, "Gob and JSON should restore the same document count")
+	assert.Equal(t, fromJSON.DictCount, fromGob.DictCount, "Gob and JSON should restore the same vocabulary size")
+	assert.Equal(t, fromJSON.StopWords, fromGob.StopWords, "Gob and JSON should restore the same stop words")
+
+	for _, doc := range []string{"My mother is in Los Angeles", "love the CiTy"} {
+		class, p := model.Probability(doc)
+		restoredClass, restoredP := fromGob.Probability(doc)
+		assert.Equal(t, class, restoredClass, "Restored model should predict the same class")
+		assert.InDelta(t, p, restoredP, 1e-12, "Restored model should predict the same probability")
+	}
+
+	assert.NotNil(t, fromGob.RestoreFromGob(""), "Restoring error should not be nil")
+}
@@ -0,0 +1,469 @@
+package text
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sync"
+
+	"golang.org/x/text/transform"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+/*
+BernoulliNaiveBayes is a Naive Bayes text
+classifier which, unlike the multinomial
+NaiveBayes model, only models whether each
+word in the vocabulary is present in a
+document or not. Words repeated within a
+document aren't counted more than once,
+and words in the vocabulary which are
+absent from a document count as evidence
+too. This often works better than the
+multinomial model for short documents.
+
+For each class c and word w the model
+estimates (with Laplace smoothing) the
+probability that a document of class c
+contains w
+	P(w|y = c) = (docs of class c with w + 1) / (docs of class c + 2)
+and classifies a document x with
+	Class(x) = argmax_c{log(P(y = c)) + Σ_{w∈x} log(P(w|y = c)) + Σ_{w∉x} log(1 - P(w|y = c))}
+where the sums run over the vocabulary.
+
+http://nlp.stanford.edu/IR-book/html/htmledition/the-bernoulli-model-1.html
+
+Example Online Bernoulli Naive Bayes Text Classifier:
+
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewBernoulliNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "I love the city",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "I hate Los Angeles",
+		Y: 0,
+	}
+
+	close(stream)
+
+	for {
+		err, more := <-errors
+		if more {
+			fmt.Printf("Error passed: %v", err)
+		} else {
+			// training is done!
+			break
+		}
+	}
+
+	// now you can predict like normal
+	class := model.Predict("My mother is in Los Angeles") // 0
+*/
+type BernoulliNaiveBayes struct {
+	// Words holds a map of words
+	// to their corresponding Word
+	// structure. Word.Count[i] holds
+	// the number of documents of
+	// class i containing the word.
+	Words concurrentMap `json:"words"`
+
+	// Count holds the number of times
+	// class i was seen as Count[i]
+	Count []uint64 `json:"count"`
+
+	// Probabilities holds the probability
+	// that class Y is class i as
+	// Probabilities[i] for
+	Probabilities []float64 `json:"probabilities"`
+
+	// DocumentCount holds the number of
+	// documents that have been seen
+	DocumentCount uint64 `json:"document_count"`
+
+	// DictCount holds the size of the
+	// model's vocabulary
+	DictCount uint64 `json:"vocabulary_size"`
+
+	// sanitize is used by a model
+	// to sanitize input of text
+	sanitize transform.Transformer
+
+	// stream holds the datastream
+	stream <-chan base.TextDatapoint
+
+	// tokenizer is used by a model
+	// to split the input into tokens
+	Tokenizer Tokenizer `json:"tokenizer"`
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool `json:"-"`
+
+	// Output is the io.Writer used for logging
+	// and printing. Defaults to os.Stdout.
+	Output io.Writer `json:"-"`
+}
+
+// NewBernoulliNaiveBayes returns a BernoulliNaiveBayes
+// model with the given number of classes instantiated,
+// ready to learn off the given data stream. The
+// sanitization function is set to the given function.
+// It must comply with the transform.RemoveFunc interface
+func NewBernoulliNaiveBayes(stream <-chan base.TextDatapoint, classes uint8, sanitize func(rune) bool) *BernoulliNaiveBayes {
+	return &BernoulliNaiveBayes{
+		Words:         concurrentMap{sync.RWMutex{}, make(map[string]Word)},
+		Count:         make([]uint64, classes),
+		Probabilities: make([]float64, classes),
+
+		sanitize:  transform.RemoveFunc(sanitize),
+		stream:    stream,
+		Tokenizer: &SimpleTokenizer{SplitOn: " "},
+
+		Output: os.Stdout,
+	}
+}
+
+// logProbabilities returns the log of the
+// (unnormalized) probability that the given
+// document is each class
+func (b *BernoulliNaiveBayes) logProbabilities(sentence string) []float64 {
+	sums := make([]float64, len(b.Count))
+
+	sentence, _, _ = transform.String(b.sanitize, sentence)
+	present := make(map[string]bool)
+	for _, word := range b.Tokenizer.Tokenize(sentence) {
+		present[word] = true
+	}
+
+	b.Words.RLock()
+	for word, w := range b.Words.words {
+		for i := range sums {
+			p := float64(w.Count[i]+1) / float64(b.Count[i]+2)
+			if present[word] {
+				sums[i] += math.Log(p)
+			} else {
+				sums[i] += math.Log(1 - p)
+			}
+		}
+	}
+	b.Words.RUnlock()
+
+	for i := range sums {
+		sums[i] += math.Log(b.Probabilities[i])
+	}
+
+	return sums
+}
+
+// Predict takes in a document, predicts the
+// class of the document based on the training
+// data passed so far, and returns the class
+// estimated for the document.
+func (b *BernoulliNaiveBayes) Predict(sentence string) uint8 {
+	sums := b.logProbabilities(sentence)
+
+	return uint8(base.ArgMax(sums))
+}
+
+// Probability takes in a document, returns the
+// estimated class of the document based on the
+// model as well as the probability that the
+// document is part of that class.
+//
+// The probabilities are normalized in log space
+// so, unlike the multinomial model, this doesn't
+// underflow on longer documents.
+func (b *BernoulliNaiveBayes) Probability(sentence string) (uint8, float64) {
+	sums := b.logProbabilities(sentence)
+
+	maxI := base.ArgMax(sums)
+
+	var denom float64
+	for i := range sums {
+		denom += math.Exp(sums[i] - sums[maxI])
+	}
+
+	return uint8(maxI), 1 / denom
+}
+
+// OnlineLearn lets the BernoulliNaiveBayes model
+// learn from the datastream, waiting for new data
+// to come into the stream from a separate goroutine.
+//
+// Each word is only counted once per document, no
+// matter how many times it's repeated.
+func (b *BernoulliNaiveBayes) OnlineLearn(errors chan<- error) {
+	b.OnlineLearnContext(context.Background(), errors)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// data stream is still open. This lets you stop
+// training, ie. when shutting down a server, without
+// having to close the stream yourself.
+func (b *BernoulliNaiveBayes) OnlineLearnContext(ctx context.Context, errors chan<- error) {
+	if errors == nil {
+		errors = make(chan error)
+	}
+	if b.stream == nil {
+		errors <- fmt.Errorf("ERROR: attempting to learn with nil data stream!\n")
+		close(errors)
+		return
+	}
+
+	b.logf("Training:\n\tModel: Bernoulli Naïve Bayes\n\tClasses: %v\n", len(b.Count))
+
+	var point base.TextDatapoint
+	var more bool
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.logf("Training Cancelled.\n%v\n\n", b)
+			close(errors)
+			return
+		case point, more = <-b.stream:
+		}
+
+		if more {
+			// sanitize and break up document
+			sanitized, _, _ := transform.String(b.sanitize, point.X)
+			words := b.Tokenizer.Tokenize(sanitized)
+
+			C := int(point.Y)
+
+			if C > len(b.Count)-1 {
+				errors <- fmt.Errorf("ERROR: given document class is greater than the number of classes in the model!\n")
+				continue
+			}
+
+			// update global class probabilities
+			b.Count[C]++
+			b.DocumentCount++
+			for i := range b.Probabilities {
+				b.Probabilities[i] = float64(b.Count[i]) / float64(b.DocumentCount)
+			}
+
+			// only count each word once
+			// per document
+			seen := make(map[string]bool)
+
+			for _, word := range words {
+				if len(word) < 3 || seen[word] {
+					continue
+				}
+				seen[word] = true
+
+				w, ok := b.Words.Get(word)
+
+				if !ok {
+					w = Word{
+						Count: make([]uint64, len(b.Count)),
+						Seen:  uint64(0),
+					}
+
+					b.DictCount++
+				}
+
+				w.Count[C]++
+				w.Seen++
+				w.DocsSeen++
+
+				b.Words.Set(word, w)
+			}
+		} else {
+			b.logf("Training Completed.\n%v\n\n", b)
+			close(errors)
+			return
+		}
+	}
+}
+
+// UpdateStream updates the BernoulliNaiveBayes
+// model's text datastream
+func (b *BernoulliNaiveBayes) UpdateStream(stream chan base.TextDatapoint) {
+	b.stream = stream
+}
+
+// UpdateSanitize updates the BernoulliNaiveBayes
+// model's text sanitization transformation function
+func (b *BernoulliNaiveBayes) UpdateSanitize(sanitize func(rune) bool) {
+	b.sanitize = transform.RemoveFunc(sanitize)
+}
+
+// UpdateTokenizer updates BernoulliNaiveBayes model's
+// tokenizer function. The default implementation will
+// convert the input to lower case and split on the
+// space character.
+func (b *BernoulliNaiveBayes) UpdateTokenizer(tokenizer Tokenizer) {
+	b.Tokenizer = tokenizer
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (b *BernoulliNaiveBayes) logf(format string, a ...interface{}) {
+	if b.Verbose {
+		fmt.Fprintf(b.Output, format, a...)
+	}
+}
+
+// String implements the fmt interface for clean printing. Here
+// we're using it to print the model as the equation h(θ)=...
+// where h is the Bernoulli Naive Bayes hypothesis model.
+func (b *BernoulliNaiveBayes) String() string {
+	return fmt.Sprintf("h(θ) = argmax_c{log(P(y = c)) + Σ_{w∈x}log(P(w|y = c)) + Σ_{w∉x}log(1 - P(w|y = c))}\n\tClasses: %v\n\tDocuments evaluated in model: %v\n\tWords evaluated in model: %v\n", len(b.Count), int(b.DocumentCount), int(b.DictCount))
+}
+
+// PersistToFile takes in an absolute filepath and saves the
+// model to the file, which can be restored later. The
+// function will take paths from the current directory, but
+// functions
+//
+// The data is stored as JSON because it's one of the most
+// efficient storage method (you only need one comma extra
+// per feature + two brackets, total!) And it's extendable.
+func (b *BernoulliNaiveBayes) PersistToFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, bytes, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Restore takes the bytes of a BernoulliNaiveBayes model
+// and restores a model to it. It defaults the sanitizer
+// to base.OnlyWordsAndNumbers and the tokenizer to
+// to a SimpleTokenizer that splits on spaces.
+func (b *BernoulliNaiveBayes) Restore(data []byte) error {
+	return b.RestoreWithFuncs(bytes.NewReader(data), base.OnlyWordsAndNumbers, &SimpleTokenizer{SplitOn: " "})
+}
+
+// RestoreWithFuncs takes raw JSON data of a model and
+// restores a model from it. The tokenizer and sanitizer
+// passed in will be assigned to the restored model.
+func (b *BernoulliNaiveBayes) RestoreWithFuncs(data io.Reader, sanitizer func(rune) bool, tokenizer Tokenizer) error {
+	if b == nil {
+		return errors.New("Cannot restore a model to a nil pointer")
+	}
+	err := json.NewDecoder(data).Decode(b)
+	if err != nil {
+		return err
+	}
+	b.sanitize = transform.RemoveFunc(sanitizer)
+	b.Tokenizer = tokenizer
+	return nil
+}
+
+// RestoreFromFile takes in a path to a persisted model
+// and restores the model it's operating on to it. The
+// only parameters not persisted are the sanitization
+// and tokenization functions which default to
+// base.OnlyWordsAndNumbers and SimpleTokenizer{SplitOn: " "}
+//
+// The path must ba an absolute path or a path from the current
+// directory
+func (b *BernoulliNaiveBayes) RestoreFromFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to restore your model from a file with no path! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	err = b.Restore(bytes)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// persistedBernoulliNaiveBayes holds the fields of a
+// BernoulliNaiveBayes model that are persisted with
+// PersistToGob
+type persistedBernoulliNaiveBayes struct {
+	Words         map[string]Word
+	Count         []uint64
+	Probabilities []float64
+	DocumentCount uint64
+	DictCount     uint64
+}
+
+// PersistToGob saves the model to the given file like
+// PersistToFile, but encoded with encoding/gob (see
+// base.PersistToGob.)
+func (b *BernoulliNaiveBayes) PersistToGob(path string) error {
+	b.Words.RLock()
+	defer b.Words.RUnlock()
+
+	return base.PersistToGob(path, persistedBernoulliNaiveBayes{
+		Words:         b.Words.words,
+		Count:         b.Count,
+		Probabilities: b.Probabilities,
+		DocumentCount: b.DocumentCount,
+		DictCount:     b.DictCount,
+	})
+}
+
+// RestoreFromGob takes in a path to a model saved with
+// PersistToGob and restores the model it's operating on
+// to it. The sanitization and tokenization functions
+// default to base.OnlyWordsAndNumbers and
+// SimpleTokenizer{SplitOn: " "}
+func (b *BernoulliNaiveBayes) RestoreFromGob(path string) error {
+	if b == nil {
+		return errors.New("Cannot restore a model to a nil pointer")
+	}
+
+	var model persistedBernoulliNaiveBayes
+	err := base.RestoreFromGob(path, &model)
+	if err != nil {
+		return err
+	}
+
+	if model.Words == nil {
+		model.Words = make(map[string]Word)
+	}
+
+	b.Words.Lock()
+	b.Words.words = model.Words
+	b.Words.Unlock()
+
+	b.Count = model.Count
+	b.Probabilities = model.Probabilities
+	b.DocumentCount = model.DocumentCount
+	b.DictCount = model.DictCount
+
+	b.sanitize = transform.RemoveFunc(base.OnlyWordsAndNumbers)
+	b.Tokenizer = &SimpleTokenizer{SplitOn: " "}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package text
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cdipaolo/goml/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBernoulliClassificationShouldPass1(t *testing.T) {
+	// create the channel of data and errors
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewBernoulliNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "I love the city",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "I hate Los Angeles",
+		Y: 0,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "My mother is not a nice lady",
+		Y: 0,
+	}
+
+	close(stream)
+
+	for {
+		err, more := <-errors
+		if more {
+			fmt.Printf("Error passed: %v", err)
+		} else {
+			// training is done!
+			break
+		}
+	}
+
+	// now you can predict like normal
+	class := model.Predict("My mo~~~ther is in Los Angeles") // 0
+	assert.EqualValues(t, 0, class, "Class should be 0")
+
+	class, p := model.Probability("Mother Los Angeles")
+	assert.EqualValues(t, 0, class, "Class should be 0")
+	assert.True(t, p > 0.75, "There should be a greater than 75 percent chance the document is negative - Given %v", p)
+
+	class, p = model.Probability("love the CiTy")
+	assert.EqualValues(t, 1, class, "Class should be 1")
+	assert.True(t, p > 0.75, "There should be a greater than 75 percent chance the document is positive - Given %v", p)
+}
+
+// repeating a word within a document shouldn't
+// count as more evidence than using it once
+func TestBernoulliRepeatedWordsShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewBernoulliNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "great great great great great movie",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "great film",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "terrible movie",
+		Y: 0,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "boring film",
+		Y: 0,
+	}
+
+	close(stream)
+
+	for {
+		_, more := <-errors
+		if !more {
+			break
+		}
+	}
+
+	w, ok := model.Words.Get("great")
+	assert.True(t, ok, "'great' should be in the vocabulary")
+	assert.EqualValues(t, []uint64{0, 2}, w.Count, "'great' should only be counted once for each document")
+	assert.EqualValues(t, 2, w.DocsSeen, "'great' should have been seen in 2 documents")
+
+	_, p1 := model.Probability("great movie")
+	_, p2 := model.Probability("great great great movie")
+	assert.InDelta(t, p1, p2, 1e-12, "Repeating a word shouldn't change the probability")
+
+	// absent words count as evidence too, so leaving
+	// out 'great' (which every class 1 document has)
+	// points towards class 0
+	class := model.Predict("movie")
+	assert.EqualValues(t, 0, class, "A document without 'great' should be class 0")
+}
+
+func TestBernoulliClassificationShouldFail1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error, 10)
+
+	model := NewBernoulliNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "this class doesn't exist",
+		Y: 4,
+	}
+
+	close(stream)
+
+	var count int
+	for {
+		_, more := <-errors
+		if more {
+			count++
+		} else {
+			break
+		}
+	}
+
+	assert.Equal(t, 1, count, "There should have been an error passed for the invalid class")
+	assert.EqualValues(t, 0, model.DocumentCount, "The document with an invalid class shouldn't be learned")
+
+	// nil data stream
+	model = NewBernoulliNaiveBayes(nil, 2, base.OnlyWordsAndNumbers)
+	errors = make(chan error, 10)
+	model.OnlineLearn(errors)
+
+	err := <-errors
+	assert.NotNil(t, err, "Learning with a nil stream should return an error")
+}
+
+func TestPersistBernoulliNaiveBayesShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewBernoulliNaiveBayes(stream, 3, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "I love the city",
+		Y: 0,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "I hate Los Angeles",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "My mother is not a nice lady",
+		Y: 1,
+	}
+
+	close(stream)
+
+	for {
+		_, more := <-errors
+		if !more {
+			break
+		}
+	}
+
+	class := model.Predict("My mother is in Los Angeles")
+	assert.EqualValues(t, 1, class, "Class should be 1")
+
+	// now persist to file
+	err := model.PersistToFile("/tmp/.goml/BernoulliNaiveBayes.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	// reset model
+	model = NewBernoulliNaiveBayes(stream, 3, base.OnlyWordsAndNumbers)
+
+	class = model.Predict("My mother is in Los Angeles")
+	assert.EqualValues(t, 0, class, "Class should be 0")
+
+	// restore from file
+	err = model.RestoreFromFile("/tmp/.goml/BernoulliNaiveBayes.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	class = model.Predict("My mother is in Los Angeles")
+	assert.EqualValues(t, 1, class, "Class should be 1")
+
+	// the gob format should restore the same model
+	err = model.PersistToGob("/tmp/.goml/BernoulliNaiveBayes.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	fromGob := NewBernoulliNaiveBayes(stream, 3, base.OnlyWordsAndNumbers)
+	err = fromGob.RestoreFromGob("/tmp/.goml/BernoulliNaiveBayes.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+	assert.Equal(t, model.Words.words, fromGob.Words.words, "Gob should restore the same vocabulary")
+	assert.Equal(t, model.Count, fromGob.Count, "Gob should restore the same class counts")
+
+	class = fromGob.Predict("My mother is in Los Angeles")
+	assert.EqualValues(t, 1, class, "Class should be 1")
+
+	assert.NotNil(t, model.PersistToFile(""), "Persisting to an empty path should return an error")
+	assert.NotNil(t, model.RestoreFromFile(""), "Restoring from an empty path should return an error")
+	assert.NotNil(t, model.PersistToGob(""), "Persisting to an empty path should return an error")
+	assert.NotNil(t, model.RestoreFromGob(""), "Restoring from an empty path should return an error")
+}
@@ -0,0 +1,472 @@
+package text
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sync"
+
+	"golang.org/x/text/transform"
+
+	"github.com/cdipaolo/goml/base"
+)
+
+/*
+ComplementNaiveBayes is a Naive Bayes text
+classifier which, instead of estimating how
+likely each word is within a class, estimates
+how likely it is within the _complement_ of
+each class (every other class.) Because each
+complement is estimated from the documents of
+all the other classes, it has much more data
+to work with for the small classes, so the
+model isn't skewed towards the classes with
+the most training documents like the
+multinomial NaiveBayes model is. This makes
+it a much better fit for imbalanced datasets
+(like spam filtering, where most mail is ham.)
+
+For each class c and word w the model
+estimates (with Laplace smoothing) the
+weight
+	w(c, w) = log((N(~c, w) + 1) / (N(~c) + |V|))
+where N(~c, w) is the number of times w was
+seen in documents not of class c, N(~c) is
+the number of words seen in documents not of
+class c, and |V| is the size of the
+vocabulary. The weights of each class are
+normalized by their sum of absolute values
+so longer or more frequent classes don't
+dominate, and a document x is classified as
+the class it is _least_ like the complement of
+	Class(x) = argmin_c{Σ_{w∈x} w(c, w)}
+where the sum counts repeated words.
+
+This is the weight-normalized Complement
+Naive Bayes (WCNB) model of Rennie et al,
+without the TF-IDF transforms of the data.
+
+http://people.csail.mit.edu/jrennie/papers/icml03-nb.pdf
+
+Example Online Complement Naive Bayes Text Classifier:
+
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewComplementNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "I love the city",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "I hate Los Angeles",
+		Y: 0,
+	}
+
+	close(stream)
+
+	for {
+		err, more := <-errors
+		if more {
+			fmt.Printf("Error passed: %v", err)
+		} else {
+			// training is done!
+			break
+		}
+	}
+
+	// now you can predict like normal
+	class := model.Predict("My mother is in Los Angeles") // 0
+*/
+type ComplementNaiveBayes struct {
+	// Words holds a map of words
+	// to their corresponding Word
+	// structure
+	Words concurrentMap `json:"words"`
+
+	// Count holds the number of times
+	// class i was seen as Count[i]
+	Count []uint64 `json:"count"`
+
+	// WordCount holds the number of
+	// words seen in documents of class
+	// i as WordCount[i]
+	WordCount []uint64 `json:"word_count"`
+
+	// DocumentCount holds the number of
+	// documents that have been seen
+	DocumentCount uint64 `json:"document_count"`
+
+	// DictCount holds the size of the
+	// model's vocabulary
+	DictCount uint64 `json:"vocabulary_size"`
+
+	// sanitize is used by a model
+	// to sanitize input of text
+	sanitize transform.Transformer
+
+	// stream holds the datastream
+	stream <-chan base.TextDatapoint
+
+	// tokenizer is used by a model
+	// to split the input into tokens
+	Tokenizer Tokenizer `json:"tokenizer"`
+
+	// Verbose turns on logging training progress
+	// to Output. Defaults to false, so the model
+	// is silent unless you ask for its logs.
+	Verbose bool `json:"-"`
+
+	// Output is the io.Writer used for logging
+	// and printing. Defaults to os.Stdout.
+	Output io.Writer `json:"-"`
+}
+
+// NewComplementNaiveBayes returns a ComplementNaiveBayes
+// model with the given number of classes instantiated,
+// ready to learn off the given data stream. The
+// sanitization function is set to the given function.
+// It must comply with the transform.RemoveFunc interface
+func NewComplementNaiveBayes(stream <-chan base.TextDatapoint, classes uint8, sanitize func(rune) bool) *ComplementNaiveBayes {
+	return &ComplementNaiveBayes{
+		Words:     concurrentMap{sync.RWMutex{}, make(map[string]Word)},
+		Count:     make([]uint64, classes),
+		WordCount: make([]uint64, classes),
+
+		sanitize:  transform.RemoveFunc(sanitize),
+		stream:    stream,
+		Tokenizer: &SimpleTokenizer{SplitOn: " "},
+
+		Output: os.Stdout,
+	}
+}
+
+// scores returns the normalized complement
+// score of the given document for each class.
+// The lowest score is the best class.
+func (b *ComplementNaiveBayes) scores(sentence string) []float64 {
+	sums := make([]float64, len(b.Count))
+	norms := make([]float64, len(b.Count))
+
+	sentence, _, _ = transform.String(b.sanitize, sentence)
+	counts := make(map[string]float64)
+	for _, word := range b.Tokenizer.Tokenize(sentence) {
+		counts[word]++
+	}
+
+	var total uint64
+	for i := range b.WordCount {
+		total += b.WordCount[i]
+	}
+
+	b.Words.RLock()
+	vocabulary := float64(len(b.Words.words))
+	for word, w := range b.Words.words {
+		n := counts[word]
+		for i := range sums {
+			weight := math.Log(float64(w.Seen-w.Count[i]+1) / (float64(total-b.WordCount[i]) + vocabulary))
+
+			norms[i] += math.Abs(weight)
+			sums[i] += n * weight
+		}
+	}
+	b.Words.RUnlock()
+
+	for i := range sums {
+		if norms[i] != 0 {
+			sums[i] /= norms[i]
+		}
+	}
+
+	return sums
+}
+
+// Predict takes in a document, predicts the
+// class of the document based on the training
+// data passed so far, and returns the class
+// estimated for the document.
+func (b *ComplementNaiveBayes) Predict(sentence string) uint8 {
+	sums := b.scores(sentence)
+
+	// find the class least like
+	// each complement
+	var minI int
+	for i := range sums {
+		if sums[i] < sums[minI] {
+			minI = i
+		}
+	}
+
+	return uint8(minI)
+}
+
+// OnlineLearn lets the ComplementNaiveBayes model
+// learn from the datastream, waiting for new data
+// to come into the stream from a separate goroutine.
+func (b *ComplementNaiveBayes) OnlineLearn(errors chan<- error) {
+	b.OnlineLearnContext(context.Background(), errors)
+}
+
+// OnlineLearnContext is the same as OnlineLearn, but
+// also stops learning (closing the errors channel)
+// when the given context is cancelled, even if the
+// data stream is still open. This lets you stop
+// training, ie. when shutting down a server, without
+// having to close the stream yourself.
+func (b *ComplementNaiveBayes) OnlineLearnContext(ctx context.Context, errors chan<- error) {
+	if errors == nil {
+		errors = make(chan error)
+	}
+	if b.stream == nil {
+		errors <- fmt.Errorf("ERROR: attempting to learn with nil data stream!\n")
+		close(errors)
+		return
+	}
+
+	b.logf("Training:\n\tModel: Complement Naïve Bayes\n\tClasses: %v\n", len(b.Count))
+
+	var point base.TextDatapoint
+	var more bool
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.logf("Training Cancelled.\n%v\n\n", b)
+			close(errors)
+			return
+		case point, more = <-b.stream:
+		}
+
+		if more {
+			// sanitize and break up document
+			sanitized, _, _ := transform.String(b.sanitize, point.X)
+			words := b.Tokenizer.Tokenize(sanitized)
+
+			C := int(point.Y)
+
+			if C > len(b.Count)-1 {
+				errors <- fmt.Errorf("ERROR: given document class is greater than the number of classes in the model!\n")
+				continue
+			}
+
+			b.Count[C]++
+			b.DocumentCount++
+
+			// store words seen in document (to add to DocsSeen)
+			seen := make(map[string]bool)
+
+			for _, word := range words {
+				if len(word) < 3 {
+					continue
+				}
+
+				w, ok := b.Words.Get(word)
+
+				if !ok {
+					w = Word{
+						Count: make([]uint64, len(b.Count)),
+						Seen:  uint64(0),
+					}
+
+					b.DictCount++
+				}
+
+				w.Count[C]++
+				w.Seen++
+				if !seen[word] {
+					w.DocsSeen++
+					seen[word] = true
+				}
+
+				b.Words.Set(word, w)
+				b.WordCount[C]++
+			}
+		} else {
+			b.logf("Training Completed.\n%v\n\n", b)
+			close(errors)
+			return
+		}
+	}
+}
+
+// UpdateStream updates the ComplementNaiveBayes
+// model's text datastream
+func (b *ComplementNaiveBayes) UpdateStream(stream chan base.TextDatapoint) {
+	b.stream = stream
+}
+
+// UpdateSanitize updates the ComplementNaiveBayes
+// model's text sanitization transformation function
+func (b *ComplementNaiveBayes) UpdateSanitize(sanitize func(rune) bool) {
+	b.sanitize = transform.RemoveFunc(sanitize)
+}
+
+// UpdateTokenizer updates ComplementNaiveBayes model's
+// tokenizer function. The default implementation will
+// convert the input to lower case and split on the
+// space character.
+func (b *ComplementNaiveBayes) UpdateTokenizer(tokenizer Tokenizer) {
+	b.Tokenizer = tokenizer
+}
+
+// logf prints training progress to Output
+// when the model is Verbose
+func (b *ComplementNaiveBayes) logf(format string, a ...interface{}) {
+	if b.Verbose {
+		fmt.Fprintf(b.Output, format, a...)
+	}
+}
+
+// String implements the fmt interface for clean printing. Here
+// we're using it to print the model as the equation h(θ)=...
+// where h is the Complement Naive Bayes hypothesis model.
+func (b *ComplementNaiveBayes) String() string {
+	return fmt.Sprintf("h(θ) = argmin_c{Σ_{w∈x}log(P(w|y ≠ c)) / Σ_w|log(P(w|y ≠ c))|}\n\tClasses: %v\n\tDocuments evaluated in model: %v\n\tWords evaluated in model: %v\n", len(b.Count), int(b.DocumentCount), int(b.DictCount))
+}
+
+// PersistToFile takes in an absolute filepath and saves the
+// model to the file, which can be restored later. The
+// function will take paths from the current directory, but
+// functions
+//
+// The data is stored as JSON because it's one of the most
+// efficient storage method (you only need one comma extra
+// per feature + two brackets, total!) And it's extendable.
+func (b *ComplementNaiveBayes) PersistToFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to persist your model to a file with no path!! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, bytes, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Restore takes the bytes of a ComplementNaiveBayes model
+// and restores a model to it. It defaults the sanitizer
+// to base.OnlyWordsAndNumbers and the tokenizer to
+// to a SimpleTokenizer that splits on spaces.
+func (b *ComplementNaiveBayes) Restore(data []byte) error {
+	return b.RestoreWithFuncs(bytes.NewReader(data), base.OnlyWordsAndNumbers, &SimpleTokenizer{SplitOn: " "})
+}
+
+// RestoreWithFuncs takes raw JSON data of a model and
+// restores a model from it. The tokenizer and sanitizer
+// passed in will be assigned to the restored model.
+func (b *ComplementNaiveBayes) RestoreWithFuncs(data io.Reader, sanitizer func(rune) bool, tokenizer Tokenizer) error {
+	if b == nil {
+		return errors.New("Cannot restore a model to a nil pointer")
+	}
+	err := json.NewDecoder(data).Decode(b)
+	if err != nil {
+		return err
+	}
+	b.sanitize = transform.RemoveFunc(sanitizer)
+	b.Tokenizer = tokenizer
+	return nil
+}
+
+// RestoreFromFile takes in a path to a persisted model
+// and restores the model it's operating on to it. The
+// only parameters not persisted are the sanitization
+// and tokenization functions which default to
+// base.OnlyWordsAndNumbers and SimpleTokenizer{SplitOn: " "}
+//
+// The path must ba an absolute path or a path from the current
+// directory
+func (b *ComplementNaiveBayes) RestoreFromFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("ERROR: you just tried to restore your model from a file with no path! That's a no-no. Try it with a valid filepath")
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	err = b.Restore(bytes)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// persistedComplementNaiveBayes holds the fields of a
+// ComplementNaiveBayes model that are persisted with
+// PersistToGob
+type persistedComplementNaiveBayes struct {
+	Words         map[string]Word
+	Count         []uint64
+	WordCount     []uint64
+	DocumentCount uint64
+	DictCount     uint64
+}
+
+// PersistToGob saves the model to the given file like
+// PersistToFile, but encoded with encoding/gob (see
+// base.PersistToGob.)
+func (b *ComplementNaiveBayes) PersistToGob(path string) error {
+	b.Words.RLock()
+	defer b.Words.RUnlock()
+
+	return base.PersistToGob(path, persistedComplementNaiveBayes{
+		Words:         b.Words.words,
+		Count:         b.Count,
+		WordCount:     b.WordCount,
+		DocumentCount: b.DocumentCount,
+		DictCount:     b.DictCount,
+	})
+}
+
+// RestoreFromGob takes in a path to a model saved with
+// PersistToGob and restores the model it's operating on
+// to it. The sanitization and tokenization functions
+// default to base.OnlyWordsAndNumbers and
+// SimpleTokenizer{SplitOn: " "}
+func (b *ComplementNaiveBayes) RestoreFromGob(path string) error {
+	if b == nil {
+		return errors.New("Cannot restore a model to a nil pointer")
+	}
+
+	var model persistedComplementNaiveBayes
+	err := base.RestoreFromGob(path, &model)
+	if err != nil {
+		return err
+	}
+
+	if model.Words == nil {
+		model.Words = make(map[string]Word)
+	}
+
+	b.Words.Lock()
+	b.Words.words = model.Words
+	b.Words.Unlock()
+
+	b.Count = model.Count
+	b.WordCount = model.WordCount
+	b.DocumentCount = model.DocumentCount
+	b.DictCount = model.DictCount
+
+	b.sanitize = transform.RemoveFunc(base.OnlyWordsAndNumbers)
+	b.Tokenizer = &SimpleTokenizer{SplitOn: " "}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package text
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/cdipaolo/goml/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplementClassificationShouldPass1(t *testing.T) {
+	// create the channel of data and errors
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewComplementNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "I love the city",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "I hate Los Angeles",
+		Y: 0,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "My mother is not a nice lady",
+		Y: 0,
+	}
+
+	close(stream)
+
+	for {
+		err, more := <-errors
+		if more {
+			fmt.Printf("Error passed: %v", err)
+		} else {
+			// training is done!
+			break
+		}
+	}
+
+	assert.EqualValues(t, 3, model.DocumentCount, "There should have been 3 documents learned")
+	assert.EqualValues(t, 2, model.Count[0], "There should have been 2 documents of class 0")
+	assert.EqualValues(t, []uint64{7, 3}, model.WordCount, "The words of each class should be counted")
+
+	// now you can predict like normal
+	class := model.Predict("My mo~~~ther is in Los Angeles") // 0
+	assert.EqualValues(t, 0, class, "Class should be 0")
+
+	class = model.Predict("love the CiTy")
+	assert.EqualValues(t, 1, class, "Class should be 1")
+}
+
+// the multinomial model leans towards the class
+// with the most documents, so with a 10:1 class
+// imbalance it labels every spam document as ham,
+// while the complement model shouldn't
+func TestComplementImbalancedShouldPass1(t *testing.T) {
+	ham := []string{"meeting", "project", "report", "lunch", "schedule", "review", "deadline", "budget", "team", "client"}
+	spam := []string{"free", "money", "winner", "prize", "cash"}
+
+	train := []base.TextDatapoint{}
+	for i := 0; i < 20; i++ {
+		train = append(train, base.TextDatapoint{
+			X: fmt.Sprintf("%v %v %v %v", ham[i%10], ham[(i+3)%10], ham[(i+7)%10], ham[(i+1)%10]),
+			Y: 0,
+		})
+	}
+	for i := 0; i < 2; i++ {
+		train = append(train, base.TextDatapoint{
+			X: fmt.Sprintf("%v %v %v", spam[i], spam[i+1], spam[i+2]),
+			Y: 1,
+		})
+	}
+
+	test := []base.TextDatapoint{}
+	for i := 0; i < 10; i++ {
+		test = append(test, base.TextDatapoint{
+			X: fmt.Sprintf("%v %v %v", ham[i], ham[(i+5)%10], ham[(i+2)%10]),
+			Y: 0,
+		})
+		test = append(test, base.TextDatapoint{
+			X: fmt.Sprintf("%v %v %v", spam[i%5], spam[(i+2)%5], ham[i]),
+			Y: 1,
+		})
+	}
+
+	multinomialStream := make(chan base.TextDatapoint, 100)
+	multinomial := NewNaiveBayes(multinomialStream, 2, base.OnlyWordsAndNumbers)
+	multinomial.Output = ioutil.Discard
+
+	complementStream := make(chan base.TextDatapoint, 100)
+	complement := NewComplementNaiveBayes(complementStream, 2, base.OnlyWordsAndNumbers)
+	complement.Output = ioutil.Discard
+
+	multinomialErrors := make(chan error)
+	complementErrors := make(chan error)
+
+	go multinomial.OnlineLearn(multinomialErrors)
+	go complement.OnlineLearn(complementErrors)
+
+	for _, point := range train {
+		multinomialStream <- point
+		complementStream <- point
+	}
+
+	close(multinomialStream)
+	close(complementStream)
+
+	for range multinomialErrors {
+		t.Errorf("There shouldn't be an error learning the multinomial model")
+	}
+	for range complementErrors {
+		t.Errorf("There shouldn't be an error learning the complement model")
+	}
+
+	var multinomialCorrect, complementCorrect int
+	for _, point := range test {
+		if multinomial.Predict(point.X) == point.Y {
+			multinomialCorrect++
+		}
+		if complement.Predict(point.X) == point.Y {
+			complementCorrect++
+		}
+	}
+
+	assert.Equal(t, len(test), complementCorrect, "The complement model should classify every test document correctly")
+	assert.True(t, complementCorrect > multinomialCorrect, "The complement model should beat the multinomial model on imbalanced data - Given %v vs %v", complementCorrect, multinomialCorrect)
+}
+
+func TestComplementClassificationShouldFail1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error, 10)
+
+	model := NewComplementNaiveBayes(stream, 2, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "this class doesn't exist",
+		Y: 4,
+	}
+
+	close(stream)
+
+	var count int
+	for {
+		_, more := <-errors
+		if more {
+			count++
+		} else {
+			break
+		}
+	}
+
+	assert.Equal(t, 1, count, "There should have been an error passed for the invalid class")
+	assert.EqualValues(t, 0, model.DocumentCount, "The document with an invalid class shouldn't be learned")
+
+	// nil data stream
+	model = NewComplementNaiveBayes(nil, 2, base.OnlyWordsAndNumbers)
+	errors = make(chan error, 10)
+	model.OnlineLearn(errors)
+
+	err := <-errors
+	assert.NotNil(t, err, "Learning with a nil stream should return an error")
+}
+
+func TestPersistComplementNaiveBayesShouldPass1(t *testing.T) {
+	stream := make(chan base.TextDatapoint, 100)
+	errors := make(chan error)
+
+	model := NewComplementNaiveBayes(stream, 3, base.OnlyWordsAndNumbers)
+
+	go model.OnlineLearn(errors)
+
+	stream <- base.TextDatapoint{
+		X: "I love the city",
+		Y: 0,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "I hate Los Angeles",
+		Y: 1,
+	}
+
+	stream <- base.TextDatapoint{
+		X: "My mother is not a nice lady",
+		Y: 1,
+	}
+
+	close(stream)
+
+	for {
+		_, more := <-errors
+		if !more {
+			break
+		}
+	}
+
+	class := model.Predict("My mother is in Los Angeles")
+	assert.EqualValues(t, 1, class, "Class should be 1")
+
+	// now persist to file
+	err := model.PersistToFile("/tmp/.goml/ComplementNaiveBayes.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	// reset model
+	model = NewComplementNaiveBayes(stream, 3, base.OnlyWordsAndNumbers)
+
+	class = model.Predict("My mother is in Los Angeles")
+	assert.EqualValues(t, 0, class, "Class should be 0")
+
+	// restore from file
+	err = model.RestoreFromFile("/tmp/.goml/ComplementNaiveBayes.json")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	class = model.Predict("My mother is in Los Angeles")
+	assert.EqualValues(t, 1, class, "Class should be 1")
+
+	// the gob format should restore the same model
+	err = model.PersistToGob("/tmp/.goml/ComplementNaiveBayes.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+
+	fromGob := NewComplementNaiveBayes(stream, 3, base.OnlyWordsAndNumbers)
+	err = fromGob.RestoreFromGob("/tmp/.goml/ComplementNaiveBayes.gob")
+	assert.Nil(t, err, "Persistance error should be nil")
+	assert.Equal(t, model.Words.words, fromGob.Words.words, "Gob should restore the same vocabulary")
+	assert.Equal(t, model.WordCount, fromGob.WordCount, "Gob should restore the same word counts")
+
+	class = fromGob.Predict("My mother is in Los Angeles")
+	assert.EqualValues(t, 1, class, "Class should be 1")
+
+	assert.NotNil(t, model.PersistToFile(""), "Persisting to an empty path should return an error")
+	assert.NotNil(t, model.RestoreFromFile(""), "Restoring from an empty path should return an error")
+}